@@ -1,4 +1,7 @@
-use once_cell::sync::Lazy;
+use once_cell::sync::{Lazy, OnceCell};
+use std::collections::HashMap;
+use std::sync::atomic::{AtomicU32, Ordering};
+use std::sync::RwLock;
 
 #[cfg(not(target_arch = "wasm32"))]
 #[allow(dead_code)]
@@ -53,3 +56,269 @@ pub static EXTERNAL_SORTING_BUFFER_LIMIT: Lazy<usize> =
 
 /// The number of records that should be fetched and grouped together in an INSERT statement when exporting.
 pub static EXPORT_BATCH_SIZE: Lazy<u32> = lazy_env_parse!("SURREAL_EXPORT_BATCH_SIZE", u32, 1000);
+
+/// The maximum number of queries which can be run against a single namespace each second (0 disables the limit).
+pub static NAMESPACE_MAX_QUERIES_PER_SECOND: Dynamic =
+	Dynamic::new("SURREAL_NAMESPACE_MAX_QUERIES_PER_SECOND", 0);
+
+/// The maximum number of records which can be stored in a single table (0 disables the limit).
+pub static TABLE_MAX_RECORDS: Dynamic = Dynamic::new("SURREAL_TABLE_MAX_RECORDS", 0);
+
+/// The maximum size in MiB of the record data stored in a single database (0 disables the limit).
+pub static DATABASE_MAX_STORAGE_MB: Dynamic = Dynamic::new("SURREAL_DATABASE_MAX_STORAGE_MB", 0);
+
+/// The time in milliseconds after which a statement is logged as a slow query (0 disables the log).
+pub static SLOW_QUERY_THRESHOLD: Dynamic = Dynamic::new("SURREAL_SLOW_QUERY_THRESHOLD", 0);
+
+/// Whether the resources consumed by each namespace should be metered.
+pub static NAMESPACE_USAGE_METERING: Lazy<bool> =
+	lazy_env_parse!("SURREAL_NAMESPACE_USAGE_METERING", bool, false);
+
+/// Whether authentication attempts and data-changing statements should be recorded in the audit log.
+pub static AUDIT_LOG: Lazy<bool> = lazy_env_parse!("SURREAL_AUDIT_LOG", bool, false);
+
+/// The maximum number of bytes of subquery results which are reused within a single statement.
+pub static SUBQUERY_CACHE_SIZE: Lazy<usize> =
+	lazy_env_parse!("SURREAL_SUBQUERY_CACHE_SIZE", usize, 16 * 1024 * 1024);
+
+/// The number of table permission clauses which are shared between transactions.
+pub static PERMISSION_CACHE_SIZE: Lazy<usize> =
+	lazy_env_parse!("SURREAL_PERMISSION_CACHE_SIZE", usize, 1000);
+
+/// The maximum time in milliseconds to wait for an outbound HTTP request (0 disables the limit).
+pub static HTTP_REQUEST_TIMEOUT: Lazy<u64> =
+	lazy_env_parse!("SURREAL_HTTP_REQUEST_TIMEOUT", u64, 0);
+
+/// The maximum size in bytes of an outbound HTTP response body (0 disables the limit).
+pub static HTTP_MAX_RESPONSE_SIZE: Lazy<usize> =
+	lazy_env_parse!("SURREAL_HTTP_MAX_RESPONSE_SIZE", usize, 0);
+
+/// The maximum stack size in bytes of an embedded JavaScript function.
+pub static SCRIPTING_MAX_STACK_SIZE: Lazy<usize> =
+	lazy_env_parse!("SURREAL_SCRIPTING_MAX_STACK_SIZE", usize, 256 * 1024);
+
+/// The maximum memory in bytes which an embedded JavaScript function can allocate.
+pub static SCRIPTING_MAX_MEMORY_LIMIT: Lazy<usize> =
+	lazy_env_parse!("SURREAL_SCRIPTING_MAX_MEMORY_LIMIT", usize, 2_000_000);
+
+/// The maximum time in milliseconds for which an embedded JavaScript function can run (0 disables the limit).
+pub static SCRIPTING_MAX_TIME_LIMIT: Lazy<u64> =
+	lazy_env_parse!("SURREAL_SCRIPTING_MAX_TIME_LIMIT", u64, 0);
+
+/// Seeds random functions and generated record ids deterministically for each query or session, for reproducible tests only (0 disables seeding).
+/// The generated values are predictable, so this must never be used in production.
+pub static RANDOM_SEED: Lazy<u64> = lazy_env_parse!("SURREAL_RANDOM_SEED", u64, 0);
+
+/// The maximum number of bytes which the results of all running queries can retain in memory (0 disables the budget).
+pub static MEMORY_BUDGET: Lazy<usize> = lazy_env_parse!("SURREAL_MEMORY_BUDGET", usize, 0);
+
+/// The memory cost in KiB used when hashing passwords with Argon2id (defaults to 19 MiB).
+pub static ARGON2_MEMORY_COST: Lazy<u32> =
+	lazy_env_parse!("SURREAL_ARGON2_MEMORY_COST", u32, 19 * 1024);
+
+/// The number of iterations used when hashing passwords with Argon2id.
+pub static ARGON2_TIME_COST: Lazy<u32> = lazy_env_parse!("SURREAL_ARGON2_TIME_COST", u32, 2);
+
+/// The degree of parallelism used when hashing passwords with Argon2id.
+pub static ARGON2_PARALLELISM: Lazy<u32> = lazy_env_parse!("SURREAL_ARGON2_PARALLELISM", u32, 1);
+
+/// The settings which are used in place of the environment variables, once
+/// they have been loaded from a configuration file.
+static SETTINGS: RwLock<Option<HashMap<String, String>>> = RwLock::new(None);
+
+/// Use the specified settings in place of the environment variables, and
+/// reload the settings which can be changed while the server is running.
+///
+/// The settings should already include any values which were specified in
+/// the environment, as the environment is no longer read once settings have
+/// been specified. This allows the settings to be changed at runtime without
+/// modifying the environment of the running process.
+pub fn set_settings(settings: HashMap<String, String>) {
+	*SETTINGS.write().unwrap_or_else(|e| e.into_inner()) = Some(settings);
+	reload();
+}
+
+/// Get the value of a setting, from the specified settings if any, or
+/// otherwise from the environment.
+pub fn setting(key: &str) -> Option<String> {
+	match SETTINGS.read().unwrap_or_else(|e| e.into_inner()).as_ref() {
+		Some(settings) => settings.get(key).cloned(),
+		None => std::env::var(key).ok(),
+	}
+}
+
+/// A numeric setting which is parsed from an environment variable, and which
+/// can be parsed again to change its value while the server is running.
+pub struct Dynamic {
+	key: &'static str,
+	default: u32,
+	value: OnceCell<AtomicU32>,
+}
+
+impl Dynamic {
+	/// Create a setting with the specified environment variable and default value
+	pub const fn new(key: &'static str, default: u32) -> Self {
+		Self {
+			key,
+			default,
+			value: OnceCell::new(),
+		}
+	}
+
+	/// Get the current value of this setting
+	pub fn get(&self) -> u32 {
+		self.value.get_or_init(|| AtomicU32::new(self.parse())).load(Ordering::Relaxed)
+	}
+
+	/// Parse the value of this setting from the current settings again
+	pub fn reload(&self) {
+		self.value
+			.get_or_init(|| AtomicU32::new(self.parse()))
+			.store(self.parse(), Ordering::Relaxed)
+	}
+
+	/// Check that the value of this setting in the specified settings is valid
+	pub fn check(&self, settings: &HashMap<String, String>) -> Result<(), String> {
+		match settings.get(self.key) {
+			Some(v) if v.parse::<u32>().is_err() => {
+				Err(format!("The setting `{}` must be a positive integer, found `{v}`", self.key))
+			}
+			_ => Ok(()),
+		}
+	}
+
+	fn parse(&self) -> u32 {
+		setting(self.key).and_then(|v| v.parse().ok()).unwrap_or(self.default)
+	}
+}
+
+/// The quotas which apply to specific namespaces and databases in place of the defaults.
+static QUOTAS: RwLock<Vec<Quota>> = RwLock::new(Vec::new());
+
+/// The quotas which apply to a namespace, or to a single database within a
+/// namespace, in place of the default quotas. Any quota which is not set
+/// falls back to the quota for the namespace, and then to the default.
+#[derive(Clone, Debug, Default, Eq, PartialEq)]
+#[non_exhaustive]
+pub struct Quota {
+	/// The namespace which these quotas apply to
+	pub ns: String,
+	/// The database which these quotas apply to, or every database if not set
+	pub db: Option<String>,
+	/// The maximum number of queries which can be run against the namespace each second
+	pub queries_per_second: Option<u32>,
+	/// The maximum number of records which can be stored in a single table
+	pub table_max_records: Option<u32>,
+	/// The maximum size in MiB of the record data stored in a single database
+	pub storage_max_mb: Option<u32>,
+}
+
+impl Quota {
+	/// Create quotas for the specified namespace, and optionally a single database
+	pub fn new(ns: impl Into<String>, db: Option<String>) -> Self {
+		Self {
+			ns: ns.into(),
+			db,
+			..Default::default()
+		}
+	}
+}
+
+/// Replace the quotas which apply to specific namespaces and databases.
+pub fn set_quotas(quotas: Vec<Quota>) {
+	*QUOTAS.write().unwrap_or_else(|e| e.into_inner()) = quotas;
+}
+
+/// Get the quota which applies to the specified namespace and database,
+/// preferring a quota for the database, then a quota for the namespace,
+/// and then the default value of the specified setting.
+pub(crate) fn quota<F>(ns: &str, db: Option<&str>, default: &Dynamic, get: F) -> u32
+where
+	F: Fn(&Quota) -> Option<u32>,
+{
+	let quotas = QUOTAS.read().unwrap_or_else(|e| e.into_inner());
+	let mut found = None;
+	for quota in quotas.iter().filter(|q| q.ns == ns) {
+		match (quota.db.as_deref(), db) {
+			// A quota for this database takes precedence
+			(Some(a), Some(b)) if a == b => {
+				if let Some(v) = get(quota) {
+					return v;
+				}
+			}
+			// A quota for the namespace applies otherwise
+			(None, _) => found = found.or_else(|| get(quota)),
+			_ => (),
+		}
+	}
+	found.unwrap_or_else(|| default.get())
+}
+
+/// Check the settings which can be changed while the server is running,
+/// so that invalid settings can be rejected before any are applied.
+pub fn check(settings: &HashMap<String, String>) -> Result<(), String> {
+	NAMESPACE_MAX_QUERIES_PER_SECOND.check(settings)?;
+	TABLE_MAX_RECORDS.check(settings)?;
+	DATABASE_MAX_STORAGE_MB.check(settings)?;
+	SLOW_QUERY_THRESHOLD.check(settings)
+}
+
+/// Reload the settings which can be changed while the server is running.
+pub fn reload() {
+	NAMESPACE_MAX_QUERIES_PER_SECOND.reload();
+	TABLE_MAX_RECORDS.reload();
+	DATABASE_MAX_STORAGE_MB.reload();
+	SLOW_QUERY_THRESHOLD.reload();
+}
+
+#[cfg(test)]
+mod tests {
+	use super::{quota, set_quotas, set_settings, Dynamic, Quota};
+	use std::collections::HashMap;
+
+	#[test]
+	fn quotas_are_scoped() {
+		static DEFAULT: Dynamic = Dynamic::new("SURREAL_TEST_QUOTA_SETTING", 5);
+		let mut ns = Quota::new("test", None);
+		ns.table_max_records = Some(10);
+		let mut db = Quota::new("test", Some("test".to_owned()));
+		db.table_max_records = Some(20);
+		set_quotas(vec![db, ns]);
+		let get = |q: &Quota| q.table_max_records;
+		assert_eq!(quota("test", Some("test"), &DEFAULT, get), 20);
+		assert_eq!(quota("test", Some("other"), &DEFAULT, get), 10);
+		assert_eq!(quota("test", None, &DEFAULT, get), 10);
+		assert_eq!(quota("other", Some("test"), &DEFAULT, get), 5);
+		assert_eq!(quota("test", Some("test"), &DEFAULT, |q| q.storage_max_mb), 5);
+		set_quotas(Vec::new());
+	}
+
+	#[test]
+	fn dynamic_setting_reloads() {
+		static SETTING: Dynamic = Dynamic::new("SURREAL_TEST_DYNAMIC_SETTING", 5);
+		assert_eq!(SETTING.get(), 5);
+		let settings =
+			HashMap::from([("SURREAL_TEST_DYNAMIC_SETTING".to_owned(), "10".to_owned())]);
+		set_settings(settings);
+		assert_eq!(SETTING.get(), 5);
+		SETTING.reload();
+		assert_eq!(SETTING.get(), 10);
+		set_settings(HashMap::new());
+		SETTING.reload();
+		assert_eq!(SETTING.get(), 5);
+	}
+
+	#[test]
+	fn dynamic_setting_checks() {
+		static SETTING: Dynamic = Dynamic::new("SURREAL_TEST_CHECKED_SETTING", 5);
+		let valid = HashMap::from([("SURREAL_TEST_CHECKED_SETTING".to_owned(), "10".to_owned())]);
+		assert!(SETTING.check(&valid).is_ok());
+		assert!(SETTING.check(&HashMap::new()).is_ok());
+		let invalid =
+			HashMap::from([("SURREAL_TEST_CHECKED_SETTING".to_owned(), "ten".to_owned())]);
+		assert!(SETTING.check(&invalid).is_err());
+		let negative =
+			HashMap::from([("SURREAL_TEST_CHECKED_SETTING".to_owned(), "-1".to_owned())]);
+		assert!(SETTING.check(&negative).is_err());
+	}
+}
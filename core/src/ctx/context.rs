@@ -1,5 +1,6 @@
 use crate::ctx::canceller::Canceller;
 use crate::ctx::reason::Reason;
+use crate::ctx::rng::SeededRng;
 #[cfg(feature = "http")]
 use crate::dbs::capabilities::NetTarget;
 use crate::dbs::{Capabilities, Notification, Transaction};
@@ -8,9 +9,13 @@ use crate::idx::planner::executor::QueryExecutor;
 use crate::idx::planner::{IterationStage, QueryPlanner};
 use crate::idx::trees::store::IndexStores;
 use crate::kvs;
+use crate::kvs::{MemoryBudget, SessionTracker, UsageTracker};
+use crate::sql::subquery::SubqueryCache;
 use crate::sql::value::Value;
 use channel::Sender;
+use chrono::Utc;
 use futures::lock::MutexLockFuture;
+use rand::RngCore;
 use std::borrow::Cow;
 use std::collections::HashMap;
 use std::fmt::{self, Debug};
@@ -40,6 +45,7 @@ impl<'a> From<&'a Value> for Cow<'a, Value> {
 		Cow::Borrowed(v)
 	}
 }
+
 #[non_exhaustive]
 pub struct Context<'a> {
 	// An optional parent context.
@@ -73,6 +79,16 @@ pub struct Context<'a> {
 	temporary_directory: Option<Arc<PathBuf>>,
 	// An optional transaction
 	transaction: Option<Transaction>,
+	// An optional namespace usage tracker
+	usage: Option<Arc<UsageTracker>>,
+	// An optional tracker of the connected client sessions
+	sessions: Option<Arc<SessionTracker>>,
+	// An optional seeded random number generator
+	rng: Option<SeededRng>,
+	// An optional server-wide budget for retained query results
+	memory: Option<Arc<MemoryBudget>>,
+	// An optional cache of subquery results for the current statement
+	subqueries: Option<Arc<SubqueryCache>>,
 }
 
 impl<'a> Default for Context<'a> {
@@ -126,6 +142,11 @@ impl<'a> Context<'a> {
 			))]
 			temporary_directory,
 			transaction: None,
+			usage: None,
+			sessions: None,
+			rng: None,
+			memory: None,
+			subqueries: None,
 		};
 		if let Some(timeout) = time_out {
 			ctx.add_timeout(timeout)?;
@@ -154,6 +175,11 @@ impl<'a> Context<'a> {
 			))]
 			temporary_directory: None,
 			transaction: None,
+			usage: None,
+			sessions: None,
+			rng: None,
+			memory: None,
+			subqueries: None,
 		}
 	}
 
@@ -179,6 +205,11 @@ impl<'a> Context<'a> {
 			))]
 			temporary_directory: parent.temporary_directory.clone(),
 			transaction: parent.transaction.clone(),
+			usage: parent.usage.clone(),
+			sessions: parent.sessions.clone(),
+			rng: parent.rng.clone(),
+			memory: parent.memory.clone(),
+			subqueries: parent.subqueries.clone(),
 		}
 	}
 
@@ -239,6 +270,26 @@ impl<'a> Context<'a> {
 		self.iteration_stage = Some(is);
 	}
 
+	pub(crate) fn add_usage(&mut self, usage: Option<Arc<UsageTracker>>) {
+		self.usage = usage;
+	}
+
+	pub(crate) fn add_sessions(&mut self, sessions: Option<Arc<SessionTracker>>) {
+		self.sessions = sessions;
+	}
+
+	pub(crate) fn add_rng(&mut self, rng: Option<SeededRng>) {
+		self.rng = rng;
+	}
+
+	pub(crate) fn add_memory_budget(&mut self, memory: Option<Arc<MemoryBudget>>) {
+		self.memory = memory;
+	}
+
+	pub(crate) fn add_subquery_cache(&mut self, subqueries: Option<Arc<SubqueryCache>>) {
+		self.subqueries = subqueries;
+	}
+
 	pub(crate) fn set_transaction_mut(&mut self, txn: Transaction) {
 		self.transaction = Some(txn);
 	}
@@ -274,6 +325,49 @@ impl<'a> Context<'a> {
 		self.iteration_stage.as_ref()
 	}
 
+	/// Get the namespace usage tracker for this context/ds
+	pub(crate) fn get_usage(&self) -> Option<&Arc<UsageTracker>> {
+		self.usage.as_ref()
+	}
+
+	/// Get the connected client session tracker for this context/ds
+	pub(crate) fn get_sessions(&self) -> Option<&Arc<SessionTracker>> {
+		self.sessions.as_ref()
+	}
+
+	/// Get the server memory budget for this context/ds
+	pub(crate) fn get_memory_budget(&self) -> Option<&Arc<MemoryBudget>> {
+		self.memory.as_ref()
+	}
+
+	/// Get the cache of uncorrelated subquery results for the current statement
+	pub(crate) fn get_subquery_cache(&self) -> Option<&Arc<SubqueryCache>> {
+		self.subqueries.as_ref()
+	}
+
+	/// Run a function with the random number generator for this context/ds. This
+	/// is the seeded generator if one is configured, and the thread generator otherwise.
+	pub(crate) fn with_rng<R>(&self, f: impl FnOnce(&mut dyn RngCore) -> R) -> R {
+		match &self.rng {
+			Some(rng) => rng.with(f),
+			None => f(&mut rand::thread_rng()),
+		}
+	}
+
+	/// Check if the random number generator for this context/ds is seeded
+	pub(crate) fn is_seeded(&self) -> bool {
+		self.rng.is_some()
+	}
+
+	/// Get the timestamp in milliseconds for time-based ids. This is the
+	/// logical clock of the seeded generator if one is configured.
+	pub(crate) fn id_millis(&self) -> u64 {
+		match &self.rng {
+			Some(rng) => rng.tick(),
+			None => Utc::now().timestamp_millis() as u64,
+		}
+	}
+
 	/// Get the index_store for this context/ds
 	pub(crate) fn get_index_stores(&self) -> &IndexStores {
 		&self.index_stores
@@ -22,8 +22,10 @@
 
 pub use self::canceller::*;
 pub use self::context::*;
+pub use self::rng::SeededRng;
 
 pub mod cancellation;
 pub mod canceller;
 pub mod context;
 pub mod reason;
+pub mod rng;
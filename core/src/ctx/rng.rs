@@ -0,0 +1,54 @@
+use rand::rngs::StdRng;
+use rand::{RngCore, SeedableRng};
+use std::fmt::{self, Debug};
+use std::sync::{Arc, Mutex};
+
+/// A random number generator and logical clock which are seeded
+/// deterministically, so that random values, and generated record
+/// ids, can be reproduced in tests. Clones share the same state.
+#[derive(Clone)]
+pub struct SeededRng(Arc<Mutex<SeededState>>);
+
+struct SeededState {
+	/// The seeded random number generator
+	rng: StdRng,
+	/// The logical clock used for time-based ids, in milliseconds
+	clock: u64,
+}
+
+impl SeededRng {
+	/// Create a new generator with the given seed
+	pub fn new(seed: u64) -> Self {
+		Self(Arc::new(Mutex::new(SeededState {
+			rng: StdRng::seed_from_u64(seed),
+			clock: 0,
+		})))
+	}
+
+	/// Run a function with the seeded random number generator
+	pub(crate) fn with<R>(&self, f: impl FnOnce(&mut dyn RngCore) -> R) -> R {
+		let mut state = self.0.lock().unwrap_or_else(|e| e.into_inner());
+		f(&mut state.rng)
+	}
+
+	/// Advance the logical clock, returning the next timestamp in milliseconds
+	pub(crate) fn tick(&self) -> u64 {
+		let mut state = self.0.lock().unwrap_or_else(|e| e.into_inner());
+		state.clock += 1;
+		state.clock
+	}
+}
+
+impl Debug for SeededRng {
+	fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+		f.write_str("SeededRng")
+	}
+}
+
+impl PartialEq for SeededRng {
+	fn eq(&self, other: &Self) -> bool {
+		Arc::ptr_eq(&self.0, &other.0)
+	}
+}
+
+impl Eq for SeededRng {}
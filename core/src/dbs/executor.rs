@@ -11,6 +11,7 @@ use trice::Instant;
 #[cfg(target_arch = "wasm32")]
 use wasm_bindgen_futures::spawn_local as spawn;
 
+use crate::cnf::SLOW_QUERY_THRESHOLD;
 use crate::ctx::Context;
 use crate::dbs::response::Response;
 use crate::dbs::Force;
@@ -19,6 +20,7 @@ use crate::dbs::Options;
 use crate::dbs::QueryType;
 use crate::dbs::Transaction;
 use crate::err::Error;
+use crate::iam::audit;
 use crate::iam::Action;
 use crate::iam::ResourceKind;
 use crate::kvs::lq_structs::TrackedResult;
@@ -135,6 +137,7 @@ impl<'a> Executor<'a> {
 			time: v.time,
 			result: Err(Error::QueryCancelled),
 			query_type: QueryType::Other,
+			memory: None,
 		}
 	}
 
@@ -152,6 +155,7 @@ impl<'a> Executor<'a> {
 					Err(e) => Err(e),
 				},
 				query_type: QueryType::Other,
+				memory: None,
 			},
 			_ => v,
 		}
@@ -226,6 +230,8 @@ impl<'a> Executor<'a> {
 		// Initialise array of responses
 		let mut out: Vec<Response> = vec![];
 		let mut live_queries: Vec<TrackedResult> = vec![];
+		// The memory retained by parameters until the query completes
+		let mut params = ctx.get_memory_budget().map(|m| m.reservation());
 		// Process all statements in query
 		for stm in qry.into_iter() {
 			// Log the statement
@@ -236,12 +242,31 @@ impl<'a> Executor<'a> {
 			}
 			// Get the statement start time
 			let now = Instant::now();
+			// Get the kind of statement for logging
+			let kind = stm.kind();
+			// Check if this statement changes the schema or data
+			let is_stm_audited = matches!(
+				stm,
+				Statement::Create(_)
+					| Statement::Define(_)
+					| Statement::Delete(_)
+					| Statement::Insert(_)
+					| Statement::Rebuild(_)
+					| Statement::Relate(_)
+					| Statement::Remove(_)
+					| Statement::Rename(_)
+					| Statement::Revoke(_)
+					| Statement::Update(_)
+					| Statement::Upsert(_)
+			);
 			// Check if this is a LIVE statement
 			let is_stm_live = matches!(stm, Statement::Live(_));
 			// Check if this is a KILL statement
 			let is_stm_kill = matches!(stm, Statement::Kill(_));
 			// Check if this is a RETURN statement
 			let is_stm_output = matches!(stm, Statement::Output(_));
+			// The memory reserved for the statement result
+			let mut memory = None;
 			// Process a single statement
 			let res = match stm {
 				// Specify runtime options
@@ -292,7 +317,7 @@ impl<'a> Executor<'a> {
 					continue;
 				}
 				// Switch to a different NS or DB
-				Statement::Use(stm) => {
+				Statement::Use(ref stm) => {
 					if let Some(ref ns) = stm.ns {
 						self.set_ns(&mut ctx, &mut opt, ns).await;
 					}
@@ -302,7 +327,7 @@ impl<'a> Executor<'a> {
 					Ok(Value::None)
 				}
 				// Process param definition statements
-				Statement::Set(stm) => {
+				Statement::Set(ref stm) => {
 					// Create a transaction
 					let loc = self.begin(stm.writeable().into()).await;
 					// Check the transaction
@@ -313,16 +338,21 @@ impl<'a> Executor<'a> {
 						false => {
 							ctx.set_transaction_mut(self.txn());
 							// Check the statement
-							match stack
+							let res = stack
 								.enter(|stk| stm.compute(stk, &ctx, &opt, None))
 								.finish()
-								.await
-							{
+								.await;
+							// Reserve the memory retained by the parameter
+							let res = match (res, params.as_mut()) {
+								(Ok(val), Some(params)) => params.retain(&val).map(|_| val),
+								(res, _) => res,
+							};
+							match res {
 								Ok(val) => {
 									// Check if writeable
 									let writeable = stm.writeable();
 									// Set the parameter
-									ctx.add_value(stm.name, val);
+									ctx.add_value(stm.name.clone(), val);
 									// Finalise transaction, returning nothing unless it couldn't commit
 									if writeable {
 										match self.commit(loc).await {
@@ -361,6 +391,16 @@ impl<'a> Executor<'a> {
 						}
 					}
 				}
+				// Process rename statements, which commit in batches
+				Statement::Rename(ref stm) => match self.txn.is_some() {
+					// Renames can not be run within a transaction
+					true => Err(Error::RenameInTransaction),
+					// Process the statement in its own transactions
+					false => {
+						let kvs = self.kvs;
+						stack.enter(|stk| stm.compute(stk, kvs, &ctx, &opt)).finish().await
+					}
+				},
 				// Process all other normal statements
 				_ => match self.err {
 					// This transaction has failed
@@ -411,6 +451,20 @@ impl<'a> Executor<'a> {
 									true => Err(Error::QueryTimedout),
 									false => res,
 								};
+								// Reserve the memory retained by the result until it has been sent
+								let res = match (res, ctx.get_memory_budget()) {
+									(Ok(v), Some(budget)) => {
+										let mut reservation = budget.reservation();
+										match reservation.retain(&v) {
+											Ok(_) => {
+												memory = Some(reservation);
+												Ok(v)
+											}
+											Err(e) => Err(e),
+										}
+									}
+									(res, _) => res,
+								};
 								// Finalise transaction and return the result.
 								if res.is_ok() && stm.writeable() {
 									if let Err(e) = self.commit(loc).await {
@@ -467,7 +521,33 @@ impl<'a> Executor<'a> {
 					}
 					_ => QueryType::Other,
 				},
+				memory,
 			};
+			// Log the outcome of the statement
+			debug!(
+				statement = kind,
+				rows = match &res.result {
+					Ok(Value::Array(v)) => v.len(),
+					Ok(Value::None) | Err(_) => 0,
+					Ok(_) => 1,
+				},
+				duration_ms = res.time.as_millis() as u64,
+				success = res.result.is_ok(),
+				"Executed statement"
+			);
+			// Log statements which exceeded the slow query threshold
+			let slow = SLOW_QUERY_THRESHOLD.get();
+			if slow > 0 && res.time.as_millis() >= slow as u128 {
+				warn!(
+					statement = kind,
+					duration_ms = res.time.as_millis() as u64,
+					"Slow query: {stm}"
+				);
+			}
+			// Record data-changing statements in the audit log
+			if is_stm_audited {
+				audit::statement(&ctx, &opt, kind, res.result.is_ok());
+			}
 			// Output the response
 			if self.txn.is_some() {
 				if is_stm_output {
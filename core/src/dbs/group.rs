@@ -277,33 +277,37 @@ impl Aggregator {
 				self.math_mean = Some((s, i + 1));
 			}
 			if let Some(m) = self.math_min.take() {
-				self.math_min = Some(if m.is_none() {
+				// Only copy the value when it replaces the current min
+				self.math_min = Some(if m.is_none() || val < m {
 					val.clone()
 				} else {
-					m.min(val.clone())
+					m
 				});
 			}
 			if let Some(m) = self.math_max.take() {
-				self.math_max = Some(if m.is_none() {
+				// Only copy the value when it replaces the current max
+				self.math_max = Some(if m.is_none() || val >= m {
 					val.clone()
 				} else {
-					m.max(val.clone())
+					m
 				});
 			}
 		}
 		if val.is_datetime() {
 			if let Some(m) = self.time_min.take() {
-				self.time_min = Some(if m.is_none() {
+				// Only copy the value when it replaces the current min
+				self.time_min = Some(if m.is_none() || val < m {
 					val.clone()
 				} else {
-					m.min(val.clone())
+					m
 				});
 			}
 			if let Some(m) = self.time_max.take() {
-				self.time_max = Some(if m.is_none() {
+				// Only copy the value when it replaces the current max
+				self.time_max = Some(if m.is_none() || val >= m {
 					val.clone()
 				} else {
-					m.max(val.clone())
+					m
 				});
 			}
 		}
@@ -11,13 +11,16 @@ use crate::doc::Document;
 use crate::err::Error;
 use crate::idx::planner::iterators::{IteratorRecord, IteratorRef};
 use crate::idx::planner::IterationStage;
+use crate::kvs::MemoryReservation;
 use crate::sql::edges::Edges;
+use crate::sql::mock::Mock;
 use crate::sql::range::Range;
 use crate::sql::table::Table;
 use crate::sql::thing::Thing;
 use crate::sql::value::Value;
 use reblessive::{tree::Stk, TreeStack};
 use std::mem;
+use tracing::{instrument, Instrument};
 
 #[derive(Clone)]
 pub(crate) enum Iterable {
@@ -64,6 +67,8 @@ pub(crate) struct Iterator {
 	results: Results,
 	// Iterator input values
 	entries: Vec<Iterable>,
+	// Iterator retained memory
+	memory: Option<MemoryReservation>,
 }
 
 impl Clone for Iterator {
@@ -75,6 +80,7 @@ impl Clone for Iterator {
 			error: None,
 			results: Results::default(),
 			entries: self.entries.clone(),
+			memory: None,
 		}
 	}
 }
@@ -90,6 +96,31 @@ impl Iterator {
 		self.entries.push(val)
 	}
 
+	/// Ingests the records of a mock, generating the
+	/// ids of counted records with the table generator
+	pub(crate) async fn ingest_mock(
+		&mut self,
+		ctx: &Context<'_>,
+		opt: &Options,
+		val: Mock,
+	) -> Result<(), Error> {
+		match val {
+			// Generate a new table id for each record
+			Mock::Count(tb, c) => {
+				let tb = Table::from(tb);
+				for _ in 0..c {
+					self.ingest(Iterable::Thing(tb.generate_defined(ctx, opt).await?))
+				}
+			}
+			v => {
+				for v in v {
+					self.ingest(Iterable::Thing(v))
+				}
+			}
+		}
+		Ok(())
+	}
+
 	/// Prepares a value for processing
 	pub async fn prepare(
 		&mut self,
@@ -108,8 +139,8 @@ impl Iterator {
 						let id = match data.rid(stk, ctx, opt).await? {
 							// Generate a new id from the id field
 							Some(id) => id.generate(&v, false)?,
-							// Generate a new random table id
-							None => v.generate(),
+							// Generate a new table id
+							None => v.generate_defined(ctx, opt).await?,
 						};
 						self.ingest(Iterable::Thing(id))
 					}
@@ -121,8 +152,8 @@ impl Iterator {
 				// There is no data clause so create a record id
 				None => match stm {
 					Statement::Create(_) => {
-						// Generate a new random table id
-						self.ingest(Iterable::Thing(v.generate()))
+						// Generate a new table id
+						self.ingest(Iterable::Thing(v.generate_defined(ctx, opt).await?))
 					}
 					_ => {
 						// Ingest the table for scanning
@@ -169,9 +200,7 @@ impl Iterator {
 					}
 				}
 				// Add the records to the iterator
-				for v in v {
-					self.ingest(Iterable::Thing(v))
-				}
+				self.ingest_mock(ctx, opt, v).await?;
 			}
 			Value::Range(v) => {
 				// Check if this is a create statement
@@ -276,6 +305,7 @@ impl Iterator {
 	}
 
 	/// Process the records and output
+	#[instrument(level = "trace", name = "iterator::output", skip_all)]
 	pub async fn output(
 		&mut self,
 		stk: &mut Stk,
@@ -304,6 +334,10 @@ impl Iterator {
 			ctx,
 			stm,
 		)?;
+		// Register retained results against the server memory budget
+		self.memory = ctx.get_memory_budget().map(|m| m.reservation());
+		// Check if each result is retained in memory as it is processed
+		let in_memory = self.results.in_memory();
 		// Extract the expected behaviour depending on the presence of EXPLAIN with or without FULL
 		let mut plan = Plan::new(ctx, stm, &self.entries, &self.results);
 		if plan.do_iterate {
@@ -326,12 +360,14 @@ impl Iterator {
 			self.output_split(stk, ctx, opt, stm).await?;
 			// Process any GROUP clause
 			if let Results::Groups(g) = &mut self.results {
-				self.results = Results::Memory(g.output(stk, ctx, opt, stm).await?);
+				self.results = Results::Memory(
+					g.output(stk, ctx, opt, stm).instrument(trace_span!("iterator::group")).await?,
+				);
 			}
 
 			// Process any ORDER clause
 			if let Some(orders) = stm.order() {
-				self.results.sort(orders);
+				trace_span!("iterator::order").in_scope(|| self.results.sort(orders));
 			}
 
 			// Process any START & LIMIT clause
@@ -347,6 +383,14 @@ impl Iterator {
 
 		// Extract the output from the result
 		let mut results = self.results.take()?;
+		// Grouped or file-backed results are only loaded into memory here
+		if !in_memory {
+			if let Some(m) = &mut self.memory {
+				for v in results.iter() {
+					m.retain(v)?;
+				}
+			}
+		}
 
 		// Output the explanation if any
 		if let Some(e) = plan.explanation {
@@ -361,6 +405,7 @@ impl Iterator {
 	}
 
 	#[inline]
+	#[instrument(level = "trace", name = "iterator::setup_limit", skip_all)]
 	async fn setup_limit(
 		&mut self,
 		stk: &mut Stk,
@@ -375,6 +420,7 @@ impl Iterator {
 	}
 
 	#[inline]
+	#[instrument(level = "trace", name = "iterator::setup_start", skip_all)]
 	async fn setup_start(
 		&mut self,
 		stk: &mut Stk,
@@ -389,6 +435,7 @@ impl Iterator {
 	}
 
 	#[inline]
+	#[instrument(level = "trace", name = "iterator::split", skip_all)]
 	async fn output_split(
 		&mut self,
 		stk: &mut Stk,
@@ -402,15 +449,19 @@ impl Iterator {
 				// Get the query result
 				let res = self.results.take()?;
 				// Loop over each value
-				for obj in &res {
+				for mut obj in res {
 					// Get the value at the path
 					let val = obj.pick(split);
 					// Set the value at the path
 					match val {
 						Value::Array(v) => {
-							for val in v {
-								// Make a copy of object
-								let mut obj = obj.clone();
+							let mut vals = v.into_iter().peekable();
+							while let Some(val) = vals.next() {
+								// Copy the object, unless this is the last value
+								let mut obj = match vals.peek() {
+									Some(_) => obj.clone(),
+									None => mem::take(&mut obj),
+								};
 								// Set the value at the path
 								obj.set(stk, ctx, opt, split, val).await?;
 								// Add the object to the results
@@ -418,8 +469,6 @@ impl Iterator {
 							}
 						}
 						_ => {
-							// Make a copy of object
-							let mut obj = obj.clone();
 							// Set the value at the path
 							obj.set(stk, ctx, opt, split, val).await?;
 							// Add the object to the results
@@ -433,6 +482,7 @@ impl Iterator {
 	}
 
 	#[inline]
+	#[instrument(level = "trace", name = "iterator::fetch", skip_all)]
 	async fn output_fetch(
 		&mut self,
 		stk: &mut Stk,
@@ -455,6 +505,7 @@ impl Iterator {
 	}
 
 	#[cfg(target_arch = "wasm32")]
+	#[instrument(level = "trace", name = "iterator::scan", skip_all)]
 	async fn iterate(
 		&mut self,
 		stk: &mut Stk,
@@ -475,6 +526,7 @@ impl Iterator {
 	}
 
 	#[cfg(not(target_arch = "wasm32"))]
+	#[instrument(level = "trace", name = "iterator::scan", skip_all)]
 	async fn iterate(
 		&mut self,
 		stk: &mut Stk,
@@ -607,6 +659,15 @@ impl Iterator {
 				return;
 			}
 			Ok(v) => {
+				if self.results.in_memory() {
+					if let Some(m) = &mut self.memory {
+						if let Err(e) = m.retain(&v) {
+							self.error = Some(e);
+							self.run.cancel();
+							return;
+						}
+					}
+				}
 				if let Err(e) = self.results.push(stk, ctx, opt, stm, v).await {
 					self.error = Some(e);
 					self.run.cancel();
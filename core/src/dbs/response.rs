@@ -1,4 +1,5 @@
 use crate::err::Error;
+use crate::kvs::MemoryReservation;
 use crate::sql::value::Value;
 use revision::revisioned;
 use revision::Revisioned;
@@ -28,6 +29,8 @@ pub struct Response {
 	pub result: Result<Value, Error>,
 	// Record the query type in case processing the response is necessary (such as tracking live queries).
 	pub query_type: QueryType,
+	// The memory reserved for the result, released once the response has been sent
+	pub(crate) memory: Option<MemoryReservation>,
 }
 
 impl Response {
@@ -40,6 +43,13 @@ impl Response {
 	pub fn output(self) -> Result<Value, Error> {
 		self.result
 	}
+
+	/// Detach the memory reserved for the result, so
+	/// that it can be held until the result has been sent
+	#[doc(hidden)]
+	pub fn take_memory(&mut self) -> Option<MemoryReservation> {
+		self.memory.take()
+	}
 }
 
 #[revisioned(revision = 1)]
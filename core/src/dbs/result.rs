@@ -121,6 +121,11 @@ impl Results {
 		}
 	}
 
+	/// Whether the results are retained in memory
+	pub(super) fn in_memory(&self) -> bool {
+		matches!(self, Self::Memory(_))
+	}
+
 	pub(super) fn len(&self) -> usize {
 		match self {
 			Self::None => 0,
@@ -1,4 +1,4 @@
-use crate::ctx::Context;
+use crate::ctx::{Context, SeededRng};
 use crate::iam::Auth;
 use crate::iam::{Level, Role};
 use crate::sql::value::Value;
@@ -31,6 +31,8 @@ pub struct Session {
 	pub rd: Option<Value>,
 	/// The current expiration time of the session
 	pub exp: Option<i64>,
+	/// The seeded random number generator for this session
+	pub(crate) rng: Option<SeededRng>,
 }
 
 impl Session {
@@ -58,6 +60,14 @@ impl Session {
 		self
 	}
 
+	/// Seed the random functions and generated record ids of all the queries
+	/// in this session deterministically. The values which are generated are
+	/// predictable, so this must only be used for testing.
+	pub fn with_random_seed(mut self, seed: Option<u64>) -> Session {
+		self.rng = seed.map(SeededRng::new);
+		self
+	}
+
 	/// Retrieves the selected namespace
 	pub(crate) fn ns(&self) -> Option<Arc<str>> {
 		self.ns.as_deref().map(Into::into)
@@ -147,6 +157,7 @@ impl Session {
 			tk: None,
 			rd: Some(rid),
 			exp: None,
+			rng: None,
 		}
 	}
 
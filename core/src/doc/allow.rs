@@ -3,6 +3,7 @@ use crate::dbs::Options;
 use crate::dbs::Statement;
 use crate::doc::Document;
 use crate::err::Error;
+use crate::kvs::PermissionKind;
 use crate::sql::permission::Permission;
 use reblessive::tree::Stk;
 
@@ -18,20 +19,34 @@ impl<'a> Document<'a> {
 		if self.id.is_some() {
 			// Should we run permissions checks?
 			if opt.check_perms(stm.into())? {
-				// Get the table
-				let tb = self.tb(ctx, opt).await?;
-				// Get the permission clause
-				let perms = if stm.is_delete() {
-					&tb.permissions.delete
+				// Get the permission type
+				let kind = if stm.is_delete() {
+					PermissionKind::Delete
 				} else if stm.is_select() {
-					&tb.permissions.select
+					PermissionKind::Select
 				} else if self.is_new() {
-					&tb.permissions.create
+					PermissionKind::Create
 				} else {
-					&tb.permissions.update
+					PermissionKind::Update
+				};
+				// Get the record id
+				let rid = self.id.as_ref().unwrap();
+				// Get the shared permission clause
+				let cached =
+					ctx.tx_lock().await.get_tb_perms(opt.ns()?, opt.db()?, &rid.tb, kind).await?;
+				// Otherwise get the permission clause from the table
+				let perms = match cached {
+					Some(perms) => perms,
+					None => {
+						let tb = self.tb(ctx, opt).await?;
+						ctx.tx_lock()
+							.await
+							.set_tb_perms(opt.ns()?, opt.db()?, &rid.tb, kind, &tb.permissions)
+							.await?
+					}
 				};
 				// Process the table permissions
-				match perms {
+				match perms.as_ref() {
 					Permission::None => return Err(Error::Ignore),
 					Permission::Full => return Ok(()),
 					Permission::Specific(e) => {
@@ -3,6 +3,8 @@ use crate::dbs::Options;
 use crate::dbs::Statement;
 use crate::doc::Document;
 use crate::err::Error;
+use crate::iam::audit;
+use crate::kvs::{StorageQuota, Val};
 use crate::sql::dir::Dir;
 use crate::sql::edges::Edges;
 use crate::sql::paths::EDGE;
@@ -32,6 +34,12 @@ impl<'a> Document<'a> {
 			// Purge the record data
 			let key = crate::key::thing::new(opt.ns()?, opt.db()?, &rid.tb, &rid.id);
 			run.del(key).await?;
+			// Release the record from the record and storage quotas
+			let quota = StorageQuota::new(opt.ns()?, opt.db()?);
+			let bytes = Val::from(self.initial_doc()).len() as i64;
+			quota.update(&mut run, opt.ns()?, opt.db()?, &rid.tb, -1, -bytes).await?;
+			// Record the deletion in the audit log
+			audit::record(ctx, opt, "delete", rid);
 			// Purge the record edges
 			match (
 				self.initial.doc.pick(&*EDGE),
@@ -16,6 +16,10 @@ impl<'a> Document<'a> {
 	) -> Result<Value, Error> {
 		// Check if record exists
 		self.empty(ctx, opt, stm).await?;
+		// Meter the record read
+		if let Some(usage) = ctx.get_usage() {
+			usage.read(opt.ns()?, 1);
+		}
 		// Check where clause
 		self.check(stk, ctx, opt, stm).await?;
 		// Check if allowed
@@ -3,7 +3,9 @@ use crate::dbs::Options;
 use crate::dbs::Statement;
 use crate::doc::Document;
 use crate::err::Error;
+use crate::iam::audit;
 use crate::key::key_req::KeyRequirements;
+use crate::kvs::{StorageQuota, Val};
 
 impl<'a> Document<'a> {
 	pub async fn store(
@@ -26,10 +28,19 @@ impl<'a> Document<'a> {
 		let rid = self.id.as_ref().unwrap();
 		// Store the record data
 		let key = crate::key::thing::new(opt.ns()?, opt.db()?, &rid.tb, &rid.id);
+		let val: Val = self.into();
+		let len = val.len() as u64;
+		// Check and update the record and storage quotas
+		let quota = StorageQuota::new(opt.ns()?, opt.db()?);
+		let (records, bytes) = match self.is_new() {
+			true => (1, len as i64),
+			false => (0, len as i64 - Val::from(self.initial_doc()).len() as i64),
+		};
+		quota.update(&mut run, opt.ns()?, opt.db()?, &rid.tb, records, bytes).await?;
 		//
 		match stm {
 			// This is a CREATE statement so try to insert the key
-			Statement::Create(_) => match run.put(key.key_category(), key, self).await {
+			Statement::Create(_) => match run.put(key.key_category(), key, val).await {
 				// The key already exists, so return an error
 				Err(Error::TxKeyAlreadyExistsCategory(_)) => Err(Error::RecordExists {
 					thing: rid.to_string(),
@@ -40,8 +51,18 @@ impl<'a> Document<'a> {
 				Ok(v) => Ok(v),
 			},
 			// This is not a CREATE statement, so update the key
-			_ => run.set(key, self).await,
+			_ => run.set(key, val).await,
 		}?;
+		// Meter the record write
+		if let Some(usage) = ctx.get_usage() {
+			usage.write(opt.ns()?, len);
+		}
+		// Record the write in the audit log
+		let action = match self.is_new() {
+			true => "create",
+			false => "update",
+		};
+		audit::record(ctx, opt, action, rid);
 		// Carry on
 		Ok(())
 	}
@@ -127,6 +127,10 @@ pub enum Error {
 	#[error("Parse error: {0}")]
 	InvalidQuery(RenderedParserError),
 
+	/// The query could not be formatted, because its comments would be removed
+	#[error("Unable to format a query containing comments, as the comments would be removed")]
+	FormatComments,
+
 	/// There was an error with the SQL query
 	#[error("Can not use {value} in a CONTENT clause")]
 	InvalidContent {
@@ -270,6 +274,10 @@ pub enum Error {
 	#[error("The query was not executed due to a cancelled transaction")]
 	QueryCancelled,
 
+	/// The query did not execute, because the datastore is shutting down
+	#[error("The query was not executed because the server is shutting down")]
+	ShuttingDown,
+
 	/// The query did not execute, because the transaction has failed
 	#[error("The query was not executed due to a failed transaction")]
 	QueryNotExecuted,
@@ -280,6 +288,10 @@ pub enum Error {
 		message: String,
 	},
 
+	/// A RENAME statement was run within a transaction, which it commits in batches
+	#[error("RENAME statements can not be run within a transaction")]
+	RenameInTransaction,
+
 	/// The permissions do not allow for changing to the specified namespace
 	#[error("You don't have permission to change to the {ns} namespace")]
 	NsNotAllowed {
@@ -376,6 +388,20 @@ pub enum Error {
 		value: String,
 	},
 
+	/// The requested table can not be renamed
+	#[error("The table '{value}' can not be renamed: {message}")]
+	TbNotRenamable {
+		value: String,
+		message: String,
+	},
+
+	/// The requested field can not be renamed
+	#[error("The field '{value}' can not be renamed: {message}")]
+	FdNotRenamable {
+		value: String,
+		message: String,
+	},
+
 	/// The requested live query does not exist
 	#[error("The live query '{value}' does not exist")]
 	LvNotFound {
@@ -522,6 +548,12 @@ pub enum Error {
 		value: String,
 	},
 
+	/// Can not execute REVOKE statement using the specified value
+	#[error("Can not execute REVOKE statement using value '{value}'")]
+	RevokeStatement {
+		value: String,
+	},
+
 	/// Can not execute CREATE statement using the specified value
 	#[error("Expected a single result output when using the ONLY keyword")]
 	SingleOnlyOutput,
@@ -802,6 +834,10 @@ pub enum Error {
 	#[error("The password did not verify")]
 	InvalidPass,
 
+	/// The configured Argon2id password hashing parameters are invalid
+	#[error("The configured password hashing parameters are invalid: {0}")]
+	InvalidPasswordParams(String),
+
 	/// There was an error with authentication
 	#[error("There was a problem with authentication")]
 	InvalidAuth,
@@ -1010,6 +1046,46 @@ pub enum Error {
 	TbInvalid {
 		value: String,
 	},
+
+	/// The namespace has exceeded its allowed number of queries per second
+	#[error("The namespace '{ns}' has exceeded its quota of {limit} queries per second")]
+	NsQueryQuotaExceeded {
+		ns: String,
+		limit: u32,
+	},
+
+	/// The table has reached its allowed number of records
+	#[error("The table '{tb}' has reached its quota of {limit} records")]
+	TbRecordQuotaExceeded {
+		tb: String,
+		limit: u64,
+	},
+
+	/// The database has reached its allowed size of record data
+	#[error("The database '{db}' has reached its storage quota of {limit} bytes")]
+	DbStorageQuotaExceeded {
+		db: String,
+		limit: u64,
+	},
+
+	/// The datastore was written by a newer release, with a storage format this release can not read
+	#[error("The storage format version {version} is newer than this release supports ({latest})")]
+	StorageVersionNewer {
+		version: u16,
+		latest: u16,
+	},
+
+	/// Another node is already migrating the datastore to a newer storage format
+	#[error("The datastore is already being migrated by node {node}")]
+	MigrationInProgress {
+		node: String,
+	},
+
+	/// The results retained by all running queries have exceeded the server memory budget
+	#[error("The query exceeded the server memory budget of {limit} bytes")]
+	MemoryBudgetExceeded {
+		limit: usize,
+	},
 }
 
 impl From<Error> for String {
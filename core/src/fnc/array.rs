@@ -1,3 +1,6 @@
+use crate::ctx::Context;
+use crate::dbs::Options;
+use crate::doc::CursorDoc;
 use crate::err::Error;
 use crate::sql::array::Array;
 use crate::sql::array::Clump;
@@ -13,6 +16,7 @@ use crate::sql::array::Uniq;
 use crate::sql::value::Value;
 
 use rand::prelude::SliceRandom;
+use reblessive::tree::Stk;
 
 pub fn add((mut array, value): (Array, Value)) -> Result<Value, Error> {
 	match value {
@@ -314,6 +318,25 @@ pub fn push((mut array, value): (Array, Value)) -> Result<Value, Error> {
 	Ok(array.into())
 }
 
+pub fn range((start, count): (i64, i64)) -> Result<Value, Error> {
+	// Ensure the output array stays within bounds
+	const LIMIT: i64 = 2i64.pow(20);
+	if !(0..=LIMIT).contains(&count) {
+		return Err(Error::InvalidArguments {
+			name: String::from("array::range"),
+			message: format!("The count must be between 0 and {LIMIT}."),
+		});
+	}
+	// Ensure the last value does not overflow
+	if start.checked_add(count).is_none() {
+		return Err(Error::InvalidArguments {
+			name: String::from("array::range"),
+			message: String::from("The range exceeds the maximum integer value."),
+		});
+	}
+	Ok((start..start + count).map(Value::from).collect::<Vec<_>>().into())
+}
+
 pub fn remove((mut array, mut index): (Array, i64)) -> Result<Value, Error> {
 	// Negative index means start from the back
 	if index < 0 {
@@ -399,6 +422,61 @@ pub fn union((array, other): (Array, Array)) -> Result<Value, Error> {
 	Ok(array.union(other).into())
 }
 
+/// Returns `true` if this function evaluates its second argument once for
+/// each array element, rather than before the function is called.
+pub fn is_iterator(name: &str) -> bool {
+	matches!(name, "array::filter" | "array::map")
+}
+
+/// Runs a function which evaluates an expression against each array
+/// element. Each element, and its position in the array, are bound to
+/// the `$value` and `$index` parameters, so the expression can still
+/// refer to the fields of the current document.
+pub async fn iterate(
+	stk: &mut Stk,
+	ctx: &Context<'_>,
+	opt: &Options,
+	doc: Option<&CursorDoc<'_>>,
+	name: &str,
+	args: &[Value],
+) -> Result<Value, Error> {
+	// Check the number of arguments
+	let (array, exp) = match args {
+		[array, exp] => (array, exp),
+		_ => {
+			return Err(Error::InvalidArguments {
+				name: name.to_owned(),
+				message: String::from("Expected 2 arguments."),
+			})
+		}
+	};
+	// Compute the array to iterate over
+	let array = stk.run(|stk| array.compute(stk, ctx, opt, doc)).await?;
+	let array = array.coerce_to_array().map_err(|e| Error::InvalidArguments {
+		name: name.to_owned(),
+		message: format!("Argument 1 was the wrong type. {e}"),
+	})?;
+	// Evaluate the expression for each element
+	let mut out = Vec::with_capacity(array.len());
+	for (i, v) in array.into_iter().enumerate() {
+		let res = {
+			let mut ctx = Context::new(ctx);
+			ctx.add_value("value", &v);
+			ctx.add_value("index", Value::from(i));
+			stk.run(|stk| exp.compute(stk, &ctx, opt, doc)).await?
+		};
+		match name {
+			"array::filter" => {
+				if res.is_truthy() {
+					out.push(v);
+				}
+			}
+			_ => out.push(res),
+		}
+	}
+	Ok(out.into())
+}
+
 pub mod sort {
 
 	use crate::err::Error;
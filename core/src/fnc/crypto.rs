@@ -38,6 +38,32 @@ pub fn sha512((arg,): (String,)) -> Result<Value, Error> {
 	Ok(val.into())
 }
 
+pub mod hmac {
+
+	use crate::err::Error;
+	use crate::sql::value::Value;
+	use hmac::{Hmac, Mac};
+	use sha2::{Sha256, Sha512};
+
+	pub fn sha256((key, arg): (String, String)) -> Result<Value, Error> {
+		let mut mac = Hmac::<Sha256>::new_from_slice(key.as_bytes())
+			.map_err(|e| Error::Thrown(e.to_string()))?;
+		mac.update(arg.as_bytes());
+		let val = mac.finalize().into_bytes();
+		let val = format!("{val:x}");
+		Ok(val.into())
+	}
+
+	pub fn sha512((key, arg): (String, String)) -> Result<Value, Error> {
+		let mut mac = Hmac::<Sha512>::new_from_slice(key.as_bytes())
+			.map_err(|e| Error::Thrown(e.to_string()))?;
+		mac.update(arg.as_bytes());
+		let val = mac.finalize().into_bytes();
+		let val = format!("{val:x}");
+		Ok(val.into())
+	}
+}
+
 /// Allowed to cost this much more than default setting for each hash function.
 const COST_ALLOWANCE: u32 = 4;
 
@@ -81,22 +107,27 @@ pub mod argon2 {
 
 	use super::COST_ALLOWANCE;
 	use crate::err::Error;
+	use crate::iam::password;
 	use crate::sql::value::Value;
 	use argon2::{
-		password_hash::{PasswordHash, PasswordHasher, SaltString},
+		password_hash::{PasswordHash, PasswordHasher},
 		Argon2,
 	};
-	use rand::rngs::OsRng;
 
 	pub fn cmp((hash, pass): (String, String)) -> Result<Value, Error> {
 		type Params<'a> = <Argon2<'a> as PasswordHasher>::Params;
+		// Allow for hashes created with the configured parameters
+		let max = password::params();
+		let m_cost = max.m_cost().max(Params::DEFAULT_M_COST);
+		let t_cost = max.t_cost().max(Params::DEFAULT_T_COST);
+		let p_cost = max.p_cost().max(Params::DEFAULT_P_COST);
 		Ok(PasswordHash::new(&hash)
 			.ok()
 			.filter(|test| {
 				bounded_verify_password!(Argon2, pass, test, |params: &Params| {
-					params.m_cost() <= Params::DEFAULT_M_COST.saturating_mul(COST_ALLOWANCE)
-						&& params.t_cost() <= Params::DEFAULT_T_COST.saturating_mul(COST_ALLOWANCE)
-						&& params.p_cost() <= Params::DEFAULT_P_COST.saturating_mul(COST_ALLOWANCE)
+					params.m_cost() <= m_cost.saturating_mul(COST_ALLOWANCE)
+						&& params.t_cost() <= t_cost.saturating_mul(COST_ALLOWANCE)
+						&& params.p_cost() <= p_cost.saturating_mul(COST_ALLOWANCE)
 				})
 			})
 			.is_some()
@@ -104,10 +135,7 @@ pub mod argon2 {
 	}
 
 	pub fn gen((pass,): (String,)) -> Result<Value, Error> {
-		let algo = Argon2::default();
-		let salt = SaltString::generate(&mut OsRng);
-		let hash = algo.hash_password(pass.as_ref(), &salt).unwrap().to_string();
-		Ok(hash.into())
+		Ok(password::hash(&pass).into())
 	}
 }
 
@@ -128,6 +128,7 @@ pub fn synchronous(
 		"array::pop" => array::pop,
 		"array::prepend" => array::prepend,
 		"array::push" => array::push,
+		"array::range" => array::range,
 		"array::remove" => array::remove,
 		"array::reverse" => array::reverse,
 		"array::shuffle" => array::shuffle,
@@ -146,6 +147,8 @@ pub fn synchronous(
 		"crypto::sha1" => crypto::sha1,
 		"crypto::sha256" => crypto::sha256,
 		"crypto::sha512" => crypto::sha512,
+		"crypto::hmac::sha256" => crypto::hmac::sha256,
+		"crypto::hmac::sha512" => crypto::hmac::sha512,
 		//
 		"duration::days" => duration::days,
 		"duration::hours" => duration::hours,
@@ -228,8 +231,10 @@ pub fn synchronous(
 		"object::from_entries" => object::from_entries,
 		"object::keys" => object::keys,
 		"object::len" => object::len,
+		"object::merge" => object::merge,
 		"object::values" => object::values,
 		//
+		"parse::email::domain" => parse::email::domain,
 		"parse::email::host" => parse::email::host,
 		"parse::email::user" => parse::email::user,
 		"parse::url::domain" => parse::url::domain,
@@ -240,18 +245,18 @@ pub fn synchronous(
 		"parse::url::query" => parse::url::query,
 		"parse::url::scheme" => parse::url::scheme,
 		//
-		"rand" => rand::rand,
-		"rand::bool" => rand::bool,
-		"rand::enum" => rand::r#enum,
-		"rand::float" => rand::float,
-		"rand::guid" => rand::guid,
-		"rand::int" => rand::int,
-		"rand::string" => rand::string,
-		"rand::time" => rand::time,
-		"rand::ulid" => rand::ulid,
-		"rand::uuid::v4" => rand::uuid::v4,
-		"rand::uuid::v7" => rand::uuid::v7,
-		"rand::uuid" => rand::uuid,
+		"rand" => rand::rand(ctx),
+		"rand::bool" => rand::bool(ctx),
+		"rand::enum" => rand::r#enum(ctx),
+		"rand::float" => rand::float(ctx),
+		"rand::guid" => rand::guid(ctx),
+		"rand::int" => rand::int(ctx),
+		"rand::string" => rand::string(ctx),
+		"rand::time" => rand::time(ctx),
+		"rand::ulid" => rand::ulid(ctx),
+		"rand::uuid::v4" => rand::uuid::v4(ctx),
+		"rand::uuid::v7" => rand::uuid::v7(ctx),
+		"rand::uuid" => rand::uuid(ctx),
 		//
 		"session::ac" => session::ac(ctx),
 		"session::db" => session::db(ctx),
@@ -283,6 +288,8 @@ pub fn synchronous(
 		"string::distance::levenshtein" => string::distance::levenshtein,
 		"string::html::encode" => string::html::encode,
 		"string::html::sanitize" => string::html::sanitize,
+		"string::pad::end" => string::pad::end,
+		"string::pad::start" => string::pad::start,
 		"string::is::alphanum" => string::is::alphanum,
 		"string::is::alpha" => string::is::alpha,
 		"string::is::ascii" => string::is::ascii,
@@ -66,6 +66,12 @@ pub fn keys((object,): (Object,)) -> Result<Value, Error> {
 	Ok(Value::Array(Array(object.keys().map(|v| Value::Strand(Strand(v.to_owned()))).collect())))
 }
 
+pub fn merge((object, other): (Object, Object)) -> Result<Value, Error> {
+	let mut object = Value::Object(object);
+	object.merge(Value::Object(other))?;
+	Ok(object)
+}
+
 pub fn values((object,): (Object,)) -> Result<Value, Error> {
 	Ok(Value::Array(Array(object.values().map(|v| v.to_owned()).collect())))
 }
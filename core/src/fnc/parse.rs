@@ -4,6 +4,18 @@ pub mod email {
 	use crate::sql::value::Value;
 	use addr::email::Host;
 
+	pub fn domain((string,): (String,)) -> Result<Value, Error> {
+		// Parse the email address
+		Ok(match addr::parse_email_address(&string) {
+			// Return the host part only if it is a domain name
+			Ok(v) => match v.host() {
+				Host::Domain(name) => name.as_str().into(),
+				Host::IpAddr(_) => Value::None,
+			},
+			Err(_) => Value::None,
+		})
+	}
+
 	pub fn host((string,): (String,)) -> Result<Value, Error> {
 		// Parse the email address
 		Ok(match addr::parse_email_address(&string) {
@@ -27,6 +39,16 @@ pub mod email {
 
 	#[cfg(test)]
 	mod tests {
+		#[test]
+		fn domain() {
+			let input = (String::from("john.doe@example.com"),);
+			let value = super::domain(input).unwrap();
+			assert_eq!(value, "example.com".into());
+			let input = (String::from("john.doe@[127.0.0.1]"),);
+			let value = super::domain(input).unwrap();
+			assert_eq!(value, super::Value::None);
+		}
+
 		#[test]
 		fn host() {
 			let input = (String::from("john.doe@example.com"),);
@@ -1,55 +1,58 @@
 use crate::cnf::ID_CHARS;
+use crate::ctx::Context;
 use crate::err::Error;
+use crate::sql::id::Id;
 use crate::sql::uuid::Uuid;
 use crate::sql::value::Value;
 use chrono::{TimeZone, Utc};
-use nanoid::nanoid;
 use rand::distributions::{Alphanumeric, DistString};
 use rand::prelude::IteratorRandom;
 use rand::Rng;
-use ulid::Ulid;
 
-pub fn rand(_: ()) -> Result<Value, Error> {
-	Ok(rand::random::<f64>().into())
+pub fn rand(ctx: &Context, _: ()) -> Result<Value, Error> {
+	Ok(ctx.with_rng(|rng| rng.gen::<f64>()).into())
 }
 
-pub fn bool(_: ()) -> Result<Value, Error> {
-	Ok(rand::random::<bool>().into())
+pub fn bool(ctx: &Context, _: ()) -> Result<Value, Error> {
+	Ok(ctx.with_rng(|rng| rng.gen::<bool>()).into())
 }
 
-pub fn r#enum(mut args: Vec<Value>) -> Result<Value, Error> {
+pub fn r#enum(ctx: &Context, mut args: Vec<Value>) -> Result<Value, Error> {
 	Ok(match args.len() {
 		0 => Value::None,
 		1 => match args.remove(0) {
-			Value::Array(v) => v.into_iter().choose(&mut rand::thread_rng()).unwrap_or(Value::None),
+			Value::Array(v) => ctx.with_rng(|rng| v.into_iter().choose(rng)).unwrap_or(Value::None),
 			v => v,
 		},
-		_ => args.into_iter().choose(&mut rand::thread_rng()).unwrap(),
+		_ => ctx.with_rng(|rng| args.into_iter().choose(rng)).unwrap(),
 	})
 }
 
-pub fn float((range,): (Option<(f64, f64)>,)) -> Result<Value, Error> {
-	Ok(if let Some((min, max)) = range {
-		if max < min {
-			rand::thread_rng().gen_range(max..=min)
-		} else {
-			rand::thread_rng().gen_range(min..=max)
-		}
-	} else {
-		rand::random::<f64>()
-	}
-	.into())
+pub fn float(ctx: &Context, (range,): (Option<(f64, f64)>,)) -> Result<Value, Error> {
+	Ok(ctx
+		.with_rng(|rng| {
+			if let Some((min, max)) = range {
+				if max < min {
+					rng.gen_range(max..=min)
+				} else {
+					rng.gen_range(min..=max)
+				}
+			} else {
+				rng.gen::<f64>()
+			}
+		})
+		.into())
 }
 
-pub fn guid((arg1, arg2): (Option<i64>, Option<i64>)) -> Result<Value, Error> {
+pub fn guid(ctx: &Context, (arg1, arg2): (Option<i64>, Option<i64>)) -> Result<Value, Error> {
 	// Set a reasonable maximum length
 	const LIMIT: i64 = 64;
 	// Check the function input arguments
 	let val = if let Some((min, max)) = arg1.zip(arg2) {
 		match min {
 			min if (1..=LIMIT).contains(&min) => match max {
-				max if min <= max && max <= LIMIT => rand::thread_rng().gen_range(min as usize..=max as usize),
-				max if max >= 1 && max <= min => rand::thread_rng().gen_range(max as usize..=min as usize),
+				max if min <= max && max <= LIMIT => ctx.with_rng(|rng| rng.gen_range(min as usize..=max as usize)),
+				max if max >= 1 && max <= min => ctx.with_rng(|rng| rng.gen_range(max as usize..=min as usize)),
 				_ => return Err(Error::InvalidArguments {
 					name: String::from("rand::guid"),
 					message: format!("To generate a guid of between X and Y characters in length, the 2 arguments must be positive numbers and no higher than {LIMIT}."),
@@ -73,31 +76,38 @@ pub fn guid((arg1, arg2): (Option<i64>, Option<i64>)) -> Result<Value, Error> {
 		20
 	};
 	// Generate the random guid
-	Ok(nanoid!(val, &ID_CHARS).into())
+	Ok(ctx
+		.with_rng(|rng| {
+			(0..val).map(|_| ID_CHARS[rng.gen_range(0..ID_CHARS.len())]).collect::<String>()
+		})
+		.into())
 }
 
-pub fn int((range,): (Option<(i64, i64)>,)) -> Result<Value, Error> {
-	Ok(if let Some((min, max)) = range {
-		if max < min {
-			rand::thread_rng().gen_range(max..=min)
-		} else {
-			rand::thread_rng().gen_range(min..=max)
-		}
-	} else {
-		rand::random::<i64>()
-	}
-	.into())
+pub fn int(ctx: &Context, (range,): (Option<(i64, i64)>,)) -> Result<Value, Error> {
+	Ok(ctx
+		.with_rng(|rng| {
+			if let Some((min, max)) = range {
+				if max < min {
+					rng.gen_range(max..=min)
+				} else {
+					rng.gen_range(min..=max)
+				}
+			} else {
+				rng.gen::<i64>()
+			}
+		})
+		.into())
 }
 
-pub fn string((arg1, arg2): (Option<i64>, Option<i64>)) -> Result<Value, Error> {
+pub fn string(ctx: &Context, (arg1, arg2): (Option<i64>, Option<i64>)) -> Result<Value, Error> {
 	// Set a reasonable maximum length
 	const LIMIT: i64 = 65536;
 	// Check the function input arguments
 	let val = if let Some((min, max)) = arg1.zip(arg2) {
 		match min {
 			min if (1..=LIMIT).contains(&min) => match max {
-				max if min <= max && max <= LIMIT => rand::thread_rng().gen_range(min as usize..=max as usize),
-				max if max >= 1 && max <= min => rand::thread_rng().gen_range(max as usize..=min as usize),
+				max if min <= max && max <= LIMIT => ctx.with_rng(|rng| rng.gen_range(min as usize..=max as usize)),
+				max if max >= 1 && max <= min => ctx.with_rng(|rng| rng.gen_range(max as usize..=min as usize)),
 				_ => return Err(Error::InvalidArguments {
 					name: String::from("rand::string"),
 					message: format!("To generate a string of between X and Y characters in length, the 2 arguments must be positive numbers and no higher than {LIMIT}."),
@@ -121,18 +131,18 @@ pub fn string((arg1, arg2): (Option<i64>, Option<i64>)) -> Result<Value, Error>
 		32
 	};
 	// Generate the random string
-	Ok(Alphanumeric.sample_string(&mut rand::thread_rng(), val).into())
+	Ok(ctx.with_rng(|rng| Alphanumeric.sample_string(rng, val)).into())
 }
 
-pub fn time((range,): (Option<(i64, i64)>,)) -> Result<Value, Error> {
+pub fn time(ctx: &Context, (range,): (Option<(i64, i64)>,)) -> Result<Value, Error> {
 	// Set the maximum valid seconds
 	const LIMIT: i64 = 8210298412799;
 	// Check the function input arguments
 	let val = if let Some((min, max)) = range {
 		match min {
 			min if (1..=LIMIT).contains(&min) => match max {
-				max if min <= max && max <= LIMIT => rand::thread_rng().gen_range(min..=max),
-				max if max >= 1 && max <= min => rand::thread_rng().gen_range(max..=min),
+				max if min <= max && max <= LIMIT => ctx.with_rng(|rng| rng.gen_range(min..=max)),
+				max if max >= 1 && max <= min => ctx.with_rng(|rng| rng.gen_range(max..=min)),
 				_ => return Err(Error::InvalidArguments {
 					name: String::from("rand::time"),
 					message: format!("To generate a time between X and Y seconds, the 2 arguments must be positive numbers and no higher than {LIMIT}."),
@@ -144,31 +154,35 @@ pub fn time((range,): (Option<(i64, i64)>,)) -> Result<Value, Error> {
 			}),
 		}
 	} else {
-		rand::thread_rng().gen_range(0..=LIMIT)
+		ctx.with_rng(|rng| rng.gen_range(0..=LIMIT))
 	};
 	// Generate the random time
 	Ok(Utc.timestamp_opt(val, 0).earliest().unwrap().into())
 }
 
-pub fn ulid(_: ()) -> Result<Value, Error> {
-	Ok(Ulid::new().to_string().into())
+pub fn ulid(ctx: &Context, _: ()) -> Result<Value, Error> {
+	let millis = ctx.id_millis();
+	Ok(ctx.with_rng(|rng| Id::ulid_from(millis, rng)).to_raw().into())
 }
 
-pub fn uuid(_: ()) -> Result<Value, Error> {
-	Ok(Uuid::new().into())
+pub fn uuid(ctx: &Context, _: ()) -> Result<Value, Error> {
+	let millis = ctx.id_millis();
+	Ok(ctx.with_rng(|rng| Uuid::new_v7_from(millis, rng)).into())
 }
 
 pub mod uuid {
 
+	use crate::ctx::Context;
 	use crate::err::Error;
 	use crate::sql::uuid::Uuid;
 	use crate::sql::value::Value;
 
-	pub fn v4(_: ()) -> Result<Value, Error> {
-		Ok(Uuid::new_v4().into())
+	pub fn v4(ctx: &Context, _: ()) -> Result<Value, Error> {
+		Ok(ctx.with_rng(Uuid::new_v4_from).into())
 	}
 
-	pub fn v7(_: ()) -> Result<Value, Error> {
-		Ok(Uuid::new_v7().into())
+	pub fn v7(ctx: &Context, _: ()) -> Result<Value, Error> {
+		let millis = ctx.id_millis();
+		Ok(ctx.with_rng(|rng| Uuid::new_v7_from(millis, rng)).into())
 	}
 }
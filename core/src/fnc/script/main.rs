@@ -6,6 +6,7 @@ use super::modules::loader;
 use super::modules::resolver;
 use super::modules::surrealdb::query::QueryContext;
 use super::modules::surrealdb::query::QUERY_DATA_PROP_NAME;
+use crate::cnf::{SCRIPTING_MAX_MEMORY_LIMIT, SCRIPTING_MAX_STACK_SIZE, SCRIPTING_MAX_TIME_LIMIT};
 use crate::ctx::Context;
 use crate::dbs::Options;
 use crate::doc::CursorDoc;
@@ -16,6 +17,10 @@ use js::object::Property;
 use js::prelude::*;
 use js::CatchResultExt;
 use js::{Class, Ctx, Function, Module, Promise};
+use std::sync::atomic::{AtomicBool, Ordering};
+use std::sync::Arc;
+use std::time::Duration;
+use trice::Instant;
 
 /// Insert query data into the context,
 ///
@@ -59,13 +64,27 @@ pub async fn run(
 	}
 	// Create an JavaScript context
 	let run = js::AsyncRuntime::new().unwrap();
-	// Explicitly set max stack size to 256 KiB
-	run.set_max_stack_size(262_144).await;
-	// Explicitly set max memory size to 2 MB
-	run.set_memory_limit(2_000_000).await;
-	// Ensure scripts are cancelled with context
+	// Explicitly set max stack size
+	run.set_max_stack_size(*SCRIPTING_MAX_STACK_SIZE).await;
+	// Explicitly set max memory size
+	run.set_memory_limit(*SCRIPTING_MAX_MEMORY_LIMIT).await;
+	// Ensure scripts are cancelled with context, or once they run for too long
 	let cancellation = context.cancellation();
-	let handler = Box::new(move || cancellation.is_done());
+	let limit = match *SCRIPTING_MAX_TIME_LIMIT {
+		0 => None,
+		v => Some(Duration::from_millis(v)),
+	};
+	let start = Instant::now();
+	let timedout = Arc::new(AtomicBool::new(false));
+	let interrupted = timedout.clone();
+	let handler = Box::new(move || match limit {
+		_ if cancellation.is_done() => true,
+		Some(limit) if start.elapsed() > limit => {
+			interrupted.store(true, Ordering::Relaxed);
+			true
+		}
+		_ => false,
+	});
 	run.set_interrupt_handler(Some(handler)).await;
 	// Create an execution context
 	let ctx = js::AsyncContext::full(&run).await.unwrap();
@@ -115,4 +134,11 @@ pub async fn run(
 		res.catch(&ctx).map_err(Error::from)
 	})
 	.await
+	.map_err(|e| match (timedout.load(Ordering::Relaxed), limit) {
+		// The script was interrupted once it reached the time limit
+		(true, Some(limit)) => Error::InvalidScript {
+			message: format!("The script exceeded the time limit of {}ms", limit.as_millis()),
+		},
+		_ => e,
+	})
 }
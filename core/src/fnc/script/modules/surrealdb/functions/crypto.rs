@@ -3,6 +3,7 @@ use crate::fnc::script::modules::impl_module_def;
 
 mod argon2;
 mod bcrypt;
+mod hmac;
 mod pbkdf2;
 mod scrypt;
 
@@ -18,6 +19,7 @@ impl_module_def!(
 	"sha512" => run,
 	"argon2" => (argon2::Package),
 	"bcrypt" => (bcrypt::Package),
+	"hmac" => (hmac::Package),
 	"pbkdf2" => (pbkdf2::Package),
 	"scrypt" => (scrypt::Package)
 );
@@ -0,0 +1,12 @@
+use super::super::run;
+use crate::fnc::script::modules::impl_module_def;
+
+#[non_exhaustive]
+pub struct Package;
+
+impl_module_def!(
+	Package,
+	"crypto::hmac",
+	"sha256" => run,
+	"sha512" => run
+);
@@ -11,5 +11,6 @@ impl_module_def!(
 	"from_entries" => run,
 	"keys" => run,
 	"len" => run,
+	"merge" => run,
 	"values" => run
 );
@@ -7,6 +7,7 @@ pub struct Package;
 impl_module_def!(
 	Package,
 	"parse::email",
+	"domain" => run,
 	"host" => run,
 	"user" => run
 );
@@ -4,6 +4,7 @@ use crate::fnc::script::modules::impl_module_def;
 mod distance;
 mod html;
 mod is;
+mod pad;
 mod semver;
 mod similarity;
 
@@ -23,6 +24,7 @@ impl_module_def!(
 	"len" => run,
 	"lowercase" => run,
 	"matches" => run,
+	"pad" => (pad::Package),
 	"repeat" => run,
 	"replace" => run,
 	"reverse" => run,
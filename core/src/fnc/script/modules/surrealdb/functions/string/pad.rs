@@ -0,0 +1,12 @@
+use super::run;
+use crate::fnc::script::modules::impl_module_def;
+
+#[non_exhaustive]
+pub struct Package;
+
+impl_module_def!(
+	Package,
+	"string::pad",
+	"end" => run,
+	"start" => run
+);
@@ -16,6 +16,20 @@ fn limit(name: &str, n: usize) -> Result<(), Error> {
 	}
 }
 
+/// Returns an error if either string is too long to compare, as comparing
+/// two strings takes time proportional to the product of their lengths.
+fn compare_limit(name: &str, a: &str, b: &str) -> Result<(), Error> {
+	const LIMIT: usize = 2usize.pow(13);
+	if a.len() > LIMIT || b.len() > LIMIT {
+		Err(Error::InvalidArguments {
+			name: name.to_owned(),
+			message: format!("The strings must not exceed {LIMIT} bytes."),
+		})
+	} else {
+		Ok(())
+	}
+}
+
 pub fn concat(args: Vec<Value>) -> Result<Value, Error> {
 	let strings = args.into_iter().map(Value::as_string).collect::<Vec<_>>();
 	limit("string::concat", strings.iter().map(String::len).sum::<usize>())?;
@@ -129,8 +143,20 @@ pub fn slug((string,): (String,)) -> Result<Value, Error> {
 	Ok(string::slug::slug(string).into())
 }
 
-pub fn split((val, chr): (String, String)) -> Result<Value, Error> {
-	Ok(val.split(&chr).collect::<Vec<&str>>().into())
+/// Splits a string, into at most `lim` parts if a limit is specified.
+/// A limit of 0 would never return any parts, so it is rejected.
+pub fn split((val, chr, lim): (String, String, Option<usize>)) -> Result<Value, Error> {
+	Ok(match lim {
+		Some(0) => {
+			return Err(Error::InvalidArguments {
+				name: String::from("string::split"),
+				message: String::from("The limit must be greater than 0."),
+			})
+		}
+		Some(lim) => val.splitn(lim, &chr).collect::<Vec<&str>>(),
+		None => val.split(&chr).collect::<Vec<&str>>(),
+	}
+	.into())
 }
 
 pub fn starts_with((val, chr): (String, String)) -> Result<Value, Error> {
@@ -151,19 +177,24 @@ pub fn words((string,): (String,)) -> Result<Value, Error> {
 
 pub mod distance {
 
+	use super::compare_limit;
 	use crate::err::Error;
 	use crate::sql::Value;
 
-	pub fn hamming((_, _): (String, String)) -> Result<Value, Error> {
-		Err(Error::FeatureNotYetImplemented {
-			feature: "string::distance::hamming() function".to_string(),
-		})
+	pub fn hamming((a, b): (String, String)) -> Result<Value, Error> {
+		compare_limit("string::distance::hamming", &a, &b)?;
+		match strsim::hamming(&a, &b) {
+			Ok(v) => Ok(v.into()),
+			Err(_) => Err(Error::InvalidArguments {
+				name: String::from("string::distance::hamming"),
+				message: String::from("The two strings must be of equal length."),
+			}),
+		}
 	}
 
-	pub fn levenshtein((_, _): (String, String)) -> Result<Value, Error> {
-		Err(Error::FeatureNotYetImplemented {
-			feature: "string::distance::levenshtein() function".to_string(),
-		})
+	pub fn levenshtein((a, b): (String, String)) -> Result<Value, Error> {
+		compare_limit("string::distance::levenshtein", &a, &b)?;
+		Ok(strsim::levenshtein(&a, &b).into())
 	}
 }
 
@@ -180,6 +211,36 @@ pub mod html {
 	}
 }
 
+pub mod pad {
+	use super::limit;
+	use crate::err::Error;
+	use crate::sql::value::Value;
+
+	/// Builds the padding needed to bring `val` up to `len` characters.
+	fn fill(name: &str, val: &str, len: usize, chr: Option<String>) -> Result<String, Error> {
+		let chr = chr.unwrap_or_else(|| String::from(" "));
+		if chr.is_empty() {
+			return Err(Error::InvalidArguments {
+				name: name.to_owned(),
+				message: String::from("The padding string must not be empty."),
+			});
+		}
+		let num = len.saturating_sub(val.chars().count());
+		limit(name, val.len().saturating_add(num.saturating_mul(chr.len())))?;
+		Ok(chr.chars().cycle().take(num).collect())
+	}
+
+	pub fn start((val, len, chr): (String, usize, Option<String>)) -> Result<Value, Error> {
+		let pad = fill("string::pad::start", &val, len, chr)?;
+		Ok((pad + &val).into())
+	}
+
+	pub fn end((val, len, chr): (String, usize, Option<String>)) -> Result<Value, Error> {
+		let pad = fill("string::pad::end", &val, len, chr)?;
+		Ok((val + &pad).into())
+	}
+}
+
 pub mod is {
 	use crate::err::Error;
 	use crate::sql::value::Value;
@@ -267,6 +328,7 @@ pub mod is {
 
 pub mod similarity {
 
+	use super::compare_limit;
 	use crate::err::Error;
 	use crate::fnc::util::string::fuzzy::Fuzzy;
 	use crate::sql::Value;
@@ -275,10 +337,9 @@ pub mod similarity {
 		Ok(a.as_str().fuzzy_score(b.as_str()).into())
 	}
 
-	pub fn jaro((_, _): (String, String)) -> Result<Value, Error> {
-		Err(Error::FeatureNotYetImplemented {
-			feature: "string::similarity::jaro() function".to_string(),
-		})
+	pub fn jaro((a, b): (String, String)) -> Result<Value, Error> {
+		compare_limit("string::similarity::jaro", &a, &b)?;
+		Ok(strsim::jaro(&a, &b).into())
 	}
 
 	pub fn smithwaterman((a, b): (String, String)) -> Result<Value, Error> {
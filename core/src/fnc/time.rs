@@ -3,7 +3,7 @@ use crate::sql::datetime::Datetime;
 use crate::sql::duration::Duration;
 use crate::sql::value::Value;
 use chrono::offset::TimeZone;
-use chrono::{DateTime, Datelike, DurationRound, Local, Timelike, Utc};
+use chrono::{DateTime, Datelike, DurationRound, FixedOffset, Local, Timelike, Utc};
 
 pub fn ceil((val, duration): (Datetime, Duration)) -> Result<Value, Error> {
 	match chrono::Duration::from_std(*duration) {
@@ -68,8 +68,27 @@ pub fn floor((val, duration): (Datetime, Duration)) -> Result<Value, Error> {
 	}
 }
 
-pub fn format((val, format): (Datetime, String)) -> Result<Value, Error> {
-	Ok(val.format(&format).to_string().into())
+pub fn format((val, format, tz): (Datetime, String, Option<String>)) -> Result<Value, Error> {
+	match tz {
+		// Format the datetime in the given timezone offset
+		Some(tz) => match tz.as_str() {
+			"Z" | "UTC" => Ok(val.format(&format).to_string().into()),
+			tz => match tz.parse::<FixedOffset>() {
+				Ok(tz) => Ok(val.with_timezone(&tz).format(&format).to_string().into()),
+				// Named timezones need a timezone database, which is not available
+				_ if tz.contains(|c: char| c.is_ascii_alphabetic()) => Err(Error::InvalidArguments {
+					name: String::from("time::format"),
+					message: format!("Timezone names such as '{tz}' are not supported. The third argument must be a timezone offset, such as '+05:30', '-08:00', or 'UTC'."),
+				}),
+				_ => Err(Error::InvalidArguments {
+					name: String::from("time::format"),
+					message: String::from("The third argument must be a timezone offset, such as '+05:30', '-08:00', or 'UTC'."),
+				}),
+			},
+		},
+		// Format the datetime in UTC
+		None => Ok(val.format(&format).to_string().into()),
+	}
 }
 
 pub fn group((val, group): (Datetime, String)) -> Result<Value, Error> {
@@ -89,6 +108,12 @@ pub fn group((val, group): (Datetime, String)) -> Result<Value, Error> {
 			.earliest()
 			.unwrap()
 			.into()),
+		"week" => {
+			// Weeks start on a Monday, as in ISO 8601
+			let days = val.weekday().num_days_from_monday() as i64;
+			let date = val.date_naive() - chrono::Duration::days(days);
+			Ok(Utc.from_utc_datetime(&date.and_hms_opt(0, 0, 0).unwrap()).into())
+		}
 		"hour" => Ok(Utc
 			.with_ymd_and_hms(val.year(), val.month(), val.day(), val.hour(),0,0)
 			.earliest()
@@ -106,7 +131,7 @@ pub fn group((val, group): (Datetime, String)) -> Result<Value, Error> {
 			.into()),
 		_ => Err(Error::InvalidArguments {
 			name: String::from("time::group"),
-			message: String::from("The second argument must be a string, and can be one of 'year', 'month', 'day', 'hour', 'minute', or 'second'."),
+			message: String::from("The second argument must be a string, and can be one of 'year', 'month', 'week', 'day', 'hour', 'minute', or 'second'."),
 		}),
 	}
 }
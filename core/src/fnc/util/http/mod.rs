@@ -1,10 +1,13 @@
+use crate::cnf::{HTTP_MAX_RESPONSE_SIZE, HTTP_REQUEST_TIMEOUT};
 use crate::ctx::Context;
 use crate::err::Error;
 use crate::sql::{Bytes, Object, Strand, Value};
 use crate::syn;
 
+use encoding_rs::{Encoding, UTF_8};
 use reqwest::header::CONTENT_TYPE;
 use reqwest::{Client, RequestBuilder, Response};
+use std::time::Duration;
 use url::Url;
 
 pub(crate) fn uri_is_valid(uri: &str) -> bool {
@@ -19,21 +22,97 @@ fn encode_body(req: RequestBuilder, body: Value) -> RequestBuilder {
 	}
 }
 
+fn timeout(ctx: &Context<'_>) -> Option<Duration> {
+	let limit = match *HTTP_REQUEST_TIMEOUT {
+		0 => None,
+		v => Some(Duration::from_millis(v)),
+	};
+	match (ctx.timeout(), limit) {
+		(Some(a), Some(b)) => Some(a.min(b)),
+		(a, b) => a.or(b),
+	}
+}
+
+fn too_large(limit: usize) -> Error {
+	Error::Http(format!("The response body exceeded the maximum size of {limit} bytes"))
+}
+
+async fn read_body(res: Response, limit: usize) -> Result<Vec<u8>, Error> {
+	// Check the advertised size of the response
+	if limit > 0 && res.content_length().is_some_and(|v| v > limit as u64) {
+		return Err(too_large(limit));
+	}
+	// Read the response body in chunks
+	#[cfg(not(target_arch = "wasm32"))]
+	{
+		let mut res = res;
+		let mut body = Vec::new();
+		while let Some(chunk) = res.chunk().await? {
+			if limit > 0 && body.len() + chunk.len() > limit {
+				return Err(too_large(limit));
+			}
+			body.extend_from_slice(&chunk);
+		}
+		Ok(body)
+	}
+	// Read the response body at once
+	#[cfg(target_arch = "wasm32")]
+	{
+		let body = res.bytes().await?;
+		if limit > 0 && body.len() > limit {
+			return Err(too_large(limit));
+		}
+		Ok(body.into())
+	}
+}
+
+fn decode_text(mime: &str, body: &[u8]) -> Result<String, Error> {
+	// Find the charset specified in the content type
+	let label = mime.split(';').skip(1).find_map(|param| {
+		let (key, val) = param.split_once('=')?;
+		key.trim().eq_ignore_ascii_case("charset").then(|| val.trim().trim_matches('"'))
+	});
+	// A byte order mark takes precedence over the content type
+	let (encoding, body) = match Encoding::for_bom(body) {
+		Some((encoding, len)) => (encoding, &body[len..]),
+		None => match label {
+			Some(label) => match Encoding::for_label(label.as_bytes()) {
+				Some(encoding) => (encoding, body),
+				None => {
+					return Err(Error::Http(format!(
+						"The response body has an unsupported charset '{label}'"
+					)))
+				}
+			},
+			None => (UTF_8, body),
+		},
+	};
+	// Decode the body, rejecting malformed input
+	match encoding.decode_without_bom_handling_and_without_replacement(body) {
+		Some(txt) => Ok(txt.into_owned()),
+		None => {
+			Err(Error::Http(format!("The response body is not valid {} text", encoding.name())))
+		}
+	}
+}
+
 async fn decode_response(res: Response) -> Result<Value, Error> {
 	match res.status() {
 		s if s.is_success() => match res.headers().get(CONTENT_TYPE) {
 			Some(mime) => match mime.to_str() {
 				Ok(v) if v.starts_with("application/json") => {
-					let txt = res.text().await?;
+					let mime = v.to_owned();
+					let txt = decode_text(&mime, &read_body(res, *HTTP_MAX_RESPONSE_SIZE).await?)?;
 					let val = syn::json(&txt)?;
 					Ok(val)
 				}
 				Ok(v) if v.starts_with("application/octet-stream") => {
-					let bytes = res.bytes().await?;
-					Ok(Value::Bytes(Bytes(bytes.into())))
+					let bytes = read_body(res, *HTTP_MAX_RESPONSE_SIZE).await?;
+					Ok(Value::Bytes(Bytes(bytes)))
 				}
 				Ok(v) if v.starts_with("text") => {
-					let txt = res.text().await?;
+					let mime = v.to_owned();
+					let txt = decode_text(&mime, &read_body(res, *HTTP_MAX_RESPONSE_SIZE).await?)?;
 					let val = txt.into();
 					Ok(val)
 				}
@@ -62,7 +141,7 @@ pub async fn head(ctx: &Context<'_>, uri: Strand, opts: impl Into<Object>) -> Re
 		req = req.header(k.as_str(), v.to_raw_string());
 	}
 	// Send the request and wait
-	let res = match ctx.timeout() {
+	let res = match timeout(ctx) {
 		#[cfg(not(target_arch = "wasm32"))]
 		Some(d) => req.timeout(d).send().await?,
 		_ => req.send().await?,
@@ -91,7 +170,7 @@ pub async fn get(ctx: &Context<'_>, uri: Strand, opts: impl Into<Object>) -> Res
 		req = req.header(k.as_str(), v.to_raw_string());
 	}
 	// Send the request and wait
-	let res = match ctx.timeout() {
+	let res = match timeout(ctx) {
 		#[cfg(not(target_arch = "wasm32"))]
 		Some(d) => req.timeout(d).send().await?,
 		_ => req.send().await?,
@@ -124,7 +203,7 @@ pub async fn put(
 	// Submit the request body
 	req = encode_body(req, body);
 	// Send the request and wait
-	let res = match ctx.timeout() {
+	let res = match timeout(ctx) {
 		#[cfg(not(target_arch = "wasm32"))]
 		Some(d) => req.timeout(d).send().await?,
 		_ => req.send().await?,
@@ -157,7 +236,7 @@ pub async fn post(
 	// Submit the request body
 	req = encode_body(req, body);
 	// Send the request and wait
-	let res = match ctx.timeout() {
+	let res = match timeout(ctx) {
 		#[cfg(not(target_arch = "wasm32"))]
 		Some(d) => req.timeout(d).send().await?,
 		_ => req.send().await?,
@@ -190,7 +269,7 @@ pub async fn patch(
 	// Submit the request body
 	req = encode_body(req, body);
 	// Send the request and wait
-	let res = match ctx.timeout() {
+	let res = match timeout(ctx) {
 		#[cfg(not(target_arch = "wasm32"))]
 		Some(d) => req.timeout(d).send().await?,
 		_ => req.send().await?,
@@ -220,7 +299,7 @@ pub async fn delete(
 		req = req.header(k.as_str(), v.to_raw_string());
 	}
 	// Send the request and wait
-	let res = match ctx.timeout() {
+	let res = match timeout(ctx) {
 		#[cfg(not(target_arch = "wasm32"))]
 		Some(d) => req.timeout(d).send().await?,
 		_ => req.send().await?,
@@ -228,3 +307,78 @@ pub async fn delete(
 	// Receive the response as a value
 	decode_response(res).await
 }
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+	use crate::dbs::Capabilities;
+	use std::time::Instant;
+	use wiremock::matchers::method;
+	use wiremock::{Mock, MockServer, ResponseTemplate};
+
+	#[test]
+	fn decode_text_defaults_to_utf8() {
+		let txt = decode_text("text/plain", "café".as_bytes()).unwrap();
+		assert_eq!(txt, "café");
+	}
+
+	#[test]
+	fn decode_text_uses_charset() {
+		let txt = decode_text("text/plain; charset=ISO-8859-1", b"caf\xe9").unwrap();
+		assert_eq!(txt, "café");
+		let txt = decode_text("application/json;charset=\"windows-1252\"", b"\"\x80\"").unwrap();
+		assert_eq!(txt, "\"€\"");
+	}
+
+	#[test]
+	fn decode_text_uses_byte_order_mark() {
+		let txt = decode_text("text/plain; charset=utf-8", b"\xff\xfeo\x00k\x00").unwrap();
+		assert_eq!(txt, "ok");
+	}
+
+	#[test]
+	fn decode_text_rejects_malformed_input() {
+		let res = decode_text("text/plain; charset=utf-8", b"caf\xe9");
+		assert!(matches!(res, Err(Error::Http(e)) if e.contains("UTF-8")), "{res:?}");
+		let res = decode_text("text/plain; charset=unknown", b"ok");
+		assert!(matches!(res, Err(Error::Http(e)) if e.contains("unknown")), "{res:?}");
+	}
+
+	#[tokio::test]
+	async fn read_body_respects_size_limit() {
+		let server = MockServer::start().await;
+		Mock::given(method("GET"))
+			.respond_with(ResponseTemplate::new(200).set_body_bytes(vec![0u8; 16]))
+			.mount(&server)
+			.await;
+		// The body fits within the limit
+		let res = reqwest::get(server.uri()).await.unwrap();
+		assert_eq!(read_body(res, 16).await.unwrap().len(), 16);
+		// A limit of zero disables the check
+		let res = reqwest::get(server.uri()).await.unwrap();
+		assert_eq!(read_body(res, 0).await.unwrap().len(), 16);
+		// The body exceeds the limit
+		let res = reqwest::get(server.uri()).await.unwrap();
+		let res = read_body(res, 8).await;
+		assert!(
+			matches!(res, Err(Error::Http(e)) if e.contains("maximum size of 8 bytes")),
+			"{res:?}"
+		);
+	}
+
+	#[tokio::test]
+	async fn request_respects_query_timeout() {
+		let server = MockServer::start().await;
+		Mock::given(method("GET"))
+			.respond_with(ResponseTemplate::new(200).set_delay(Duration::from_secs(10)))
+			.mount(&server)
+			.await;
+		let mut ctx = Context::background();
+		ctx.add_capabilities(Capabilities::all());
+		ctx.add_timeout(Duration::from_millis(100)).unwrap();
+		let now = Instant::now();
+		let res = get(&ctx, server.uri().into(), Object::default()).await;
+		assert!(matches!(res, Err(Error::Http(_))), "{res:?}");
+		assert!(now.elapsed() < Duration::from_secs(5));
+	}
+}
@@ -0,0 +1,180 @@
+use crate::dbs::Session;
+use crate::err::Error;
+use crate::iam::Level;
+use crate::kvs::{Datastore, LockType::*, TransactionType::*};
+use crate::sql::AllowIp;
+
+/// Checks that the client address of an authenticated session is allowed by
+/// the namespace of the actor, and by the user or access method it used.
+pub(crate) async fn check(kvs: &Datastore, session: &Session) -> Result<(), Error> {
+	let ac = session.ac.as_deref();
+	match session.au.level() {
+		Level::Root if ac.is_none() => allowed(kvs, session, None, None, None).await,
+		Level::Namespace(ns) => allowed(kvs, session, Some(ns.as_str()), None, ac).await,
+		Level::Database(ns, db) => {
+			allowed(kvs, session, Some(ns.as_str()), Some(db.as_str()), ac).await
+		}
+		Level::Record(ns, db, _) => {
+			allowed(kvs, session, Some(ns.as_str()), Some(db.as_str()), ac).await
+		}
+		_ => Ok(()),
+	}
+}
+
+/// Checks that the client address of a session is allowed to use a
+/// database access method, before any authentication takes place.
+pub(crate) async fn check_db_access(
+	kvs: &Datastore,
+	session: &Session,
+	ns: &str,
+	db: &str,
+	ac: &str,
+) -> Result<(), Error> {
+	allowed(kvs, session, Some(ns), Some(db), Some(ac)).await
+}
+
+/// Checks that the client address of a session which was authenticated with
+/// a client certificate is allowed by the namespace of the actor. Certificates
+/// are not tied to a user or an access method, so only the namespace list is
+/// checked, but the namespace and database of the actor need to exist.
+pub(crate) async fn check_certificate(kvs: &Datastore, session: &Session) -> Result<(), Error> {
+	// Create a new readonly transaction
+	let mut tx = kvs.transaction(Read, Optimistic).await?;
+	let res = async {
+		let mut lists: Vec<AllowIp> = Vec::new();
+		let level = session.au.level();
+		// Fetch the allowed addresses for the namespace
+		if let Some(ns) = level.ns() {
+			lists.push(tx.get_ns(ns).await?.allow);
+		}
+		// Check that the database exists
+		if let (Some(ns), Some(db)) = (level.ns(), level.db()) {
+			tx.get_db(ns, db).await?;
+		}
+		Ok::<_, Error>(lists)
+	}
+	.await;
+	// Ensure that the transaction is cancelled
+	tx.cancel().await?;
+	// Deny access if any of the definitions could not be fetched
+	let lists = match res {
+		Ok(v) => v,
+		Err(e) => {
+			trace!("Unable to fetch the allowed addresses: {e}");
+			return Err(Error::InvalidAuth);
+		}
+	};
+	permitted(session, &lists)
+}
+
+async fn allowed(
+	kvs: &Datastore,
+	session: &Session,
+	ns: Option<&str>,
+	db: Option<&str>,
+	ac: Option<&str>,
+) -> Result<(), Error> {
+	// Create a new readonly transaction
+	let mut tx = kvs.transaction(Read, Optimistic).await?;
+	let res = async {
+		let mut lists: Vec<AllowIp> = Vec::new();
+		// Fetch the allowed addresses for the namespace
+		if let Some(ns) = ns {
+			lists.push(tx.get_ns(ns).await?.allow);
+		}
+		// Fetch the allowed addresses for the access method or user
+		let id = session.au.id();
+		let allow = match (ns, db, ac) {
+			(Some(ns), Some(db), Some(ac)) => tx.get_db_access(ns, db, ac).await?.allow,
+			(Some(ns), None, Some(ac)) => tx.get_ns_access(ns, ac).await?.allow,
+			(Some(ns), Some(db), None) => tx.get_db_user(ns, db, id).await?.allow,
+			(Some(ns), None, None) => tx.get_ns_user(ns, id).await?.allow,
+			(None, _, _) => tx.get_root_user(id).await?.allow,
+		};
+		lists.push(allow);
+		Ok::<_, Error>(lists)
+	}
+	.await;
+	// Ensure that the transaction is cancelled
+	tx.cancel().await?;
+	// Deny access if any of the definitions could not be fetched
+	let lists = match res {
+		Ok(v) => v,
+		Err(e) => {
+			trace!("Unable to fetch the allowed addresses: {e}");
+			return Err(Error::InvalidAuth);
+		}
+	};
+	permitted(session, &lists)
+}
+
+/// Checks the client address of a session against each list of allowed addresses
+fn permitted(session: &Session, lists: &[AllowIp]) -> Result<(), Error> {
+	// There is nothing to check if no addresses are restricted
+	if lists.iter().all(AllowIp::is_empty) {
+		return Ok(());
+	}
+	// Deny access if the client address is unknown
+	let Some(ip) = &session.ip else {
+		trace!("Authentication is not allowed from an unknown address");
+		return Err(Error::InvalidAuth);
+	};
+	// Check the client address against each list
+	if lists.iter().all(|v| v.allows(ip)) {
+		Ok(())
+	} else {
+		trace!("Authentication is not allowed from address `{ip}`");
+		Err(Error::InvalidAuth)
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+	use crate::iam::signin::root_user;
+
+	#[tokio::test]
+	async fn test_allow_ip() {
+		let ds = Datastore::new("memory").await.unwrap();
+		let sql = "DEFINE USER tobie ON ROOT PASSWORD 'pass' ROLES OWNER ALLOW IP '10.0.0.0/8'";
+		ds.execute(sql, &Session::owner(), None).await.unwrap();
+		// Authentication is allowed from within the range
+		let mut sess = Session::default();
+		sess.ip = Some("10.1.2.3".to_owned());
+		root_user(&ds, &mut sess, "tobie".to_owned(), "pass".to_owned()).await.unwrap();
+		assert!(check(&ds, &sess).await.is_ok());
+		// Authentication is rejected from outside the range
+		sess.ip = Some("192.168.1.1".to_owned());
+		assert!(matches!(check(&ds, &sess).await, Err(Error::InvalidAuth)));
+		// Authentication is rejected from an unknown address
+		sess.ip = None;
+		assert!(matches!(check(&ds, &sess).await, Err(Error::InvalidAuth)));
+		// Authentication is rejected if the user no longer exists
+		sess.ip = Some("10.1.2.3".to_owned());
+		ds.execute("REMOVE USER tobie ON ROOT", &Session::owner(), None).await.unwrap();
+		assert!(matches!(check(&ds, &sess).await, Err(Error::InvalidAuth)));
+	}
+
+	#[tokio::test]
+	async fn test_allow_ip_certificate() {
+		use crate::iam::{Actor, Auth, Role};
+		use std::sync::Arc;
+		let ds = Datastore::new("memory").await.unwrap();
+		let sql = "DEFINE NAMESPACE test ALLOW IP '10.0.0.0/8'; USE NS test; DEFINE DATABASE test;";
+		ds.execute(sql, &Session::owner(), None).await.unwrap();
+		let level = Level::Database("test".to_owned(), "test".to_owned());
+		let mut sess = Session::default();
+		sess.au = Arc::new(Auth::new(Actor::new("client".to_owned(), vec![Role::Viewer], level)));
+		// Authentication is allowed from within the range of the namespace
+		sess.ip = Some("10.1.2.3".to_owned());
+		assert!(check_certificate(&ds, &sess).await.is_ok());
+		// Authentication is rejected from outside the range
+		sess.ip = Some("192.168.1.1".to_owned());
+		assert!(matches!(check_certificate(&ds, &sess).await, Err(Error::InvalidAuth)));
+		// Authentication is rejected if the database does not exist
+		let level = Level::Database("test".to_owned(), "other".to_owned());
+		sess.au = Arc::new(Auth::new(Actor::new("client".to_owned(), vec![Role::Viewer], level)));
+		sess.ip = Some("10.1.2.3".to_owned());
+		assert!(matches!(check_certificate(&ds, &sess).await, Err(Error::InvalidAuth)));
+	}
+}
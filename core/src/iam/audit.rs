@@ -0,0 +1,114 @@
+//! The audit log records who authenticated with the datastore, who executed
+//! statements which change the schema or the stored data, which records
+//! were changed by them, and which tokens and sessions were revoked. Audit events are emitted with the `surrealdb::audit`
+//! target, so that they can be routed to a dedicated sink independently of
+//! the application logs.
+use crate::cnf::AUDIT_LOG;
+use crate::ctx::Context;
+use crate::dbs::{Options, Session};
+use crate::sql::{Thing, Value};
+
+/// The target under which all audit events are emitted
+pub const TARGET: &str = "surrealdb::audit";
+
+/// Retrieve the id of the token used to authenticate, if any
+fn token_id(tk: Option<&Value>) -> Option<String> {
+	match tk {
+		Some(Value::Object(tk)) => tk.get("jti").map(Value::to_raw_string),
+		_ => None,
+	}
+}
+
+/// Record an authentication attempt
+pub(crate) fn authentication(method: &str, user: Option<&str>, session: &Session, success: bool) {
+	if *AUDIT_LOG {
+		info!(
+			target: TARGET,
+			method,
+			success,
+			actor = user.unwrap_or(session.au.id()),
+			level = %session.au.level(),
+			access = session.ac.as_deref(),
+			token = token_id(session.tk.as_ref()).as_deref(),
+			ip = session.ip.as_deref(),
+			"Authentication"
+		);
+	}
+}
+
+/// Retrieve a field of the session which is running a statement
+fn session_field(ctx: &Context<'_>, name: &str) -> Option<Value> {
+	match ctx.value("session") {
+		Some(Value::Object(v)) => v.get(name).filter(|v| v.is_some()).cloned(),
+		_ => None,
+	}
+}
+
+/// Record the execution of a data-changing statement
+pub(crate) fn statement(ctx: &Context<'_>, opt: &Options, statement: &str, success: bool) {
+	if *AUDIT_LOG {
+		info!(
+			target: TARGET,
+			statement,
+			success,
+			actor = opt.auth.id(),
+			level = %opt.auth.level(),
+			access = session_field(ctx, "ac").map(|v| v.to_raw_string()).as_deref(),
+			token = token_id(session_field(ctx, "tk").as_ref()).as_deref(),
+			ip = session_field(ctx, "ip").map(|v| v.to_raw_string()).as_deref(),
+			ns = opt.ns().ok(),
+			db = opt.db().ok(),
+			"Statement"
+		);
+	}
+}
+
+/// Record the revocation of a token, of the tokens issued to a user or
+/// record user, or the termination of a session, along with the number of
+/// connected sessions which were terminated as a result.
+pub(crate) fn revocation(
+	ctx: &Context<'_>,
+	opt: &Options,
+	kind: &str,
+	target: &str,
+	sessions: usize,
+) {
+	if *AUDIT_LOG {
+		info!(
+			target: TARGET,
+			kind,
+			revoked = target,
+			sessions,
+			actor = opt.auth.id(),
+			level = %opt.auth.level(),
+			access = session_field(ctx, "ac").map(|v| v.to_raw_string()).as_deref(),
+			token = token_id(session_field(ctx, "tk").as_ref()).as_deref(),
+			ip = session_field(ctx, "ip").map(|v| v.to_raw_string()).as_deref(),
+			ns = opt.ns().ok(),
+			db = opt.db().ok(),
+			"Revocation"
+		);
+	}
+}
+
+/// Record a change to a single record. Changes are recorded where the record
+/// is written, so this includes the records which are changed by events,
+/// functions, and table views, as well as by the statement itself.
+pub(crate) fn record(ctx: &Context<'_>, opt: &Options, action: &str, rid: &Thing) {
+	if *AUDIT_LOG {
+		info!(
+			target: TARGET,
+			action,
+			actor = opt.auth.id(),
+			level = %opt.auth.level(),
+			access = session_field(ctx, "ac").map(|v| v.to_raw_string()).as_deref(),
+			token = token_id(session_field(ctx, "tk").as_ref()).as_deref(),
+			ip = session_field(ctx, "ip").map(|v| v.to_raw_string()).as_deref(),
+			ns = opt.ns().ok(),
+			db = opt.db().ok(),
+			table = rid.tb.as_str(),
+			record = %rid,
+			"Record"
+		);
+	}
+}
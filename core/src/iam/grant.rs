@@ -0,0 +1,411 @@
+use super::audit;
+use super::revoke;
+use super::verify::authenticate_record;
+use super::{Actor, Level};
+use crate::cnf::SERVER_NAME;
+use crate::dbs::Session;
+use crate::err::Error;
+use crate::iam::issue::{config, expiration};
+use crate::iam::token::Claims;
+use crate::iam::Auth;
+use crate::kvs::{Datastore, LockType::*, ScanPage, Transaction, TransactionType::*};
+use crate::sql::statements::DefineAccessStatement;
+use crate::sql::{AccessType, Datetime, Object, Thing, Value};
+use chrono::Utc;
+use jsonwebtoken::{encode, Header};
+use rand::distributions::Alphanumeric;
+use rand::Rng;
+use sha2::{Digest, Sha256};
+use std::sync::Arc;
+use uuid::Uuid;
+
+/// The length of a randomly generated refresh grant
+const LENGTH: usize = 64;
+
+/// The number of refresh grants which are scanned at once when deleting
+const GC_BATCH_SIZE: u32 = 1000;
+
+/// Issues a refresh grant for the record user which is authenticated on the
+/// session, when the record access method defines a `DURATION FOR GRANT`.
+pub async fn issue(kvs: &Datastore, session: &Session) -> Result<Option<String>, Error> {
+	// Refresh grants are only issued to record users
+	let (ns, db, ac, rid) = match (&session.ns, &session.db, &session.ac, &session.rd) {
+		(Some(ns), Some(db), Some(ac), Some(Value::Thing(rid))) => (ns, db, ac, rid),
+		_ => return Ok(None),
+	};
+	// Create a new writeable transaction
+	let mut tx = kvs.transaction(Write, Optimistic).await?;
+	// Store the grant if the access method allows it
+	let res = async {
+		let av = tx.get_db_access(ns, db, ac).await?;
+		match (av.kind, av.duration.grant) {
+			(AccessType::Record(_), Some(_)) => {
+				store(&mut tx, ns, db, ac, rid, expiration(av.duration.grant)?).await.map(Some)
+			}
+			_ => Ok(None),
+		}
+	}
+	.await;
+	// Commit or cancel the transaction
+	match res {
+		Ok(Some(_)) => tx.commit().await?,
+		_ => tx.cancel().await?,
+	}
+	res
+}
+
+/// Exchanges a refresh grant for a new authentication token and a new refresh
+/// grant. Each refresh grant can only be used once, after which it is revoked.
+pub async fn renew(
+	kvs: &Datastore,
+	session: &mut Session,
+	ns: String,
+	db: String,
+	ac: String,
+	grant: String,
+) -> Result<(String, String), Error> {
+	// Attempt to renew the grant with a copy of the session
+	let mut sess = session.clone();
+	let res = match renew_grant(kvs, &mut sess, ns, db, ac, grant).await {
+		// Check that the client address is allowed
+		Ok(v) => super::allow::check(kvs, &sess).await.map(|_| v),
+		Err(e) => Err(e),
+	};
+	// Only authenticate the session if successful
+	if res.is_ok() {
+		*session = sess;
+	}
+	// Record the authentication attempt
+	audit::authentication("renew", None, session, res.is_ok());
+	// Return the result
+	res
+}
+
+async fn renew_grant(
+	kvs: &Datastore,
+	session: &mut Session,
+	ns: String,
+	db: String,
+	ac: String,
+	grant: String,
+) -> Result<(String, String), Error> {
+	// Revoke the grant and fetch the record user it was issued to
+	let (av, rid) = consume(kvs, &ns, &db, &ac, &grant).await?;
+	// Only record access methods with an issuer can renew tokens
+	let iss = match av.kind {
+		AccessType::Record(at) => match at.jwt.issue {
+			Some(iss) => iss,
+			_ => return Err(Error::AccessMethodMismatch),
+		},
+		_ => return Err(Error::AccessMethodMismatch),
+	};
+	// Run the access method AUTHENTICATE clause
+	let rid = authenticate_record(kvs, session, &ns, &db, &ac, rid, av.authenticate).await?;
+	// Issue a replacement grant
+	let mut tx = kvs.transaction(Write, Optimistic).await?;
+	let grant = match store(&mut tx, &ns, &db, &ac, &rid, expiration(av.duration.grant)?).await {
+		Ok(grant) => {
+			tx.commit().await?;
+			grant
+		}
+		Err(e) => {
+			tx.cancel().await?;
+			return Err(e);
+		}
+	};
+	// Create the authentication key
+	let key = config(iss.alg, iss.key)?;
+	// Create the authentication claim
+	let val = Claims {
+		iss: Some(SERVER_NAME.to_owned()),
+		iat: Some(Utc::now().timestamp()),
+		nbf: Some(Utc::now().timestamp()),
+		exp: expiration(av.duration.token)?,
+		jti: Some(Uuid::now_v7().to_string()),
+		ns: Some(ns.to_owned()),
+		db: Some(db.to_owned()),
+		ac: Some(ac.to_owned()),
+		id: Some(rid.to_raw()),
+		..Claims::default()
+	};
+	// Create the authentication token
+	let tk = match encode(&Header::new(iss.alg.into()), &val, &key) {
+		Ok(tk) => tk,
+		_ => return Err(Error::TokenMakingFailed),
+	};
+	// Set the authentication on the session
+	session.tk = Some(val.into());
+	session.ns = Some(ns.to_owned());
+	session.db = Some(db.to_owned());
+	session.ac = Some(ac.to_owned());
+	session.rd = Some(Value::from(rid.to_owned()));
+	session.exp = expiration(av.duration.session)?;
+	session.au = Arc::new(Auth::new(Actor::new(
+		rid.to_string(),
+		Default::default(),
+		Level::Record(ns, db, rid.to_string()),
+	)));
+	Ok((tk, grant))
+}
+
+/// Revokes a refresh grant so that it can not be used again, and returns the
+/// access method and the record user which the grant was issued to, as long
+/// as the grant was still valid.
+async fn consume(
+	kvs: &Datastore,
+	ns: &str,
+	db: &str,
+	ac: &str,
+	grant: &str,
+) -> Result<(DefineAccessStatement, Thing), Error> {
+	// Create a new writeable transaction
+	let mut tx = kvs.transaction(Write, Optimistic).await?;
+	// Fetch and revoke the grant
+	let res = async {
+		// Fetch the specified access method from storage
+		let av = tx.get_db_access(ns, db, ac).await?;
+		// The access method must still issue refresh grants
+		if av.duration.grant.is_none() {
+			return Err(Error::InvalidAuth);
+		}
+		// Fetch and revoke the specified grant
+		let key = crate::key::database::gr::new(ns, db, ac, &hash(grant));
+		let val: Value = match tx.get(key.clone()).await? {
+			Some(v) => v.into(),
+			None => return Err(Error::InvalidAuth),
+		};
+		tx.del(key).await?;
+		// Check that the grant has not expired
+		if let Value::Number(exp) = val.pick(&["exp".into()]) {
+			if exp.as_int() < Utc::now().timestamp() {
+				return Err(Error::InvalidAuth);
+			}
+		}
+		// Check that the record user still exists
+		let rid = match val.pick(&["id".into()]) {
+			Value::Thing(rid) => rid,
+			_ => return Err(Error::InvalidAuth),
+		};
+		if tx.get(crate::key::thing::new(ns, db, &rid.tb, &rid.id)).await?.is_none() {
+			return Err(Error::InvalidAuth);
+		}
+		// Check that the record user has not been revoked since the grant was issued
+		let iat = match val.pick(&["iat".into()]) {
+			Value::Datetime(v) => v.0,
+			_ => Default::default(),
+		};
+		let rd = rid.to_string();
+		let level = Level::Record(ns.to_owned(), db.to_owned(), rd.clone());
+		if revoke::revoked(&mut tx, None, &revoke::subject(&level, &rd), iat).await? {
+			trace!("The refresh grant has been revoked");
+			return Err(Error::InvalidAuth);
+		}
+		Ok((av, rid))
+	}
+	.await;
+	// Commit the revocation even when the grant is no longer valid
+	match res {
+		Ok(_) | Err(Error::InvalidAuth) => tx.commit().await?,
+		_ => tx.cancel().await?,
+	}
+	res
+}
+
+/// Stores a new refresh grant for a record user
+async fn store(
+	tx: &mut Transaction,
+	ns: &str,
+	db: &str,
+	ac: &str,
+	rid: &Thing,
+	exp: Option<i64>,
+) -> Result<String, Error> {
+	// Generate a random refresh grant
+	let grant = rand::thread_rng()
+		.sample_iter(&Alphanumeric)
+		.take(LENGTH)
+		.map(char::from)
+		.collect::<String>();
+	// Only a hash of the grant is stored
+	let key = crate::key::database::gr::new(ns, db, ac, &hash(&grant));
+	let mut val = Object::default();
+	val.insert("id".to_owned(), Value::from(rid.to_owned()));
+	val.insert("iat".to_owned(), Value::from(Datetime::from(Utc::now())));
+	if let Some(exp) = exp {
+		val.insert("exp".to_owned(), Value::from(exp));
+	}
+	tx.set(key, Value::from(val)).await?;
+	Ok(grant)
+}
+
+/// Deletes all of the refresh grants which have expired at the given timestamp
+pub(crate) async fn gc_all_at(tx: &mut Transaction, ts: i64) -> Result<(), Error> {
+	let nses = tx.all_ns().await?;
+	for ns in nses.iter() {
+		let dbs = tx.all_db(&ns.name).await?;
+		for db in dbs.iter() {
+			let acs = tx.all_db_accesses(&ns.name, &db.name).await?;
+			for ac in acs.iter().filter(|ac| matches!(ac.kind, AccessType::Record(_))) {
+				gc_access(tx, &ns.name, &db.name, &ac.name, ts).await?;
+			}
+		}
+	}
+	Ok(())
+}
+
+/// Deletes the expired refresh grants of a single record access method
+async fn gc_access(
+	tx: &mut Transaction,
+	ns: &str,
+	db: &str,
+	ac: &str,
+	ts: i64,
+) -> Result<(), Error> {
+	let beg = crate::key::database::gr::prefix(ns, db, ac);
+	let end = crate::key::database::gr::suffix(ns, db, ac);
+	let mut next = Some(ScanPage::from(beg..end));
+	while let Some(page) = next {
+		let res = tx.scan_paged(page, GC_BATCH_SIZE).await?;
+		for (k, v) in res.values.into_iter() {
+			if let Value::Number(exp) = Value::from(v).pick(&["exp".into()]) {
+				if exp.as_int() < ts {
+					tx.del(k).await?;
+				}
+			}
+		}
+		next = res.next_page;
+	}
+	Ok(())
+}
+
+/// Hashes a refresh grant for storage
+fn hash(grant: &str) -> String {
+	hex::encode(Sha256::digest(grant.as_bytes()))
+}
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+	use crate::iam::signin::signin;
+	use std::collections::HashMap;
+
+	async fn setup(clauses: &str) -> (Datastore, Session) {
+		let ds = Datastore::new("memory").await.unwrap();
+		let sess = Session::owner().with_ns("test").with_db("test");
+		ds.execute(
+			&format!(
+				r#"
+				DEFINE TABLE user PERMISSIONS FULL;
+
+				DEFINE ACCESS user ON DATABASE TYPE RECORD
+					SIGNIN (
+						SELECT * FROM user WHERE name = $user AND crypto::argon2::compare(pass, $pass)
+					)
+					{clauses};
+
+				CREATE user:test CONTENT {{
+					name: 'user',
+					pass: crypto::argon2::generate('pass'),
+					enabled: true
+				}}
+				"#
+			),
+			&sess,
+			None,
+		)
+		.await
+		.unwrap();
+		// Signin with the record user
+		let mut sess = Session {
+			ns: Some("test".to_string()),
+			db: Some("test".to_string()),
+			..Default::default()
+		};
+		let mut vars: HashMap<&str, Value> = HashMap::new();
+		vars.insert("user", "user".into());
+		vars.insert("pass", "pass".into());
+		vars.insert("NS", "test".into());
+		vars.insert("DB", "test".into());
+		vars.insert("AC", "user".into());
+		signin(&ds, &mut sess, vars.into()).await.unwrap();
+		(ds, sess)
+	}
+
+	#[tokio::test]
+	async fn test_grant_not_issued_without_duration() {
+		let (ds, sess) = setup("DURATION FOR TOKEN 1h").await;
+		assert_eq!(issue(&ds, &sess).await.unwrap(), None);
+	}
+
+	#[tokio::test]
+	async fn test_grant_renew_and_rotate() {
+		let (ds, sess) = setup("DURATION FOR GRANT 30d, FOR TOKEN 5m").await;
+		let grant = issue(&ds, &sess).await.unwrap().expect("a grant to be issued");
+		// The grant can be exchanged for a new token and grant
+		let mut sess = Session::default();
+		let (_, next) =
+			renew(&ds, &mut sess, "test".into(), "test".into(), "user".into(), grant.clone())
+				.await
+				.unwrap();
+		assert_ne!(grant, next);
+		assert_eq!(sess.rd, Some(Value::from(Thing::from(("user", "test")))));
+		assert!(sess.au.is_record());
+		// The original grant has been revoked
+		let mut sess = Session::default();
+		let res = renew(&ds, &mut sess, "test".into(), "test".into(), "user".into(), grant).await;
+		assert!(matches!(res, Err(Error::InvalidAuth)));
+		// The replacement grant can be used
+		let res = renew(&ds, &mut sess, "test".into(), "test".into(), "user".into(), next).await;
+		assert!(res.is_ok());
+	}
+	#[tokio::test]
+	async fn test_grant_renew_runs_authenticate() {
+		let (ds, sess) = setup(
+			r#"AUTHENTICATE (IF $auth.enabled { $auth } ELSE { THROW "This user is disabled" })
+			DURATION FOR GRANT 30d, FOR TOKEN 5m"#,
+		)
+		.await;
+		let grant = issue(&ds, &sess).await.unwrap().expect("a grant to be issued");
+		// The grant can be renewed while the clause allows it
+		let mut sess = Session::default();
+		let (_, next) = renew(&ds, &mut sess, "test".into(), "test".into(), "user".into(), grant)
+			.await
+			.unwrap();
+		// The grant can not be renewed once the clause denies it
+		let owner = Session::owner().with_ns("test").with_db("test");
+		ds.execute("UPDATE user:test SET enabled = false", &owner, None).await.unwrap();
+		let mut sess = Session::default();
+		let res = renew(&ds, &mut sess, "test".into(), "test".into(), "user".into(), next).await;
+		assert!(matches!(res, Err(Error::Thrown(_))));
+		assert!(sess.au.is_anon());
+	}
+
+	#[tokio::test]
+	async fn test_grant_renew_after_revoke() {
+		let (ds, sess) = setup("DURATION FOR GRANT 30d, FOR TOKEN 5m").await;
+		let grant = issue(&ds, &sess).await.unwrap().expect("a grant to be issued");
+		// Revoke all tokens and grants issued to the record user
+		let owner = Session::owner().with_ns("test").with_db("test");
+		let res = ds.execute("REVOKE RECORD user:test", &owner, None).await.unwrap();
+		assert!(res[0].result.is_ok());
+		// The grant can no longer be renewed
+		let mut sess = Session::default();
+		let res = renew(&ds, &mut sess, "test".into(), "test".into(), "user".into(), grant).await;
+		assert!(matches!(res, Err(Error::InvalidAuth)));
+	}
+
+	#[tokio::test]
+	async fn test_grant_expired_are_deleted() {
+		let (ds, sess) = setup("DURATION FOR GRANT 30d, FOR TOKEN 5m").await;
+		let grant = issue(&ds, &sess).await.unwrap().expect("a grant to be issued");
+		let key = crate::key::database::gr::new("test", "test", "user", &hash(&grant));
+		// Grants which have not expired are kept
+		let mut tx = ds.transaction(Write, Optimistic).await.unwrap();
+		gc_all_at(&mut tx, Utc::now().timestamp()).await.unwrap();
+		assert!(tx.get(key.clone()).await.unwrap().is_some());
+		// Grants which have expired are deleted
+		gc_all_at(&mut tx, Utc::now().timestamp() + 31 * 86400).await.unwrap();
+		assert!(tx.get(key).await.unwrap().is_none());
+		tx.cancel().await.unwrap();
+	}
+}
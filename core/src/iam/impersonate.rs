@@ -0,0 +1,125 @@
+use super::audit;
+use crate::dbs::Session;
+use crate::err::Error;
+use crate::iam::{Action, Auth, ResourceKind};
+use crate::kvs::{Datastore, LockType::*, TransactionType::*};
+use crate::sql::{AccessType, Value};
+use crate::syn;
+use std::sync::Arc;
+
+/// Switches a session which is authenticated as a system user over to a
+/// record user, so that operators can check what the record user can see
+/// and do under table permissions, without needing the user's credentials.
+/// Only system users which can manage users on the database are allowed.
+pub async fn impersonate(
+	kvs: &Datastore,
+	session: &mut Session,
+	ac: &str,
+	rid: &str,
+) -> Result<(), Error> {
+	// Keep the original actor, for auditing
+	let user = session.au.id().to_owned();
+	let res = impersonate_record(kvs, session, ac, rid).await;
+	// Record the authentication attempt
+	audit::authentication("impersonate", Some(&user), session, res.is_ok());
+	// Return the result
+	res
+}
+
+async fn impersonate_record(
+	kvs: &Datastore,
+	session: &mut Session,
+	ac: &str,
+	rid: &str,
+) -> Result<(), Error> {
+	// Impersonation takes place on the selected database
+	let (ns, db) = match (&session.ns, &session.db) {
+		(Some(ns), Some(db)) => (ns.to_owned(), db.to_owned()),
+		_ => return Err(Error::InvalidAuth),
+	};
+	// Check that the actor is allowed to manage users on the database
+	if kvs.is_auth_enabled() || !session.au.is_anon() {
+		let res = ResourceKind::Actor.on_db(&ns, &db);
+		session.au.is_allowed(Action::Edit, &res).map_err(Error::IamError)?;
+	}
+	// Parse the specified record id
+	let rid = syn::thing(rid)?;
+	// Create a new readonly transaction
+	let mut tx = kvs.transaction(Read, Optimistic).await?;
+	let res = async {
+		// Only record access methods can be impersonated
+		match tx.get_db_access(&ns, &db, ac).await?.kind {
+			AccessType::Record(_) => (),
+			_ => return Err(Error::AccessMethodMismatch),
+		}
+		// Check that the record user exists
+		if tx.get(crate::key::thing::new(&ns, &db, &rid.tb, &rid.id)).await?.is_none() {
+			return Err(Error::InvalidAuth);
+		}
+		Ok(())
+	}
+	.await;
+	// Ensure that the transaction is cancelled
+	tx.cancel().await?;
+	res?;
+	// Set the authentication on the session
+	session.tk = None;
+	session.ac = Some(ac.to_owned());
+	session.rd = Some(Value::from(rid.to_owned()));
+	session.au = Arc::new(Auth::for_record(rid.to_string(), &ns, &db, ac));
+	Ok(())
+}
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+	use crate::iam::Role;
+
+	async fn setup() -> Datastore {
+		let ds = Datastore::new("memory").await.unwrap().with_auth_enabled(true);
+		let sess = Session::owner().with_ns("test").with_db("test");
+		ds.execute(
+			r#"
+			DEFINE ACCESS user ON DATABASE TYPE RECORD;
+			DEFINE TABLE post PERMISSIONS FOR select WHERE author = $auth.id;
+			CREATE user:tobie, user:jaime;
+			CREATE post:one SET author = user:tobie;
+			CREATE post:two SET author = user:jaime;
+			"#,
+			&sess,
+			None,
+		)
+		.await
+		.unwrap();
+		ds
+	}
+
+	#[tokio::test]
+	async fn test_impersonate_record_user() {
+		let ds = setup().await;
+		let mut sess = Session::owner().with_ns("test").with_db("test");
+		impersonate(&ds, &mut sess, "user", "user:tobie").await.unwrap();
+		assert!(sess.au.is_record());
+		assert_eq!(sess.au.id(), "user:tobie");
+		// Table permissions are applied for the record user
+		let res = ds.execute("SELECT VALUE id FROM post", &sess, None).await.unwrap();
+		let val = res.into_iter().next().unwrap().result.unwrap();
+		assert_eq!(val, syn::value("[post:one]").unwrap());
+	}
+
+	#[tokio::test]
+	async fn test_impersonate_not_allowed() {
+		let ds = setup().await;
+		// Only users which can manage users are allowed
+		let mut sess = Session::for_level(("test", "test").into(), Role::Editor)
+			.with_ns("test")
+			.with_db("test");
+		let res = impersonate(&ds, &mut sess, "user", "user:tobie").await;
+		assert!(res.is_err());
+		assert!(!sess.au.is_record());
+		// Only existing record users can be impersonated
+		let mut sess = Session::owner().with_ns("test").with_db("test");
+		let res = impersonate(&ds, &mut sess, "user", "user:unknown").await;
+		assert!(matches!(res, Err(Error::InvalidAuth)));
+	}
+}
@@ -2,15 +2,21 @@ use cedar_policy::Context;
 pub use entities::Level;
 use thiserror::Error;
 
+pub mod allow;
+pub mod audit;
 pub mod auth;
 pub mod base;
 pub mod check;
 pub mod clear;
 pub mod entities;
+pub mod grant;
+pub mod impersonate;
 pub mod issue;
 #[cfg(feature = "jwks")]
 pub mod jwks;
+pub mod password;
 pub mod policies;
+pub mod revoke;
 pub mod signin;
 pub mod signup;
 pub mod token;
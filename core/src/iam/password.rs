@@ -0,0 +1,172 @@
+use crate::cnf::{ARGON2_MEMORY_COST, ARGON2_PARALLELISM, ARGON2_TIME_COST};
+use crate::err::Error;
+use argon2::password_hash::{PasswordHash, PasswordHasher, PasswordVerifier, SaltString};
+use argon2::{Algorithm, Argon2, Params, Version};
+use once_cell::sync::Lazy;
+use pbkdf2::Pbkdf2;
+use rand::rngs::OsRng;
+use scrypt::Scrypt;
+
+/// The highest Argon2 memory cost in KiB accepted in a stored hash
+const MAX_ARGON2_MEMORY_COST: u32 = 64 * 1024;
+
+/// The highest Argon2 time cost accepted in a stored hash
+const MAX_ARGON2_TIME_COST: u32 = 10;
+
+/// The highest Argon2 parallelism accepted in a stored hash
+const MAX_ARGON2_PARALLELISM: u32 = 8;
+
+/// The highest number of PBKDF2 rounds accepted in a stored hash
+const MAX_PBKDF2_ROUNDS: u32 = 1_000_000;
+
+/// The highest scrypt work factor (N * r * p) accepted in a stored hash
+const MAX_SCRYPT_COST: u64 = 1 << 20;
+
+/// The Argon2id parameters configured for this server
+static PARAMS: Lazy<Result<Params, argon2::Error>> =
+	Lazy::new(|| Params::new(*ARGON2_MEMORY_COST, *ARGON2_TIME_COST, *ARGON2_PARALLELISM, None));
+
+/// Checks that the configured Argon2id parameters are valid. This is
+/// checked once when the datastore is created.
+pub(crate) fn check() -> Result<(), Error> {
+	match &*PARAMS {
+		Ok(_) => Ok(()),
+		Err(e) => Err(Error::InvalidPasswordParams(e.to_string())),
+	}
+}
+
+/// Returns the Argon2id parameters configured for this server
+pub(crate) fn params() -> Params {
+	(*PARAMS).clone().unwrap_or_default()
+}
+
+/// Returns the Argon2id hasher configured for this server
+pub(crate) fn argon2() -> Argon2<'static> {
+	Argon2::new(Algorithm::Argon2id, Version::V0x13, params())
+}
+
+/// Hashes a password with Argon2id, using the configured parameters
+pub(crate) fn hash(pass: &str) -> String {
+	argon2().hash_password(pass.as_bytes(), &SaltString::generate(&mut OsRng)).unwrap().to_string()
+}
+
+/// Verifies a password against a stored hash. Passwords stored with
+/// Argon2, PBKDF2 or scrypt are supported, so that users which were
+/// defined with a PASSHASH from another system are able to sign in.
+pub(crate) fn verify(pass: &str, hash: &str) -> Result<(), Error> {
+	// Parse the stored password hash
+	let hash = PasswordHash::new(hash).map_err(|_| Error::InvalidPass)?;
+	// Check that the hash is not too costly to verify
+	if !bounded(&hash) {
+		warn!("A stored password hash exceeds the accepted cost parameters");
+		return Err(Error::InvalidPass);
+	}
+	// Attempt to verify the password using each algorithm
+	let algs: &[&dyn PasswordVerifier] = &[&Argon2::default(), &Pbkdf2, &Scrypt];
+	match hash.verify_password(algs, pass) {
+		Ok(_) => Ok(()),
+		_ => Err(Error::InvalidPass),
+	}
+}
+
+/// Checks that the cost parameters of a stored hash are within the bounds
+/// which are accepted, so that a crafted hash can not be used to pin the
+/// CPU or exhaust the memory of the server. Hashes which use the configured
+/// Argon2id parameters are always accepted.
+fn bounded(hash: &PasswordHash) -> bool {
+	let param = |name: &str, default: u32| hash.params.get_decimal(name).unwrap_or(default);
+	match hash.algorithm.as_str() {
+		"argon2id" | "argon2i" | "argon2d" => match Params::try_from(hash) {
+			Ok(p) => {
+				let c = params();
+				p.m_cost() <= MAX_ARGON2_MEMORY_COST.max(c.m_cost())
+					&& p.t_cost() <= MAX_ARGON2_TIME_COST.max(c.t_cost())
+					&& p.p_cost() <= MAX_ARGON2_PARALLELISM.max(c.p_cost())
+			}
+			Err(_) => false,
+		},
+		"pbkdf2" | "pbkdf2-sha256" | "pbkdf2-sha512" => {
+			param("i", pbkdf2::Params::default().rounds) <= MAX_PBKDF2_ROUNDS
+		}
+		"scrypt" => {
+			let def = scrypt::Params::recommended();
+			let n = 1u64.checked_shl(param("ln", def.log_n() as u32)).unwrap_or(u64::MAX);
+			let r = param("r", def.r()) as u64;
+			let p = param("p", def.p()) as u64;
+			n.saturating_mul(r).saturating_mul(p) <= MAX_SCRYPT_COST
+		}
+		_ => false,
+	}
+}
+
+/// Checks whether a stored hash should be replaced, because it was not
+/// created with Argon2id or not with the currently configured parameters.
+pub(crate) fn needs_rehash(hash: &str) -> bool {
+	let Ok(hash) = PasswordHash::new(hash) else {
+		return true;
+	};
+	if hash.algorithm != Algorithm::Argon2id.ident() {
+		return true;
+	}
+	match Params::try_from(&hash) {
+		Ok(p) => {
+			let c = params();
+			p.m_cost() != c.m_cost() || p.t_cost() != c.t_cost() || p.p_cost() != c.p_cost()
+		}
+		Err(_) => true,
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+
+	#[test]
+	fn test_hash_and_verify() {
+		let hash = hash("pass");
+		assert!(hash.starts_with("$argon2id$"));
+		assert!(verify("pass", &hash).is_ok());
+		assert!(verify("fail", &hash).is_err());
+		assert!(!needs_rehash(&hash));
+	}
+
+	#[test]
+	fn test_needs_rehash() {
+		let salt = SaltString::generate(&mut OsRng);
+		// Hashes created with other algorithms are migrated
+		let hash = Pbkdf2.hash_password(b"pass", &salt).unwrap().to_string();
+		assert!(verify("pass", &hash).is_ok());
+		assert!(needs_rehash(&hash));
+		// Hashes created with other parameters are migrated
+		let argon2 =
+			Argon2::new(Algorithm::Argon2id, Version::V0x13, Params::new(8, 1, 1, None).unwrap());
+		let hash = argon2.hash_password(b"pass", &salt).unwrap().to_string();
+		assert!(verify("pass", &hash).is_ok());
+		assert!(needs_rehash(&hash));
+	}
+
+	#[test]
+	fn test_bounded() {
+		let salt = SaltString::generate(&mut OsRng);
+		let argon2 =
+			Argon2::new(Algorithm::Argon2id, Version::V0x13, Params::new(8, 1, 1, None).unwrap());
+		let hash = argon2.hash_password(b"pass", &salt).unwrap().to_string();
+		assert!(bounded(&PasswordHash::new(&hash).unwrap()));
+		// Hashes with excessive cost parameters are not verified
+		let hash = hash.replace("m=8,t=1,p=1", "m=4194304,t=1,p=1");
+		assert!(!bounded(&PasswordHash::new(&hash).unwrap()));
+		assert!(matches!(verify("pass", &hash), Err(Error::InvalidPass)));
+		let hash = "$pbkdf2-sha256$i=100000000,l=32$c2FsdHNhbHQ$YWFhYWFhYWFhYWFhYWFhYWFhYWFhYWFhYWFhYWFhYWE";
+		assert!(!bounded(&PasswordHash::new(hash).unwrap()));
+		let hash = "$scrypt$ln=30,r=8,p=1$c2FsdHNhbHQ$YWFhYWFhYWFhYWFhYWFhYWFhYWFhYWFhYWFhYWFhYWE";
+		assert!(!bounded(&PasswordHash::new(hash).unwrap()));
+		let hash = "$scrypt$ln=17,r=8,p=1$c2FsdHNhbHQ$YWFhYWFhYWFhYWFhYWFhYWFhYWFhYWFhYWFhYWFhYWE";
+		assert!(bounded(&PasswordHash::new(hash).unwrap()));
+	}
+
+	#[test]
+	fn test_check() {
+		assert!(check().is_ok());
+		assert_eq!(params().m_cost(), *ARGON2_MEMORY_COST);
+	}
+}
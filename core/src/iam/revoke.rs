@@ -0,0 +1,298 @@
+use crate::dbs::Session;
+use crate::err::Error;
+use crate::iam::Level;
+use crate::kvs::{Datastore, LockType::*, ScanPage, Transaction, TransactionType::*};
+use crate::sql::statements::DefineAccessStatement;
+use crate::sql::{AccessType, Datetime, Object, Value};
+use chrono::{DateTime, TimeZone, Utc};
+use std::time::Duration;
+use uuid::Uuid;
+
+/// The number of revocations which are scanned at once when garbage collecting
+const GC_BATCH_SIZE: u32 = 1000;
+
+/// Returns the identifier under which revocations for an actor are stored
+pub(crate) fn subject(level: &Level, id: &str) -> String {
+	format!("{level}{id}")
+}
+
+/// Revokes a single token by its id, or all of the tokens which have
+/// been issued to an actor up until now, by the actor's subject.
+pub(crate) async fn revoke(tx: &mut Transaction, rv: &str) -> Result<(), Error> {
+	let at = Utc::now();
+	let mut val = Object::default();
+	val.insert("at".to_owned(), Value::from(Datetime::from(at)));
+	// The revocation is no longer needed once every token and refresh grant
+	// which was issued before it has expired
+	let exp = lifetime(tx)
+		.await?
+		.and_then(|v| chrono::Duration::from_std(v).ok())
+		.and_then(|v| at.checked_add_signed(v));
+	if let Some(exp) = exp {
+		val.insert("exp".to_owned(), Value::from(exp.timestamp()));
+	}
+	tx.set(crate::key::root::rv::new(rv), Value::from(val)).await
+}
+
+/// Returns the longest time for which a token or refresh grant can be used,
+/// according to the users and access methods which are currently defined.
+/// Returns `None` when tokens or refresh grants may never expire, or when
+/// tokens from other issuers are accepted, as their expiry is not known.
+async fn lifetime(tx: &mut Transaction) -> Result<Option<Duration>, Error> {
+	let mut durations = Vec::new();
+	durations.extend(tx.all_root_users().await?.iter().map(|v| v.duration.token.clone()));
+	for ns in tx.all_ns().await?.iter() {
+		durations.extend(tx.all_ns_users(&ns.name).await?.iter().map(|v| v.duration.token.clone()));
+		durations.extend(accesses(&tx.all_ns_accesses(&ns.name).await?));
+		for db in tx.all_db(&ns.name).await?.iter() {
+			let users = tx.all_db_users(&ns.name, &db.name).await?;
+			durations.extend(users.iter().map(|v| v.duration.token.clone()));
+			durations.extend(accesses(&tx.all_db_accesses(&ns.name, &db.name).await?));
+		}
+	}
+	Ok(durations.into_iter().try_fold(Duration::ZERO, |max, v| v.map(|v| max.max(v.0))))
+}
+
+/// Returns the lifetimes of the tokens and refresh grants of access methods
+fn accesses(acs: &[DefineAccessStatement]) -> Vec<Option<crate::sql::Duration>> {
+	acs.iter()
+		.flat_map(|ac| match ac.kind {
+			AccessType::Record(_) => vec![ac.duration.token.clone(), ac.duration.grant.clone()],
+			AccessType::Jwt(_) => vec![None],
+		})
+		.collect()
+}
+
+/// Returns the time at which a token was issued. Tokens which are issued by
+/// the datastore have a time-ordered id, which is more precise than the issue
+/// time in seconds, and which is used when it agrees with the issue time.
+fn issued(tk: &Value) -> DateTime<Utc> {
+	// Tokens without an issue time are always affected
+	let iat = match tk.pick(&["iat".into()]) {
+		Value::Number(v) => v.as_int(),
+		_ => 0,
+	};
+	if let Value::Strand(jti) = tk.pick(&["jti".into()]) {
+		let at = Uuid::try_parse(&jti)
+			.ok()
+			.and_then(|v| v.get_timestamp())
+			.map(|v| v.to_unix())
+			.and_then(|(secs, nanos)| Utc.timestamp_opt(secs as i64, nanos).single());
+		if let Some(at) = at.filter(|v| v.timestamp() == iat) {
+			return at;
+		}
+	}
+	Utc.timestamp_opt(iat, 0).single().unwrap_or_default()
+}
+
+/// Checks that the token which authenticated a session has not been
+/// revoked, either individually or along with all tokens for the actor.
+pub(crate) async fn check(kvs: &Datastore, session: &Session) -> Result<(), Error> {
+	// Only token authentication can be revoked
+	let tk = match &session.tk {
+		Some(tk) => tk,
+		None => return Ok(()),
+	};
+	// Check both the token id and the actor subject
+	let jti = match tk.pick(&["jti".into()]) {
+		Value::Strand(jti) => Some(jti.0),
+		_ => None,
+	};
+	let sub = subject(session.au.level(), session.au.id());
+	// Create a new readonly transaction
+	let mut tx = kvs.transaction(Read, Optimistic).await?;
+	let res = match revoked(&mut tx, jti.as_deref(), &sub, issued(tk)).await {
+		Ok(true) => {
+			trace!("The authentication token has been revoked");
+			Err(Error::InvalidAuth)
+		}
+		Ok(false) => Ok(()),
+		Err(e) => Err(e),
+	};
+	// Ensure that the transaction is cancelled
+	tx.cancel().await?;
+	res
+}
+
+/// Checks whether a token has been revoked by its id, or whether an actor
+/// has been revoked at or after the time that a token or refresh grant was
+/// issued to them.
+pub(crate) async fn revoked(
+	tx: &mut Transaction,
+	jti: Option<&str>,
+	subject: &str,
+	issued: DateTime<Utc>,
+) -> Result<bool, Error> {
+	// A revoked token id can never be used again
+	if let Some(jti) = jti {
+		if tx.exi(crate::key::root::rv::new(jti)).await? {
+			return Ok(true);
+		}
+	}
+	// Tokens issued after the actor was revoked are not affected
+	if let Some(v) = tx.get(crate::key::root::rv::new(subject)).await? {
+		if let Value::Datetime(at) = Value::from(v).pick(&["at".into()]) {
+			return Ok(issued <= at.0);
+		}
+	}
+	Ok(false)
+}
+
+/// Deletes all of the revocations which have expired at the given timestamp
+pub(crate) async fn gc_all_at(tx: &mut Transaction, ts: i64) -> Result<(), Error> {
+	let beg = crate::key::root::rv::prefix();
+	let end = crate::key::root::rv::suffix();
+	let mut next = Some(ScanPage::from(beg..end));
+	while let Some(page) = next {
+		let res = tx.scan_paged(page, GC_BATCH_SIZE).await?;
+		for (k, v) in res.values.into_iter() {
+			if let Value::Number(exp) = Value::from(v).pick(&["exp".into()]) {
+				if exp.as_int() < ts {
+					tx.del(k).await?;
+				}
+			}
+		}
+		next = res.next_page;
+	}
+	Ok(())
+}
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+	use crate::iam::signin::root_user;
+	use crate::iam::verify::token;
+
+	async fn setup() -> (Datastore, Session, String) {
+		let ds = Datastore::new("memory").await.unwrap();
+		let sess = Session::owner();
+		ds.execute("DEFINE USER tobie ON ROOT PASSWORD 'pass' ROLES OWNER", &sess, None)
+			.await
+			.unwrap();
+		let mut sess = Session::default();
+		let tk = root_user(&ds, &mut sess, "tobie".to_owned(), "pass".to_owned())
+			.await
+			.unwrap()
+			.expect("a token to be issued");
+		(ds, sess, tk)
+	}
+
+	#[tokio::test]
+	async fn test_revoke_token() {
+		let (ds, sess, tk) = setup().await;
+		// The token can be used before it is revoked
+		token(&ds, &mut Session::default(), &tk).await.unwrap();
+		// Revoke the token by its id
+		let jti = match sess.tk.unwrap().pick(&["jti".into()]) {
+			Value::Strand(v) => v.0,
+			v => panic!("unexpected token id: {v}"),
+		};
+		let res = ds
+			.execute(
+				"REVOKE TOKEN $jti",
+				&Session::owner(),
+				Some([("jti".into(), jti.into())].into()),
+			)
+			.await
+			.unwrap();
+		assert!(res[0].result.is_ok());
+		// The token can no longer be used
+		let mut sess = Session::default();
+		let res = token(&ds, &mut sess, &tk).await;
+		assert!(matches!(res, Err(Error::InvalidAuth)));
+		assert!(sess.au.is_anon());
+	}
+
+	#[tokio::test]
+	async fn test_revoke_user() {
+		let (ds, _, tk) = setup().await;
+		// Revoke all tokens issued to the user
+		let res = ds.execute("REVOKE USER tobie ON ROOT", &Session::owner(), None).await.unwrap();
+		assert!(res[0].result.is_ok());
+		// The token can no longer be used
+		let res = token(&ds, &mut Session::default(), &tk).await;
+		assert!(matches!(res, Err(Error::InvalidAuth)));
+		// Revoking an unknown user fails
+		let res = ds.execute("REVOKE USER jaime ON ROOT", &Session::owner(), None).await.unwrap();
+		assert!(res[0].result.is_err());
+	}
+
+	#[tokio::test]
+	async fn test_revoke_user_then_signin() {
+		let (ds, _, _) = setup().await;
+		let res = ds.execute("REVOKE USER tobie ON ROOT", &Session::owner(), None).await.unwrap();
+		assert!(res[0].result.is_ok());
+		// Tokens issued after the revocation, even within the same second, can be used
+		let mut sess = Session::default();
+		let tk = root_user(&ds, &mut sess, "tobie".to_owned(), "pass".to_owned())
+			.await
+			.unwrap()
+			.expect("a token to be issued");
+		token(&ds, &mut Session::default(), &tk).await.unwrap();
+	}
+
+	#[tokio::test]
+	async fn test_revocation_expires() {
+		let (ds, sess, _) = setup().await;
+		let jti = match sess.tk.unwrap().pick(&["jti".into()]) {
+			Value::Strand(v) => v.0,
+			v => panic!("unexpected token id: {v}"),
+		};
+		let key = crate::key::root::rv::new(&jti);
+		let mut tx = ds.transaction(Write, Optimistic).await.unwrap();
+		revoke(&mut tx, &jti).await.unwrap();
+		tx.commit().await.unwrap();
+		// The revocation is kept while the tokens issued before it can be used
+		let now = Utc::now().timestamp() as u64;
+		ds.tick_at(now).await.unwrap();
+		let mut tx = ds.transaction(Read, Optimistic).await.unwrap();
+		assert!(tx.exi(key.clone()).await.unwrap());
+		tx.cancel().await.unwrap();
+		// The revocation is removed once those tokens have expired
+		ds.tick_at(now + 2 * 60 * 60).await.unwrap();
+		let mut tx = ds.transaction(Read, Optimistic).await.unwrap();
+		assert!(!tx.exi(key).await.unwrap());
+		tx.cancel().await.unwrap();
+	}
+
+	#[tokio::test]
+	async fn test_revocation_without_expiry() {
+		let (ds, _, _) = setup().await;
+		// Tokens from other issuers may never expire
+		let sess = Session::owner().with_ns("test").with_db("test");
+		ds.execute(
+			"DEFINE ACCESS api ON DATABASE TYPE JWT URL 'https://example.com/jwks'",
+			&sess,
+			None,
+		)
+		.await
+		.unwrap();
+		let mut tx = ds.transaction(Write, Optimistic).await.unwrap();
+		revoke(&mut tx, "token").await.unwrap();
+		let val: Value = tx.get(crate::key::root::rv::new("token")).await.unwrap().unwrap().into();
+		tx.commit().await.unwrap();
+		assert!(val.pick(&["exp".into()]).is_none());
+	}
+
+	#[test]
+	fn test_issued() {
+		let jti = Uuid::now_v7();
+		let (secs, _) = jti.get_timestamp().unwrap().to_unix();
+		// The token id is used when it agrees with the issue time
+		let tk = Value::from(map! {
+			"iat".to_owned() => Value::from(secs as i64),
+			"jti".to_owned() => Value::from(jti.to_string()),
+		});
+		let at = issued(&tk);
+		assert_eq!(at.timestamp(), secs as i64);
+		assert_eq!(at.timestamp_millis() as u128, jti.as_u128() >> 80);
+		// Otherwise the issue time in seconds is used
+		let tk = Value::from(map! {
+			"iat".to_owned() => Value::from(secs as i64 + 10),
+			"jti".to_owned() => Value::from(jti.to_string()),
+		});
+		assert_eq!(issued(&tk), Utc.timestamp_opt(secs as i64 + 10, 0).unwrap());
+		// Tokens without an issue time are always affected
+		assert_eq!(issued(&Value::from(Object::default())).timestamp(), 0);
+	}
+}
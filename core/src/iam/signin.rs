@@ -1,5 +1,6 @@
-use super::verify::{verify_db_creds, verify_ns_creds, verify_root_creds};
-use super::{Actor, Level};
+use super::audit;
+use super::verify::{authenticate_record, verify_db_creds, verify_ns_creds, verify_root_creds};
+use super::{Actor, Level, Role};
 use crate::cnf::{INSECURE_FORWARD_RECORD_ACCESS_ERRORS, SERVER_NAME};
 use crate::dbs::Session;
 use crate::err::Error;
@@ -24,9 +25,12 @@ pub async fn signin(
 	let ns = vars.get("NS").or_else(|| vars.get("ns"));
 	let db = vars.get("DB").or_else(|| vars.get("db"));
 	let ac = vars.get("AC").or_else(|| vars.get("ac"));
-
+	// Parse the specified user, for auditing
+	let user = vars.get("user").map(Value::to_raw_string);
+	// Authenticate a copy of the session
+	let mut sess = session.clone();
 	// Check if the parameters exist
-	match (ns, db, ac) {
+	let res = match (ns, db, ac) {
 		// DB signin with access method
 		(Some(ns), Some(db), Some(ac)) => {
 			// Process the provided values
@@ -34,7 +38,7 @@ pub async fn signin(
 			let db = db.to_raw_string();
 			let ac = ac.to_raw_string();
 			// Attempt to signin using specified access method
-			super::signin::db_access(kvs, session, ns, db, ac, vars).await
+			super::signin::db_access(kvs, &mut sess, ns, db, ac, vars).await
 		}
 		// DB signin with user credentials
 		(Some(ns), Some(db), None) => {
@@ -51,7 +55,7 @@ pub async fn signin(
 					let user = user.to_raw_string();
 					let pass = pass.to_raw_string();
 					// Attempt to signin to database
-					super::signin::db_user(kvs, session, ns, db, user, pass).await
+					super::signin::db_user(kvs, &mut sess, ns, db, user, pass).await
 				}
 				_ => Err(Error::MissingUserOrPass),
 			}
@@ -70,7 +74,7 @@ pub async fn signin(
 					let user = user.to_raw_string();
 					let pass = pass.to_raw_string();
 					// Attempt to signin to namespace
-					super::signin::ns_user(kvs, session, ns, user, pass).await
+					super::signin::ns_user(kvs, &mut sess, ns, user, pass).await
 				}
 				_ => Err(Error::MissingUserOrPass),
 			}
@@ -88,13 +92,66 @@ pub async fn signin(
 					let user = user.to_raw_string();
 					let pass = pass.to_raw_string();
 					// Attempt to signin to root
-					super::signin::root_user(kvs, session, user, pass).await
+					super::signin::root_user(kvs, &mut sess, user, pass).await
 				}
 				_ => Err(Error::MissingUserOrPass),
 			}
 		}
 		_ => Err(Error::NoSigninTarget),
+	};
+	// Check that the client address is allowed
+	let res = match res {
+		Ok(v) => super::allow::check(kvs, &sess).await.map(|_| v),
+		Err(e) => Err(e),
+	};
+	// Only authenticate the session if successful
+	if res.is_ok() {
+		*session = sess;
+	}
+	// Record the authentication attempt
+	audit::authentication("signin", user.as_deref(), session, res.is_ok());
+	// Return the result
+	res
+}
+
+/// Authenticate a session with a client certificate which was verified by the
+/// server, and mapped to a role at a root, namespace, or database level. The
+/// client address needs to be allowed by the namespace of the level, and the
+/// attempt is recorded in the audit log like any other signin.
+pub async fn certificate(
+	kvs: &Datastore,
+	session: &mut Session,
+	id: String,
+	role: Role,
+	level: Level,
+) -> Result<(), Error> {
+	// Authenticate a copy of the session
+	let mut sess = session.clone();
+	let res = match level {
+		Level::Root | Level::Namespace(_) | Level::Database(_, _) => {
+			// Default to the namespace and database of the level
+			if sess.ns.is_none() {
+				sess.ns = level.ns().map(str::to_owned);
+			}
+			if sess.db.is_none() {
+				sess.db = level.db().map(str::to_owned);
+			}
+			// Log the authenticated certificate info
+			trace!("Signing in with client certificate `{id}` as {role} at {level}");
+			sess.au = Arc::new(Auth::new(Actor::new(id.clone(), vec![role], level)));
+			// Check that the client address is allowed
+			super::allow::check_certificate(kvs, &sess).await
+		}
+		_ => Err(Error::InvalidAuth),
+	};
+	// Only authenticate the session if successful
+	if res.is_ok() {
+		*session = sess;
 	}
+	// Record the authentication attempt
+	audit::authentication("certificate", Some(&id), session, res.is_ok());
+	// Return the result
+	res
 }
 
 pub async fn db_access(
@@ -141,6 +198,17 @@ pub async fn db_access(
 									match val.record() {
 										// There is a record returned
 										Some(rid) => {
+											// Run the access method AUTHENTICATE clause
+											let rid = authenticate_record(
+												kvs,
+												session,
+												&ns,
+												&db,
+												&ac,
+												rid,
+												av.authenticate,
+											)
+											.await?;
 											// Create the authentication key
 											let key = config(iss.alg, iss.key)?;
 											// Create the authentication claim
@@ -149,7 +217,7 @@ pub async fn db_access(
 												iat: Some(Utc::now().timestamp()),
 												nbf: Some(Utc::now().timestamp()),
 												exp: expiration(av.duration.token)?,
-												jti: Some(Uuid::new_v4().to_string()),
+												jti: Some(Uuid::now_v7().to_string()),
 												ns: Some(ns.to_owned()),
 												db: Some(db.to_owned()),
 												ac: Some(ac.to_owned()),
@@ -218,7 +286,7 @@ pub async fn db_user(
 				iat: Some(Utc::now().timestamp()),
 				nbf: Some(Utc::now().timestamp()),
 				exp: expiration(u.duration.token)?,
-				jti: Some(Uuid::new_v4().to_string()),
+				jti: Some(Uuid::now_v7().to_string()),
 				ns: Some(ns.to_owned()),
 				db: Some(db.to_owned()),
 				id: Some(user),
@@ -262,7 +330,7 @@ pub async fn ns_user(
 				iat: Some(Utc::now().timestamp()),
 				nbf: Some(Utc::now().timestamp()),
 				exp: expiration(u.duration.token)?,
-				jti: Some(Uuid::new_v4().to_string()),
+				jti: Some(Uuid::now_v7().to_string()),
 				ns: Some(ns.to_owned()),
 				id: Some(user),
 				..Claims::default()
@@ -304,7 +372,7 @@ pub async fn root_user(
 				iat: Some(Utc::now().timestamp()),
 				nbf: Some(Utc::now().timestamp()),
 				exp: expiration(u.duration.token)?,
-				jti: Some(Uuid::new_v4().to_string()),
+				jti: Some(Uuid::now_v7().to_string()),
 				id: Some(user),
 				..Claims::default()
 			};
@@ -1,8 +1,10 @@
 use crate::cnf::{INSECURE_FORWARD_RECORD_ACCESS_ERRORS, SERVER_NAME};
 use crate::dbs::Session;
 use crate::err::Error;
+use crate::iam::audit;
 use crate::iam::issue::{config, expiration};
 use crate::iam::token::Claims;
+use crate::iam::verify::authenticate_record;
 use crate::iam::Auth;
 use crate::iam::{Actor, Level};
 use crate::kvs::{Datastore, LockType::*, TransactionType::*};
@@ -24,18 +26,26 @@ pub async fn signup(
 	let db = vars.get("DB").or_else(|| vars.get("db"));
 	let ac = vars.get("AC").or_else(|| vars.get("ac"));
 	// Check if the parameters exist
-	match (ns, db, ac) {
+	let res = match (ns, db, ac) {
 		(Some(ns), Some(db), Some(ac)) => {
 			// Process the provided values
 			let ns = ns.to_raw_string();
 			let db = db.to_raw_string();
 			let ac = ac.to_raw_string();
-			// Attempt to signup using specified access method
-			// Currently, signup is only supported at the database level
-			super::signup::db_access(kvs, session, ns, db, ac, vars).await
+			// Check that the client address is allowed
+			match super::allow::check_db_access(kvs, session, &ns, &db, &ac).await {
+				// Attempt to signup using specified access method
+				// Currently, signup is only supported at the database level
+				Ok(_) => super::signup::db_access(kvs, session, ns, db, ac, vars).await,
+				Err(e) => Err(e),
+			}
 		}
 		_ => Err(Error::InvalidSignup),
-	}
+	};
+	// Record the authentication attempt
+	audit::authentication("signup", None, session, res.is_ok());
+	// Return the result
+	res
 }
 
 pub async fn db_access(
@@ -80,6 +90,17 @@ pub async fn db_access(
 									match val.record() {
 										// There is a record returned
 										Some(rid) => {
+											// Run the access method AUTHENTICATE clause
+											let rid = authenticate_record(
+												kvs,
+												session,
+												&ns,
+												&db,
+												&ac,
+												rid,
+												av.authenticate,
+											)
+											.await?;
 											// Create the authentication key
 											let key = config(iss.alg, iss.key)?;
 											// Create the authentication claim
@@ -88,7 +109,7 @@ pub async fn db_access(
 												iat: Some(Utc::now().timestamp()),
 												nbf: Some(Utc::now().timestamp()),
 												exp: expiration(av.duration.token)?,
-												jti: Some(Uuid::new_v4().to_string()),
+												jti: Some(Uuid::now_v7().to_string()),
 												ns: Some(ns.to_owned()),
 												db: Some(db.to_owned()),
 												ac: Some(ac.to_owned()),
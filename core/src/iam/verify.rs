@@ -1,13 +1,15 @@
+use crate::cnf::INSECURE_FORWARD_RECORD_ACCESS_ERRORS;
 use crate::dbs::Session;
 use crate::err::Error;
+use crate::iam::audit;
 #[cfg(feature = "jwks")]
 use crate::iam::jwks;
-use crate::iam::{issue::expiration, token::Claims, Actor, Auth, Level, Role};
-use crate::kvs::{Datastore, LockType::*, TransactionType::*};
+use crate::iam::{issue::expiration, password, token::Claims, Actor, Auth, Level, Role};
+use crate::key;
+use crate::kvs::{Datastore, Key, LockType::*, TransactionType::*};
 use crate::sql::access_type::{AccessType, JwtAccessVerify};
-use crate::sql::{statements::DefineUserStatement, Algorithm, Value};
+use crate::sql::{statements::DefineUserStatement, Algorithm, Thing, Value};
 use crate::syn;
-use argon2::{Argon2, PasswordHash, PasswordVerifier};
 use chrono::Utc;
 use jsonwebtoken::{decode, DecodingKey, Validation};
 use once_cell::sync::Lazy;
@@ -92,14 +94,16 @@ pub async fn basic(
 	// Log the authentication type
 	trace!("Attempting basic authentication");
 
+	// Authenticate a copy of the session
+	let mut sess = session.clone();
 	// Check if the parameters exist
-	match (ns, db) {
+	let res = match (ns, db) {
 		// DB signin
 		(Some(ns), Some(db)) => match verify_db_creds(kvs, ns, db, user, pass).await {
 			Ok(u) => {
 				debug!("Authenticated as database user '{}'", user);
-				session.exp = expiration(u.duration.session)?;
-				session.au = Arc::new((&u, Level::Database(ns.to_owned(), db.to_owned())).into());
+				sess.exp = expiration(u.duration.session)?;
+				sess.au = Arc::new((&u, Level::Database(ns.to_owned(), db.to_owned())).into());
 				Ok(())
 			}
 			Err(err) => Err(err),
@@ -108,8 +112,8 @@ pub async fn basic(
 		(Some(ns), None) => match verify_ns_creds(kvs, ns, user, pass).await {
 			Ok(u) => {
 				debug!("Authenticated as namespace user '{}'", user);
-				session.exp = expiration(u.duration.session)?;
-				session.au = Arc::new((&u, Level::Namespace(ns.to_owned())).into());
+				sess.exp = expiration(u.duration.session)?;
+				sess.au = Arc::new((&u, Level::Namespace(ns.to_owned())).into());
 				Ok(())
 			}
 			Err(err) => Err(err),
@@ -118,17 +122,90 @@ pub async fn basic(
 		(None, None) => match verify_root_creds(kvs, user, pass).await {
 			Ok(u) => {
 				debug!("Authenticated as root user '{}'", user);
-				session.exp = expiration(u.duration.session)?;
-				session.au = Arc::new((&u, Level::Root).into());
+				sess.exp = expiration(u.duration.session)?;
+				sess.au = Arc::new((&u, Level::Root).into());
 				Ok(())
 			}
 			Err(err) => Err(err),
 		},
 		(None, Some(_)) => Err(Error::InvalidAuth),
+	};
+	// Check that the client address is allowed
+	let res = match res {
+		Ok(_) => super::allow::check(kvs, &sess).await,
+		Err(e) => Err(e),
+	};
+	// Only authenticate the session if successful
+	if res.is_ok() {
+		*session = sess;
 	}
+	// Record the authentication attempt
+	audit::authentication("basic", Some(user), session, res.is_ok());
+	// Return the result
+	res
 }
 
 pub async fn token(kvs: &Datastore, session: &mut Session, token: &str) -> Result<(), Error> {
+	// Attempt to authenticate with the token
+	let mut sess = session.clone();
+	let res = match verify_token(kvs, &mut sess, token).await {
+		// Check that the token has not been revoked
+		Ok(_) => match super::revoke::check(kvs, &sess).await {
+			// Check that the client address is allowed
+			Ok(_) => super::allow::check(kvs, &sess).await,
+			Err(e) => Err(e),
+		},
+		Err(e) => Err(e),
+	};
+	// Only authenticate the session if successful
+	if res.is_ok() {
+		*session = sess;
+	}
+	// Record the authentication attempt
+	audit::authentication("token", None, session, res.is_ok());
+	// Return the result
+	res
+}
+
+/// Runs the `AUTHENTICATE` clause of a record access method, if it has one,
+/// as the record user which is authenticating, so `$auth` is that record, and
+/// returns the record which the session should be authenticated as. Access is
+/// denied unless the clause returns a record id.
+pub(crate) async fn authenticate_record(
+	kvs: &Datastore,
+	session: &Session,
+	ns: &str,
+	db: &str,
+	ac: &str,
+	rid: Thing,
+	authenticate: Option<Value>,
+) -> Result<Thing, Error> {
+	// Without a clause the record is authenticated as is
+	let Some(authenticate) = authenticate else {
+		return Ok(rid);
+	};
+	// Setup the record session for running the clause
+	let mut sess = Session::for_record(ns, db, ac, Value::from(rid));
+	sess.ip.clone_from(&session.ip);
+	sess.or.clone_from(&session.or);
+	// Compute the value as the record user
+	match kvs.evaluate(authenticate, &sess, None).await {
+		Ok(val) => match val.record() {
+			Some(rid) => Ok(rid),
+			_ => {
+				trace!("The access method AUTHENTICATE clause did not return a record");
+				Err(Error::InvalidAuth)
+			}
+		},
+		Err(e) => match e {
+			Error::Thrown(_) => Err(e),
+			e if *INSECURE_FORWARD_RECORD_ACCESS_ERRORS => Err(e),
+			_ => Err(Error::InvalidAuth),
+		},
+	}
+}
+
+async fn verify_token(kvs: &Datastore, session: &mut Session, token: &str) -> Result<(), Error> {
 	// Log the authentication type
 	trace!("Attempting token authentication");
 	// Decode the token without verifying
@@ -186,6 +263,8 @@ pub async fn token(kvs: &Datastore, session: &mut Session, token: &str) -> Resul
 			}?;
 			// Verify the token
 			decode::<Claims>(token, &cf.0, &cf.1)?;
+			// Run the access method AUTHENTICATE clause
+			let id = authenticate_record(kvs, session, &ns, &db, &ac, id, de.authenticate).await?;
 			// Log the success
 			debug!("Authenticated with record access method `{}`", ac);
 			// Set the session
@@ -427,6 +506,10 @@ pub async fn verify_root_creds(
 	})?;
 	// Verify the specified password for the user
 	verify_pass(pass, user.hash.as_ref())?;
+	// Migrate the stored password hash if necessary
+	if password::needs_rehash(&user.hash) {
+		rehash(ds, key::root::us::new(&user.name), &user, pass).await;
+	}
 	// Return the verified user object
 	Ok(user)
 }
@@ -446,6 +529,10 @@ pub async fn verify_ns_creds(
 	})?;
 	// Verify the specified password for the user
 	verify_pass(pass, user.hash.as_ref())?;
+	// Migrate the stored password hash if necessary
+	if password::needs_rehash(&user.hash) {
+		rehash(ds, key::namespace::us::new(ns, &user.name), &user, pass).await;
+	}
 	// Return the verified user object
 	Ok(user)
 }
@@ -466,17 +553,63 @@ pub async fn verify_db_creds(
 	})?;
 	// Verify the specified password for the user
 	verify_pass(pass, user.hash.as_ref())?;
+	// Migrate the stored password hash if necessary
+	if password::needs_rehash(&user.hash) {
+		rehash(ds, key::database::us::new(ns, db, &user.name), &user, pass).await;
+	}
 	// Return the verified user object
 	Ok(user)
 }
 
 fn verify_pass(pass: &str, hash: &str) -> Result<(), Error> {
-	// Compute the hash and verify the password
-	let hash = PasswordHash::new(hash).unwrap();
-	// Attempt to verify the password using Argon2
-	match Argon2::default().verify_password(pass.as_ref(), &hash) {
-		Ok(_) => Ok(()),
-		_ => Err(Error::InvalidPass),
+	// Verify the password against the stored hash
+	password::verify(pass, hash)
+}
+
+/// Replaces the stored password hash of a user who has just signed in, so
+/// that existing hashes migrate to the configured Argon2id parameters.
+///
+/// The user is fetched again within the write transaction, and is only
+/// updated if the stored hash is still the hash which was verified, so
+/// that a user which was changed or removed in the meantime is untouched.
+async fn rehash<K>(ds: &Datastore, key: K, user: &DefineUserStatement, pass: &str)
+where
+	K: Into<Key> + std::fmt::Debug,
+{
+	// Hash the password with the current parameters
+	let hash = password::hash(pass);
+	let key: Key = key.into();
+	// Create a new writeable transaction
+	let res = match ds.transaction(Write, Optimistic).await {
+		Ok(mut tx) => match tx.get(key.clone()).await {
+			// The stored hash is unchanged, so replace it
+			Ok(Some(val)) => {
+				let mut stored: DefineUserStatement = val.into();
+				if stored.hash == user.hash {
+					stored.set_passhash(hash);
+					match tx.set(key, stored).await {
+						Ok(_) => tx.commit().await,
+						Err(e) => {
+							let _ = tx.cancel().await;
+							Err(e)
+						}
+					}
+				} else {
+					tx.cancel().await
+				}
+			}
+			// The user has been removed
+			Ok(None) => tx.cancel().await,
+			Err(e) => {
+				let _ = tx.cancel().await;
+				Err(e)
+			}
+		},
+		Err(e) => Err(e),
+	};
+	// A failed migration does not prevent authentication
+	if let Err(e) = res {
+		warn!("Failed to rehash the password of user `{}`: {e}", user.name);
 	}
 }
 
@@ -1615,6 +1748,56 @@ mod tests {
 		}
 	}
 
+	#[tokio::test]
+	async fn test_verify_creds_rehash() {
+		let ds = Datastore::new("memory").await.unwrap();
+		// Define a user with a password hash from another algorithm
+		let salt = SaltString::generate(&mut rand::rngs::OsRng);
+		let hash = pbkdf2::Pbkdf2.hash_password(b"pass", &salt).unwrap().to_string();
+		let sql = format!("DEFINE USER user ON ROOT PASSHASH '{hash}'");
+		ds.execute(&sql, &Session::owner(), None).await.unwrap();
+		// The user can sign in with the existing hash
+		let res = verify_root_creds(&ds, "user", "pass").await;
+		assert!(res.is_ok());
+		// The stored hash has been migrated to Argon2id
+		let mut tx = ds.transaction(Read, Optimistic).await.unwrap();
+		let user = tx.get_root_user("user").await.unwrap();
+		tx.cancel().await.unwrap();
+		assert!(user.hash.starts_with("$argon2id$"));
+		// The user can still sign in with the new hash
+		let res = verify_root_creds(&ds, "user", "pass").await;
+		assert!(res.is_ok());
+	}
+
+	#[tokio::test]
+	async fn test_verify_creds_rehash_changed_user() {
+		let ds = Datastore::new("memory").await.unwrap();
+		// Define a user with a password hash from another algorithm
+		let salt = SaltString::generate(&mut rand::rngs::OsRng);
+		let hash = pbkdf2::Pbkdf2.hash_password(b"pass", &salt).unwrap().to_string();
+		let sql = format!("DEFINE USER user ON ROOT PASSHASH '{hash}'");
+		ds.execute(&sql, &Session::owner(), None).await.unwrap();
+		let mut tx = ds.transaction(Read, Optimistic).await.unwrap();
+		let user = tx.get_root_user("user").await.unwrap();
+		tx.cancel().await.unwrap();
+		// The password is changed before the stored hash is migrated
+		let sql = "REMOVE USER user ON ROOT; DEFINE USER user ON ROOT PASSWORD 'other'";
+		ds.execute(sql, &Session::owner(), None).await.unwrap();
+		rehash(&ds, key::root::us::new("user"), &user, "pass").await;
+		// The changed password is not overwritten
+		let res = verify_root_creds(&ds, "user", "pass").await;
+		assert!(res.is_err());
+		let res = verify_root_creds(&ds, "user", "other").await;
+		assert!(res.is_ok());
+		// A removed user is not recreated
+		let sql = "REMOVE USER user ON ROOT";
+		ds.execute(sql, &Session::owner(), None).await.unwrap();
+		rehash(&ds, key::root::us::new("user"), &user, "pass").await;
+		let mut tx = ds.transaction(Read, Optimistic).await.unwrap();
+		assert!(tx.get_root_user("user").await.is_err());
+		tx.cancel().await.unwrap();
+	}
+
 	#[tokio::test]
 	async fn test_expired_token() {
 		let secret = "jwt_secret";
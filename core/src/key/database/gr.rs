@@ -0,0 +1,93 @@
+/// Stores a refresh grant issued by a DEFINE ACCESS ON DATABASE record access method
+use crate::key::error::KeyCategory;
+use crate::key::key_req::KeyRequirements;
+use derive::Key;
+use serde::{Deserialize, Serialize};
+
+#[derive(Clone, Debug, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Key)]
+#[non_exhaustive]
+pub struct Gr<'a> {
+	__: u8,
+	_a: u8,
+	pub ns: &'a str,
+	_b: u8,
+	pub db: &'a str,
+	_c: u8,
+	_d: u8,
+	_e: u8,
+	pub ac: &'a str,
+	pub gr: &'a str,
+}
+
+pub fn new<'a>(ns: &'a str, db: &'a str, ac: &'a str, gr: &'a str) -> Gr<'a> {
+	Gr::new(ns, db, ac, gr)
+}
+
+pub fn prefix(ns: &str, db: &str, ac: &str) -> Vec<u8> {
+	let mut k = super::all::new(ns, db).encode().unwrap();
+	k.extend_from_slice(&[b'!', b'g', b'r']);
+	k.extend_from_slice(ac.as_bytes());
+	k.extend_from_slice(&[0x00]);
+	k
+}
+
+pub fn suffix(ns: &str, db: &str, ac: &str) -> Vec<u8> {
+	let mut k = prefix(ns, db, ac);
+	k.extend_from_slice(&[0xff]);
+	k
+}
+
+impl KeyRequirements for Gr<'_> {
+	fn key_category(&self) -> KeyCategory {
+		KeyCategory::DatabaseGrant
+	}
+}
+
+impl<'a> Gr<'a> {
+	pub fn new(ns: &'a str, db: &'a str, ac: &'a str, gr: &'a str) -> Self {
+		Self {
+			__: b'/',
+			_a: b'*',
+			ns,
+			_b: b'*',
+			db,
+			_c: b'!',
+			_d: b'g',
+			_e: b'r',
+			ac,
+			gr,
+		}
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	#[test]
+	fn key() {
+		use super::*;
+		#[rustfmt::skip]
+		let val = Gr::new(
+			"testns",
+			"testdb",
+			"testac",
+			"testgr",
+		);
+		let enc = Gr::encode(&val).unwrap();
+		assert_eq!(enc, b"/*testns\x00*testdb\x00!grtestac\x00testgr\x00");
+
+		let dec = Gr::decode(&enc).unwrap();
+		assert_eq!(val, dec);
+	}
+
+	#[test]
+	fn test_prefix() {
+		let val = super::prefix("testns", "testdb", "testac");
+		assert_eq!(val, b"/*testns\0*testdb\0!grtestac\0");
+	}
+
+	#[test]
+	fn test_suffix() {
+		let val = super::suffix("testns", "testdb", "testac");
+		assert_eq!(val, b"/*testns\0*testdb\0!grtestac\0\xff");
+	}
+}
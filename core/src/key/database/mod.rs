@@ -2,10 +2,14 @@ pub mod ac;
 pub mod all;
 pub mod az;
 pub mod fc;
+pub mod gr;
 pub mod ml;
 pub mod pa;
+pub mod qs;
+pub mod qt;
 pub mod tb;
 pub mod ti;
 pub mod ts;
+pub mod tv;
 pub mod us;
 pub mod vs;
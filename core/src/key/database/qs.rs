@@ -0,0 +1,61 @@
+//! Stores the size of the record data in a database
+use crate::key::error::KeyCategory;
+use crate::key::key_req::KeyRequirements;
+use derive::Key;
+use serde::{Deserialize, Serialize};
+
+#[derive(Clone, Debug, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Key)]
+#[non_exhaustive]
+pub struct Qs<'a> {
+	__: u8,
+	_a: u8,
+	pub ns: &'a str,
+	_b: u8,
+	pub db: &'a str,
+	_c: u8,
+	_d: u8,
+	_e: u8,
+}
+
+pub fn new<'a>(ns: &'a str, db: &'a str) -> Qs<'a> {
+	Qs::new(ns, db)
+}
+
+impl KeyRequirements for Qs<'_> {
+	fn key_category(&self) -> KeyCategory {
+		KeyCategory::DatabaseStorage
+	}
+}
+
+impl<'a> Qs<'a> {
+	pub fn new(ns: &'a str, db: &'a str) -> Self {
+		Self {
+			__: b'/',
+			_a: b'*',
+			ns,
+			_b: b'*',
+			db,
+			_c: b'!',
+			_d: b'q',
+			_e: b's',
+		}
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	#[test]
+	fn key() {
+		use super::*;
+		#[rustfmt::skip]
+		let val = Qs::new(
+			"testns",
+			"testdb",
+		);
+		let enc = Qs::encode(&val).unwrap();
+		assert_eq!(enc, b"/*testns\0*testdb\0!qs");
+
+		let dec = Qs::decode(&enc).unwrap();
+		assert_eq!(val, dec);
+	}
+}
@@ -0,0 +1,76 @@
+//! Stores the number of records and the size of the record data in a table
+use crate::key::error::KeyCategory;
+use crate::key::key_req::KeyRequirements;
+use derive::Key;
+use serde::{Deserialize, Serialize};
+
+#[derive(Clone, Debug, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Key)]
+#[non_exhaustive]
+pub struct Qt<'a> {
+	__: u8,
+	_a: u8,
+	pub ns: &'a str,
+	_b: u8,
+	pub db: &'a str,
+	_c: u8,
+	_d: u8,
+	_e: u8,
+	pub tb: &'a str,
+}
+
+pub fn new<'a>(ns: &'a str, db: &'a str, tb: &'a str) -> Qt<'a> {
+	Qt::new(ns, db, tb)
+}
+
+pub fn prefix(ns: &str, db: &str) -> Vec<u8> {
+	let mut k = super::all::new(ns, db).encode().unwrap();
+	k.extend_from_slice(&[b'!', b'q', b't', 0x00]);
+	k
+}
+
+pub fn suffix(ns: &str, db: &str) -> Vec<u8> {
+	let mut k = super::all::new(ns, db).encode().unwrap();
+	k.extend_from_slice(&[b'!', b'q', b't', 0xff]);
+	k
+}
+
+impl KeyRequirements for Qt<'_> {
+	fn key_category(&self) -> KeyCategory {
+		KeyCategory::DatabaseQuota
+	}
+}
+
+impl<'a> Qt<'a> {
+	pub fn new(ns: &'a str, db: &'a str, tb: &'a str) -> Self {
+		Self {
+			__: b'/',
+			_a: b'*',
+			ns,
+			_b: b'*',
+			db,
+			_c: b'!',
+			_d: b'q',
+			_e: b't',
+			tb,
+		}
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	#[test]
+	fn key() {
+		use super::*;
+		#[rustfmt::skip]
+		let val = Qt::new(
+			"testns",
+			"testdb",
+			"testtb",
+		);
+		let enc = Qt::encode(&val).unwrap();
+		assert_eq!(enc, b"/*testns\0*testdb\0!qttesttb\0");
+
+		let dec = Qt::decode(&enc).unwrap();
+		assert_eq!(val, dec);
+	}
+}
@@ -0,0 +1,76 @@
+//! Stores the version of a table definition, which changes whenever the table is changed
+use crate::key::error::KeyCategory;
+use crate::key::key_req::KeyRequirements;
+use derive::Key;
+use serde::{Deserialize, Serialize};
+
+#[derive(Clone, Debug, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Key)]
+#[non_exhaustive]
+pub struct Tv<'a> {
+	__: u8,
+	_a: u8,
+	pub ns: &'a str,
+	_b: u8,
+	pub db: &'a str,
+	_c: u8,
+	_d: u8,
+	_e: u8,
+	pub tb: &'a str,
+}
+
+pub fn new<'a>(ns: &'a str, db: &'a str, tb: &'a str) -> Tv<'a> {
+	Tv::new(ns, db, tb)
+}
+
+pub fn prefix(ns: &str, db: &str) -> Vec<u8> {
+	let mut k = super::all::new(ns, db).encode().unwrap();
+	k.extend_from_slice(&[b'!', b't', b'v', 0x00]);
+	k
+}
+
+pub fn suffix(ns: &str, db: &str) -> Vec<u8> {
+	let mut k = super::all::new(ns, db).encode().unwrap();
+	k.extend_from_slice(&[b'!', b't', b'v', 0xff]);
+	k
+}
+
+impl KeyRequirements for Tv<'_> {
+	fn key_category(&self) -> KeyCategory {
+		KeyCategory::DatabaseTableVersion
+	}
+}
+
+impl<'a> Tv<'a> {
+	pub fn new(ns: &'a str, db: &'a str, tb: &'a str) -> Self {
+		Self {
+			__: b'/',
+			_a: b'*',
+			ns,
+			_b: b'*',
+			db,
+			_c: b'!',
+			_d: b't',
+			_e: b'v',
+			tb,
+		}
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	#[test]
+	fn key() {
+		use super::*;
+		#[rustfmt::skip]
+		let val = Tv::new(
+			"testns",
+			"testdb",
+			"testtb",
+		);
+		let enc = Tv::encode(&val).unwrap();
+		assert_eq!(enc, b"/*testns\0*testdb\0!tvtesttb\0");
+
+		let dec = Tv::decode(&enc).unwrap();
+		assert_eq!(val, dec);
+	}
+}
@@ -12,12 +12,18 @@ pub enum KeyCategory {
 	Access,
 	/// crate::key::root::hb                 /!hb{ts}/{nd}
 	Heartbeat,
+	/// crate::key::root::mg                 /!mg
+	Migration,
 	/// crate::key::root::nd                 /!nd{nd}
 	Node,
 	/// crate::key::root::ni                 /!ni
 	NamespaceIdentifier,
 	/// crate::key::root::ns                 /!ns{ns}
 	Namespace,
+	/// crate::key::root::rv                 /!rv{rv}
+	Revocation,
+	/// crate::key::root::sv                 /!sv
+	StorageVersion,
 	/// crate::key::root::us                 /!us{us}
 	User,
 	///
@@ -47,18 +53,26 @@ pub enum KeyCategory {
 	DatabaseAnalyzer,
 	/// crate::key::database::fc             /*{ns}*{db}!fn{fc}
 	DatabaseFunction,
+	/// crate::key::database::gr             /*{ns}*{db}!gr{ac}{gr}
+	DatabaseGrant,
 	/// crate::key::database::lg             /*{ns}*{db}!lg{lg}
 	DatabaseLog,
 	/// crate::key::database::ml             /*{ns}*{db}!ml{ml}{vn}
 	DatabaseModel,
 	/// crate::key::database::pa             /*{ns}*{db}!pa{pa}
 	DatabaseParameter,
+	/// crate::key::database::qs             /*{ns}*{db}!qs
+	DatabaseStorage,
+	/// crate::key::database::qt             /*{ns}*{db}!qt{tb}
+	DatabaseQuota,
 	/// crate::key::database::tb             /*{ns}*{db}!tb{tb}
 	DatabaseTable,
 	/// crate::key::database::ti             /+{ns id}*{db id}!ti
 	DatabaseTableIdentifier,
 	/// crate::key::database::ts             /*{ns}*{db}!ts{ts}
 	DatabaseTimestamp,
+	/// crate::key::database::tv             /*{ns}*{db}!tv{tb}
+	DatabaseTableVersion,
 	/// crate::key::database::us             /*{ns}*{db}!us{us}
 	DatabaseUser,
 	/// crate::key::database::vs             /*{ns}*{db}!vs
@@ -121,9 +135,12 @@ impl Display for KeyCategory {
 			KeyCategory::Root => "Root",
 			KeyCategory::Access => "Access",
 			KeyCategory::Heartbeat => "Heartbeat",
+			KeyCategory::Migration => "Migration",
 			KeyCategory::Node => "Node",
 			KeyCategory::NamespaceIdentifier => "NamespaceIdentifier",
 			KeyCategory::Namespace => "Namespace",
+			KeyCategory::Revocation => "Revocation",
+			KeyCategory::StorageVersion => "StorageVersion",
 			KeyCategory::User => "User",
 			KeyCategory::NodeRoot => "NodeRoot",
 			KeyCategory::NodeLiveQuery => "NodeLiveQuery",
@@ -137,12 +154,16 @@ impl Display for KeyCategory {
 			KeyCategory::DatabaseAccess => "DatabaseAccess",
 			KeyCategory::DatabaseAnalyzer => "DatabaseAnalyzer",
 			KeyCategory::DatabaseFunction => "DatabaseFunction",
+			KeyCategory::DatabaseGrant => "DatabaseGrant",
 			KeyCategory::DatabaseLog => "DatabaseLog",
 			KeyCategory::DatabaseModel => "DatabaseModel",
 			KeyCategory::DatabaseParameter => "DatabaseParameter",
+			KeyCategory::DatabaseStorage => "DatabaseStorage",
+			KeyCategory::DatabaseQuota => "DatabaseQuota",
 			KeyCategory::DatabaseTable => "DatabaseTable",
 			KeyCategory::DatabaseTableIdentifier => "DatabaseTableIdentifier",
 			KeyCategory::DatabaseTimestamp => "DatabaseTimestamp",
+			KeyCategory::DatabaseTableVersion => "DatabaseTableVersion",
 			KeyCategory::DatabaseUser => "DatabaseUser",
 			KeyCategory::DatabaseVersionstamp => "DatabaseVersionstamp",
 			KeyCategory::TableRoot => "TableRoot",
@@ -128,6 +128,18 @@ pub fn new<'a>(
 	Graph::new(ns, db, tb, id.to_owned(), eg.to_owned(), fk)
 }
 
+pub fn tbprefix(ns: &str, db: &str, tb: &str) -> Vec<u8> {
+	let mut k = crate::key::table::all::new(ns, db, tb).encode().unwrap();
+	k.extend_from_slice(&[b'~', 0x00]);
+	k
+}
+
+pub fn tbsuffix(ns: &str, db: &str, tb: &str) -> Vec<u8> {
+	let mut k = crate::key::table::all::new(ns, db, tb).encode().unwrap();
+	k.extend_from_slice(&[b'~', 0xff]);
+	k
+}
+
 pub fn prefix(ns: &str, db: &str, tb: &str, id: &Id) -> Vec<u8> {
 	let mut k = Prefix::new(ns, db, tb, id).encode().unwrap();
 	k.extend_from_slice(&[0x00]);
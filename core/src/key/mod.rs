@@ -2,9 +2,12 @@
 ///
 /// crate::key::root::all                /
 /// crate::key::root::hb                 /!hb{ts}/{nd}
+/// crate::key::root::mg                 /!mg
 /// crate::key::root::nd                 /!nd{nd}
 /// crate::key::root::ni                 /!ni
 /// crate::key::root::ns                 /!ns{ns}
+/// crate::key::root::rv                 /!rv{rv}
+/// crate::key::root::sv                 /!sv
 /// crate::key::root::us                 /!us{us}
 ///
 /// crate::key::node::all                /${nd}
@@ -21,11 +24,15 @@
 /// crate::key::database::ac             /*{ns}*{db}!ac{ac}
 /// crate::key::database::az             /*{ns}*{db}!az{az}
 /// crate::key::database::fc             /*{ns}*{db}!fn{fc}
+/// crate::key::database::gr             /*{ns}*{db}!gr{ac}{gr}
 /// crate::key::database::lg             /*{ns}*{db}!lg{lg}
 /// crate::key::database::pa             /*{ns}*{db}!pa{pa}
+/// crate::key::database::qs             /*{ns}*{db}!qs
+/// crate::key::database::qt             /*{ns}*{db}!qt{tb}
 /// crate::key::database::tb             /*{ns}*{db}!tb{tb}
 /// crate::key::database::ti             /+{ns id}*{db id}!ti
 /// crate::key::database::ts             /*{ns}*{db}!ts{ts}
+/// crate::key::database::tv             /*{ns}*{db}!tv{tb}
 /// crate::key::database::us             /*{ns}*{db}!us{us}
 /// crate::key::database::vs             /*{ns}*{db}!vs
 ///
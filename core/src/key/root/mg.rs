@@ -0,0 +1,50 @@
+//! Stores the lease of the node which is migrating the storage format
+use crate::key::error::KeyCategory;
+use crate::key::key_req::KeyRequirements;
+use derive::Key;
+use serde::{Deserialize, Serialize};
+
+#[derive(Clone, Debug, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Key)]
+#[non_exhaustive]
+pub struct Mg {
+	__: u8,
+	_a: u8,
+	_b: u8,
+	_c: u8,
+}
+
+impl Default for Mg {
+	fn default() -> Self {
+		Self::new()
+	}
+}
+
+impl KeyRequirements for Mg {
+	fn key_category(&self) -> KeyCategory {
+		KeyCategory::Migration
+	}
+}
+
+impl Mg {
+	pub fn new() -> Self {
+		Self {
+			__: b'/',
+			_a: b'!',
+			_b: b'm',
+			_c: b'g',
+		}
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	#[test]
+	fn key() {
+		use super::*;
+		let val = Mg::new();
+		let enc = Mg::encode(&val).unwrap();
+		assert_eq!(enc, b"/!mg");
+		let dec = Mg::decode(&enc).unwrap();
+		assert_eq!(val, dec);
+	}
+}
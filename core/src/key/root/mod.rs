@@ -1,7 +1,10 @@
 pub mod ac;
 pub mod all;
 pub mod hb;
+pub mod mg;
 pub mod nd;
 pub mod ni;
 pub mod ns;
+pub mod rv;
+pub mod sv;
 pub mod us;
@@ -0,0 +1,75 @@
+/// Stores a revocation of a token or of all tokens issued to an actor
+use crate::key::error::KeyCategory;
+use crate::key::key_req::KeyRequirements;
+use derive::Key;
+use serde::{Deserialize, Serialize};
+
+#[derive(Clone, Debug, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Key)]
+#[non_exhaustive]
+pub struct Rv<'a> {
+	__: u8,
+	_a: u8,
+	_b: u8,
+	_c: u8,
+	pub rv: &'a str,
+}
+
+pub fn new(rv: &str) -> Rv<'_> {
+	Rv::new(rv)
+}
+
+pub fn prefix() -> Vec<u8> {
+	let mut k = super::all::new().encode().unwrap();
+	k.extend_from_slice(&[b'!', b'r', b'v', 0x00]);
+	k
+}
+
+pub fn suffix() -> Vec<u8> {
+	let mut k = super::all::new().encode().unwrap();
+	k.extend_from_slice(&[b'!', b'r', b'v', 0xff]);
+	k
+}
+
+impl KeyRequirements for Rv<'_> {
+	fn key_category(&self) -> KeyCategory {
+		KeyCategory::Revocation
+	}
+}
+
+impl<'a> Rv<'a> {
+	pub fn new(rv: &'a str) -> Self {
+		Self {
+			__: b'/',
+			_a: b'!',
+			_b: b'r',
+			_c: b'v',
+			rv,
+		}
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	#[test]
+	fn key() {
+		use super::*;
+		#[rustfmt::skip]
+		let val = Rv::new("testrv");
+		let enc = Rv::encode(&val).unwrap();
+		assert_eq!(enc, b"/!rvtestrv\x00");
+		let dec = Rv::decode(&enc).unwrap();
+		assert_eq!(val, dec);
+	}
+
+	#[test]
+	fn test_prefix() {
+		let val = super::prefix();
+		assert_eq!(val, b"/!rv\0");
+	}
+
+	#[test]
+	fn test_suffix() {
+		let val = super::suffix();
+		assert_eq!(val, b"/!rv\xff");
+	}
+}
@@ -0,0 +1,50 @@
+//! Stores the version of the storage format
+use crate::key::error::KeyCategory;
+use crate::key::key_req::KeyRequirements;
+use derive::Key;
+use serde::{Deserialize, Serialize};
+
+#[derive(Clone, Debug, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Key)]
+#[non_exhaustive]
+pub struct Sv {
+	__: u8,
+	_a: u8,
+	_b: u8,
+	_c: u8,
+}
+
+impl Default for Sv {
+	fn default() -> Self {
+		Self::new()
+	}
+}
+
+impl KeyRequirements for Sv {
+	fn key_category(&self) -> KeyCategory {
+		KeyCategory::StorageVersion
+	}
+}
+
+impl Sv {
+	pub fn new() -> Self {
+		Self {
+			__: b'/',
+			_a: b'!',
+			_b: b's',
+			_c: b'v',
+		}
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	#[test]
+	fn key() {
+		use super::*;
+		let val = Sv::new();
+		let enc = Sv::encode(&val).unwrap();
+		assert_eq!(enc, b"/!sv");
+		let dec = Sv::decode(&enc).unwrap();
+		assert_eq!(val, dec);
+	}
+}
@@ -1,5 +1,7 @@
+use crate::cnf::PERMISSION_CACHE_SIZE;
 use crate::idg::u32::U32;
 use crate::kvs::kv::Key;
+use crate::sql::permission::{Permission, Permissions};
 use crate::sql::statements::DefineAccessStatement;
 use crate::sql::statements::DefineAnalyzerStatement;
 use crate::sql::statements::DefineDatabaseStatement;
@@ -13,9 +15,94 @@ use crate::sql::statements::DefineParamStatement;
 use crate::sql::statements::DefineTableStatement;
 use crate::sql::statements::DefineUserStatement;
 use crate::sql::statements::LiveStatement;
+use crate::sql::Value;
 use std::collections::HashMap;
 use std::sync::Arc;
 
+/// The statement types which a table permission clause is defined for
+#[derive(Clone, Copy, Debug, Eq, Hash, PartialEq)]
+pub(crate) enum PermissionKind {
+	Select,
+	Create,
+	Update,
+	Delete,
+}
+
+impl PermissionKind {
+	const ALL: [PermissionKind; 4] = [Self::Select, Self::Create, Self::Update, Self::Delete];
+
+	/// Get the permission clause for this statement type
+	pub(crate) fn of(self, perms: &Permissions) -> &Permission {
+		match self {
+			Self::Select => &perms.select,
+			Self::Create => &perms.create,
+			Self::Update => &perms.update,
+			Self::Delete => &perms.delete,
+		}
+	}
+}
+
+/// Table permission clauses which are shared between the transactions of a
+/// datastore, keyed by table and statement type, so that the table definition
+/// is not fetched again for every record which is checked. Each clause is
+/// stored with the version of the table definition it was taken from, and is
+/// only used by a transaction which reads that same version, so a table which
+/// is changed by another transaction or node is never checked against a stale
+/// clause.
+pub(crate) struct PermissionCache {
+	entries: quick_cache::sync::Cache<(Key, PermissionKind), (u64, Arc<Permission>)>,
+}
+
+impl Default for PermissionCache {
+	fn default() -> Self {
+		Self {
+			entries: quick_cache::sync::Cache::new((*PERMISSION_CACHE_SIZE).max(1)),
+		}
+	}
+}
+
+impl PermissionCache {
+	/// Get the permission clause of a table, if it was cached for this version of the table
+	pub(crate) fn get(
+		&self,
+		key: &Key,
+		kind: PermissionKind,
+		version: u64,
+	) -> Option<Arc<Permission>> {
+		match self.entries.get(&(key.to_owned(), kind))? {
+			(v, perm) if v == version => Some(perm),
+			_ => None,
+		}
+	}
+	/// Compile and cache the permission clause of a table. A clause is not
+	/// cached if a clause from a later version of the table is already cached,
+	/// so that a transaction reading an older snapshot does not replace it.
+	pub(crate) fn set(
+		&self,
+		key: Key,
+		kind: PermissionKind,
+		version: u64,
+		perm: &Permission,
+	) -> Arc<Permission> {
+		let perm = Arc::new(compile(perm));
+		let key = (key, kind);
+		if !matches!(self.entries.get(&key), Some((v, _)) if v > version) {
+			self.entries.insert(key, (version, Arc::clone(&perm)));
+		}
+		perm
+	}
+}
+
+/// Compile a permission clause, so that a clause which is a literal
+/// boolean does not need to be computed for every record it is checked for
+fn compile(perm: &Permission) -> Permission {
+	match perm {
+		Permission::Specific(Value::Bool(true)) => Permission::Full,
+		Permission::Specific(Value::Bool(false) | Value::None | Value::Null) => Permission::None,
+		perm => perm.clone(),
+	}
+}
+
 #[derive(Clone)]
 #[non_exhaustive]
 pub enum Entry {
@@ -46,6 +133,8 @@ pub enum Entry {
 	Tbs(Arc<[DefineTableStatement]>),
 	// Sequences
 	Seq(U32),
+	// Versions
+	Tv(u64),
 }
 
 #[derive(Default)]
@@ -70,3 +159,50 @@ impl Cache {
 		self.0.clear()
 	}
 }
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+	use crate::sql::Expression;
+
+	#[test]
+	fn shared_table_permissions() {
+		let cache = PermissionCache::default();
+		let key = crate::key::database::tb::new("test", "test", "shared").encode().unwrap();
+		let other = crate::key::database::tb::new("test", "test", "other").encode().unwrap();
+		// Literal clauses are compiled to fixed permissions
+		let perm =
+			cache.set(key.clone(), PermissionKind::Select, 1, &Permission::Specific(true.into()));
+		assert_eq!(*perm, Permission::Full);
+		let perm = Permission::Specific(Value::Expression(Box::new(Expression::default())));
+		cache.set(key.clone(), PermissionKind::Update, 1, &perm);
+		cache.set(other.clone(), PermissionKind::Select, 1, &Permission::None);
+		// The clauses are cached by table and statement type
+		assert_eq!(cache.get(&key, PermissionKind::Select, 1).as_deref(), Some(&Permission::Full));
+		assert_eq!(cache.get(&key, PermissionKind::Update, 1).as_deref(), Some(&perm));
+		assert_eq!(cache.get(&key, PermissionKind::Create, 1), None);
+		assert_eq!(
+			cache.get(&other, PermissionKind::Select, 1).as_deref(),
+			Some(&Permission::None)
+		);
+	}
+
+	#[test]
+	fn shared_table_permissions_are_versioned() {
+		let cache = PermissionCache::default();
+		let key = crate::key::database::tb::new("test", "test", "versioned").encode().unwrap();
+		cache.set(key.clone(), PermissionKind::Select, 1, &Permission::Full);
+		// A clause is not used for another version of the table
+		assert_eq!(cache.get(&key, PermissionKind::Select, 2), None);
+		assert_eq!(cache.get(&key, PermissionKind::Select, 0), None);
+		// A later version replaces the cached clause
+		cache.set(key.clone(), PermissionKind::Select, 2, &Permission::None);
+		assert_eq!(cache.get(&key, PermissionKind::Select, 2).as_deref(), Some(&Permission::None));
+		assert_eq!(cache.get(&key, PermissionKind::Select, 1), None);
+		// An earlier version does not replace the cached clause
+		let perm = cache.set(key.clone(), PermissionKind::Select, 1, &Permission::Full);
+		assert_eq!(*perm, Permission::Full);
+		assert_eq!(cache.get(&key, PermissionKind::Select, 2).as_deref(), Some(&Permission::None));
+		assert_eq!(cache.get(&key, PermissionKind::Select, 1), None);
+	}
+}
@@ -8,24 +8,31 @@ use std::fmt;
 	feature = "kv-tikv",
 ))]
 use std::path::PathBuf;
+use std::pin::pin;
+use std::sync::atomic::{AtomicBool, Ordering};
 use std::sync::Arc;
 use std::time::Duration;
 #[cfg(not(target_arch = "wasm32"))]
 use std::time::{SystemTime, UNIX_EPOCH};
 
+use bytes::BytesMut;
 use channel::{Receiver, Sender};
-use futures::{lock::Mutex, Future};
+use futures::{lock::Mutex, Future, Stream, StreamExt};
 use reblessive::{tree::Stk, TreeStack};
 use tokio::sync::RwLock;
-use tracing::instrument;
 use tracing::trace;
+use tracing::{instrument, Instrument};
+use trice::Instant;
 
 #[cfg(target_arch = "wasm32")]
 use wasmtimer::std::{SystemTime, UNIX_EPOCH};
 
 use super::tx::Transaction;
 use crate::cf;
-use crate::ctx::Context;
+use crate::cnf::{
+	quota, MEMORY_BUDGET, NAMESPACE_MAX_QUERIES_PER_SECOND, NAMESPACE_USAGE_METERING, RANDOM_SEED,
+};
+use crate::ctx::{Canceller, Context, SeededRng};
 #[cfg(feature = "jwks")]
 use crate::dbs::capabilities::NetTarget;
 use crate::dbs::{
@@ -38,15 +45,26 @@ use crate::iam::jwks::JwksCache;
 use crate::iam::{Action, Auth, Error as IamError, Resource, Role};
 use crate::idx::trees::store::IndexStores;
 use crate::key::root::hb::Hb;
+use crate::kvs::cache::PermissionCache;
 use crate::kvs::clock::SizedClock;
 #[allow(unused_imports)]
 use crate::kvs::clock::SystemClock;
 use crate::kvs::lq_cf::LiveQueryTracker;
 use crate::kvs::lq_structs::{LqValue, TrackedResult, UnreachableLqType};
 use crate::kvs::lq_v2_fut::process_lq_notifications;
+use crate::kvs::queries::{ActiveQueryGuard, QueryTracker};
+use crate::kvs::quota::QueryQuota;
+use crate::kvs::version::Migration;
+use crate::kvs::{
+	ActiveQuery, ActiveSession, MemoryBudget, SessionTracker, Usage, UsageTracker, Version,
+};
 use crate::kvs::{LockType, LockType::*, TransactionType, TransactionType::*};
 use crate::options::EngineOptions;
-use crate::sql::{self, statements::DefineUserStatement, Base, Query, Uuid, Value};
+use crate::sql::{
+	self,
+	statements::{DefineUserStatement, OutputStatement},
+	Base, Query, Statement, Statements, Uuid, Value,
+};
 use crate::syn;
 use crate::vs::{conv, Oracle, Versionstamp};
 
@@ -101,6 +119,24 @@ pub struct Datastore {
 	// The temporary directory
 	temporary_directory: Option<Arc<PathBuf>>,
 	pub(crate) lq_cf_store: Arc<RwLock<LiveQueryTracker>>,
+	// The per-namespace query quota tracker
+	query_quota: Arc<QueryQuota>,
+	// The table permission clauses shared between transactions
+	permissions: Arc<PermissionCache>,
+	// The per-namespace resource usage tracker
+	usage: Option<Arc<UsageTracker>>,
+	// The queries which are currently being processed
+	queries: Arc<QueryTracker>,
+	// The client sessions which are currently connected
+	sessions: Arc<SessionTracker>,
+	// The seed for random functions and generated record ids, if configured
+	random_seed: Option<u64>,
+	// The seeded random number generator which is shared by all sessions
+	rng: Option<SeededRng>,
+	// The server-wide budget for retained query results, if configured
+	memory: Option<Arc<MemoryBudget>>,
+	// Whether new queries are rejected, because the datastore is shutting down
+	draining: AtomicBool,
 }
 
 /// We always want to be circulating the live query information
@@ -201,6 +237,8 @@ impl Datastore {
 		path: &str,
 		#[allow(unused_variables)] clock_override: Option<Arc<SizedClock>>,
 	) -> Result<Datastore, Error> {
+		// Check the configured password hashing parameters
+		crate::iam::password::check()?;
 		#[allow(unused_variables)]
 		let default_clock: Arc<SizedClock> = Arc::new(SizedClock::System(SystemClock::new()));
 
@@ -359,6 +397,30 @@ impl Datastore {
 			))]
 			temporary_directory: None,
 			lq_cf_store: Arc::new(RwLock::new(LiveQueryTracker::new())),
+			query_quota: Arc::new(QueryQuota::default()),
+			permissions: Arc::new(PermissionCache::default()),
+			usage: match *NAMESPACE_USAGE_METERING {
+				true => Some(Arc::new(UsageTracker::default())),
+				false => None,
+			},
+			queries: Arc::new(QueryTracker::default()),
+			sessions: Arc::new(SessionTracker::default()),
+			random_seed: match *RANDOM_SEED {
+				0 => None,
+				seed => {
+					warn!("Random functions and record ids are seeded with SURREAL_RANDOM_SEED. The generated values are predictable, so this must only be used for testing!");
+					Some(seed)
+				}
+			},
+			rng: match *RANDOM_SEED {
+				0 => None,
+				seed => Some(SeededRng::new(seed)),
+			},
+			memory: match *MEMORY_BUDGET {
+				0 => None,
+				limit => Some(Arc::new(MemoryBudget::new(limit))),
+			},
+			draining: AtomicBool::new(false),
 		})
 	}
 
@@ -416,6 +478,28 @@ impl Datastore {
 		self
 	}
 
+	/// Seed random functions and generated record ids deterministically. The
+	/// generated values are predictable, so this must only be used for testing.
+	pub fn with_random_seed(mut self, seed: Option<u64>) -> Self {
+		if seed.is_some() {
+			warn!("Random functions and record ids are seeded deterministically. The generated values are predictable, so this must only be used for testing!");
+		}
+		self.random_seed = seed;
+		self.rng = seed.map(SeededRng::new);
+		self
+	}
+
+	/// Get the seed for random functions and generated record ids, if configured
+	pub fn random_seed(&self) -> Option<u64> {
+		self.random_seed
+	}
+
+	/// Limit the bytes which the results of all running queries can retain in memory
+	pub fn with_memory_budget(mut self, limit: Option<usize>) -> Self {
+		self.memory = limit.map(|limit| Arc::new(MemoryBudget::new(limit)));
+		self
+	}
+
 	/// Set the engine options for the datastore
 	pub fn with_engine_options(mut self, engine_options: EngineOptions) -> Self {
 		self.engine_options = engine_options;
@@ -485,6 +569,59 @@ impl Datastore {
 		}
 	}
 
+	/// Check the storage format of the datastore, returning an error if it
+	/// was written by a newer release. Datastores which are still using an
+	/// older storage format can be upgraded with [`Datastore::migrate`].
+	pub async fn check_version(&self) -> Result<Version, Error> {
+		let mut tx = self.transaction(Write, Optimistic).await?;
+		let version = match Version::fetch(&mut tx).await {
+			Ok(v) => {
+				tx.commit().await?;
+				v
+			}
+			Err(e) => {
+				tx.cancel().await?;
+				return Err(e);
+			}
+		};
+		if version > Version::LATEST {
+			return Err(Error::StorageVersionNewer {
+				version: version.0,
+				latest: Version::LATEST.0,
+			});
+		}
+		Ok(version)
+	}
+
+	/// Upgrade a datastore which uses an older storage format to the storage
+	/// format of this release, returning the version which the datastore was
+	/// upgraded from. Stored data is not rewritten, as data which was written
+	/// by older releases is still read at its own revision. Only one node can
+	/// migrate a datastore at once.
+	pub async fn migrate(&self) -> Result<Version, Error> {
+		let from = self.check_version().await?;
+		if from.is_latest() {
+			return Ok(from);
+		}
+		let migration = Migration::new(self, self.id.into());
+		let res = migration.run(from).await;
+		// Release the lease, so that an interrupted migration can be resumed straight away
+		migration.release().await?;
+		res.map(|_| from)
+	}
+
+	/// Rewrite all definitions and records at their latest revisions. The
+	/// data is rewritten in small transactions, so the datastore can be
+	/// rewritten while it is in use, and only one node can rewrite or migrate
+	/// a datastore at once.
+	pub async fn rewrite(&self) -> Result<(), Error> {
+		let migration = Migration::new(self, self.id.into());
+		let res = migration.rewrite_all().await;
+		// Release the lease, so that an interrupted rewrite can be resumed straight away
+		migration.release().await?;
+		res
+	}
+
 	// Initialise bootstrap with implicit values intended for runtime
 	// An error indicates that a failure happened, but that does not mean that the bootstrap
 	// completely failed. It may have partially completed. It certainly has side-effects
@@ -831,6 +968,8 @@ impl Datastore {
 		trace!("Ticking at timestamp {} ({:?})", ts, conv::u64_to_versionstamp(ts));
 		let _vs = self.save_timestamp_for_versionstamp(ts).await?;
 		self.garbage_collect_stale_change_feeds(ts).await?;
+		self.garbage_collect_expired_grants(ts).await?;
+		self.garbage_collect_expired_revocations(ts).await?;
 		// TODO Add LQ GC
 		// TODO Add Node GC?
 		Ok(())
@@ -937,6 +1076,30 @@ impl Datastore {
 		Ok(())
 	}
 
+	// garbage_collect_expired_grants deletes all refresh grants which have expired.
+	pub(crate) async fn garbage_collect_expired_grants(&self, ts: u64) -> Result<(), Error> {
+		let mut tx = self.transaction(Write, Optimistic).await?;
+		match crate::iam::grant::gc_all_at(&mut tx, ts as i64).await {
+			Ok(_) => tx.commit().await,
+			Err(e) => {
+				tx.cancel().await?;
+				Err(e)
+			}
+		}
+	}
+
+	// garbage_collect_expired_revocations deletes all token revocations which have expired.
+	pub(crate) async fn garbage_collect_expired_revocations(&self, ts: u64) -> Result<(), Error> {
+		let mut tx = self.transaction(Write, Optimistic).await?;
+		match crate::iam::revoke::gc_all_at(&mut tx, ts as i64).await {
+			Ok(_) => tx.commit().await,
+			Err(e) => {
+				tx.cancel().await?;
+				Err(e)
+			}
+		}
+	}
+
 	// Creates a heartbeat entry for the member indicating to the cluster
 	// that the node is alive.
 	// This is the preferred way of creating heartbeats inside the database, so try to use this.
@@ -979,6 +1142,7 @@ impl Datastore {
 	/// }
 	/// ```
 	#[allow(unreachable_code)]
+	#[instrument(level = "trace", name = "kvs::transaction", skip_all)]
 	pub async fn transaction(
 		&self,
 		write: TransactionType,
@@ -1043,6 +1207,8 @@ impl Datastore {
 			clock: self.clock.clone(),
 			prepared_async_events: (Arc::new(send), Arc::new(recv)),
 			engine_options: self.engine_options,
+			perms: self.permissions.clone(),
+			changed_tbs: Vec::new(),
 		})
 	}
 
@@ -1113,6 +1279,12 @@ impl Datastore {
 			}
 			.into());
 		}
+		// Check the namespace has not exceeded its query quota
+		if let Some(ns) = &sess.ns {
+			let limit =
+				quota(ns, None, &NAMESPACE_MAX_QUERIES_PER_SECOND, |q| q.queries_per_second);
+			self.query_quota.check(ns, limit)?;
+		}
 		// Create a new query options
 		let opt = Options::default()
 			.with_id(self.id.0)
@@ -1142,12 +1314,39 @@ impl Datastore {
 		if let Some(channel) = &self.notification_channel {
 			ctx.add_notifications(Some(&channel.0));
 		}
+		// Setup the namespace usage tracker
+		ctx.add_usage(self.usage.clone());
+		// Setup the connected session tracker
+		ctx.add_sessions(Some(self.sessions.clone()));
+		// Setup the seeded random number generator, which continues the
+		// sequence of the session, or otherwise of the whole datastore
+		ctx.add_rng(sess.rng.clone().or_else(|| self.rng.clone()));
+		// Setup the server memory budget
+		ctx.add_memory_budget(self.memory.clone());
+		// Track the query while it is running
+		let active = self.admit(sess, ctx.add_cancel())?;
 		// Start an execution context
 		let ctx = sess.context(ctx);
 		// Store the query variables
 		let ctx = vars.attach(ctx)?;
-		// Process all statements
-		let res = exe.execute(ctx, opt, ast).await;
+		// Get the query start time
+		let now = Instant::now();
+		// Process all statements, correlated with the session
+		let res = exe
+			.execute(ctx, opt, ast)
+			.instrument(debug_span!(
+				"query",
+				session.id = sess.id.as_deref(),
+				ns = sess.ns.as_deref(),
+				db = sess.db.as_deref(),
+			))
+			.await;
+		// The query is no longer running
+		drop(active);
+		// Meter the time spent processing the query
+		if let (Some(usage), Some(ns)) = (&self.usage, &sess.ns) {
+			usage.time(ns, now.elapsed());
+		}
 		match res {
 			Ok((responses, lives)) => {
 				// Register live queries
@@ -1221,6 +1420,8 @@ impl Datastore {
 		if let Some(channel) = &self.notification_channel {
 			ctx.add_notifications(Some(&channel.0));
 		}
+		// Track the query while it is running
+		let _active = self.admit(sess, ctx.add_cancel())?;
 		// Start an execution context
 		let ctx = sess.context(ctx);
 		// Store the query variables
@@ -1296,6 +1497,8 @@ impl Datastore {
 		if let Some(channel) = &self.notification_channel {
 			ctx.add_notifications(Some(&channel.0));
 		}
+		// Track the query while it is running
+		let _active = self.admit(sess, ctx.add_cancel())?;
 		// Start an execution context
 		let ctx = sess.context(ctx);
 		// Store the query variables
@@ -1341,6 +1544,117 @@ impl Datastore {
 		self.notification_channel.as_ref().map(|v| v.1.clone())
 	}
 
+	/// Retrieve the resources consumed by each namespace
+	///
+	/// Usage is only metered when the `SURREAL_NAMESPACE_USAGE_METERING`
+	/// environment variable is enabled, otherwise this returns no entries.
+	pub fn usage(&self) -> BTreeMap<String, Usage> {
+		match &self.usage {
+			Some(usage) => usage.all(),
+			None => BTreeMap::new(),
+		}
+	}
+
+	/// Retrieve the queries which are currently being processed, oldest first
+	pub fn active_queries(&self) -> Vec<ActiveQuery> {
+		self.queries.all()
+	}
+
+	/// Register a query before it is processed, so that it can be waited
+	/// for, or cancelled, when the datastore is drained. Any new queries
+	/// are rejected once the datastore has started draining.
+	fn admit(&self, sess: &Session, canceller: Canceller) -> Result<ActiveQueryGuard, Error> {
+		// Track the query before checking if the datastore is draining,
+		// so that either the drain waits for this query, or this query
+		// sees that the datastore is draining and is rejected
+		let active = self.queries.start(sess.ns.clone(), sess.db.clone(), canceller);
+		// Check if the datastore is shutting down
+		if self.draining.load(Ordering::Acquire) {
+			return Err(Error::ShuttingDown);
+		}
+		Ok(active)
+	}
+
+	/// Stop accepting new queries, and wait for the running queries to
+	/// finish. Any queries which are still running after the grace period
+	/// are cancelled, and are given the same period again to stop.
+	pub async fn drain(&self, grace: Duration) {
+		// Reject any new queries
+		self.draining.store(true, Ordering::Release);
+		// Wait for the running queries to finish
+		if !self.wait_for_queries(grace).await {
+			warn!("Cancelling {} queries which did not finish in time", self.queries.all().len());
+			self.queries.cancel_all();
+			self.wait_for_queries(grace).await;
+		}
+	}
+
+	/// Wait for the running queries to finish, returning
+	/// false if they did not finish within the timeout
+	async fn wait_for_queries(&self, timeout: Duration) -> bool {
+		let deadline = Instant::now() + timeout;
+		while !self.queries.is_empty() {
+			if Instant::now() >= deadline {
+				return false;
+			}
+			#[cfg(target_arch = "wasm32")]
+			wasmtimer::tokio::sleep(Duration::from_millis(50)).await;
+			#[cfg(not(target_arch = "wasm32"))]
+			tokio::time::sleep(Duration::from_millis(50)).await;
+		}
+		true
+	}
+
+	/// Close the live queries registered on this node, and the notification
+	/// channel, and flush any buffered writes to storage. This should be run
+	/// once the datastore has been drained.
+	pub async fn shutdown(&self) -> Result<(), Error> {
+		// Remove the live queries registered on this node
+		let mut tx = self.transaction(Write, Optimistic).await?;
+		for lq in tx.scan_ndlq(&self.id, NON_PAGED_BATCH_SIZE).await? {
+			tx.del(crate::key::node::lq::new(lq.nd.0, lq.lq.0, &lq.ns, &lq.db)).await?;
+			tx.del(crate::key::table::lq::new(&lq.ns, &lq.db, &lq.tb, lq.lq.0)).await?;
+		}
+		tx.commit().await?;
+		// Close the live query notification channel
+		if let Some(channel) = &self.notification_channel {
+			channel.0.close();
+		}
+		// Flush any buffered writes to storage
+		match &self.inner {
+			#[cfg(feature = "kv-rocksdb")]
+			Inner::RocksDB(v) => v.flush(),
+			#[allow(unreachable_patterns)]
+			_ => Ok(()),
+		}
+	}
+
+	/// Register a long-lived client session, so that it can be listed and
+	/// revoked. The callback is run when the session is revoked.
+	pub fn register_session(
+		&self,
+		id: uuid::Uuid,
+		sess: &Session,
+		kill: impl Fn() + Send + Sync + 'static,
+	) {
+		self.sessions.register(id, sess, kill)
+	}
+
+	/// Update the details of a registered client session
+	pub fn update_session(&self, id: &uuid::Uuid, sess: &Session) {
+		self.sessions.update(id, sess)
+	}
+
+	/// Remove a client session once it has disconnected
+	pub fn unregister_session(&self, id: &uuid::Uuid) {
+		self.sessions.unregister(id)
+	}
+
+	/// Retrieve the client sessions which are currently connected, oldest first
+	pub fn active_sessions(&self) -> Vec<ActiveSession> {
+		self.sessions.all()
+	}
+
 	/// Performs a database import from SQL
 	#[instrument(level = "debug", skip(self, sess, sql))]
 	pub async fn import(&self, sql: &str, sess: &Session) -> Result<Vec<Response>, Error> {
@@ -1348,12 +1662,112 @@ impl Datastore {
 		self.execute(sql, sess, None).await
 	}
 
+	/// Performs a database import from a stream of SQL
+	///
+	/// Statements are parsed and executed as the input arrives, one statement or transaction
+	/// block at a time, so that the full import never needs to be held in memory. Parameters
+	/// which are set with a LET statement remain available to the rest of the import. The
+	/// import stops at the first statement which fails.
+	#[instrument(level = "debug", skip_all)]
+	pub async fn import_stream<S, B>(
+		&self,
+		sess: &Session,
+		vars: Variables,
+		stream: S,
+	) -> Result<(), Error>
+	where
+		S: Stream<Item = Result<B, Error>>,
+		B: AsRef<[u8]>,
+	{
+		let mut stream = pin!(stream);
+		let mut buffer = BytesMut::new();
+		let mut import = ImportBatch::default();
+		let mut vars = vars.unwrap_or_default();
+		// The length of the incomplete statement at the end of the buffer
+		let mut pending = 0;
+		// Parse and execute statements as they are received
+		while let Some(chunk) = stream.next().await {
+			buffer.extend_from_slice(chunk?.as_ref());
+			// A statement can not be parsed incrementally, so an incomplete
+			// statement is only parsed again once the buffer has doubled in
+			// size, otherwise a large statement would be parsed from the
+			// start for every chunk which is received.
+			if buffer.len() < pending * 2 {
+				continue;
+			}
+			while let Some(stmt) = syn::parse_buffered_statement(&mut buffer)? {
+				if let Some(query) = import.push(stmt) {
+					self.import_query(sess, &mut vars, query).await?;
+				}
+			}
+			pending = buffer.len();
+		}
+		// Parse any statements remaining at the end of the input
+		for stmt in syn::parse(&String::from_utf8_lossy(&buffer))? {
+			if let Some(query) = import.push(stmt) {
+				self.import_query(sess, &mut vars, query).await?;
+			}
+		}
+		// Execute any unterminated transaction
+		if let Some(query) = import.finish() {
+			self.import_query(sess, &mut vars, query).await?;
+		}
+		Ok(())
+	}
+
+	/// Executes a batch of imported statements, returning the first error.
+	/// The values of any parameters which are set by the batch are kept,
+	/// so that they can be used by the statements in later batches.
+	async fn import_query(
+		&self,
+		sess: &Session,
+		vars: &mut BTreeMap<String, Value>,
+		mut query: Query,
+	) -> Result<(), Error> {
+		// Return the value of each parameter which is set in this batch
+		let names: Vec<String> = query
+			.iter()
+			.filter_map(|stm| match stm {
+				Statement::Set(stm) => Some(stm.name.clone()),
+				_ => None,
+			})
+			.collect();
+		for name in names.iter() {
+			query.0 .0.push(Statement::Output(OutputStatement {
+				what: Value::Param(name.as_str().into()),
+				fetch: None,
+			}));
+		}
+		// Execute the batch with the parameters which are already set
+		let mut res = self.process(query, sess, Some(vars.clone())).await?;
+		let values = res.split_off(res.len().saturating_sub(names.len()));
+		for res in res {
+			res.result?;
+		}
+		for (name, res) in names.into_iter().zip(values) {
+			vars.insert(name, res.result?);
+		}
+		Ok(())
+	}
+
 	/// Performs a full database export as SQL
 	#[instrument(level = "debug", skip(self, sess, chn))]
 	pub async fn export(
 		&self,
 		sess: &Session,
 		chn: Sender<Vec<u8>>,
+	) -> Result<impl Future<Output = Result<(), Error>>, Error> {
+		self.export_tables(sess, Vec::new(), chn).await
+	}
+
+	/// Performs an export of the specified tables as SQL, or a
+	/// full database export if no tables are specified
+	#[instrument(level = "debug", skip(self, sess, chn))]
+	pub async fn export_tables(
+		&self,
+		sess: &Session,
+		tables: Vec<String>,
+		chn: Sender<Vec<u8>>,
 	) -> Result<impl Future<Output = Result<(), Error>>, Error> {
 		// Check if the session has expired
 		if sess.expired() {
@@ -1363,10 +1777,14 @@ impl Datastore {
 		let (ns, db) = crate::iam::check::check_ns_db(sess)?;
 		// Create a new readonly transaction
 		let mut txn = self.transaction(Read, Optimistic).await?;
+		// Ensure the specified tables exist
+		for tb in tables.iter() {
+			txn.get_tb(&ns, &db, tb).await?;
+		}
 		// Return an async export job
 		Ok(async move {
 			// Process the export
-			txn.export(&ns, &db, chn).await?;
+			txn.export(&ns, &db, &tables, chn).await?;
 			// Everything ok
 			Ok(())
 		})
@@ -1389,6 +1807,51 @@ impl Datastore {
 	}
 }
 
+/// Groups statements from a streamed import into queries which can be executed on their own
+#[derive(Default)]
+struct ImportBatch {
+	/// The OPTION and USE statements which apply to every following query
+	prelude: Vec<Statement>,
+	/// The statements of the transaction which is currently being read
+	batch: Vec<Statement>,
+}
+
+impl ImportBatch {
+	/// Adds a statement, returning a query once a complete statement or transaction is ready
+	fn push(&mut self, stmt: Statement) -> Option<Query> {
+		match (self.batch.first(), &stmt) {
+			// Statements within a transaction are executed together
+			(Some(Statement::Begin(_)), Statement::Commit(_) | Statement::Cancel(_)) => {
+				self.batch.push(stmt);
+				self.finish()
+			}
+			(Some(Statement::Begin(_)), _) | (None, Statement::Begin(_)) => {
+				self.batch.push(stmt);
+				None
+			}
+			// Options and the selected namespace and database apply to all later statements
+			(None, Statement::Option(_) | Statement::Use(_)) => {
+				self.prelude.push(stmt);
+				None
+			}
+			_ => {
+				self.batch.push(stmt);
+				self.finish()
+			}
+		}
+	}
+
+	/// Returns a query for any remaining statements
+	fn finish(&mut self) -> Option<Query> {
+		if self.batch.is_empty() {
+			return None;
+		}
+		let mut stmts = self.prelude.clone();
+		stmts.append(&mut self.batch);
+		Some(Query(Statements(stmts)))
+	}
+}
+
 #[cfg(test)]
 mod test {
 	use super::*;
@@ -1440,4 +1903,58 @@ mod test {
 		assert_eq!(res, Value::Number(Number::Int(2)));
 		Ok(())
 	}
+
+	#[tokio::test]
+	async fn import_stream_executes_statements_in_chunks() {
+		let dbs = Datastore::new("memory").await.unwrap();
+		let sess = Session::owner().with_ns("test").with_db("test");
+		let sql = "OPTION IMPORT;\nBEGIN TRANSACTION;\nCREATE person:one SET name = 'Tobie';\nCREATE person:two SET name = 'Jaime';\nCOMMIT TRANSACTION;\nCREATE person:three";
+		// Split the input into small chunks, including within statements
+		let chunks = sql.as_bytes().chunks(7).map(Ok::<_, Error>).collect::<Vec<_>>();
+		dbs.import_stream(&sess, None, futures::stream::iter(chunks)).await.unwrap();
+		let res = dbs.execute("SELECT VALUE id FROM person", &sess, None).await.unwrap();
+		let val = res.into_iter().next().unwrap().result.unwrap();
+		assert_eq!(val, syn::value("[person:one, person:three, person:two]").unwrap());
+	}
+
+	#[tokio::test]
+	async fn import_stream_keeps_parameters() {
+		let dbs = Datastore::new("memory").await.unwrap();
+		let sess = Session::owner().with_ns("test").with_db("test");
+		let sql = "LET $name = 'Tobie';\nBEGIN;\nLET $age = 21;\nCOMMIT;\nCREATE person:one SET name = $name, age = $age, role = $role;";
+		let vars = map! { String::from("role") => Value::from("admin") };
+		// Split the input into a single chunk per byte
+		let chunks = sql.as_bytes().chunks(1).map(Ok::<_, Error>).collect::<Vec<_>>();
+		dbs.import_stream(&sess, Some(vars), futures::stream::iter(chunks)).await.unwrap();
+		let res = dbs.execute("SELECT * FROM person", &sess, None).await.unwrap();
+		let val = res.into_iter().next().unwrap().result.unwrap();
+		let exp = "[{ id: person:one, name: 'Tobie', age: 21, role: 'admin' }]";
+		assert_eq!(val, syn::value(exp).unwrap());
+	}
+
+	#[tokio::test]
+	async fn export_tables_only_outputs_the_specified_tables() {
+		let dbs = Datastore::new("memory").await.unwrap();
+		let sess = Session::owner().with_ns("test").with_db("test");
+		let sql = "DEFINE PARAM $limit VALUE 10; CREATE person:one; CREATE animal:one;";
+		dbs.execute(sql, &sess, None).await.unwrap();
+		// Export a single table
+		let (snd, rcv) = crate::channel::bounded(16);
+		let task = dbs.export_tables(&sess, vec!["person".to_owned()], snd).await.unwrap();
+		tokio::spawn(task);
+		let mut out = Vec::new();
+		while let Ok(v) = rcv.recv().await {
+			out.extend(v);
+		}
+		let out = String::from_utf8(out).unwrap();
+		assert!(out.contains("OPTION IMPORT;"));
+		assert!(out.contains("DEFINE TABLE person"));
+		assert!(out.contains("INSERT [ { id: person:one } ];"));
+		assert!(!out.contains("animal"));
+		assert!(!out.contains("DEFINE PARAM"));
+		// Exporting a missing table fails before any output
+		let (snd, _) = crate::channel::bounded(16);
+		let res = dbs.export_tables(&sess, vec!["missing".to_owned()], snd).await;
+		assert!(matches!(res, Err(Error::TbNotFound { .. })));
+	}
 }
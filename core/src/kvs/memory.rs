@@ -0,0 +1,179 @@
+use crate::err::Error;
+use crate::sql::{Geometry, Id, Value};
+use geo::{Coord, CoordsIter};
+use std::fmt;
+use std::mem;
+use std::sync::atomic::{AtomicUsize, Ordering};
+use std::sync::Arc;
+
+/// Tracks the bytes retained by the results of all running queries,
+/// so that heavy queries fail instead of exhausting process memory.
+pub(crate) struct MemoryBudget {
+	// The maximum number of bytes which can be retained
+	limit: usize,
+	// The number of bytes which are currently retained
+	used: AtomicUsize,
+}
+
+impl MemoryBudget {
+	/// Create a new budget with the specified limit in bytes
+	pub(crate) fn new(limit: usize) -> Self {
+		Self {
+			limit,
+			used: AtomicUsize::new(0),
+		}
+	}
+
+	/// The number of bytes which are currently retained
+	pub(crate) fn used(&self) -> usize {
+		self.used.load(Ordering::Acquire)
+	}
+
+	/// Create an empty reservation against this budget
+	pub(crate) fn reservation(self: &Arc<Self>) -> MemoryReservation {
+		MemoryReservation {
+			budget: self.clone(),
+			bytes: 0,
+		}
+	}
+}
+
+/// The bytes retained by a single query, which are
+/// released back to the budget when this is dropped.
+#[doc(hidden)]
+pub struct MemoryReservation {
+	budget: Arc<MemoryBudget>,
+	bytes: usize,
+}
+
+impl MemoryReservation {
+	/// Reserve the memory retained by a value, returning an
+	/// error if this would exceed the server memory budget.
+	pub(crate) fn retain(&mut self, val: &Value) -> Result<(), Error> {
+		self.grow(estimate(val))
+	}
+
+	/// Reserve the specified number of bytes
+	pub(crate) fn grow(&mut self, bytes: usize) -> Result<(), Error> {
+		let used = self.budget.used.fetch_add(bytes, Ordering::AcqRel) + bytes;
+		// Check if the budget has been exceeded
+		if used > self.budget.limit {
+			self.budget.used.fetch_sub(bytes, Ordering::AcqRel);
+			return Err(Error::MemoryBudgetExceeded {
+				limit: self.budget.limit,
+			});
+		}
+		// Track the bytes held by this query
+		self.bytes += bytes;
+		// All ok
+		Ok(())
+	}
+}
+
+impl fmt::Debug for MemoryReservation {
+	fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+		f.debug_struct("MemoryReservation").field("bytes", &self.bytes).finish()
+	}
+}
+
+impl Drop for MemoryReservation {
+	fn drop(&mut self) {
+		self.budget.used.fetch_sub(self.bytes, Ordering::AcqRel);
+	}
+}
+
+/// Approximate the number of bytes used by a value
+pub(crate) fn estimate(val: &Value) -> usize {
+	mem::size_of::<Value>()
+		+ match val {
+			Value::Number(v) => mem::size_of_val(v),
+			Value::Duration(v) => mem::size_of_val(v),
+			Value::Datetime(v) => mem::size_of_val(v),
+			Value::Uuid(v) => mem::size_of_val(v),
+			Value::Strand(v) => v.0.len(),
+			Value::Bytes(v) => v.0.len(),
+			Value::Thing(v) => v.tb.len() + estimate_id(&v.id),
+			Value::Geometry(v) => estimate_geometry(v),
+			Value::Array(v) => v.iter().map(estimate).sum(),
+			Value::Object(v) => v.iter().map(|(k, v)| k.len() + estimate(v)).sum(),
+			_ => 0,
+		}
+}
+
+/// Approximate the number of bytes used by a record id
+fn estimate_id(id: &Id) -> usize {
+	mem::size_of::<Id>()
+		+ match id {
+			Id::String(v) => v.len(),
+			Id::Array(v) => v.iter().map(estimate).sum(),
+			Id::Object(v) => v.iter().map(|(k, v)| k.len() + estimate(v)).sum(),
+			_ => 0,
+		}
+}
+
+/// Approximate the number of bytes used by a geometry
+fn estimate_geometry(geo: &Geometry) -> usize {
+	let coords = match geo {
+		Geometry::Point(v) => v.coords_count(),
+		Geometry::Line(v) => v.coords_count(),
+		Geometry::Polygon(v) => v.coords_count(),
+		Geometry::MultiPoint(v) => v.coords_count(),
+		Geometry::MultiLine(v) => v.coords_count(),
+		Geometry::MultiPolygon(v) => v.coords_count(),
+		Geometry::Collection(v) => {
+			return v.iter().map(|v| mem::size_of::<Geometry>() + estimate_geometry(v)).sum()
+		}
+	};
+	coords * mem::size_of::<Coord<f64>>()
+}
+
+#[cfg(test)]
+mod tests {
+	use super::{estimate, MemoryBudget};
+	use crate::err::Error;
+	use crate::sql::{Geometry, Value};
+	use std::sync::Arc;
+
+	#[test]
+	fn released_on_drop() {
+		let budget = Arc::new(MemoryBudget::new(1024));
+		let mut res = budget.reservation();
+		assert!(res.retain(&Value::from("test")).is_ok());
+		assert!(budget.used() > 0);
+		drop(res);
+		assert_eq!(budget.used(), 0);
+	}
+
+	#[test]
+	fn exceeded_budget() {
+		let budget = Arc::new(MemoryBudget::new(1024));
+		let mut one = budget.reservation();
+		let mut two = budget.reservation();
+		let val = Value::from("x".repeat(600));
+		assert!(one.retain(&val).is_ok());
+		assert!(matches!(two.retain(&val), Err(Error::MemoryBudgetExceeded { .. })));
+		// Memory is available again once the first query finishes
+		drop(one);
+		assert!(two.retain(&val).is_ok());
+	}
+
+	#[test]
+	fn estimates_nested_values() {
+		let base = estimate(&Value::None);
+		// Record ids include the size of their id
+		let short = estimate(&Value::parse("person:a"));
+		let long = estimate(&Value::parse("person:⟨a very long record identifier⟩"));
+		assert!(long >= short + 20);
+		let complex = estimate(&Value::parse("person:[1, 'a very long record identifier']"));
+		assert!(complex > long);
+		// Geometries include each of their coordinates
+		let point = estimate(&Geometry::Point((1.0, 2.0).into()).into());
+		let line = Geometry::Line(vec![(1.0, 2.0), (3.0, 4.0), (5.0, 6.0)].into());
+		let line = estimate(&line.into());
+		assert!(point > base);
+		assert!(line > point);
+		// Datetimes and durations are counted
+		assert!(estimate(&Value::parse("d'2024-01-01T00:00:00Z'")) > base);
+		assert!(estimate(&Value::parse("1h30m")) > base);
+	}
+}
@@ -18,10 +18,16 @@ mod fdb;
 mod indxdb;
 mod kv;
 mod mem;
+mod memory;
+mod queries;
+mod quota;
 mod rocksdb;
+mod sessions;
 mod surrealkv;
 mod tikv;
 mod tx;
+mod usage;
+mod version;
 
 pub(crate) mod lq_structs;
 
@@ -33,4 +39,16 @@ mod tests;
 
 pub use self::ds::*;
 pub use self::kv::*;
+#[doc(hidden)]
+pub use self::memory::MemoryReservation;
+pub use self::queries::ActiveQuery;
+pub use self::sessions::ActiveSession;
 pub use self::tx::*;
+pub use self::usage::Usage;
+pub use self::version::Version;
+
+pub(crate) use self::cache::PermissionKind;
+pub(crate) use self::memory::{estimate, MemoryBudget};
+pub(crate) use self::quota::{del_table_usage, StorageQuota};
+pub(crate) use self::sessions::SessionTracker;
+pub(crate) use self::usage::UsageTracker;
@@ -0,0 +1,130 @@
+use crate::ctx::Canceller;
+use crate::sql::Datetime;
+use std::collections::HashMap;
+use std::sync::{Arc, Mutex};
+use uuid::Uuid;
+
+/// A query which is currently being processed by the datastore
+#[derive(Clone, Debug, Eq, PartialEq)]
+#[non_exhaustive]
+pub struct ActiveQuery {
+	/// The unique id of this query
+	pub id: Uuid,
+	/// The namespace selected when the query was started
+	pub ns: Option<String>,
+	/// The database selected when the query was started
+	pub db: Option<String>,
+	/// The time at which the query was started
+	pub started: Datetime,
+}
+
+/// Keeps track of the queries which are currently running, so
+/// that long running or stalled queries can be diagnosed, and
+/// so that they can be cancelled when the server shuts down.
+#[derive(Default)]
+#[non_exhaustive]
+pub(crate) struct QueryTracker {
+	queries: Mutex<HashMap<Uuid, (ActiveQuery, Canceller)>>,
+}
+
+impl QueryTracker {
+	/// Register a new query, which is removed when the guard is dropped
+	pub(crate) fn start(
+		self: &Arc<Self>,
+		ns: Option<String>,
+		db: Option<String>,
+		canceller: Canceller,
+	) -> ActiveQueryGuard {
+		let id = Uuid::new_v4();
+		if let Ok(mut queries) = self.queries.lock() {
+			queries.insert(
+				id,
+				(
+					ActiveQuery {
+						id,
+						ns,
+						db,
+						started: Datetime::default(),
+					},
+					canceller,
+				),
+			);
+		}
+		ActiveQueryGuard {
+			id,
+			tracker: self.clone(),
+		}
+	}
+
+	/// Retrieve all of the currently running queries, oldest first
+	pub(crate) fn all(&self) -> Vec<ActiveQuery> {
+		let mut out: Vec<ActiveQuery> = match self.queries.lock() {
+			Ok(queries) => queries.values().map(|(q, _)| q.clone()).collect(),
+			Err(_) => Vec::new(),
+		};
+		out.sort_by(|a, b| a.started.cmp(&b.started));
+		out
+	}
+
+	/// Check if there are no queries currently running
+	pub(crate) fn is_empty(&self) -> bool {
+		match self.queries.lock() {
+			Ok(queries) => queries.is_empty(),
+			Err(_) => true,
+		}
+	}
+
+	/// Cancel all of the currently running queries
+	pub(crate) fn cancel_all(&self) {
+		if let Ok(queries) = self.queries.lock() {
+			for (_, canceller) in queries.values() {
+				canceller.cancel();
+			}
+		}
+	}
+}
+
+/// Removes a query from the tracker once it has finished
+pub(crate) struct ActiveQueryGuard {
+	id: Uuid,
+	tracker: Arc<QueryTracker>,
+}
+
+impl Drop for ActiveQueryGuard {
+	fn drop(&mut self) {
+		if let Ok(mut queries) = self.tracker.queries.lock() {
+			queries.remove(&self.id);
+		}
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+	use std::sync::atomic::{AtomicBool, Ordering};
+
+	#[test]
+	fn queries_are_removed_when_finished() {
+		let tracker = Arc::new(QueryTracker::default());
+		let one = tracker.start(Some("test".to_owned()), None, Canceller::default());
+		let two = tracker.start(None, None, Canceller::default());
+		assert_eq!(tracker.all().len(), 2);
+		drop(one);
+		let all = tracker.all();
+		assert_eq!(all.len(), 1);
+		assert_eq!(all[0].ns, None);
+		drop(two);
+		assert!(tracker.all().is_empty());
+		assert!(tracker.is_empty());
+	}
+
+	#[test]
+	fn queries_are_cancelled() {
+		let tracker = Arc::new(QueryTracker::default());
+		let cancelled = Arc::new(AtomicBool::new(false));
+		let _query = tracker.start(None, None, Canceller::new(cancelled.clone()));
+		assert!(!cancelled.load(Ordering::Relaxed));
+		tracker.cancel_all();
+		assert!(cancelled.load(Ordering::Relaxed));
+	}
+}
@@ -0,0 +1,179 @@
+use crate::cnf::{quota, DATABASE_MAX_STORAGE_MB, TABLE_MAX_RECORDS};
+use crate::err::Error;
+use crate::kvs::Transaction;
+use derive::Store;
+use revision::revisioned;
+use serde::{Deserialize, Serialize};
+use std::collections::HashMap;
+use std::sync::Mutex;
+use std::time::Duration;
+use trice::Instant;
+
+/// Tracks the number of queries run against each namespace, so
+/// that a single tenant can not monopolise a shared datastore.
+#[derive(Default)]
+#[non_exhaustive]
+pub(crate) struct QueryQuota {
+	// The start of the current window, and the queries run within it
+	windows: Mutex<HashMap<String, (Instant, u32)>>,
+}
+
+impl QueryQuota {
+	/// Record a query against the specified namespace, returning an
+	/// error if the namespace has exceeded the specified number of
+	/// queries for the current one second window.
+	pub(crate) fn check(&self, ns: &str, limit: u32) -> Result<(), Error> {
+		// A limit of zero disables the quota
+		if limit == 0 {
+			return Ok(());
+		}
+		// Lock the namespace windows
+		let mut windows = self.windows.lock().map_err(|e| Error::Internal(e.to_string()))?;
+		// Fetch the window for this namespace
+		let window = windows.entry(ns.to_owned()).or_insert_with(|| (Instant::now(), 0));
+		// Start a new window every second
+		if window.0.elapsed() >= Duration::from_secs(1) {
+			*window = (Instant::now(), 0);
+		}
+		// Check if the quota has been exceeded
+		if window.1 >= limit {
+			return Err(Error::NsQueryQuotaExceeded {
+				ns: ns.to_owned(),
+				limit,
+			});
+		}
+		// Count this query
+		window.1 += 1;
+		// All ok
+		Ok(())
+	}
+}
+
+/// The record and storage quotas which apply to a database.
+pub(crate) struct StorageQuota {
+	/// The maximum number of records in each table
+	records: u64,
+	/// The maximum size in bytes of the record data in the database
+	bytes: u64,
+}
+
+/// The number of records and the size of the record data in a single
+/// table, which is updated in the same transaction as the records.
+#[revisioned(revision = 1)]
+#[derive(Clone, Copy, Debug, Default, Eq, PartialEq, Serialize, Deserialize, Store)]
+#[non_exhaustive]
+pub(crate) struct TableUsage {
+	pub records: u64,
+	pub bytes: u64,
+}
+
+/// The size of the record data in a whole database, which is updated
+/// in the same transaction as the records.
+#[revisioned(revision = 1)]
+#[derive(Clone, Copy, Debug, Default, Eq, PartialEq, Serialize, Deserialize, Store)]
+#[non_exhaustive]
+pub(crate) struct DatabaseUsage {
+	pub bytes: u64,
+}
+
+impl StorageQuota {
+	/// Get the record and storage quotas for the specified database
+	pub(crate) fn new(ns: &str, db: &str) -> Self {
+		let records = quota(ns, Some(db), &TABLE_MAX_RECORDS, |q| q.table_max_records);
+		let storage = quota(ns, Some(db), &DATABASE_MAX_STORAGE_MB, |q| q.storage_max_mb);
+		Self {
+			records: records as u64,
+			bytes: storage as u64 * 1024 * 1024,
+		}
+	}
+
+	/// Update the usage of a table and its database when a record is stored
+	/// or removed, returning an error if the change would exceed one of the
+	/// quotas. The usage is updated with the change alone, and is tracked
+	/// whether or not a quota applies, so that it is up to date whenever a
+	/// quota is configured. Records which are updated are only checked
+	/// against the storage quota if they grow, and records which are
+	/// removed never fail.
+	pub(crate) async fn update(
+		&self,
+		tx: &mut Transaction,
+		ns: &str,
+		db: &str,
+		tb: &str,
+		records: i64,
+		bytes: i64,
+	) -> Result<(), Error> {
+		// Fetch the current usage of the table and database
+		let tkey = crate::key::database::qt::new(ns, db, tb);
+		let mut table: TableUsage = tx.get(tkey.clone()).await?.map(Into::into).unwrap_or_default();
+		let dkey = crate::key::database::qs::new(ns, db);
+		let mut database: DatabaseUsage =
+			tx.get(dkey.clone()).await?.map(Into::into).unwrap_or_default();
+		// Check the table has not reached its record quota
+		if records > 0 && self.records > 0 && table.records + records as u64 > self.records {
+			return Err(Error::TbRecordQuotaExceeded {
+				tb: tb.to_owned(),
+				limit: self.records,
+			});
+		}
+		// Check the database has not reached its storage quota
+		if bytes > 0 && self.bytes > 0 && database.bytes + bytes as u64 > self.bytes {
+			return Err(Error::DbStorageQuotaExceeded {
+				db: db.to_owned(),
+				limit: self.bytes,
+			});
+		}
+		// Store the updated usage of the table and database
+		table.records = table.records.saturating_add_signed(records);
+		table.bytes = table.bytes.saturating_add_signed(bytes);
+		database.bytes = database.bytes.saturating_add_signed(bytes);
+		tx.set(tkey, table).await?;
+		tx.set(dkey, database).await
+	}
+}
+
+/// Remove the usage of a table, when the table is removed, releasing the
+/// size of its record data from the database.
+pub(crate) async fn del_table_usage(
+	tx: &mut Transaction,
+	ns: &str,
+	db: &str,
+	tb: &str,
+) -> Result<(), Error> {
+	let tkey = crate::key::database::qt::new(ns, db, tb);
+	if let Some(v) = tx.get(tkey.clone()).await? {
+		let table: TableUsage = v.into();
+		let dkey = crate::key::database::qs::new(ns, db);
+		let mut database: DatabaseUsage =
+			tx.get(dkey.clone()).await?.map(Into::into).unwrap_or_default();
+		database.bytes = database.bytes.saturating_sub(table.bytes);
+		tx.set(dkey, database).await?;
+		tx.del(tkey).await?;
+	}
+	Ok(())
+}
+
+#[cfg(test)]
+mod tests {
+	use super::QueryQuota;
+	use crate::err::Error;
+
+	#[test]
+	fn unlimited_quota() {
+		let quota = QueryQuota::default();
+		for _ in 0..1000 {
+			assert!(quota.check("test", 0).is_ok());
+		}
+	}
+
+	#[test]
+	fn exceeded_quota() {
+		let quota = QueryQuota::default();
+		for _ in 0..10 {
+			assert!(quota.check("test", 10).is_ok());
+		}
+		assert!(matches!(quota.check("test", 10), Err(Error::NsQueryQuotaExceeded { .. })));
+		// Other namespaces are tracked separately
+		assert!(quota.check("other", 10).is_ok());
+	}
+}
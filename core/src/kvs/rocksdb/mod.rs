@@ -118,6 +118,12 @@ impl Datastore {
 			db: Arc::pin(OptimisticTransactionDB::open(&opts, path)?),
 		})
 	}
+	/// Sync the write-ahead log, and flush the memtables to disk
+	pub(crate) fn flush(&self) -> Result<(), Error> {
+		self.db.flush_wal(true)?;
+		self.db.flush()?;
+		Ok(())
+	}
 	/// Start a new transaction
 	pub(crate) async fn transaction(&self, write: bool, _: bool) -> Result<Transaction, Error> {
 		// Set the transaction options
@@ -0,0 +1,176 @@
+use crate::dbs::Session;
+use crate::sql::statements::info::InfoStructure;
+use crate::sql::{Datetime, Value};
+use std::collections::HashMap;
+use std::sync::Mutex;
+use uuid::Uuid;
+
+/// A client session which is currently connected to the datastore
+#[derive(Clone, Debug, Eq, PartialEq)]
+#[non_exhaustive]
+pub struct ActiveSession {
+	/// The unique id of this session
+	pub id: Uuid,
+	/// The namespace currently selected by the session
+	pub ns: Option<String>,
+	/// The database currently selected by the session
+	pub db: Option<String>,
+	/// The access method used to authenticate the session
+	pub ac: Option<String>,
+	/// The actor authenticated on the session
+	pub actor: String,
+	/// The level at which the actor is authenticated
+	pub level: String,
+	/// The id of the token used to authenticate the session
+	pub token: Option<String>,
+	/// The address of the client which established the session
+	pub ip: Option<String>,
+	/// The time at which the session was established
+	pub started: Datetime,
+}
+
+impl ActiveSession {
+	fn update(&mut self, session: &Session) {
+		self.ns.clone_from(&session.ns);
+		self.db.clone_from(&session.db);
+		self.ac.clone_from(&session.ac);
+		self.actor = session.au.id().to_owned();
+		self.level = session.au.level().to_string();
+		self.token = match session.tk.as_ref().map(|tk| tk.pick(&["jti".into()])) {
+			Some(Value::Strand(jti)) => Some(jti.0),
+			_ => None,
+		};
+		self.ip.clone_from(&session.ip);
+	}
+}
+
+impl InfoStructure for ActiveSession {
+	fn structure(self) -> Value {
+		Value::from(map! {
+			"id".to_string() => Value::from(self.id),
+			"ns".to_string() => Value::from(self.ns),
+			"db".to_string() => Value::from(self.db),
+			"ac".to_string() => Value::from(self.ac),
+			"actor".to_string() => Value::from(self.actor),
+			"level".to_string() => Value::from(self.level),
+			"token".to_string() => Value::from(self.token),
+			"ip".to_string() => Value::from(self.ip),
+			"started".to_string() => Value::from(self.started),
+		})
+	}
+}
+
+/// A registered session, and the callback used to terminate it
+struct Entry {
+	session: ActiveSession,
+	kill: Box<dyn Fn() + Send + Sync>,
+}
+
+/// Keeps track of the long-lived client sessions which are connected
+/// to this node, so that they can be listed and forcibly terminated.
+#[derive(Default)]
+#[non_exhaustive]
+pub(crate) struct SessionTracker {
+	sessions: Mutex<HashMap<Uuid, Entry>>,
+}
+
+impl SessionTracker {
+	/// Register a new session, along with a callback to terminate it
+	pub(crate) fn register(
+		&self,
+		id: Uuid,
+		session: &Session,
+		kill: impl Fn() + Send + Sync + 'static,
+	) {
+		let mut entry = Entry {
+			session: ActiveSession {
+				id,
+				ns: None,
+				db: None,
+				ac: None,
+				actor: String::new(),
+				level: String::new(),
+				token: None,
+				ip: None,
+				started: Datetime::default(),
+			},
+			kill: Box::new(kill),
+		};
+		entry.session.update(session);
+		if let Ok(mut sessions) = self.sessions.lock() {
+			sessions.insert(id, entry);
+		}
+	}
+
+	/// Update the details of a session after it has changed
+	pub(crate) fn update(&self, id: &Uuid, session: &Session) {
+		if let Ok(mut sessions) = self.sessions.lock() {
+			if let Some(entry) = sessions.get_mut(id) {
+				entry.session.update(session);
+			}
+		}
+	}
+
+	/// Remove a session once it has disconnected
+	pub(crate) fn unregister(&self, id: &Uuid) {
+		if let Ok(mut sessions) = self.sessions.lock() {
+			sessions.remove(id);
+		}
+	}
+
+	/// Terminate all of the sessions which match the predicate
+	pub(crate) fn kill<F>(&self, predicate: F) -> usize
+	where
+		F: Fn(&ActiveSession) -> bool,
+	{
+		let mut count = 0;
+		if let Ok(sessions) = self.sessions.lock() {
+			for entry in sessions.values().filter(|entry| predicate(&entry.session)) {
+				(entry.kill)();
+				count += 1;
+			}
+		}
+		count
+	}
+
+	/// Retrieve all of the currently connected sessions, oldest first
+	pub(crate) fn all(&self) -> Vec<ActiveSession> {
+		let mut out: Vec<ActiveSession> = match self.sessions.lock() {
+			Ok(sessions) => sessions.values().map(|entry| entry.session.clone()).collect(),
+			Err(_) => Vec::new(),
+		};
+		out.sort_by(|a, b| a.started.cmp(&b.started));
+		out
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+	use std::sync::atomic::{AtomicUsize, Ordering};
+	use std::sync::Arc;
+
+	#[test]
+	fn sessions_can_be_killed() {
+		let tracker = SessionTracker::default();
+		let killed = Arc::new(AtomicUsize::new(0));
+		let one = Uuid::new_v4();
+		let two = Uuid::new_v4();
+		for id in [one, two] {
+			let killed = killed.clone();
+			tracker.register(id, &Session::default(), move || {
+				killed.fetch_add(1, Ordering::SeqCst);
+			});
+		}
+		tracker.update(&one, &Session::owner().with_ns("test"));
+		assert_eq!(tracker.all().len(), 2);
+		// Only the matching session is terminated
+		assert_eq!(tracker.kill(|s| s.ns.as_deref() == Some("test")), 1);
+		assert_eq!(killed.load(Ordering::SeqCst), 1);
+		// Sessions are removed once they disconnect
+		tracker.unregister(&one);
+		let all = tracker.all();
+		assert_eq!(all.len(), 1);
+		assert_eq!(all[0].id, two);
+	}
+}
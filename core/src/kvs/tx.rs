@@ -6,9 +6,10 @@ use std::sync::Arc;
 
 use channel::{Receiver, Sender};
 use futures::lock::Mutex;
+use tracing::instrument;
 use uuid::Uuid;
 
-use sql::permission::Permissions;
+use sql::permission::{Permission, Permissions};
 use sql::statements::DefineAccessStatement;
 use sql::statements::DefineAnalyzerStatement;
 use sql::statements::DefineDatabaseStatement;
@@ -35,6 +36,7 @@ use crate::key::error::KeyCategory;
 use crate::key::key_req::KeyRequirements;
 use crate::kvs::cache::Cache;
 use crate::kvs::cache::Entry;
+use crate::kvs::cache::{PermissionCache, PermissionKind};
 use crate::kvs::clock::SizedClock;
 use crate::kvs::lq_structs::{LqValue, TrackedResult};
 use crate::kvs::Check;
@@ -99,6 +101,8 @@ pub struct Transaction {
 	pub(super) clock: Arc<SizedClock>,
 	pub(super) prepared_async_events: (Arc<Sender<TrackedResult>>, Arc<Receiver<TrackedResult>>),
 	pub(super) engine_options: EngineOptions,
+	pub(super) perms: Arc<PermissionCache>,
+	pub(super) changed_tbs: Vec<Key>,
 }
 
 #[allow(clippy::large_enum_variant)]
@@ -237,6 +241,7 @@ impl Transaction {
 	/// Cancel a transaction.
 	///
 	/// This reverses all changes made within the transaction.
+	#[instrument(level = "trace", name = "kvs::cancel", skip_all)]
 	pub async fn cancel(&mut self) -> Result<(), Error> {
 		#[cfg(debug_assertions)]
 		trace!("Cancel");
@@ -279,6 +284,7 @@ impl Transaction {
 	/// Commit a transaction.
 	///
 	/// This attempts to commit all changes made within the transaction.
+	#[instrument(level = "trace", name = "kvs::commit", skip_all)]
 	pub async fn commit(&mut self) -> Result<(), Error> {
 		#[cfg(debug_assertions)]
 		trace!("Commit");
@@ -2044,6 +2050,7 @@ impl Transaction {
 				value,
 			}) => match strict {
 				false => {
+					self.change_tb(ns, db, tb).await?;
 					let key = crate::key::database::tb::new(ns, db, tb);
 					let val = DefineTableStatement {
 						name: tb.to_owned().into(),
@@ -2107,6 +2114,86 @@ impl Transaction {
 		})
 	}
 
+	/// Retrieve the version of a table definition, which changes every time the
+	/// table is defined, removed or renamed. Tables which have not been changed
+	/// since versions were introduced have a version of 0.
+	async fn get_and_cache_tb_version(
+		&mut self,
+		ns: &str,
+		db: &str,
+		tb: &str,
+	) -> Result<u64, Error> {
+		let key = crate::key::database::tv::new(ns, db, tb).encode()?;
+		if let Some(Entry::Tv(v)) = self.cache.get(&key) {
+			return Ok(v);
+		}
+		let val = match self.get(key.clone()).await? {
+			Some(v) => u64::from_be_bytes(
+				v.as_slice()
+					.try_into()
+					.map_err(|_| Error::Internal(format!("Invalid version for table '{tb}'")))?,
+			),
+			None => 0,
+		};
+		self.cache.set(key, Entry::Tv(val));
+		Ok(val)
+	}
+
+	/// Retrieve the shared permission clause of a table for a statement type.
+	/// The shared clauses are not used for tables changed in this transaction.
+	pub(crate) async fn get_tb_perms(
+		&mut self,
+		ns: &str,
+		db: &str,
+		tb: &str,
+		kind: PermissionKind,
+	) -> Result<Option<Arc<Permission>>, Error> {
+		let key = crate::key::database::tb::new(ns, db, tb).encode()?;
+		if self.changed_tbs.contains(&key) {
+			return Ok(None);
+		}
+		let version = self.get_and_cache_tb_version(ns, db, tb).await?;
+		Ok(self.perms.get(&key, kind, version))
+	}
+
+	/// Compile and share the permission clause of a table for a statement type.
+	/// The clauses of tables changed in this transaction are not shared.
+	pub(crate) async fn set_tb_perms(
+		&mut self,
+		ns: &str,
+		db: &str,
+		tb: &str,
+		kind: PermissionKind,
+		perms: &Permissions,
+	) -> Result<Arc<Permission>, Error> {
+		let key = crate::key::database::tb::new(ns, db, tb).encode()?;
+		if self.changed_tbs.contains(&key) {
+			return Ok(Arc::new(kind.of(perms).clone()));
+		}
+		let version = self.get_and_cache_tb_version(ns, db, tb).await?;
+		Ok(self.perms.set(key, kind, version, kind.of(perms)))
+	}
+
+	/// Record that a table definition has changed in this transaction, by
+	/// storing a new version of the table, so that other transactions stop
+	/// using the shared permission clauses of the previous version once this
+	/// transaction commits. The version is taken from the current time, so
+	/// that it also differs from any earlier table with the same name.
+	pub(crate) async fn change_tb(&mut self, ns: &str, db: &str, tb: &str) -> Result<(), Error> {
+		let key = crate::key::database::tb::new(ns, db, tb).encode()?;
+		if self.changed_tbs.contains(&key) {
+			return Ok(());
+		}
+		let prev = self.get_and_cache_tb_version(ns, db, tb).await?;
+		let now = chrono::Utc::now().timestamp_nanos_opt().unwrap_or_default() as u64;
+		let next = now.max(prev + 1);
+		let vkey = crate::key::database::tv::new(ns, db, tb).encode()?;
+		self.set(vkey.clone(), next.to_be_bytes().to_vec()).await?;
+		self.cache.set(vkey, Entry::Tv(next));
+		self.changed_tbs.push(key);
+		Ok(())
+	}
+
 	/// Retrieve and cache a specific table definition.
 	pub async fn get_and_cache_tb(
 		&mut self,
@@ -2299,6 +2386,7 @@ impl Transaction {
 				value,
 			}) => match strict {
 				false => {
+					self.change_tb(ns, db, tb).await?;
 					let key = crate::key::database::tb::new(ns, db, tb);
 					let val = DefineTableStatement {
 						name: tb.to_owned().into(),
@@ -2342,8 +2430,17 @@ impl Transaction {
 	// Additional methods
 	// --------------------------------------------------
 
-	/// Writes the full database contents as binary SQL.
-	pub async fn export(&mut self, ns: &str, db: &str, chn: Sender<Vec<u8>>) -> Result<(), Error> {
+	/// Writes the full database contents as binary SQL. If any tables are
+	/// specified, then only those tables and their records are written.
+	pub async fn export(
+		&mut self,
+		ns: &str,
+		db: &str,
+		tables: &[String],
+		chn: Sender<Vec<u8>>,
+	) -> Result<(), Error> {
+		// Check if the database definitions should be output
+		let full = tables.is_empty();
 		// Output OPTIONS
 		{
 			chn.send(bytes!("-- ------------------------------")).await?;
@@ -2354,7 +2451,7 @@ impl Transaction {
 			chn.send(bytes!("")).await?;
 		}
 		// Output USERS
-		{
+		if full {
 			let dus = self.all_db_users(ns, db).await?;
 			if !dus.is_empty() {
 				chn.send(bytes!("-- ------------------------------")).await?;
@@ -2368,7 +2465,7 @@ impl Transaction {
 			}
 		}
 		// Output ACCESSES
-		{
+		if full {
 			let dts = self.all_db_accesses(ns, db).await?;
 			if !dts.is_empty() {
 				chn.send(bytes!("-- ------------------------------")).await?;
@@ -2382,7 +2479,7 @@ impl Transaction {
 			}
 		}
 		// Output PARAMS
-		{
+		if full {
 			let pas = self.all_db_params(ns, db).await?;
 			if !pas.is_empty() {
 				chn.send(bytes!("-- ------------------------------")).await?;
@@ -2396,7 +2493,7 @@ impl Transaction {
 			}
 		}
 		// Output FUNCTIONS
-		{
+		if full {
 			let fcs = self.all_db_functions(ns, db).await?;
 			if !fcs.is_empty() {
 				chn.send(bytes!("-- ------------------------------")).await?;
@@ -2410,7 +2507,7 @@ impl Transaction {
 			}
 		}
 		// Output ANALYZERS
-		{
+		if full {
 			let azs = self.all_db_analyzers(ns, db).await?;
 			if !azs.is_empty() {
 				chn.send(bytes!("-- ------------------------------")).await?;
@@ -2425,7 +2522,13 @@ impl Transaction {
 		}
 		// Output TABLES
 		{
-			let tbs = self.all_tb(ns, db).await?;
+			let tbs: Vec<_> = self
+				.all_tb(ns, db)
+				.await?
+				.iter()
+				.filter(|tb| full || tables.contains(&tb.name.0))
+				.cloned()
+				.collect();
 			if !tbs.is_empty() {
 				for tb in tbs.iter() {
 					// Output TABLE
@@ -3123,6 +3226,46 @@ mod tests {
 			assert_eq!(res.len(), 0);
 		}
 	}
+
+	#[tokio::test]
+	async fn test_shared_table_permissions_from_other_node() {
+		use crate::kvs::cache::{PermissionCache, PermissionKind};
+		use crate::sql::permission::{Permission, Permissions};
+		use std::sync::Arc;
+		let ds = Datastore::new("memory").await.unwrap();
+		let full = Permissions::full();
+		let none = Permissions::none();
+		// Cache the permissions of the table on this node
+		{
+			let mut txn = ds.transaction(Write, Optimistic).await.unwrap();
+			txn.change_tb("test", "test", "person").await.unwrap();
+			txn.commit().await.unwrap();
+			let mut txn = ds.transaction(Read, Optimistic).await.unwrap();
+			let res = txn.get_tb_perms("test", "test", "person", PermissionKind::Select).await;
+			assert_eq!(res.unwrap(), None);
+			let res = txn.set_tb_perms("test", "test", "person", PermissionKind::Select, &full);
+			assert_eq!(*res.await.unwrap(), Permission::Full);
+			let res = txn.get_tb_perms("test", "test", "person", PermissionKind::Select).await;
+			assert_eq!(res.unwrap().as_deref(), Some(&Permission::Full));
+		}
+		// Change the table on another node, which has its own shared permissions
+		{
+			let mut txn = ds.transaction(Write, Optimistic).await.unwrap();
+			txn.perms = Arc::new(PermissionCache::default());
+			txn.change_tb("test", "test", "person").await.unwrap();
+			txn.commit().await.unwrap();
+		}
+		// The permissions cached on this node are no longer used
+		{
+			let mut txn = ds.transaction(Read, Optimistic).await.unwrap();
+			let res = txn.get_tb_perms("test", "test", "person", PermissionKind::Select).await;
+			assert_eq!(res.unwrap(), None);
+			let res = txn.set_tb_perms("test", "test", "person", PermissionKind::Select, &none);
+			assert_eq!(*res.await.unwrap(), Permission::None);
+			let res = txn.get_tb_perms("test", "test", "person", PermissionKind::Select).await;
+			assert_eq!(res.unwrap().as_deref(), Some(&Permission::None));
+		}
+	}
 }
 
 #[cfg(all(test, feature = "kv-mem"))]
@@ -0,0 +1,98 @@
+use std::collections::BTreeMap;
+use std::sync::Mutex;
+use std::time::Duration;
+
+/// The resources consumed by a single namespace
+#[derive(Clone, Copy, Debug, Default, Eq, PartialEq)]
+#[non_exhaustive]
+pub struct Usage {
+	/// The number of records which have been read
+	pub rows_read: u64,
+	/// The number of records which have been written
+	pub rows_written: u64,
+	/// The number of bytes which have been written
+	pub bytes_written: u64,
+	/// The total time spent processing queries
+	pub query_time: Duration,
+}
+
+/// Meters the resources consumed by each namespace, so that
+/// operators of a shared datastore can monitor or bill tenants.
+#[derive(Default)]
+#[non_exhaustive]
+pub(crate) struct UsageTracker {
+	namespaces: Mutex<BTreeMap<String, Usage>>,
+}
+
+impl UsageTracker {
+	/// Update the usage for a namespace
+	fn update<F>(&self, ns: &str, f: F)
+	where
+		F: FnOnce(&mut Usage),
+	{
+		if let Ok(mut namespaces) = self.namespaces.lock() {
+			match namespaces.get_mut(ns) {
+				Some(usage) => f(usage),
+				None => f(namespaces.entry(ns.to_owned()).or_default()),
+			}
+		}
+	}
+
+	/// Record records being read from a namespace
+	pub(crate) fn read(&self, ns: &str, rows: u64) {
+		self.update(ns, |u| u.rows_read += rows);
+	}
+
+	/// Record a record being written to a namespace
+	pub(crate) fn write(&self, ns: &str, bytes: u64) {
+		self.update(ns, |u| {
+			u.rows_written += 1;
+			u.bytes_written += bytes;
+		});
+	}
+
+	/// Record time spent processing a query in a namespace
+	pub(crate) fn time(&self, ns: &str, time: Duration) {
+		self.update(ns, |u| u.query_time += time);
+	}
+
+	/// Retrieve the usage for a single namespace
+	pub(crate) fn get(&self, ns: &str) -> Usage {
+		match self.namespaces.lock() {
+			Ok(namespaces) => namespaces.get(ns).copied().unwrap_or_default(),
+			Err(_) => Usage::default(),
+		}
+	}
+
+	/// Retrieve the usage for all namespaces
+	pub(crate) fn all(&self) -> BTreeMap<String, Usage> {
+		match self.namespaces.lock() {
+			Ok(namespaces) => namespaces.clone(),
+			Err(_) => BTreeMap::new(),
+		}
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	use super::UsageTracker;
+	use std::time::Duration;
+
+	#[test]
+	fn tracks_namespaces_separately() {
+		let usage = UsageTracker::default();
+		usage.read("test", 10);
+		usage.write("test", 128);
+		usage.write("test", 64);
+		usage.time("test", Duration::from_millis(5));
+		usage.read("other", 1);
+		let test = usage.get("test");
+		assert_eq!(test.rows_read, 10);
+		assert_eq!(test.rows_written, 2);
+		assert_eq!(test.bytes_written, 192);
+		assert_eq!(test.query_time, Duration::from_millis(5));
+		assert_eq!(usage.get("other").rows_read, 1);
+		assert_eq!(usage.get("none").rows_read, 0);
+		assert_eq!(usage.all().len(), 2);
+	}
+}
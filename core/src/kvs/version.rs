@@ -0,0 +1,384 @@
+use crate::cnf::EXPORT_BATCH_SIZE;
+use crate::err::Error;
+use crate::key::{database, namespace, root, table, thing};
+use crate::kvs::{Datastore, Key, LockType::*, ScanPage, Transaction, TransactionType::*, Val};
+use crate::sql::statements::{
+	DefineAccessStatement, DefineAnalyzerStatement, DefineDatabaseStatement, DefineEventStatement,
+	DefineFieldStatement, DefineFunctionStatement, DefineIndexStatement, DefineModelStatement,
+	DefineNamespaceStatement, DefineParamStatement, DefineTableStatement, DefineUserStatement,
+};
+use crate::sql::{Uuid, Value};
+use chrono::Utc;
+use derive::Store;
+use revision::revisioned;
+use serde::{Deserialize, Serialize};
+
+/// The version of the on-disk storage format, which is incremented
+/// whenever the encoding of the stored keys or values changes.
+#[derive(Clone, Copy, Debug, Eq, PartialEq, Ord, PartialOrd)]
+pub struct Version(pub u16);
+
+impl Version {
+	/// The storage format written by this release
+	pub const LATEST: Version = Version(1);
+
+	/// Check if this is the storage format written by this release
+	pub fn is_latest(&self) -> bool {
+		*self == Self::LATEST
+	}
+
+	/// Fetch the storage format of a datastore. Datastores without any
+	/// namespaces use the latest format, which is recorded the first time
+	/// it is checked, while older datastores never recorded a version.
+	pub(super) async fn fetch(tx: &mut Transaction) -> Result<Self, Error> {
+		let key = crate::key::root::sv::Sv::new();
+		match tx.get(key).await? {
+			Some(v) => Self::try_from(v),
+			None if tx.all_ns().await?.is_empty() => {
+				Self::LATEST.store(tx).await?;
+				Ok(Self::LATEST)
+			}
+			None => Ok(Version(0)),
+		}
+	}
+
+	/// Record the storage format of a datastore
+	pub(super) async fn store(&self, tx: &mut Transaction) -> Result<(), Error> {
+		let key = crate::key::root::sv::Sv::new();
+		tx.set(key, Vec::<u8>::from(*self)).await
+	}
+}
+
+/// How long the migration lease is held for, in seconds, unless it is renewed
+const LEASE_DURATION: i64 = 60;
+
+/// How many times a batch of values is rewritten, if it conflicts with
+/// another transaction, before the rewrite fails
+const REWRITE_ATTEMPTS: usize = 5;
+
+/// The lease which is held by the node which is migrating a datastore.
+/// The lease is renewed in every transaction of the migration, so that
+/// only one node can migrate a datastore at once, and so that another
+/// node can resume the migration if the node holding the lease stops.
+#[revisioned(revision = 1)]
+#[derive(Clone, Debug, Eq, PartialEq, Serialize, Deserialize, Store)]
+#[non_exhaustive]
+struct Lease {
+	node: Uuid,
+	expires: i64,
+}
+
+/// Upgrades a datastore to the storage format of this release, and, when it
+/// is requested, rewrites the stored data at the latest revisions. The data
+/// is rewritten in batches, in separate transactions, so that the datastore
+/// can be rewritten while it is in use, and so that an interrupted rewrite
+/// can be run again.
+pub(super) struct Migration<'a> {
+	ds: &'a Datastore,
+	node: Uuid,
+}
+
+impl<'a> Migration<'a> {
+	pub(super) fn new(ds: &'a Datastore, node: Uuid) -> Self {
+		Self {
+			ds,
+			node,
+		}
+	}
+
+	/// Migrate the datastore from the given storage format to the latest one
+	pub(super) async fn run(&self, mut version: Version) -> Result<(), Error> {
+		while !version.is_latest() {
+			info!("Migrating the datastore from storage version {}", version.0);
+			version = self.step(version).await?;
+			// Record each step, so the migration can resume from here
+			let mut tx = self.ds.transaction(Write, Optimistic).await?;
+			self.hold(&mut tx).await?;
+			version.store(&mut tx).await?;
+			tx.commit().await?;
+		}
+		Ok(())
+	}
+
+	/// Release the migration lease, if it is held by this node
+	pub(super) async fn release(&self) -> Result<(), Error> {
+		let mut tx = self.ds.transaction(Write, Optimistic).await?;
+		let key = root::mg::Mg::new();
+		if let Some(v) = tx.get(key.clone()).await? {
+			if Lease::from(v).node == self.node {
+				tx.del(key).await?;
+			}
+		}
+		tx.commit().await
+	}
+
+	/// Acquire or renew the migration lease within a transaction
+	async fn hold(&self, tx: &mut Transaction) -> Result<(), Error> {
+		let key = root::mg::Mg::new();
+		let now = Utc::now().timestamp();
+		if let Some(v) = tx.get(key.clone()).await? {
+			let lease = Lease::from(v);
+			if lease.node != self.node && lease.expires > now {
+				return Err(Error::MigrationInProgress {
+					node: lease.node.to_raw(),
+				});
+			}
+		}
+		let lease = Lease {
+			node: self.node,
+			expires: now + LEASE_DURATION,
+		};
+		tx.set(key, lease).await
+	}
+
+	/// Upgrade the data from a storage format to the next one
+	async fn step(&self, version: Version) -> Result<Version, Error> {
+		match version.0 {
+			// Version 1 added record id generation to table definitions.
+			// Definitions and records which were written by older releases
+			// are converted from their revision each time they are read, so
+			// only the version is recorded, and they can be rewritten with
+			// [`Migration::rewrite_all`] if required
+			0 => Ok(Version(1)),
+			v => Err(Error::Internal(format!("There is no migration from storage version {v}"))),
+		}
+	}
+
+	/// Rewrite all definitions and records at their latest revisions
+	pub(super) async fn rewrite_all(&self) -> Result<(), Error> {
+		// Fetch the namespaces, databases, and tables to rewrite
+		let mut tx = self.ds.transaction(Read, Optimistic).await?;
+		let mut namespaces = Vec::new();
+		let mut databases = Vec::new();
+		let mut tables = Vec::new();
+		for ns in tx.all_ns().await?.iter() {
+			namespaces.push(ns.name.to_raw());
+			for db in tx.all_db(&ns.name).await?.iter() {
+				for tb in tx.all_tb(&ns.name, &db.name).await?.iter() {
+					tables.push((ns.name.to_raw(), db.name.to_raw(), tb.name.to_raw()));
+				}
+				databases.push((ns.name.to_raw(), db.name.to_raw()));
+			}
+		}
+		tx.cancel().await?;
+		// Rewrite the root definitions
+		self.rewrite::<DefineUserStatement>(root::us::prefix(), root::us::suffix()).await?;
+		self.rewrite::<DefineNamespaceStatement>(root::ns::prefix(), root::ns::suffix()).await?;
+		// Rewrite the namespace and database definitions
+		for ns in namespaces.iter() {
+			self.rewrite_namespace(ns).await?;
+		}
+		for (ns, db) in databases.iter() {
+			self.rewrite_database(ns, db).await?;
+		}
+		// Rewrite the table definitions and records
+		for (ns, db, tb) in tables.iter() {
+			self.rewrite_table(ns, db, tb).await?;
+		}
+		Ok(())
+	}
+
+	/// Rewrite the users, accesses, and databases of a namespace
+	async fn rewrite_namespace(&self, ns: &str) -> Result<(), Error> {
+		self.rewrite::<DefineUserStatement>(namespace::us::prefix(ns), namespace::us::suffix(ns))
+			.await?;
+		self.rewrite::<DefineAccessStatement>(namespace::ac::prefix(ns), namespace::ac::suffix(ns))
+			.await?;
+		self.rewrite::<DefineDatabaseStatement>(
+			namespace::db::prefix(ns),
+			namespace::db::suffix(ns),
+		)
+		.await
+	}
+
+	/// Rewrite the users, accesses, analyzers, functions, params, models, and tables of a database
+	async fn rewrite_database(&self, ns: &str, db: &str) -> Result<(), Error> {
+		self.rewrite::<DefineUserStatement>(
+			database::us::prefix(ns, db),
+			database::us::suffix(ns, db),
+		)
+		.await?;
+		self.rewrite::<DefineAccessStatement>(
+			database::ac::prefix(ns, db),
+			database::ac::suffix(ns, db),
+		)
+		.await?;
+		self.rewrite::<DefineAnalyzerStatement>(
+			database::az::prefix(ns, db),
+			database::az::suffix(ns, db),
+		)
+		.await?;
+		self.rewrite::<DefineFunctionStatement>(
+			database::fc::prefix(ns, db),
+			database::fc::suffix(ns, db),
+		)
+		.await?;
+		self.rewrite::<DefineParamStatement>(
+			database::pa::prefix(ns, db),
+			database::pa::suffix(ns, db),
+		)
+		.await?;
+		self.rewrite::<DefineModelStatement>(
+			database::ml::prefix(ns, db),
+			database::ml::suffix(ns, db),
+		)
+		.await?;
+		self.rewrite::<DefineTableStatement>(
+			database::tb::prefix(ns, db),
+			database::tb::suffix(ns, db),
+		)
+		.await
+	}
+
+	/// Rewrite the fields, events, indexes, views, and records of a table
+	async fn rewrite_table(&self, ns: &str, db: &str, tb: &str) -> Result<(), Error> {
+		self.rewrite::<DefineFieldStatement>(
+			table::fd::prefix(ns, db, tb),
+			table::fd::suffix(ns, db, tb),
+		)
+		.await?;
+		self.rewrite::<DefineEventStatement>(
+			table::ev::prefix(ns, db, tb),
+			table::ev::suffix(ns, db, tb),
+		)
+		.await?;
+		self.rewrite::<DefineIndexStatement>(
+			table::ix::prefix(ns, db, tb),
+			table::ix::suffix(ns, db, tb),
+		)
+		.await?;
+		self.rewrite::<DefineTableStatement>(
+			table::ft::prefix(ns, db, tb),
+			table::ft::suffix(ns, db, tb),
+		)
+		.await?;
+		self.rewrite::<Value>(thing::prefix(ns, db, tb), thing::suffix(ns, db, tb)).await
+	}
+
+	/// Rewrite the values in a range of keys at their latest revision, in
+	/// batches. A batch which can not be committed, because a value in it
+	/// was changed by another transaction, is read and rewritten again.
+	async fn rewrite<T>(&self, beg: Key, end: Key) -> Result<(), Error>
+	where
+		T: for<'v> From<&'v Val>,
+		Val: From<T>,
+	{
+		let mut nxt: Option<ScanPage<Key>> = Some(ScanPage::from(beg..end));
+		while let Some(page) = nxt {
+			let mut attempt = 1;
+			nxt = loop {
+				match self.rewrite_batch::<T>(&page).await {
+					Ok(next) => break next,
+					Err(Error::Tx(e)) if attempt < REWRITE_ATTEMPTS => {
+						debug!("Retrying a conflicted migration batch: {e}");
+						attempt += 1;
+					}
+					Err(e) => return Err(e),
+				}
+			};
+		}
+		Ok(())
+	}
+
+	/// Rewrite a single batch of values, returning the next page to rewrite
+	async fn rewrite_batch<T>(&self, page: &ScanPage<Key>) -> Result<Option<ScanPage<Key>>, Error>
+	where
+		T: for<'v> From<&'v Val>,
+		Val: From<T>,
+	{
+		let mut tx = self.ds.transaction(Write, Optimistic).await?;
+		let res = async {
+			self.hold(&mut tx).await?;
+			let page = ScanPage {
+				range: page.range.clone(),
+				limit: page.limit,
+			};
+			let res = tx.scan_paged(page, *EXPORT_BATCH_SIZE).await?;
+			for (k, v) in res.values.into_iter() {
+				tx.set(k, Val::from(T::from(&v))).await?;
+			}
+			Ok::<_, Error>(res.next_page)
+		}
+		.await;
+		match res {
+			Ok(next) => {
+				tx.commit().await?;
+				Ok(next)
+			}
+			Err(e) => {
+				tx.cancel().await?;
+				Err(e)
+			}
+		}
+	}
+}
+
+impl From<Version> for Vec<u8> {
+	fn from(v: Version) -> Self {
+		v.0.to_be_bytes().to_vec()
+	}
+}
+
+impl TryFrom<Vec<u8>> for Version {
+	type Error = Error;
+	fn try_from(v: Vec<u8>) -> Result<Self, Self::Error> {
+		match v.try_into() {
+			Ok(v) => Ok(Version(u16::from_be_bytes(v))),
+			Err(_) => Err(Error::Internal("The storage version is invalid".to_string())),
+		}
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	use super::{Lease, Migration, Version};
+	use crate::err::Error;
+	use crate::key::root::mg::Mg;
+	use crate::kvs::{Datastore, LockType::*, TransactionType::*};
+	use chrono::Utc;
+
+	#[test]
+	fn encode_and_decode() {
+		let enc = Vec::<u8>::from(Version::LATEST);
+		assert_eq!(enc, vec![0, 1]);
+		assert_eq!(Version::try_from(enc).unwrap(), Version::LATEST);
+		assert!(Version::try_from(vec![1]).is_err());
+	}
+
+	#[tokio::test]
+	async fn lease_prevents_concurrent_migrations() {
+		let ds = Datastore::new("memory").await.unwrap();
+		let other = Migration::new(&ds, uuid::Uuid::new_v4().into());
+		let this = Migration::new(&ds, uuid::Uuid::new_v4().into());
+		// Another node acquires the lease
+		let mut tx = ds.transaction(Write, Optimistic).await.unwrap();
+		other.hold(&mut tx).await.unwrap();
+		tx.commit().await.unwrap();
+		// This node can not migrate the datastore while the lease is held
+		let mut tx = ds.transaction(Write, Optimistic).await.unwrap();
+		let res = this.hold(&mut tx).await;
+		assert!(matches!(res, Err(Error::MigrationInProgress { .. })));
+		tx.cancel().await.unwrap();
+		// Releasing the lease of another node has no effect
+		this.release().await.unwrap();
+		let mut tx = ds.transaction(Write, Optimistic).await.unwrap();
+		assert!(this.hold(&mut tx).await.is_err());
+		tx.cancel().await.unwrap();
+		// An expired lease can be taken over by another node
+		let mut tx = ds.transaction(Write, Optimistic).await.unwrap();
+		let lease = Lease {
+			node: other.node,
+			expires: Utc::now().timestamp() - 1,
+		};
+		tx.set(Mg::new(), lease).await.unwrap();
+		tx.commit().await.unwrap();
+		let mut tx = ds.transaction(Write, Optimistic).await.unwrap();
+		this.hold(&mut tx).await.unwrap();
+		tx.commit().await.unwrap();
+		// The lease is removed once it is released
+		this.release().await.unwrap();
+		let mut tx = ds.transaction(Read, Optimistic).await.unwrap();
+		assert!(tx.get(Mg::new()).await.unwrap().is_none());
+		tx.cancel().await.unwrap();
+	}
+}
@@ -0,0 +1,75 @@
+use crate::sql::statements::info::InfoStructure;
+use crate::sql::{fmt::Fmt, Strand, Value};
+use ipnet::IpNet;
+use revision::revisioned;
+use serde::{Deserialize, Serialize};
+use std::fmt::{self, Display, Formatter};
+use std::net::IpAddr;
+
+/// The network addresses and ranges from which authentication is allowed.
+/// An empty list places no restriction on the client address.
+#[revisioned(revision = 1)]
+#[derive(Clone, Debug, Default, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Hash)]
+#[cfg_attr(feature = "arbitrary", derive(arbitrary::Arbitrary))]
+#[non_exhaustive]
+pub struct AllowIp(pub Vec<Strand>);
+
+impl AllowIp {
+	/// Parses a single IP address or CIDR range
+	pub(crate) fn parse(v: &str) -> Option<IpNet> {
+		match v.parse::<IpNet>() {
+			Ok(v) => Some(v),
+			Err(_) => v.parse::<IpAddr>().ok().map(IpNet::from),
+		}
+	}
+
+	/// Checks whether this list places any restriction on the client address
+	pub fn is_empty(&self) -> bool {
+		self.0.is_empty()
+	}
+
+	/// Checks whether a client address is allowed by this list
+	pub(crate) fn allows(&self, ip: &str) -> bool {
+		if self.is_empty() {
+			return true;
+		}
+		// The left-most entries of a forwarded address list are set by the
+		// client, so only the entry added by the closest proxy is trusted
+		let ip = ip.rsplit(',').next().unwrap_or_default().trim();
+		let Ok(ip) = ip.parse::<IpAddr>() else {
+			return false;
+		};
+		self.0.iter().filter_map(|v| Self::parse(v)).any(|v| v.contains(&ip))
+	}
+}
+
+impl Display for AllowIp {
+	fn fmt(&self, f: &mut Formatter) -> fmt::Result {
+		write!(f, "ALLOW IP {}", Fmt::comma_separated(&self.0))
+	}
+}
+
+impl InfoStructure for AllowIp {
+	fn structure(self) -> Value {
+		Value::Array(self.0.into_iter().map(Value::from).collect())
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+
+	#[test]
+	fn allows_matching_addresses() {
+		let allow = AllowIp(vec!["10.0.0.0/8".into(), "192.168.1.1".into(), "::1".into()]);
+		assert!(allow.allows("10.1.2.3"));
+		assert!(allow.allows("192.168.1.1"));
+		assert!(allow.allows("::1"));
+		assert!(!allow.allows("192.168.1.2"));
+		assert!(!allow.allows("invalid"));
+		// Only the right-most forwarded address is used
+		assert!(allow.allows("192.168.1.2, 10.1.2.3"));
+		assert!(!allow.allows("10.1.2.3, 192.168.1.2"));
+		assert!(AllowIp::default().allows("192.168.1.2"));
+	}
+}
@@ -111,6 +111,22 @@ impl Function {
 		}
 	}
 
+	/// Check if this function always returns the same result for the
+	/// same arguments. Custom and scripting functions can read any
+	/// parameter, so they are never considered to be deterministic.
+	pub(crate) fn is_deterministic(&self) -> bool {
+		match self {
+			Self::Normal(f, _) if f == "rand" || f.starts_with("rand::") => false,
+			Self::Normal(f, _) if f.starts_with("http::") => false,
+			Self::Normal(f, _) if f.starts_with("crypto::") && f.ends_with("::generate") => false,
+			Self::Normal(f, _) if f == "array::shuffle" => false,
+			Self::Normal(f, _) if f == "time::now" => false,
+			Self::Normal(f, _) if f == "sleep" => false,
+			Self::Normal(_, _) => true,
+			_ => false,
+		}
+	}
+
 	/// Check if this function is a rolling function
 	pub fn is_rolling(&self) -> bool {
 		match self {
@@ -193,6 +209,10 @@ impl Function {
 			Self::Normal(s, x) => {
 				// Check this function is allowed
 				ctx.check_allowed_function(s)?;
+				// Some functions evaluate an argument per array element
+				if fnc::array::is_iterator(s) {
+					return fnc::array::iterate(stk, ctx, opt, doc, s, x).await;
+				}
 				// Compute the function arguments
 				let a = stk
 					.scope(|scope| {
@@ -5,11 +5,13 @@ use crate::doc::CursorDoc;
 use crate::err::Error;
 use crate::sql::{escape::escape_rid, Array, Number, Object, Strand, Thing, Uuid, Value};
 use nanoid::nanoid;
+use rand::{Rng, RngCore};
 use reblessive::tree::Stk;
 use revision::revisioned;
 use serde::{Deserialize, Serialize};
 use std::collections::BTreeMap;
 use std::fmt::{self, Display, Formatter};
+use std::sync::Mutex;
 use ulid::Ulid;
 
 #[revisioned(revision = 1)]
@@ -22,6 +24,67 @@ pub enum Gen {
 	Uuid,
 }
 
+/// The last ULID record id which was generated by this process
+static LAST_ULID: Mutex<u128> = Mutex::new(0);
+
+/// The last UUID record id which was generated by this process
+static LAST_UUID: Mutex<u128> = Mutex::new(0);
+
+impl Gen {
+	/// Generate a new id, using the random number generator of the context
+	pub(crate) fn generate(&self, ctx: &Context<'_>) -> Id {
+		match self {
+			Gen::Rand => ctx.with_rng(Id::rand_from),
+			Gen::Ulid => {
+				let millis = ctx.id_millis();
+				let id = ctx.with_rng(|rng| Ulid::from_parts(millis, rng.gen()));
+				// The ids of a seeded generator are ordered by its logical clock
+				let id = match ctx.is_seeded() {
+					true => id,
+					false => Ulid(monotonic(&LAST_ULID, id.0, |v| v + 1)),
+				};
+				Id::String(id.to_string())
+			}
+			Gen::Uuid => {
+				let millis = ctx.id_millis();
+				let id = ctx.with_rng(|rng| Uuid::new_v7_from(millis, rng)).0.as_u128();
+				// The ids of a seeded generator are ordered by its logical clock
+				let id = match ctx.is_seeded() {
+					true => id,
+					false => monotonic(&LAST_UUID, id, next_uuid_v7),
+				};
+				Id::String(uuid::Uuid::from_u128(id).to_string())
+			}
+		}
+	}
+}
+
+/// Returns the generated id, unless it is not greater than the last id which was
+/// generated, such as within the same millisecond, in which case the id after the
+/// last id is returned instead. This keeps time-based ids in the order they were
+/// generated.
+fn monotonic(last: &Mutex<u128>, id: u128, next: fn(u128) -> u128) -> u128 {
+	let mut last = last.lock().unwrap_or_else(|e| e.into_inner());
+	*last = match id > *last {
+		true => id,
+		false => next(*last),
+	};
+	*last
+}
+
+/// Returns the UUIDv7 after the specified UUIDv7, by incrementing its random bits
+/// and keeping its version and variant bits, or moving to the next millisecond
+fn next_uuid_v7(id: u128) -> u128 {
+	const RAND_B: u128 = (1 << 62) - 1;
+	let millis = id >> 80;
+	let rand = (((id >> 64) & 0xfff) << 62 | (id & RAND_B)) + 1;
+	let (millis, rand) = match rand >> 74 {
+		0 => (millis, rand),
+		_ => (millis + 1, 0),
+	};
+	millis << 80 | 0x7 << 76 | (rand >> 62) << 64 | 0x2 << 62 | (rand & RAND_B)
+}
+
 #[revisioned(revision = 1)]
 #[derive(Clone, Debug, Eq, PartialEq, Ord, PartialOrd, Serialize, Deserialize, Hash)]
 #[cfg_attr(feature = "arbitrary", derive(arbitrary::Arbitrary))]
@@ -147,6 +210,14 @@ impl Id {
 	pub fn uuid() -> Self {
 		Self::String(Uuid::new_v7().to_raw())
 	}
+	/// Generate a new random ID using the given random number generator
+	pub(crate) fn rand_from(rng: &mut dyn RngCore) -> Self {
+		Self::String((0..20).map(|_| ID_CHARS[rng.gen_range(0..ID_CHARS.len())]).collect())
+	}
+	/// Generate a new random ULID with the given timestamp and random number generator
+	pub(crate) fn ulid_from(millis: u64, rng: &mut dyn RngCore) -> Self {
+		Self::String(Ulid::from_parts(millis, rng.gen()).to_string())
+	}
 	/// Convert the Id to a raw String
 	pub fn to_raw(&self) -> String {
 		match self {
@@ -199,11 +270,27 @@ impl Id {
 				Value::Object(v) => Ok(Id::Object(v)),
 				_ => unreachable!(),
 			},
-			Id::Generate(v) => match v {
-				Gen::Rand => Ok(Self::rand()),
-				Gen::Ulid => Ok(Self::ulid()),
-				Gen::Uuid => Ok(Self::uuid()),
-			},
+			Id::Generate(v) => Ok(v.generate(ctx)),
 		}
 	}
 }
+
+#[cfg(test)]
+mod tests {
+	use super::next_uuid_v7;
+
+	#[test]
+	fn next_uuid_v7_keeps_version_and_variant() {
+		let id = uuid::Uuid::parse_str("018f7a2b-3c4d-7e5f-8a6b-7c8d9e0f1a2b").unwrap();
+		let next = uuid::Uuid::from_u128(next_uuid_v7(id.as_u128()));
+		assert_eq!(next.to_string(), "018f7a2b-3c4d-7e5f-8a6b-7c8d9e0f1a2c");
+		// The random bits carry over the variant bits
+		let id = uuid::Uuid::parse_str("018f7a2b-3c4d-7e5f-bfff-ffffffffffff").unwrap();
+		let next = uuid::Uuid::from_u128(next_uuid_v7(id.as_u128()));
+		assert_eq!(next.to_string(), "018f7a2b-3c4d-7e60-8000-000000000000");
+		// The timestamp is incremented once the random bits are exhausted
+		let id = uuid::Uuid::parse_str("018f7a2b-3c4d-7fff-bfff-ffffffffffff").unwrap();
+		let next = uuid::Uuid::from_u128(next_uuid_v7(id.as_u128()));
+		assert_eq!(next.to_string(), "018f7a2b-3c4e-7000-8000-000000000000");
+	}
+}
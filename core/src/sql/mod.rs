@@ -3,6 +3,7 @@
 pub(crate) mod access;
 pub(crate) mod access_type;
 pub(crate) mod algorithm;
+pub(crate) mod allow;
 #[cfg(feature = "arbitrary")]
 pub(crate) mod arbitrary;
 pub(crate) mod array;
@@ -69,6 +70,7 @@ pub(crate) mod uuid;
 pub(crate) mod value;
 pub(crate) mod version;
 pub(crate) mod view;
+pub(crate) mod visit;
 pub(crate) mod with;
 
 #[doc(hidden)]
@@ -81,6 +83,7 @@ pub use self::access::Access;
 pub use self::access::Accesses;
 pub use self::access_type::{AccessType, JwtAccess, RecordAccess};
 pub use self::algorithm::Algorithm;
+pub use self::allow::AllowIp;
 pub use self::array::Array;
 pub use self::base::Base;
 pub use self::block::Block;
@@ -152,6 +155,7 @@ pub use self::value::Value;
 pub use self::value::Values;
 pub use self::version::Version;
 pub use self::view::View;
+pub use self::visit::{Visitor, VisitorMut, Walk};
 pub use self::with::With;
 
 // module reexporting parsing function to prevent a breaking change.
@@ -160,4 +164,4 @@ mod parser {
 	pub use crate::syn::*;
 }
 
-pub use self::parser::{idiom, json, parse, subquery, thing, value};
+pub use self::parser::{format, idiom, json, parse, statement, subquery, thing, value};
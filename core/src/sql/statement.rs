@@ -9,9 +9,9 @@ use crate::sql::{
 		AnalyzeStatement, BeginStatement, BreakStatement, CancelStatement, CommitStatement,
 		ContinueStatement, CreateStatement, DefineStatement, DeleteStatement, ForeachStatement,
 		IfelseStatement, InfoStatement, InsertStatement, KillStatement, LiveStatement,
-		OptionStatement, OutputStatement, RelateStatement, RemoveStatement, SelectStatement,
-		SetStatement, ShowStatement, SleepStatement, ThrowStatement, UpdateStatement,
-		UpsertStatement, UseStatement,
+		OptionStatement, OutputStatement, RelateStatement, RemoveStatement, RenameStatement,
+		RevokeStatement, SelectStatement, SetStatement, ShowStatement, SleepStatement,
+		ThrowStatement, UpdateStatement, UpsertStatement, UseStatement,
 	},
 	value::Value,
 };
@@ -55,7 +55,7 @@ impl Display for Statements {
 	}
 }
 
-#[revisioned(revision = 3)]
+#[revisioned(revision = 5)]
 #[derive(Clone, Debug, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Store, Hash)]
 #[cfg_attr(feature = "arbitrary", derive(arbitrary::Arbitrary))]
 #[non_exhaustive]
@@ -91,6 +91,10 @@ pub enum Statement {
 	Rebuild(RebuildStatement),
 	#[revision(start = 3)]
 	Upsert(UpsertStatement),
+	#[revision(start = 4)]
+	Revoke(RevokeStatement),
+	#[revision(start = 5)]
+	Rename(RenameStatement),
 }
 
 impl Statement {
@@ -107,6 +111,42 @@ impl Statement {
 			_ => None,
 		}
 	}
+	/// Get the kind of statement, for use in logs and metrics
+	pub(crate) fn kind(&self) -> &'static str {
+		match self {
+			Self::Value(_) => "VALUE",
+			Self::Analyze(_) => "ANALYZE",
+			Self::Begin(_) => "BEGIN",
+			Self::Break(_) => "BREAK",
+			Self::Continue(_) => "CONTINUE",
+			Self::Cancel(_) => "CANCEL",
+			Self::Commit(_) => "COMMIT",
+			Self::Create(_) => "CREATE",
+			Self::Define(_) => "DEFINE",
+			Self::Delete(_) => "DELETE",
+			Self::Foreach(_) => "FOR",
+			Self::Ifelse(_) => "IF",
+			Self::Info(_) => "INFO",
+			Self::Insert(_) => "INSERT",
+			Self::Kill(_) => "KILL",
+			Self::Live(_) => "LIVE",
+			Self::Option(_) => "OPTION",
+			Self::Output(_) => "RETURN",
+			Self::Rebuild(_) => "REBUILD",
+			Self::Relate(_) => "RELATE",
+			Self::Remove(_) => "REMOVE",
+			Self::Revoke(_) => "REVOKE",
+			Self::Rename(_) => "RENAME",
+			Self::Select(_) => "SELECT",
+			Self::Set(_) => "LET",
+			Self::Show(_) => "SHOW",
+			Self::Sleep(_) => "SLEEP",
+			Self::Throw(_) => "THROW",
+			Self::Update(_) => "UPDATE",
+			Self::Upsert(_) => "UPSERT",
+			Self::Use(_) => "USE",
+		}
+	}
 	/// Check if we require a writeable transaction
 	pub(crate) fn writeable(&self) -> bool {
 		match self {
@@ -128,6 +168,8 @@ impl Statement {
 			Self::Rebuild(_) => true,
 			Self::Relate(v) => v.writeable(),
 			Self::Remove(_) => true,
+			Self::Rename(_) => true,
+			Self::Revoke(_) => true,
 			Self::Select(v) => v.writeable(),
 			Self::Set(v) => v.writeable(),
 			Self::Show(_) => false,
@@ -164,6 +206,10 @@ impl Statement {
 			Self::Relate(v) => v.compute(stk, ctx, opt, doc).await,
 			Self::Rebuild(v) => v.compute(stk, ctx, opt, doc).await,
 			Self::Remove(v) => v.compute(ctx, opt, doc).await,
+			Self::Rename(_) => {
+				Err(Error::Unreachable("Statement::Rename is processed by the executor"))
+			}
+			Self::Revoke(v) => v.compute(stk, ctx, opt, doc).await,
 			Self::Select(v) => v.compute(stk, ctx, opt, doc).await,
 			Self::Set(v) => v.compute(stk, ctx, opt, doc).await,
 			Self::Show(v) => v.compute(ctx, opt, doc).await,
@@ -206,6 +252,8 @@ impl Display for Statement {
 			Self::Rebuild(v) => write!(Pretty::from(f), "{v}"),
 			Self::Relate(v) => write!(Pretty::from(f), "{v}"),
 			Self::Remove(v) => write!(Pretty::from(f), "{v}"),
+			Self::Rename(v) => write!(Pretty::from(f), "{v}"),
+			Self::Revoke(v) => write!(Pretty::from(f), "{v}"),
 			Self::Select(v) => write!(Pretty::from(f), "{v}"),
 			Self::Set(v) => write!(Pretty::from(f), "{v}"),
 			Self::Show(v) => write!(Pretty::from(f), "{v}"),
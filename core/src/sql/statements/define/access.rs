@@ -4,7 +4,7 @@ use crate::doc::CursorDoc;
 use crate::err::Error;
 use crate::iam::{Action, ResourceKind};
 use crate::sql::statements::info::InfoStructure;
-use crate::sql::{access::AccessDuration, AccessType, Base, Ident, Object, Strand, Value};
+use crate::sql::{access::AccessDuration, AccessType, AllowIp, Base, Ident, Object, Strand, Value};
 use derive::Store;
 use rand::distributions::Alphanumeric;
 use rand::Rng;
@@ -12,7 +12,7 @@ use revision::revisioned;
 use serde::{Deserialize, Serialize};
 use std::fmt::{self, Display};
 
-#[revisioned(revision = 1)]
+#[revisioned(revision = 3)]
 #[derive(Clone, Default, Debug, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Store, Hash)]
 #[cfg_attr(feature = "arbitrary", derive(arbitrary::Arbitrary))]
 #[non_exhaustive]
@@ -23,6 +23,10 @@ pub struct DefineAccessStatement {
 	pub duration: AccessDuration,
 	pub comment: Option<Strand>,
 	pub if_not_exists: bool,
+	#[revision(start = 2)]
+	pub allow: AllowIp,
+	#[revision(start = 3)]
+	pub authenticate: Option<Value>,
 }
 
 impl DefineAccessStatement {
@@ -47,6 +51,13 @@ impl DefineAccessStatement {
 		};
 		das
 	}
+
+	/// Returns whether the grant duration is relevant for this access method
+	/// Record access methods only issue refresh grants when a duration is set
+	fn shows_grant(&self) -> bool {
+		self.kind.can_issue_grants()
+			|| matches!(self.kind, AccessType::Record(_)) && self.duration.grant.is_some()
+	}
 }
 
 impl DefineAccessStatement {
@@ -133,11 +144,14 @@ impl Display for DefineAccessStatement {
 		}
 		// The specific access method definition is displayed by AccessType
 		write!(f, " {} ON {} TYPE {}", self.name, self.base, self.kind)?;
+		if let Some(ref v) = self.authenticate {
+			write!(f, " AUTHENTICATE {v}")?
+		}
 		// Always print relevant durations so defaults can be changed in the future
 		// If default values were not printed, exports would not be forward compatible
 		// None values need to be printed, as they are different from the default values
 		write!(f, " DURATION")?;
-		if self.kind.can_issue_grants() {
+		if self.shows_grant() {
 			write!(
 				f,
 				" FOR GRANT {},",
@@ -165,6 +179,9 @@ impl Display for DefineAccessStatement {
 				None => "NONE".to_string(),
 			}
 		)?;
+		if !self.allow.is_empty() {
+			write!(f, " {}", self.allow)?
+		}
 		if let Some(ref v) = self.comment {
 			write!(f, " COMMENT {v}")?
 		}
@@ -174,12 +191,15 @@ impl Display for DefineAccessStatement {
 
 impl InfoStructure for DefineAccessStatement {
 	fn structure(self) -> Value {
+		let grant = self.shows_grant();
 		let Self {
 			name,
 			base,
 			kind,
 			duration,
 			comment,
+			allow,
+			authenticate,
 			..
 		} = self;
 		let mut acc = Object::default();
@@ -189,7 +209,7 @@ impl InfoStructure for DefineAccessStatement {
 		acc.insert("base".to_string(), base.structure());
 
 		let mut dur = Object::default();
-		if kind.can_issue_grants() {
+		if grant {
 			dur.insert("grant".to_string(), duration.grant.into());
 		}
 		if kind.can_issue_tokens() {
@@ -200,6 +220,14 @@ impl InfoStructure for DefineAccessStatement {
 
 		acc.insert("kind".to_string(), kind.structure());
 
+		if let Some(authenticate) = authenticate {
+			acc.insert("authenticate".to_string(), authenticate.structure());
+		}
+
+		if !allow.is_empty() {
+			acc.insert("allow".to_string(), allow.structure());
+		}
+
 		if let Some(comment) = comment {
 			acc.insert("comment".to_string(), comment.into());
 		}
@@ -4,13 +4,13 @@ use crate::doc::CursorDoc;
 use crate::err::Error;
 use crate::iam::{Action, ResourceKind};
 use crate::sql::statements::info::InfoStructure;
-use crate::sql::{Base, Ident, Object, Strand, Value};
+use crate::sql::{AllowIp, Base, Ident, Object, Strand, Value};
 use derive::Store;
 use revision::revisioned;
 use serde::{Deserialize, Serialize};
 use std::fmt::{self, Display};
 
-#[revisioned(revision = 2)]
+#[revisioned(revision = 3)]
 #[derive(Clone, Debug, Default, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Store, Hash)]
 #[cfg_attr(feature = "arbitrary", derive(arbitrary::Arbitrary))]
 #[non_exhaustive]
@@ -20,6 +20,8 @@ pub struct DefineNamespaceStatement {
 	pub comment: Option<Strand>,
 	#[revision(start = 2)]
 	pub if_not_exists: bool,
+	#[revision(start = 3)]
+	pub allow: AllowIp,
 }
 
 impl DefineNamespaceStatement {
@@ -78,6 +80,9 @@ impl Display for DefineNamespaceStatement {
 			write!(f, " IF NOT EXISTS")?
 		}
 		write!(f, " {}", self.name)?;
+		if !self.allow.is_empty() {
+			write!(f, " {}", self.allow)?
+		}
 		if let Some(ref v) = self.comment {
 			write!(f, " COMMENT {v}")?
 		}
@@ -90,12 +95,17 @@ impl InfoStructure for DefineNamespaceStatement {
 		let Self {
 			name,
 			comment,
+			allow,
 			..
 		} = self;
 		let mut acc = Object::default();
 
 		acc.insert("name".to_string(), name.structure());
 
+		if !allow.is_empty() {
+			acc.insert("allow".to_string(), allow.structure());
+		}
+
 		if let Some(comment) = comment {
 			acc.insert("comment".to_string(), comment.into());
 		}
@@ -11,6 +11,7 @@ use crate::sql::{
 };
 use std::sync::Arc;
 
+use crate::sql::id::Gen;
 use crate::sql::statements::info::InfoStructure;
 use crate::sql::{Idiom, Kind, Part, Table, TableType};
 use derive::Store;
@@ -21,7 +22,7 @@ use std::fmt::{self, Display, Write};
 
 use super::DefineFieldStatement;
 
-#[revisioned(revision = 3)]
+#[revisioned(revision = 4)]
 #[derive(Clone, Debug, Default, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Store, Hash)]
 #[cfg_attr(feature = "arbitrary", derive(arbitrary::Arbitrary))]
 #[non_exhaustive]
@@ -38,6 +39,8 @@ pub struct DefineTableStatement {
 	pub if_not_exists: bool,
 	#[revision(start = 3)]
 	pub kind: TableType,
+	#[revision(start = 4)]
+	pub generate: Option<Gen>,
 }
 
 impl DefineTableStatement {
@@ -64,6 +67,8 @@ impl DefineTableStatement {
 				});
 			}
 		}
+		// Change the version of the table definition
+		run.change_tb(opt.ns()?, opt.db()?, &self.name).await?;
 		// Process the statement
 		let key = crate::key::database::tb::new(opt.ns()?, opt.db()?, &self.name);
 		let ns = run.add_ns(opt.ns()?, opt.strict).await?;
@@ -193,6 +198,13 @@ impl Display for DefineTableStatement {
 		if self.drop {
 			f.write_str(" DROP")?;
 		}
+		if let Some(ref v) = self.generate {
+			f.write_str(match v {
+				Gen::Rand => " ID RAND",
+				Gen::Ulid => " ID ULID",
+				Gen::Uuid => " ID UUID",
+			})?;
+		}
 		f.write_str(if self.full {
 			" SCHEMAFULL"
 		} else {
@@ -229,6 +241,7 @@ impl InfoStructure for DefineTableStatement {
 			changefeed,
 			comment,
 			kind,
+			generate,
 			..
 		} = self;
 		let mut acc = Object::default();
@@ -254,6 +267,15 @@ impl InfoStructure for DefineTableStatement {
 
 		acc.insert("kind".to_string(), kind.structure());
 
+		if let Some(generate) = generate {
+			let generate = match generate {
+				Gen::Rand => "rand",
+				Gen::Ulid => "ulid",
+				Gen::Uuid => "uuid",
+			};
+			acc.insert("generate".to_string(), generate.into());
+		}
+
 		Value::Object(acc)
 	}
 }
@@ -2,22 +2,19 @@ use crate::ctx::Context;
 use crate::dbs::Options;
 use crate::doc::CursorDoc;
 use crate::err::Error;
-use crate::iam::{Action, ResourceKind};
+use crate::iam::{password, Action, ResourceKind};
 use crate::sql::statements::info::InfoStructure;
 use crate::sql::{
-	escape::quote_str, fmt::Fmt, user::UserDuration, Base, Duration, Ident, Object, Strand, Value,
-};
-use argon2::{
-	password_hash::{PasswordHasher, SaltString},
-	Argon2,
+	escape::quote_str, fmt::Fmt, user::UserDuration, AllowIp, Base, Duration, Ident, Object,
+	Strand, Value,
 };
 use derive::Store;
-use rand::{distributions::Alphanumeric, rngs::OsRng, Rng};
+use rand::{distributions::Alphanumeric, Rng};
 use revision::revisioned;
 use serde::{Deserialize, Serialize};
 use std::fmt::{self, Display};
 
-#[revisioned(revision = 3)]
+#[revisioned(revision = 4)]
 #[derive(Clone, Debug, Default, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Store, Hash)]
 #[cfg_attr(feature = "arbitrary", derive(arbitrary::Arbitrary))]
 #[non_exhaustive]
@@ -32,6 +29,8 @@ pub struct DefineUserStatement {
 	pub comment: Option<Strand>,
 	#[revision(start = 2)]
 	pub if_not_exists: bool,
+	#[revision(start = 4)]
+	pub allow: AllowIp,
 }
 
 impl From<(Base, &str, &str, &str)> for DefineUserStatement {
@@ -39,10 +38,7 @@ impl From<(Base, &str, &str, &str)> for DefineUserStatement {
 		DefineUserStatement {
 			base,
 			name: user.into(),
-			hash: Argon2::default()
-				.hash_password(pass.as_ref(), &SaltString::generate(&mut OsRng))
-				.unwrap()
-				.to_string(),
+			hash: password::hash(pass),
 			code: rand::thread_rng()
 				.sample_iter(&Alphanumeric)
 				.take(128)
@@ -52,6 +48,7 @@ impl From<(Base, &str, &str, &str)> for DefineUserStatement {
 			duration: UserDuration::default(),
 			comment: None,
 			if_not_exists: false,
+			allow: AllowIp::default(),
 		}
 	}
 }
@@ -78,10 +75,7 @@ impl DefineUserStatement {
 	}
 
 	pub(crate) fn set_password(&mut self, password: &str) {
-		self.hash = Argon2::default()
-			.hash_password(password.as_bytes(), &SaltString::generate(&mut OsRng))
-			.unwrap()
-			.to_string()
+		self.hash = password::hash(password)
 	}
 
 	pub(crate) fn set_passhash(&mut self, passhash: String) {
@@ -242,6 +236,9 @@ impl Display for DefineUserStatement {
 				None => "NONE".to_string(),
 			}
 		)?;
+		if !self.allow.is_empty() {
+			write!(f, " {}", self.allow)?
+		}
 		if let Some(ref v) = self.comment {
 			write!(f, " COMMENT {v}")?
 		}
@@ -258,6 +255,7 @@ impl InfoStructure for DefineUserStatement {
 			roles,
 			duration,
 			comment,
+			allow,
 			..
 		} = self;
 		let mut acc = Object::default();
@@ -278,6 +276,10 @@ impl InfoStructure for DefineUserStatement {
 		dur.insert("session".to_string(), duration.session.into());
 		acc.insert("duration".to_string(), dur.to_string().into());
 
+		if !allow.is_empty() {
+			acc.insert("allow".to_string(), allow.structure());
+		}
+
 		if let Some(comment) = comment {
 			acc.insert("comment".to_string(), comment.into());
 		}
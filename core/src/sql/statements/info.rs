@@ -4,13 +4,14 @@ use crate::doc::CursorDoc;
 use crate::err::Error;
 use crate::iam::Action;
 use crate::iam::ResourceKind;
-use crate::sql::{Base, Ident, Object, Value};
+use crate::kvs::Usage;
+use crate::sql::{Base, Duration, Ident, Object, Value};
 use derive::Store;
 use revision::revisioned;
 use serde::{Deserialize, Serialize};
 use std::fmt;
 
-#[revisioned(revision = 2)]
+#[revisioned(revision = 3)]
 #[derive(Clone, Debug, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Store, Hash)]
 #[cfg_attr(feature = "arbitrary", derive(arbitrary::Arbitrary))]
 #[non_exhaustive]
@@ -35,6 +36,8 @@ pub enum InfoStatement {
 	User(Ident, Option<Base>),
 	#[revision(start = 2)]
 	User(Ident, Option<Base>, bool),
+	#[revision(start = 3)]
+	Sessions,
 }
 
 impl InfoStatement {
@@ -119,6 +122,10 @@ impl InfoStatement {
 					tmp.insert(v.name.to_string(), v.to_string().into());
 				}
 				res.insert("accesses".to_owned(), tmp.into());
+				// Process the usage
+				if let Some(usage) = ctx.get_usage() {
+					res.insert("usage".to_owned(), process_usage(usage.get(opt.ns()?)));
+				}
 				// Ok all good
 				Value::from(res).ok()
 			}
@@ -261,6 +268,10 @@ impl InfoStatement {
 					"accesses".to_owned(),
 					process_arr(run.all_ns_accesses_redacted(opt.ns()?).await?),
 				);
+				// Process the usage
+				if let Some(usage) = ctx.get_usage() {
+					res.insert("usage".to_owned(), process_usage(usage.get(opt.ns()?)));
+				}
 				// Ok all good
 				Value::from(res).ok()
 			}
@@ -366,6 +377,19 @@ impl InfoStatement {
 				// Ok all good
 				Ok(res.structure())
 			}
+			InfoStatement::Sessions => {
+				// Allowed to run?
+				opt.is_allowed(Action::View, ResourceKind::Any, &Base::Root)?;
+				// Process the connected sessions
+				let res: Vec<Value> = match ctx.get_sessions() {
+					Some(sessions) => {
+						sessions.all().into_iter().map(InfoStructure::structure).collect()
+					}
+					None => Vec::new(),
+				};
+				// Ok all good
+				Value::from(res).ok()
+			}
 		}
 	}
 }
@@ -389,6 +413,7 @@ impl fmt::Display for InfoStatement {
 				Some(ref b) => write!(f, "INFO FOR USER {u} ON {b} STRUCTURE"),
 				None => write!(f, "INFO FOR USER {u} STRUCTURE"),
 			},
+			Self::Sessions => f.write_str("INFO FOR SESSIONS"),
 		}
 	}
 }
@@ -407,6 +432,7 @@ impl InfoStatement {
 			InfoStatement::Db(_) => InfoStatement::Db(true),
 			InfoStatement::Tb(t, _) => InfoStatement::Tb(t, true),
 			InfoStatement::User(u, b, _) => InfoStatement::User(u, b, true),
+			InfoStatement::Sessions => InfoStatement::Sessions,
 		}
 	}
 }
@@ -417,3 +443,12 @@ where
 {
 	Value::Array(a.iter().cloned().map(InfoStructure::structure).collect())
 }
+
+fn process_usage(usage: Usage) -> Value {
+	Value::from(map! {
+		"rows_read".to_string() => Value::from(usage.rows_read),
+		"rows_written".to_string() => Value::from(usage.rows_written),
+		"bytes_written".to_string() => Value::from(usage.bytes_written),
+		"query_time".to_string() => Value::from(Duration::from(usage.query_time)),
+	})
+}
@@ -71,7 +71,7 @@ impl InsertStatement {
 						o.set(stk, ctx, opt, k, v).await?;
 					}
 					// Specify the new table record id
-					let id = gen_id(&o, &into)?;
+					let id = gen_id(ctx, opt, &o, &into).await?;
 					// Pass the value to the iterator
 					i.ingest(iterable(id, o, self.relation)?)
 				}
@@ -83,14 +83,14 @@ impl InsertStatement {
 					Value::Array(v) => {
 						for v in v {
 							// Specify the new table record id
-							let id = gen_id(&v, &into)?;
+							let id = gen_id(ctx, opt, &v, &into).await?;
 							// Pass the value to the iterator
 							i.ingest(iterable(id, v, self.relation)?)
 						}
 					}
 					Value::Object(_) => {
 						// Specify the new table record id
-						let id = gen_id(&v, &into)?;
+						let id = gen_id(ctx, opt, &v, &into).await?;
 						// Pass the value to the iterator
 						i.ingest(iterable(id, v, self.relation)?)
 					}
@@ -164,9 +164,19 @@ fn iterable(id: Thing, v: Value, relation: bool) -> Result<Iterable, Error> {
 	}
 }
 
-fn gen_id(v: &Value, into: &Option<Table>) -> Result<Thing, Error> {
+async fn gen_id(
+	ctx: &Context<'_>,
+	opt: &Options,
+	v: &Value,
+	into: &Option<Table>,
+) -> Result<Thing, Error> {
 	match into {
-		Some(into) => v.rid().generate(into, true),
+		Some(into) => match v.rid() {
+			// There is no record id field
+			Value::None => into.generate_defined(ctx, opt).await,
+			// Use the specified record id
+			id => id.generate(into, true),
+		},
 		None => match v.rid() {
 			Value::Thing(v) => match v {
 				Thing {
@@ -18,6 +18,8 @@ pub(crate) mod output;
 pub(crate) mod rebuild;
 pub(crate) mod relate;
 pub(crate) mod remove;
+pub(crate) mod rename;
+pub(crate) mod revoke;
 pub(crate) mod select;
 pub(crate) mod set;
 pub(crate) mod show;
@@ -45,6 +47,8 @@ pub use self::r#break::BreakStatement;
 pub use self::r#continue::ContinueStatement;
 pub use self::r#use::UseStatement;
 pub use self::relate::RelateStatement;
+pub use self::rename::{RenameFieldStatement, RenameStatement, RenameTableStatement};
+pub use self::revoke::RevokeStatement;
 pub use self::select::SelectStatement;
 pub use self::set::SetStatement;
 pub use self::show::ShowStatement;
@@ -142,12 +142,15 @@ impl RelateStatement {
 						Some(data) => {
 							let id = match data.rid(stk, ctx, opt).await? {
 								Some(id) => id.generate(tb, false)?,
-								None => tb.generate(),
+								None => tb.generate_defined(ctx, opt).await?,
 							};
 							i.ingest(Iterable::Relatable(f, id, w, None))
 						}
 						// There is no data clause so create a record id
-						None => i.ingest(Iterable::Relatable(f, tb.generate(), w, None)),
+						None => {
+							let id = tb.generate_defined(ctx, opt).await?;
+							i.ingest(Iterable::Relatable(f, id, w, None))
+						}
 					},
 					// The relation can not be any other type
 					v => {
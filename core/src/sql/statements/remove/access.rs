@@ -50,6 +50,9 @@ impl RemoveAccessStatement {
 					// Delete the definition
 					let key = crate::key::database::ac::new(opt.ns()?, opt.db()?, &ac.name);
 					run.del(key).await?;
+					// Delete any grants issued by the access method
+					let key = crate::key::database::gr::prefix(opt.ns()?, opt.db()?, &ac.name);
+					run.delp(key, u32::MAX).await?;
 					// Ok all good
 					Ok(Value::None)
 				}
@@ -2,6 +2,7 @@ use crate::ctx::Context;
 use crate::dbs::Options;
 use crate::err::Error;
 use crate::iam::{Action, ResourceKind};
+use crate::kvs::del_table_usage;
 use crate::sql::{Base, Ident, Value};
 use derive::Store;
 use revision::revisioned;
@@ -37,9 +38,13 @@ impl RemoveTableStatement {
 			// Delete the definition
 			let key = crate::key::database::tb::new(opt.ns()?, opt.db()?, &self.name);
 			run.del(key).await?;
+			// Change the version of the table definition
+			run.change_tb(opt.ns()?, opt.db()?, &self.name).await?;
 			// Remove the resource data
 			let key = crate::key::table::all::new(opt.ns()?, opt.db()?, &self.name);
 			run.delp(key, u32::MAX).await?;
+			// Remove the tracked usage of the table
+			del_table_usage(&mut run, opt.ns()?, opt.db()?, &self.name).await?;
 			// Check if this is a foreign table
 			if let Some(view) = &tb.view {
 				// Process each foreign table
@@ -0,0 +1,653 @@
+use crate::cnf::EXPORT_BATCH_SIZE;
+use crate::ctx::Context;
+use crate::dbs::Options;
+use crate::err::Error;
+use crate::iam::{Action, ResourceKind};
+use crate::kvs::{
+	Datastore, LockType::*, ScanPage, StorageQuota, Transaction, TransactionType::*, Val,
+};
+use crate::sql::paths::{EDGE, ID};
+use crate::sql::statements::{
+	DefineEventStatement, DefineFieldStatement, DefineIndexStatement, DefineTableStatement,
+	RemoveTableStatement,
+};
+use crate::sql::{AccessType, Base, Ident, Idiom, Kind, Part, Thing, Value};
+use derive::Store;
+use reblessive::tree::Stk;
+use revision::revisioned;
+use serde::{Deserialize, Serialize};
+use std::fmt::{self, Display, Formatter};
+use std::sync::Arc;
+
+#[revisioned(revision = 2)]
+#[derive(Clone, Debug, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Store, Hash)]
+#[cfg_attr(feature = "arbitrary", derive(arbitrary::Arbitrary))]
+#[non_exhaustive]
+pub enum RenameStatement {
+	Table(RenameTableStatement),
+	#[revision(start = 2)]
+	Field(RenameFieldStatement),
+}
+
+impl RenameStatement {
+	/// Check if we require a writeable transaction
+	pub(crate) fn writeable(&self) -> bool {
+		true
+	}
+	/// Process this type returning a computed simple Value
+	///
+	/// Renaming moves the records in batches, each of which is committed in
+	/// its own transaction, so this can not be run within a transaction.
+	pub(crate) async fn compute(
+		&self,
+		stk: &mut Stk,
+		kvs: &Datastore,
+		ctx: &Context<'_>,
+		opt: &Options,
+	) -> Result<Value, Error> {
+		match self {
+			Self::Table(s) => s.compute(stk, kvs, ctx, opt).await,
+			Self::Field(s) => s.compute(kvs, ctx, opt).await,
+		}
+	}
+}
+
+impl Display for RenameStatement {
+	fn fmt(&self, f: &mut Formatter) -> fmt::Result {
+		match self {
+			Self::Table(v) => Display::fmt(v, f),
+			Self::Field(v) => Display::fmt(v, f),
+		}
+	}
+}
+
+#[revisioned(revision = 1)]
+#[derive(Clone, Debug, Default, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Store, Hash)]
+#[cfg_attr(feature = "arbitrary", derive(arbitrary::Arbitrary))]
+#[non_exhaustive]
+pub struct RenameTableStatement {
+	pub name: Ident,
+	pub into: Ident,
+	pub if_exists: bool,
+}
+
+impl RenameTableStatement {
+	/// Process this type returning a computed simple Value
+	pub(crate) async fn compute(
+		&self,
+		stk: &mut Stk,
+		kvs: &Datastore,
+		ctx: &Context<'_>,
+		opt: &Options,
+	) -> Result<Value, Error> {
+		let future = async {
+			// Allowed to run?
+			opt.is_allowed(Action::Edit, ResourceKind::Table, &Base::Db)?;
+			// Get the namespace and database
+			let (ns, db) = (opt.ns()?, opt.db()?);
+			// Check that the table can be renamed
+			let mut tx = kvs.transaction(Read, Optimistic).await?;
+			let res = match tx.get_tb(ns, db, &self.name).await {
+				Ok(tb) => self.check(&mut tx, ns, db, &tb).await,
+				Err(e) => Err(e),
+			};
+			tx.cancel().await?;
+			res?;
+			// Check that no records link to the table
+			self.links(kvs, ns, db).await?;
+			// Copy the definitions to the new table
+			let indexes = {
+				let mut ctx = Context::new(ctx);
+				ctx.set_transaction_mut(kvs.transaction(Write, Optimistic).await?.enclose());
+				let res = self.copy(&ctx, ns, db).await;
+				finish(&ctx, res).await?
+			};
+			// Move the records to the new table in batches
+			loop {
+				let mut ctx = Context::new(ctx);
+				ctx.set_transaction_mut(kvs.transaction(Write, Optimistic).await?.enclose());
+				let res = match self.move_batch(&ctx, ns, db).await {
+					// Remove the original table once it is empty
+					Ok(true) => self.remove(stk, &ctx, opt, &indexes).await.map(|_| true),
+					res => res,
+				};
+				if finish(&ctx, res).await? {
+					break;
+				}
+			}
+			// Ok all good
+			Ok(Value::None)
+		}
+		.await;
+		match future {
+			Err(Error::TbNotFound {
+				..
+			}) if self.if_exists => Ok(Value::None),
+			v => v,
+		}
+	}
+
+	/// Returns an error explaining why the table can not be renamed
+	fn error(&self, message: impl Into<String>) -> Error {
+		Error::TbNotRenamable {
+			value: self.name.to_string(),
+			message: message.into(),
+		}
+	}
+
+	/// Check that no definitions prevent the table from being renamed
+	async fn check(
+		&self,
+		tx: &mut Transaction,
+		ns: &str,
+		db: &str,
+		tb: &DefineTableStatement,
+	) -> Result<(), Error> {
+		let name = self.name.as_str();
+		// Check that the new table does not exist
+		if tx.get_tb(ns, db, &self.into).await.is_ok() {
+			return Err(Error::TbAlreadyExists {
+				value: self.into.to_string(),
+			});
+		}
+		// Foreign tables refer to their source tables by name
+		if tb.view.is_some() || !tx.all_tb_views(ns, db, name).await?.is_empty() {
+			return Err(self.error("The table is a foreign table, or is used by one"));
+		}
+		// Graph edges are referenced by the records they connect
+		let beg = crate::key::graph::tbprefix(ns, db, name);
+		let end = crate::key::graph::tbsuffix(ns, db, name);
+		if !tx.scan(beg..end, 1).await?.is_empty() {
+			return Err(self.error("The table records are connected by graph edges"));
+		}
+		// Change feeds would not record the records being moved
+		if tb.changefeed.is_some() || tx.get_db(ns, db).await?.changefeed.is_some() {
+			return Err(self.error("The table or database has a change feed"));
+		}
+		// Live queries are registered against the table by name
+		if !tx.all_tb_lives(ns, db, name).await?.is_empty() {
+			return Err(self.error("The table has running live queries"));
+		}
+		// Table, field and event definitions can refer to the table by name
+		for other in tx.all_tb(ns, db).await?.iter() {
+			if mentions(&other.kind.to_string(), name)
+				|| mentions(&other.permissions.to_string(), name)
+			{
+				return Err(self.error(format!("The table '{}' refers to the table", other.name)));
+			}
+			for fd in tx.all_tb_fields(ns, db, &other.name).await?.iter() {
+				if fd.kind.as_ref().is_some_and(|k| references(k, name))
+					|| [&fd.value, &fd.assert, &fd.default]
+						.iter()
+						.any(|v| v.as_ref().is_some_and(|v| mentions(&v.to_string(), name)))
+					|| mentions(&fd.permissions.to_string(), name)
+				{
+					return Err(self.error(format!(
+						"The field '{}' on table '{}' refers to the table",
+						fd.name, other.name
+					)));
+				}
+			}
+			for ev in tx.all_tb_events(ns, db, &other.name).await?.iter() {
+				if mentions(&ev.when.to_string(), name) || mentions(&ev.then.to_string(), name) {
+					return Err(self.error(format!(
+						"The event '{}' on table '{}' refers to the table",
+						ev.name, other.name
+					)));
+				}
+			}
+		}
+		// Functions, params and access methods can refer to the table by name
+		for fc in tx.all_db_functions(ns, db).await?.iter() {
+			if mentions(&fc.block.to_string(), name) || mentions(&fc.permissions.to_string(), name)
+			{
+				return Err(
+					self.error(format!("The function 'fn::{}' refers to the table", fc.name))
+				);
+			}
+		}
+		for pa in tx.all_db_params(ns, db).await?.iter() {
+			if mentions(&pa.value.to_string(), name) {
+				return Err(self.error(format!("The param '${}' refers to the table", pa.name)));
+			}
+		}
+		for ac in tx.all_db_accesses(ns, db).await?.iter() {
+			let mut clauses = vec![&ac.authenticate];
+			if let AccessType::Record(at) = &ac.kind {
+				clauses.extend([&at.signup, &at.signin]);
+			}
+			if clauses.iter().any(|v| v.as_ref().is_some_and(|v| mentions(&v.to_string(), name))) {
+				return Err(
+					self.error(format!("The access method '{}' refers to the table", ac.name))
+				);
+			}
+		}
+		Ok(())
+	}
+
+	/// Check that no records link to the table, and that the table does
+	/// not contain graph edges, scanning each table in batches
+	async fn links(&self, kvs: &Datastore, ns: &str, db: &str) -> Result<(), Error> {
+		// Fetch the tables in the database
+		let mut tx = kvs.transaction(Read, Optimistic).await?;
+		let tables = tx.all_tb(ns, db).await;
+		tx.cancel().await?;
+		// Check the records of each table
+		for other in tables?.iter() {
+			let beg = crate::key::thing::prefix(ns, db, &other.name);
+			let end = crate::key::thing::suffix(ns, db, &other.name);
+			let mut nxt: Option<ScanPage<Vec<u8>>> = Some(ScanPage::from(beg..end));
+			while let Some(page) = nxt {
+				let mut tx = kvs.transaction(Read, Optimistic).await?;
+				let res = tx.scan_paged(page, *EXPORT_BATCH_SIZE).await;
+				tx.cancel().await?;
+				let res = res?;
+				nxt = res.next_page;
+				for (_, v) in res.values.into_iter() {
+					let val: Value = (&v).into();
+					// Edge records are referenced by the records they connect
+					if other.name == self.name && val.pick(&*EDGE).is_true() {
+						return Err(self.error("The table contains graph edge records"));
+					}
+					if let Value::Object(v) = val {
+						if v.iter().any(|(k, v)| k != "id" && links(v, &self.name)) {
+							return Err(self.error(format!(
+								"Records in table '{}' link to the table",
+								other.name
+							)));
+						}
+					}
+				}
+			}
+		}
+		Ok(())
+	}
+
+	/// Copy the table, field and event definitions to the new table,
+	/// returning the index definitions which need to be rebuilt
+	async fn copy(
+		&self,
+		ctx: &Context<'_>,
+		ns: &str,
+		db: &str,
+	) -> Result<Arc<[DefineIndexStatement]>, Error> {
+		// Claim transaction
+		let mut run = ctx.tx_lock().await;
+		// Clear the cache
+		run.clear_cache();
+		// Check the table again, as it could have changed since
+		let tb = run.get_tb(ns, db, &self.name).await?;
+		self.check(&mut run, ns, db, &tb).await?;
+		// Change the version of the table definition
+		run.change_tb(ns, db, &self.into).await?;
+		// Copy the table definition
+		let key = crate::key::database::tb::new(ns, db, &self.into);
+		let def = DefineTableStatement {
+			name: self.into.clone(),
+			..tb
+		};
+		run.set(key, def).await?;
+		// Copy the field definitions
+		for fd in run.all_tb_fields(ns, db, &self.name).await?.iter() {
+			let name = fd.name.to_string();
+			let key = crate::key::table::fd::new(ns, db, &self.into, &name);
+			let def = DefineFieldStatement {
+				what: self.into.clone(),
+				..fd.clone()
+			};
+			run.set(key, def).await?;
+		}
+		// Copy the event definitions
+		for ev in run.all_tb_events(ns, db, &self.name).await?.iter() {
+			let key = crate::key::table::ev::new(ns, db, &self.into, &ev.name);
+			let def = DefineEventStatement {
+				what: self.into.clone(),
+				..ev.clone()
+			};
+			run.set(key, def).await?;
+		}
+		// Indexes are rebuilt once the records have been moved
+		run.all_tb_indexes(ns, db, &self.name).await
+	}
+
+	/// Move a single batch of records to the new table, returning
+	/// whether the original table has no records left to move
+	async fn move_batch(&self, ctx: &Context<'_>, ns: &str, db: &str) -> Result<bool, Error> {
+		// Claim transaction
+		let mut run = ctx.tx_lock().await;
+		// Moved records are removed, so scan from the start of the table
+		let beg = crate::key::thing::prefix(ns, db, &self.name);
+		let end = crate::key::thing::suffix(ns, db, &self.name);
+		let res = run.scan(beg..end, *EXPORT_BATCH_SIZE).await?;
+		let done = res.len() < *EXPORT_BATCH_SIZE as usize;
+		let records = res.len() as i64;
+		let (mut removed, mut added) = (0, 0);
+		for (k, v) in res.into_iter() {
+			let key: crate::key::thing::Thing = (&k).into();
+			let mut val: Value = (&v).into();
+			// Edge records are referenced by the records they connect
+			if val.pick(&*EDGE).is_true() {
+				return Err(self.error("The table contains graph edge records"));
+			}
+			// Update the record id to the new table
+			let id = Thing {
+				tb: self.into.to_raw(),
+				id: key.id.clone(),
+			};
+			val.put(&*ID, id.into());
+			// Store the record in the new table
+			let val = Val::from(val);
+			removed += v.len() as i64;
+			added += val.len() as i64;
+			run.set(crate::key::thing::new(ns, db, &self.into, &key.id), val).await?;
+			run.del(k).await?;
+		}
+		// Move the usage of the records to the new table
+		let quota = StorageQuota::new(ns, db);
+		quota.update(&mut run, ns, db, &self.name, -records, -removed).await?;
+		quota.update(&mut run, ns, db, &self.into, records, added).await?;
+		Ok(done)
+	}
+
+	/// Remove the original table, and rebuild the indexes on the new table
+	async fn remove(
+		&self,
+		stk: &mut Stk,
+		ctx: &Context<'_>,
+		opt: &Options,
+		indexes: &[DefineIndexStatement],
+	) -> Result<(), Error> {
+		// Remove the original table and its data
+		let remove = RemoveTableStatement {
+			name: self.name.clone(),
+			if_exists: false,
+		};
+		remove.compute(ctx, opt).await?;
+		// Rebuild the indexes on the new table
+		for ix in indexes.iter() {
+			let ix = DefineIndexStatement {
+				what: self.into.clone(),
+				..ix.clone()
+			};
+			ix.compute(stk, ctx, opt, None).await?;
+		}
+		Ok(())
+	}
+}
+
+impl Display for RenameTableStatement {
+	fn fmt(&self, f: &mut Formatter) -> fmt::Result {
+		write!(f, "RENAME TABLE")?;
+		if self.if_exists {
+			write!(f, " IF EXISTS")?
+		}
+		write!(f, " {} TO {}", self.name, self.into)?;
+		Ok(())
+	}
+}
+
+#[revisioned(revision = 1)]
+#[derive(Clone, Debug, Default, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Store, Hash)]
+#[cfg_attr(feature = "arbitrary", derive(arbitrary::Arbitrary))]
+#[non_exhaustive]
+pub struct RenameFieldStatement {
+	pub name: Idiom,
+	pub what: Ident,
+	pub into: Idiom,
+	pub if_exists: bool,
+}
+
+impl RenameFieldStatement {
+	/// Process this type returning a computed simple Value
+	pub(crate) async fn compute(
+		&self,
+		kvs: &Datastore,
+		ctx: &Context<'_>,
+		opt: &Options,
+	) -> Result<Value, Error> {
+		let future = async {
+			// Allowed to run?
+			opt.is_allowed(Action::Edit, ResourceKind::Field, &Base::Db)?;
+			// Get the namespace and database
+			let (ns, db) = (opt.ns()?, opt.db()?);
+			// Rename the field definitions
+			{
+				let mut ctx = Context::new(ctx);
+				ctx.set_transaction_mut(kvs.transaction(Write, Optimistic).await?.enclose());
+				let res = self.rename(&ctx, ns, db).await;
+				finish(&ctx, res).await?;
+			}
+			// Rename the field on the records in batches
+			let beg = crate::key::thing::prefix(ns, db, &self.what);
+			let end = crate::key::thing::suffix(ns, db, &self.what);
+			let mut nxt: Option<ScanPage<Vec<u8>>> = Some(ScanPage::from(beg..end));
+			while let Some(page) = nxt {
+				let mut ctx = Context::new(ctx);
+				ctx.set_transaction_mut(kvs.transaction(Write, Optimistic).await?.enclose());
+				let res = self.rename_batch(&ctx, ns, db, page).await;
+				nxt = finish(&ctx, res).await?;
+			}
+			// Ok all good
+			Ok(Value::None)
+		}
+		.await;
+		match future {
+			Err(Error::FdNotFound {
+				..
+			}) if self.if_exists => Ok(Value::None),
+			v => v,
+		}
+	}
+
+	/// Returns an error explaining why the field can not be renamed
+	fn error(&self, message: impl Into<String>) -> Error {
+		Error::FdNotRenamable {
+			value: self.name.to_string(),
+			message: message.into(),
+		}
+	}
+
+	/// Check that the field can be renamed, and rename its definition along
+	/// with the definitions of any fields nested within it
+	async fn rename(&self, ctx: &Context<'_>, ns: &str, db: &str) -> Result<(), Error> {
+		// Claim transaction
+		let mut run = ctx.tx_lock().await;
+		// Clear the cache
+		run.clear_cache();
+		// Check that the field is defined
+		let fd = self.name.to_string();
+		run.get_tb_field(ns, db, &self.what, &fd).await?;
+		// Check that the new field is not defined
+		let into = self.into.to_string();
+		if run.get_tb_field(ns, db, &self.what, &into).await.is_ok() {
+			return Err(Error::FdAlreadyExists {
+				value: into,
+			});
+		}
+		// Only top-level fields which are not record ids or edges can be renamed
+		let name = match (&self.name[..], &self.into[..]) {
+			([Part::Field(name)], [Part::Field(into)])
+				if !["id", "in", "out"].contains(&name.as_str())
+					&& !["id", "in", "out"].contains(&into.as_str()) =>
+			{
+				name.as_str()
+			}
+			_ => {
+				return Err(
+					self.error("Only top-level fields other than id, in and out can be renamed")
+				)
+			}
+		};
+		// Foreign tables are computed from their source tables
+		let tb = run.get_tb(ns, db, &self.what).await?;
+		if tb.view.is_some() {
+			return Err(self.error("The table is a foreign table"));
+		}
+		for ft in run.all_tb_views(ns, db, &self.what).await?.iter() {
+			if ft.view.as_ref().is_some_and(|v| mentions(&v.to_string(), name)) {
+				return Err(self.error(format!("The table '{}' refers to the field", ft.name)));
+			}
+		}
+		// Change feeds would not record the records being changed
+		if tb.changefeed.is_some() || run.get_db(ns, db).await?.changefeed.is_some() {
+			return Err(self.error("The table or database has a change feed"));
+		}
+		// Live queries would not be notified of the records being changed
+		if !run.all_tb_lives(ns, db, &self.what).await?.is_empty() {
+			return Err(self.error("The table has running live queries"));
+		}
+		// The table permissions can refer to the field
+		if mentions(&tb.permissions.to_string(), name) {
+			return Err(self.error("The table permissions refer to the field"));
+		}
+		// Indexes, fields and events can refer to the field
+		for ix in run.all_tb_indexes(ns, db, &self.what).await?.iter() {
+			if ix
+				.cols
+				.iter()
+				.any(|c| matches!(c.first(), Some(Part::Field(c)) if c.as_str() == name))
+			{
+				return Err(self.error(format!("The index '{}' refers to the field", ix.name)));
+			}
+		}
+		let fields = run.all_tb_fields(ns, db, &self.what).await?;
+		for fd in fields.iter() {
+			if [&fd.value, &fd.assert, &fd.default]
+				.iter()
+				.any(|v| v.as_ref().is_some_and(|v| mentions(&v.to_string(), name)))
+				|| mentions(&fd.permissions.to_string(), name)
+			{
+				return Err(self.error(format!("The field '{}' refers to the field", fd.name)));
+			}
+		}
+		for ev in run.all_tb_events(ns, db, &self.what).await?.iter() {
+			if mentions(&ev.when.to_string(), name) || mentions(&ev.then.to_string(), name) {
+				return Err(self.error(format!("The event '{}' refers to the field", ev.name)));
+			}
+		}
+		// Rename the field definition, and those of any nested fields
+		for fd in fields.iter() {
+			if matches!(fd.name.first(), Some(Part::Field(f)) if f.as_str() == name) {
+				let mut def = DefineFieldStatement {
+					name: self.into.clone(),
+					..fd.clone()
+				};
+				def.name.0.extend(fd.name[1..].iter().cloned());
+				let key = crate::key::table::fd::new(ns, db, &self.what, &def.name.to_string());
+				run.set(key, def).await?;
+				let key = crate::key::table::fd::new(ns, db, &self.what, &fd.name.to_string());
+				run.del(key).await?;
+			}
+		}
+		// Clear the cache
+		let key = crate::key::table::fd::prefix(ns, db, &self.what);
+		run.clr(key).await?;
+		// Ok all good
+		Ok(())
+	}
+
+	/// Rename the field on a single batch of records, returning the next
+	/// batch of records to rename the field on
+	async fn rename_batch(
+		&self,
+		ctx: &Context<'_>,
+		ns: &str,
+		db: &str,
+		page: ScanPage<Vec<u8>>,
+	) -> Result<Option<ScanPage<Vec<u8>>>, Error> {
+		// Claim transaction
+		let mut run = ctx.tx_lock().await;
+		// Rename the field on each record which has it
+		let res = run.scan_paged(page, *EXPORT_BATCH_SIZE).await?;
+		let mut bytes = 0;
+		for (k, v) in res.values.into_iter() {
+			let mut val: Value = (&v).into();
+			let field = val.pick(&self.name);
+			if field.is_none() {
+				continue;
+			}
+			val.cut(&self.name);
+			val.put(&self.into, field);
+			let val = Val::from(val);
+			bytes += val.len() as i64 - v.len() as i64;
+			run.set(k, val).await?;
+		}
+		// Track the change in size of the records
+		let quota = StorageQuota::new(ns, db);
+		quota.update(&mut run, ns, db, &self.what, 0, bytes).await?;
+		Ok(res.next_page)
+	}
+}
+
+impl Display for RenameFieldStatement {
+	fn fmt(&self, f: &mut Formatter) -> fmt::Result {
+		write!(f, "RENAME FIELD")?;
+		if self.if_exists {
+			write!(f, " IF EXISTS")?
+		}
+		write!(f, " {} ON {} TO {}", self.name, self.what, self.into)?;
+		Ok(())
+	}
+}
+
+/// Commit the transaction of a single batch if it succeeded, or cancel it
+async fn finish<T>(ctx: &Context<'_>, res: Result<T, Error>) -> Result<T, Error> {
+	let mut run = ctx.tx_lock().await;
+	match res {
+		Ok(v) => {
+			run.complete_changes(false).await?;
+			run.commit().await?;
+			Ok(v)
+		}
+		Err(e) => {
+			run.cancel().await?;
+			Err(e)
+		}
+	}
+}
+
+/// Check if the SurrealQL text of a definition mentions a name as a whole
+/// word. This can match names within strings and comments too, so it may
+/// reject a rename which would have been safe, but does not miss a use.
+fn mentions(text: &str, name: &str) -> bool {
+	let word = |c: char| c.is_alphanumeric() || c == '_';
+	text.match_indices(name).any(|(i, _)| {
+		!text[..i].chars().next_back().is_some_and(word)
+			&& !text[i + name.len()..].chars().next().is_some_and(word)
+	})
+}
+
+/// Check if a value contains a record link to the specified table
+fn links(val: &Value, tb: &str) -> bool {
+	match val {
+		Value::Thing(v) => v.tb == tb,
+		Value::Array(v) => v.iter().any(|v| links(v, tb)),
+		Value::Object(v) => v.values().any(|v| links(v, tb)),
+		_ => false,
+	}
+}
+
+/// Check if a field type only allows records from the specified table
+fn references(kind: &Kind, tb: &str) -> bool {
+	match kind {
+		Kind::Record(v) => v.iter().any(|v| v.0 == tb),
+		Kind::Option(v) | Kind::Set(v, _) | Kind::Array(v, _) => references(v, tb),
+		Kind::Either(v) => v.iter().any(|v| references(v, tb)),
+		_ => false,
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	use super::mentions;
+
+	#[test]
+	fn mentions_whole_words() {
+		assert!(mentions("SELECT * FROM person", "person"));
+		assert!(mentions("person:tobie", "person"));
+		assert!(mentions("(SELECT * FROM ⟨person⟩)", "person"));
+		assert!(!mentions("SELECT * FROM persons", "person"));
+		assert!(!mentions("SELECT * FROM other_person", "person"));
+		assert!(!mentions("SELECT * FROM user", "person"));
+	}
+}
@@ -0,0 +1,154 @@
+use crate::ctx::Context;
+use crate::dbs::Options;
+use crate::doc::CursorDoc;
+use crate::err::Error;
+use crate::iam::audit;
+use crate::iam::revoke::{revoke, subject};
+use crate::iam::{Action, Level, ResourceKind};
+use crate::kvs::ActiveSession;
+use crate::sql::{Base, Ident, Value};
+use derive::Store;
+use reblessive::tree::Stk;
+use revision::revisioned;
+use serde::{Deserialize, Serialize};
+use std::fmt::{self, Display, Formatter};
+
+#[revisioned(revision = 1)]
+#[derive(Clone, Debug, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Store, Hash)]
+#[cfg_attr(feature = "arbitrary", derive(arbitrary::Arbitrary))]
+#[non_exhaustive]
+pub enum RevokeStatement {
+	// Revokes a single token by its id
+	Token(Value),
+	// Terminates a connected session by its id
+	Session(Value),
+	// Revokes all tokens issued to a system user
+	User(Ident, Base),
+	// Revokes all tokens issued to a record user
+	Record(Value),
+}
+
+impl RevokeStatement {
+	/// Process this type returning a computed simple Value
+	pub(crate) async fn compute(
+		&self,
+		stk: &mut Stk,
+		ctx: &Context<'_>,
+		opt: &Options,
+		doc: Option<&CursorDoc<'_>>,
+	) -> Result<Value, Error> {
+		match self {
+			Self::Token(v) => {
+				// Allowed to run?
+				opt.is_allowed(Action::Edit, ResourceKind::Actor, &Base::Root)?;
+				// Compute the token id
+				let jti = match v.compute(stk, ctx, opt, doc).await? {
+					Value::Strand(v) => v.0,
+					Value::Uuid(v) => v.to_raw(),
+					v => return Err(self.error(v)),
+				};
+				// Store the revocation
+				revoke(&mut *ctx.tx_lock().await, &jti).await?;
+				// Terminate any sessions using the token
+				let count = kill(ctx, |s| s.token.as_deref() == Some(jti.as_str()));
+				audit::revocation(ctx, opt, "token", &jti, count);
+			}
+			Self::Session(v) => {
+				// Allowed to run?
+				opt.is_allowed(Action::Edit, ResourceKind::Actor, &Base::Root)?;
+				// Compute the session id
+				let id = match v.compute(stk, ctx, opt, doc).await? {
+					Value::Uuid(v) => v.0,
+					Value::Strand(v) => match uuid::Uuid::try_parse(&v) {
+						Ok(v) => v,
+						_ => return Err(self.error(v.into())),
+					},
+					v => return Err(self.error(v)),
+				};
+				// Terminate the session
+				let count = kill(ctx, |s| s.id == id);
+				audit::revocation(ctx, opt, "session", &id.to_string(), count);
+			}
+			Self::User(name, base) => {
+				// Allowed to run?
+				opt.is_allowed(Action::Edit, ResourceKind::Actor, base)?;
+				// Check that the user exists
+				let mut run = ctx.tx_lock().await;
+				let level = match base {
+					Base::Root => {
+						run.get_root_user(name).await?;
+						Level::Root
+					}
+					Base::Ns => {
+						run.get_ns_user(opt.ns()?, name).await?;
+						Level::Namespace(opt.ns()?.to_owned())
+					}
+					Base::Db => {
+						run.get_db_user(opt.ns()?, opt.db()?, name).await?;
+						Level::Database(opt.ns()?.to_owned(), opt.db()?.to_owned())
+					}
+					_ => return Err(Error::InvalidLevel(base.to_string())),
+				};
+				// Store the revocation
+				revoke(&mut run, &subject(&level, name)).await?;
+				// Terminate any sessions for the user
+				let sub = subject(&level, name);
+				let level = level.to_string();
+				let count = kill(ctx, |s| s.actor == name.as_str() && s.level == level);
+				audit::revocation(ctx, opt, "user", &sub, count);
+			}
+			Self::Record(v) => {
+				// Allowed to run?
+				opt.is_allowed(Action::Edit, ResourceKind::Actor, &Base::Db)?;
+				// Compute the record id
+				let rid = match v.compute(stk, ctx, opt, doc).await? {
+					Value::Thing(v) => v.to_string(),
+					v => return Err(self.error(v)),
+				};
+				let level = Level::Record(opt.ns()?.to_owned(), opt.db()?.to_owned(), rid.clone());
+				// Store the revocation
+				revoke(&mut *ctx.tx_lock().await, &subject(&level, &rid)).await?;
+				// Terminate any sessions for the record user
+				let sub = subject(&level, &rid);
+				let level = level.to_string();
+				let count = kill(ctx, |s| s.actor == rid && s.level == level);
+				audit::revocation(ctx, opt, "record", &sub, count);
+			}
+		}
+		// Ok all good
+		Ok(Value::None)
+	}
+
+	fn error(&self, value: Value) -> Error {
+		Error::RevokeStatement {
+			value: value.to_string(),
+		}
+	}
+}
+
+/// Terminates the connected sessions which match the predicate,
+/// returning the number of sessions which were terminated
+fn kill<F>(ctx: &Context<'_>, predicate: F) -> usize
+where
+	F: Fn(&ActiveSession) -> bool,
+{
+	match ctx.get_sessions() {
+		Some(sessions) => {
+			let count = sessions.kill(predicate);
+			trace!("Terminated {count} connected sessions");
+			count
+		}
+		None => 0,
+	}
+}
+
+impl Display for RevokeStatement {
+	fn fmt(&self, f: &mut Formatter) -> fmt::Result {
+		match self {
+			Self::Token(v) => write!(f, "REVOKE TOKEN {v}"),
+			Self::Session(v) => write!(f, "REVOKE SESSION {v}"),
+			Self::User(name, base) => write!(f, "REVOKE USER {name} ON {base}"),
+			Self::Record(v) => write!(f, "REVOKE RECORD {v}"),
+		}
+	}
+}
@@ -3,15 +3,17 @@ use crate::dbs::{Iterable, Iterator, Options, Statement};
 use crate::doc::CursorDoc;
 use crate::err::Error;
 use crate::idx::planner::QueryPlanner;
+use crate::sql::subquery::SubqueryCache;
 use crate::sql::{
-	Cond, Explain, Fetchs, Field, Fields, Groups, Idioms, Limit, Orders, Splits, Start, Timeout,
-	Value, Values, Version, With,
+	Cond, Explain, Fetchs, Field, Fields, Groups, Id, Idioms, Limit, Orders, Splits, Start,
+	Subquery, Timeout, Value, Values, Version, Walk, With,
 };
 use derive::Store;
 use reblessive::tree::Stk;
 use revision::revisioned;
 use serde::{Deserialize, Serialize};
 use std::fmt;
+use std::sync::Arc;
 
 #[revisioned(revision = 3)]
 #[derive(Clone, Debug, Default, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Store, Hash)]
@@ -57,6 +59,46 @@ impl SelectStatement {
 		self.cond.as_ref().map_or(false, |v| v.writeable())
 	}
 
+	/// Check if the result of this statement is the same for every
+	/// document it is run against, so that it can be computed once and
+	/// reused. Statements which read any parameters, including `$parent`
+	/// and `$this`, or which call non-deterministic functions, are
+	/// considered to be correlated with the document.
+	pub(crate) fn is_memoizable(&self) -> bool {
+		// The targets, limit, and start are computed against the outer document
+		let target = |v: &Value| match v {
+			Value::Table(_) => true,
+			Value::Thing(v) => matches!(v.id, Id::Number(_) | Id::String(_)),
+			v => v.is_static(),
+		};
+		if !self.what.iter().all(target)
+			|| !self.limit.as_ref().map_or(true, |v| v.0.is_static())
+			|| !self.start.as_ref().map_or(true, |v| v.0.is_static())
+		{
+			return false;
+		}
+		// Check every value within the statement
+		let mut memoizable = true;
+		self.walk(&mut |v: &Value| {
+			memoizable &= match v {
+				Value::Param(_) => false,
+				Value::Block(_) => false,
+				Value::Future(_) => false,
+				Value::Mock(_) => false,
+				Value::Range(_) => false,
+				Value::Edges(_) => false,
+				Value::Model(_) => false,
+				Value::Query(_) => false,
+				Value::Thing(v) => matches!(v.id, Id::Number(_) | Id::String(_)),
+				Value::Function(v) => v.is_deterministic(),
+				Value::Subquery(v) => matches!(**v, Subquery::Value(_) | Subquery::Select(_)),
+				_ => true,
+			};
+			memoizable
+		});
+		memoizable
+	}
+
 	/// Process this type returning a computed simple Value
 	pub(crate) async fn compute(
 		&self,
@@ -113,9 +155,7 @@ impl SelectStatement {
 						return Err(Error::SingleOnlyOutput);
 					}
 
-					for v in v {
-						i.ingest(Iterable::Thing(v));
-					}
+					i.ingest_mock(ctx, opt, v).await?;
 				}
 				Value::Array(v) => {
 					if self.only && !limit_is_one_or_zero {
@@ -129,11 +169,7 @@ impl SelectStatement {
 							}
 							Value::Thing(v) => i.ingest(Iterable::Thing(v)),
 							Value::Edges(v) => i.ingest(Iterable::Edges(*v)),
-							Value::Mock(v) => {
-								for v in v {
-									i.ingest(Iterable::Thing(v));
-								}
-							}
+							Value::Mock(v) => i.ingest_mock(ctx, opt, v).await?,
 							_ => i.ingest(Iterable::Value(v)),
 						}
 					}
@@ -143,6 +179,11 @@ impl SelectStatement {
 		}
 		// Create a new context
 		let mut ctx = Context::new(ctx);
+		// Share uncorrelated subquery results between the documents of a read-only statement
+		if ctx.get_subquery_cache().is_none() && !self.writeable() {
+			let memory = ctx.get_memory_budget().map(|m| m.reservation());
+			ctx.add_subquery_cache(Some(Arc::new(SubqueryCache::new(self, memory))));
+		}
 		// Assign the statement
 		let stm = Statement::from(self);
 		// Add query executors if any
@@ -216,3 +257,44 @@ impl fmt::Display for SelectStatement {
 		Ok(())
 	}
 }
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+	use crate::sql::Statement;
+	use crate::syn::parse;
+
+	fn select(sql: &str) -> SelectStatement {
+		match parse(sql).unwrap().0 .0.remove(0) {
+			Statement::Select(v) => v,
+			v => panic!("Expected a SELECT statement, found {v}"),
+		}
+	}
+
+	#[test]
+	fn memoizable() {
+		assert!(select("SELECT * FROM person").is_memoizable());
+		assert!(select("SELECT count() FROM person:tobie, user GROUP ALL").is_memoizable());
+		assert!(select("SELECT * FROM person WHERE age > 18 ORDER BY age LIMIT 10").is_memoizable());
+		assert!(
+			select("SELECT *, (SELECT * FROM ->likes->post) AS posts FROM person").is_memoizable()
+		);
+	}
+
+	#[test]
+	fn correlated() {
+		// The targets are computed against the outer document
+		assert!(!select("SELECT * FROM ->likes->post").is_memoizable());
+		assert!(!select("SELECT * FROM friends").is_memoizable());
+		// Parameters could differ between documents
+		assert!(!select("SELECT * FROM person WHERE age > $parent.age").is_memoizable());
+		assert!(!select("SELECT * FROM person WHERE id = $this.id").is_memoizable());
+		assert!(!select("SELECT * FROM person LIMIT $limit").is_memoizable());
+		assert!(!select("SELECT (SELECT * FROM post WHERE author = $parent.id) FROM person")
+			.is_memoizable());
+		// Functions could return a different result each time
+		assert!(!select("SELECT * FROM person WHERE age > rand::int(1, 10)").is_memoizable());
+		assert!(!select("SELECT * FROM person WHERE time > time::now()").is_memoizable());
+		assert!(!select("SELECT fn::name(id) FROM person").is_memoizable());
+	}
+}
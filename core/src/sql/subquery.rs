@@ -1,7 +1,9 @@
+use crate::cnf::SUBQUERY_CACHE_SIZE;
 use crate::ctx::Context;
 use crate::dbs::Options;
 use crate::doc::CursorDoc;
 use crate::err::Error;
+use crate::kvs::{estimate, MemoryReservation};
 use crate::sql::statements::rebuild::RebuildStatement;
 use crate::sql::statements::{
 	CreateStatement, DefineStatement, DeleteStatement, IfelseStatement, InsertStatement,
@@ -9,11 +11,14 @@ use crate::sql::statements::{
 	UpsertStatement,
 };
 use crate::sql::value::Value;
+use crate::sql::Walk;
 use reblessive::tree::Stk;
 use revision::revisioned;
 use serde::{Deserialize, Serialize};
 use std::cmp::Ordering;
+use std::collections::{HashMap, HashSet};
 use std::fmt::{self, Display, Formatter};
+use std::sync::Mutex;
 
 pub(crate) const TOKEN: &str = "$surrealdb::private::sql::Subquery";
 
@@ -74,6 +79,20 @@ impl Subquery {
 		opt: &Options,
 		doc: Option<&CursorDoc<'_>>,
 	) -> Result<Value, Error> {
+		// Reuse the result of an uncorrelated subquery within the same statement
+		if let (Some(cache), Self::Select(v)) = (ctx.get_subquery_cache(), self) {
+			if cache.is_memoizable(self) {
+				// Check if this subquery has already been computed
+				if let Some(v) = cache.get(self) {
+					return Ok(v);
+				}
+				// Compute the subquery without the parent document
+				let res = v.compute(stk, ctx, opt, None).await?;
+				// Store the result for the following documents
+				cache.set(self, &res);
+				return Ok(res);
+			}
+		}
 		// Duplicate context
 		let mut ctx = Context::new(ctx);
 		// Add parent document
@@ -99,6 +118,80 @@ impl Subquery {
 	}
 }
 
+/// The results of the uncorrelated subqueries within a read-only statement,
+/// which are computed for the first document, and reused for the others.
+pub(crate) struct SubqueryCache {
+	// The addresses of the subqueries which can be memoized. These do not
+	// change, as the statement is not moved or dropped while it is running.
+	memoizable: HashSet<usize>,
+	// The computed results of the memoized subqueries
+	results: Mutex<SubqueryResults>,
+}
+
+struct SubqueryResults {
+	// The computed results, keyed by the address of the subquery
+	values: HashMap<usize, Value>,
+	// The approximate number of bytes retained by the results
+	bytes: usize,
+	// The bytes retained against the server memory budget
+	memory: Option<MemoryReservation>,
+}
+
+impl SubqueryCache {
+	/// Find the uncorrelated subqueries within a statement
+	pub(crate) fn new(stm: &SelectStatement, memory: Option<MemoryReservation>) -> Self {
+		let mut memoizable = HashSet::new();
+		stm.walk(&mut |v: &Value| {
+			if let Value::Subquery(v) = v {
+				if let Subquery::Select(s) = v.as_ref() {
+					if s.is_memoizable() {
+						memoizable.insert(Self::key(v.as_ref()));
+					}
+				}
+			}
+			true
+		});
+		Self {
+			memoizable,
+			results: Mutex::new(SubqueryResults {
+				values: HashMap::new(),
+				bytes: 0,
+				memory,
+			}),
+		}
+	}
+	/// The key of a subquery within the statement
+	fn key(sq: &Subquery) -> usize {
+		sq as *const Subquery as usize
+	}
+	/// Check if the result of a subquery can be reused
+	pub(crate) fn is_memoizable(&self, sq: &Subquery) -> bool {
+		self.memoizable.contains(&Self::key(sq))
+	}
+	/// Get the computed result of a subquery
+	pub(crate) fn get(&self, sq: &Subquery) -> Option<Value> {
+		let results = self.results.lock().unwrap_or_else(|e| e.into_inner());
+		results.values.get(&Self::key(sq)).cloned()
+	}
+	/// Store the computed result of a subquery, unless this would exceed
+	/// the size of the cache, or the server memory budget, in which case
+	/// the subquery is computed again for the following documents.
+	pub(crate) fn set(&self, sq: &Subquery, val: &Value) {
+		let mut results = self.results.lock().unwrap_or_else(|e| e.into_inner());
+		let bytes = estimate(val);
+		if results.bytes + bytes > *SUBQUERY_CACHE_SIZE {
+			return;
+		}
+		if let Some(memory) = &mut results.memory {
+			if memory.grow(bytes).is_err() {
+				return;
+			}
+		}
+		results.bytes += bytes;
+		results.values.insert(Self::key(sq), val.clone());
+	}
+}
+
 impl Display for Subquery {
 	fn fmt(&self, f: &mut Formatter) -> fmt::Result {
 		match self {
@@ -118,3 +211,62 @@ impl Display for Subquery {
 		}
 	}
 }
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+	use crate::kvs::MemoryBudget;
+	use crate::sql::{Field, Statement};
+	use crate::syn::parse;
+	use std::sync::Arc;
+
+	fn select(sql: &str) -> SelectStatement {
+		match parse(sql).unwrap().0 .0.remove(0) {
+			Statement::Select(v) => v,
+			v => panic!("Expected a SELECT statement, found {v}"),
+		}
+	}
+
+	fn subquery(stm: &SelectStatement, i: usize) -> &Subquery {
+		match &stm.expr.0[i] {
+			Field::Single {
+				expr: Value::Subquery(v),
+				..
+			} => v.as_ref(),
+			v => panic!("Expected a subquery, found {v}"),
+		}
+	}
+
+	#[test]
+	fn memoizable_subqueries() {
+		let stm = select(
+			"SELECT (SELECT * FROM tag) AS tags, (SELECT * FROM post WHERE author = $parent.id) AS posts FROM person",
+		);
+		let cache = SubqueryCache::new(&stm, None);
+		// Only uncorrelated subqueries within the statement are memoized
+		assert!(cache.is_memoizable(subquery(&stm, 0)));
+		assert!(!cache.is_memoizable(subquery(&stm, 1)));
+		assert!(!cache.is_memoizable(&subquery(&stm, 0).clone()));
+		// The results are reused
+		assert_eq!(cache.get(subquery(&stm, 0)), None);
+		cache.set(subquery(&stm, 0), &Value::from(1));
+		assert_eq!(cache.get(subquery(&stm, 0)), Some(Value::from(1)));
+	}
+
+	#[test]
+	fn memoized_subqueries_use_the_memory_budget() {
+		let stm = select("SELECT (SELECT * FROM tag) AS tags FROM person");
+		let budget = Arc::new(MemoryBudget::new(1024));
+		let cache = SubqueryCache::new(&stm, Some(budget.reservation()));
+		// A result which exceeds the memory budget is not stored
+		cache.set(subquery(&stm, 0), &Value::from("x".repeat(2048)));
+		assert_eq!(cache.get(subquery(&stm, 0)), None);
+		assert_eq!(budget.used(), 0);
+		// A result is counted against the budget until the cache is dropped
+		cache.set(subquery(&stm, 0), &Value::from("x"));
+		assert_eq!(cache.get(subquery(&stm, 0)), Some(Value::from("x")));
+		assert!(budget.used() > 0);
+		drop(cache);
+		assert_eq!(budget.used(), 0);
+	}
+}
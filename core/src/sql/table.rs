@@ -1,4 +1,7 @@
-use crate::sql::{escape::escape_ident, fmt::Fmt, strand::no_nul_bytes, Id, Ident, Thing};
+use crate::ctx::Context;
+use crate::dbs::Options;
+use crate::err::Error;
+use crate::sql::{escape::escape_ident, fmt::Fmt, id::Gen, strand::no_nul_bytes, Id, Ident, Thing};
 use revision::revisioned;
 use serde::{Deserialize, Serialize};
 use std::fmt::{self, Display, Formatter};
@@ -71,6 +74,27 @@ impl Table {
 			id: Id::rand(),
 		}
 	}
+	/// Generates a new record id for this table, using the id
+	/// format specified on the table definition, if there is one.
+	pub(crate) async fn generate_defined(
+		&self,
+		ctx: &Context<'_>,
+		opt: &Options,
+	) -> Result<Thing, Error> {
+		// Fetch the table definition, if it exists
+		let gen = match ctx.tx_lock().await.get_and_cache_tb(opt.ns()?, opt.db()?, &self.0).await {
+			Ok(tb) => tb.generate.clone(),
+			Err(Error::TbNotFound {
+				..
+			}) => None,
+			Err(e) => return Err(e),
+		};
+		// Generate the record id
+		Ok(Thing {
+			tb: self.0.to_owned(),
+			id: gen.unwrap_or(Gen::Rand).generate(ctx),
+		})
+	}
 }
 
 impl Display for Table {
@@ -1,4 +1,5 @@
 use crate::sql::{escape::quote_str, strand::Strand};
+use rand::{Rng, RngCore};
 use revision::revisioned;
 use serde::{Deserialize, Serialize};
 use std::fmt::{self, Display, Formatter};
@@ -80,6 +81,14 @@ impl Uuid {
 	pub fn new_v7() -> Self {
 		Self(uuid::Uuid::now_v7())
 	}
+	/// Generate a new V4 UUID using the given random number generator
+	pub(crate) fn new_v4_from(rng: &mut dyn RngCore) -> Self {
+		Self(uuid::Builder::from_random_bytes(rng.gen()).into_uuid())
+	}
+	/// Generate a new V7 UUID with the given timestamp and random number generator
+	pub(crate) fn new_v7_from(millis: u64, rng: &mut dyn RngCore) -> Self {
+		Self(uuid::Builder::from_unix_timestamp_millis(millis, &rng.gen()).into_uuid())
+	}
 	/// Convert the Uuid to a raw String
 	pub fn to_raw(&self) -> String {
 		self.0.to_string()
@@ -0,0 +1,67 @@
+pub mod opt;
+
+use crate::err::Error;
+use crate::sql::id::Gen;
+use crate::sql::value::serde::ser;
+use serde::ser::Error as _;
+use serde::ser::Impossible;
+
+pub(super) struct Serializer;
+
+impl ser::Serializer for Serializer {
+	type Ok = Gen;
+	type Error = Error;
+
+	type SerializeSeq = Impossible<Gen, Error>;
+	type SerializeTuple = Impossible<Gen, Error>;
+	type SerializeTupleStruct = Impossible<Gen, Error>;
+	type SerializeTupleVariant = Impossible<Gen, Error>;
+	type SerializeMap = Impossible<Gen, Error>;
+	type SerializeStruct = Impossible<Gen, Error>;
+	type SerializeStructVariant = Impossible<Gen, Error>;
+
+	const EXPECTED: &'static str = "an enum `Gen`";
+
+	#[inline]
+	fn serialize_unit_variant(
+		self,
+		name: &'static str,
+		_variant_index: u32,
+		variant: &'static str,
+	) -> Result<Self::Ok, Error> {
+		match variant {
+			"Rand" => Ok(Gen::Rand),
+			"Ulid" => Ok(Gen::Ulid),
+			"Uuid" => Ok(Gen::Uuid),
+			variant => Err(Error::custom(format!("unexpected unit variant `{name}::{variant}`"))),
+		}
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+	use ser::Serializer as _;
+	use serde::Serialize;
+
+	#[test]
+	fn rand() {
+		let gen = Gen::Rand;
+		let serialized = gen.serialize(Serializer.wrap()).unwrap();
+		assert_eq!(gen, serialized);
+	}
+
+	#[test]
+	fn ulid() {
+		let gen = Gen::Ulid;
+		let serialized = gen.serialize(Serializer.wrap()).unwrap();
+		assert_eq!(gen, serialized);
+	}
+
+	#[test]
+	fn uuid() {
+		let gen = Gen::Uuid;
+		let serialized = gen.serialize(Serializer.wrap()).unwrap();
+		assert_eq!(gen, serialized);
+	}
+}
@@ -0,0 +1,56 @@
+use crate::err::Error;
+use crate::sql::id::Gen;
+use crate::sql::value::serde::ser;
+use serde::ser::Impossible;
+use serde::ser::Serialize;
+
+#[non_exhaustive]
+pub struct Serializer;
+
+impl ser::Serializer for Serializer {
+	type Ok = Option<Gen>;
+	type Error = Error;
+
+	type SerializeSeq = Impossible<Option<Gen>, Error>;
+	type SerializeTuple = Impossible<Option<Gen>, Error>;
+	type SerializeTupleStruct = Impossible<Option<Gen>, Error>;
+	type SerializeTupleVariant = Impossible<Option<Gen>, Error>;
+	type SerializeMap = Impossible<Option<Gen>, Error>;
+	type SerializeStruct = Impossible<Option<Gen>, Error>;
+	type SerializeStructVariant = Impossible<Option<Gen>, Error>;
+
+	const EXPECTED: &'static str = "an `Option<Gen>`";
+
+	#[inline]
+	fn serialize_none(self) -> Result<Self::Ok, Self::Error> {
+		Ok(None)
+	}
+
+	#[inline]
+	fn serialize_some<T>(self, value: &T) -> Result<Self::Ok, Self::Error>
+	where
+		T: ?Sized + Serialize,
+	{
+		Ok(Some(value.serialize(super::Serializer.wrap())?))
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+	use ser::Serializer as _;
+
+	#[test]
+	fn none() {
+		let option: Option<Gen> = None;
+		let serialized = option.serialize(Serializer.wrap()).unwrap();
+		assert_eq!(option, serialized);
+	}
+
+	#[test]
+	fn some() {
+		let option = Some(Gen::Ulid);
+		let serialized = option.serialize(Serializer.wrap()).unwrap();
+		assert_eq!(option, serialized);
+	}
+}
@@ -1,3 +1,5 @@
+pub(super) mod gen;
+
 use crate::err::Error;
 use crate::sql::value::serde::ser;
 use crate::sql::Array;
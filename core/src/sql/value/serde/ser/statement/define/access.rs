@@ -3,10 +3,12 @@ use crate::sql::access::AccessDuration;
 use crate::sql::access_type::AccessType;
 use crate::sql::statements::DefineAccessStatement;
 use crate::sql::value::serde::ser;
+use crate::sql::AllowIp;
 use crate::sql::Base;
 use crate::sql::Duration;
 use crate::sql::Ident;
 use crate::sql::Strand;
+use crate::sql::Value;
 use ser::Serializer as _;
 use serde::ser::Error as _;
 use serde::ser::Impossible;
@@ -48,6 +50,8 @@ pub struct SerializeDefineAccessStatement {
 	duration: AccessDuration,
 	comment: Option<Strand>,
 	if_not_exists: bool,
+	allow: AllowIp,
+	authenticate: Option<Value>,
 }
 
 impl serde::ser::SerializeStruct for SerializeDefineAccessStatement {
@@ -77,6 +81,13 @@ impl serde::ser::SerializeStruct for SerializeDefineAccessStatement {
 			"if_not_exists" => {
 				self.if_not_exists = value.serialize(ser::primitive::bool::Serializer.wrap())?
 			}
+			"allow" => {
+				let allow = value.serialize(ser::string::vec::Serializer.wrap())?;
+				self.allow = AllowIp(allow.into_iter().map(Into::into).collect());
+			}
+			"authenticate" => {
+				self.authenticate = value.serialize(ser::value::opt::Serializer.wrap())?;
+			}
 			key => {
 				return Err(Error::custom(format!(
 					"unexpected field `DefineAccessStatement::{key}`"
@@ -94,6 +105,8 @@ impl serde::ser::SerializeStruct for SerializeDefineAccessStatement {
 			duration: self.duration,
 			comment: self.comment,
 			if_not_exists: self.if_not_exists,
+			allow: self.allow,
+			authenticate: self.authenticate,
 		})
 	}
 }
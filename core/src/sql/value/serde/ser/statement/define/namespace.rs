@@ -1,6 +1,7 @@
 use crate::err::Error;
 use crate::sql::statements::DefineNamespaceStatement;
 use crate::sql::value::serde::ser;
+use crate::sql::AllowIp;
 use crate::sql::Ident;
 use crate::sql::Strand;
 use ser::Serializer as _;
@@ -42,6 +43,7 @@ pub struct SerializeDefineNamespaceStatement {
 	id: Option<u32>,
 	comment: Option<Strand>,
 	if_not_exists: bool,
+	allow: AllowIp,
 }
 
 impl serde::ser::SerializeStruct for SerializeDefineNamespaceStatement {
@@ -65,6 +67,10 @@ impl serde::ser::SerializeStruct for SerializeDefineNamespaceStatement {
 			"if_not_exists" => {
 				self.if_not_exists = value.serialize(ser::primitive::bool::Serializer.wrap())?
 			}
+			"allow" => {
+				let allow = value.serialize(ser::string::vec::Serializer.wrap())?;
+				self.allow = AllowIp(allow.into_iter().map(Into::into).collect());
+			}
 			key => {
 				return Err(Error::custom(format!(
 					"unexpected field `DefineNamespaceStatement::{key}`"
@@ -80,6 +86,7 @@ impl serde::ser::SerializeStruct for SerializeDefineNamespaceStatement {
 			id: self.id,
 			comment: self.comment,
 			if_not_exists: self.if_not_exists,
+			allow: self.allow,
 		})
 	}
 }
@@ -1,5 +1,6 @@
 use crate::err::Error;
 use crate::sql::changefeed::ChangeFeed;
+use crate::sql::id::Gen;
 use crate::sql::statements::DefineTableStatement;
 use crate::sql::value::serde::ser;
 use crate::sql::Ident;
@@ -52,6 +53,7 @@ pub struct SerializeDefineTableStatement {
 	comment: Option<Strand>,
 	if_not_exists: bool,
 	kind: TableType,
+	generate: Option<Gen>,
 }
 
 impl serde::ser::SerializeStruct for SerializeDefineTableStatement {
@@ -93,6 +95,7 @@ impl serde::ser::SerializeStruct for SerializeDefineTableStatement {
 			"if_not_exists" => {
 				self.if_not_exists = value.serialize(ser::primitive::bool::Serializer.wrap())?
 			}
+			"generate" => self.generate = value.serialize(ser::id::gen::opt::Serializer.wrap())?,
 			key => {
 				return Err(Error::custom(format!(
 					"unexpected field `DefineTableStatement::{key}`"
@@ -114,6 +117,7 @@ impl serde::ser::SerializeStruct for SerializeDefineTableStatement {
 			comment: self.comment,
 			kind: self.kind,
 			if_not_exists: self.if_not_exists,
+			generate: self.generate,
 		})
 	}
 }
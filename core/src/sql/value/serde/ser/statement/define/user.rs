@@ -2,6 +2,7 @@ use crate::err::Error;
 use crate::sql::statements::DefineUserStatement;
 use crate::sql::user::UserDuration;
 use crate::sql::value::serde::ser;
+use crate::sql::AllowIp;
 use crate::sql::Base;
 use crate::sql::Duration;
 use crate::sql::Ident;
@@ -49,6 +50,7 @@ pub struct SerializeDefineUserStatement {
 	duration: UserDuration,
 	comment: Option<Strand>,
 	if_not_exists: bool,
+	allow: AllowIp,
 }
 
 impl serde::ser::SerializeStruct for SerializeDefineUserStatement {
@@ -84,6 +86,10 @@ impl serde::ser::SerializeStruct for SerializeDefineUserStatement {
 			"if_not_exists" => {
 				self.if_not_exists = value.serialize(ser::primitive::bool::Serializer.wrap())?
 			}
+			"allow" => {
+				let allow = value.serialize(ser::string::vec::Serializer.wrap())?;
+				self.allow = AllowIp(allow.into_iter().map(Into::into).collect());
+			}
 			key => {
 				return Err(Error::custom(format!(
 					"unexpected field `DefineUserStatement::{key}`"
@@ -103,6 +109,7 @@ impl serde::ser::SerializeStruct for SerializeDefineUserStatement {
 			duration: self.duration,
 			comment: self.comment,
 			if_not_exists: self.if_not_exists,
+			allow: self.allow,
 		})
 	}
 }
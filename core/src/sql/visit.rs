@@ -0,0 +1,501 @@
+//! Traversal of the values contained within queries and statements.
+//!
+//! Every part of a query which can contain values implements [`Walk`], which
+//! passes each nested [`Value`] to a [`Visitor`], or to a [`VisitorMut`] when
+//! the query should be rewritten in place. Values are visited before their
+//! children, and a visitor can return `false` to skip the children of a value.
+
+use crate::sql::{
+	block::Entry,
+	statements::{
+		CreateStatement, DeleteStatement, ForeachStatement, IfelseStatement, InsertStatement,
+		OutputStatement, RelateStatement, SelectStatement, SetStatement, ThrowStatement,
+		UpdateStatement, UpsertStatement,
+	},
+	Block, Cond, Data, Edges, Expression, Fetch, Fetchs, Field, Fields, Function, Graph, Group,
+	Groups, Id, Idiom, Idioms, Limit, Order, Orders, Output, Part, Query, Range, Split, Splits,
+	Start, Statement, Statements, Subquery, Thing, Value, Values,
+};
+use std::ops::Bound;
+
+/// A visitor which inspects the values within a query.
+pub trait Visitor {
+	/// Visit a value, returning `false` to skip the values nested within it.
+	fn visit_value(&mut self, value: &Value) -> bool;
+}
+
+/// A visitor which can modify the values within a query.
+pub trait VisitorMut {
+	/// Visit a value, returning `false` to skip the values nested within it.
+	fn visit_value_mut(&mut self, value: &mut Value) -> bool;
+}
+
+impl<F: FnMut(&Value) -> bool> Visitor for F {
+	fn visit_value(&mut self, value: &Value) -> bool {
+		self(value)
+	}
+}
+
+impl<F: FnMut(&mut Value) -> bool> VisitorMut for F {
+	fn visit_value_mut(&mut self, value: &mut Value) -> bool {
+		self(value)
+	}
+}
+
+/// Implemented by any type which can contain values.
+pub trait Walk {
+	/// Pass each value within this type to the visitor
+	fn walk<V: Visitor + ?Sized>(&self, v: &mut V);
+	/// Pass each value within this type to the visitor, allowing modification
+	fn walk_mut<V: VisitorMut + ?Sized>(&mut self, v: &mut V);
+}
+
+impl<T: Walk> Walk for Option<T> {
+	fn walk<V: Visitor + ?Sized>(&self, v: &mut V) {
+		if let Some(x) = self {
+			x.walk(v)
+		}
+	}
+	fn walk_mut<V: VisitorMut + ?Sized>(&mut self, v: &mut V) {
+		if let Some(x) = self {
+			x.walk_mut(v)
+		}
+	}
+}
+
+impl<T: Walk> Walk for Vec<T> {
+	fn walk<V: Visitor + ?Sized>(&self, v: &mut V) {
+		self.iter().for_each(|x| x.walk(v))
+	}
+	fn walk_mut<V: VisitorMut + ?Sized>(&mut self, v: &mut V) {
+		self.iter_mut().for_each(|x| x.walk_mut(v))
+	}
+}
+
+impl<T: Walk> Walk for Box<T> {
+	fn walk<V: Visitor + ?Sized>(&self, v: &mut V) {
+		self.as_ref().walk(v)
+	}
+	fn walk_mut<V: VisitorMut + ?Sized>(&mut self, v: &mut V) {
+		self.as_mut().walk_mut(v)
+	}
+}
+
+impl<T: Walk> Walk for Bound<T> {
+	fn walk<V: Visitor + ?Sized>(&self, v: &mut V) {
+		if let Bound::Included(x) | Bound::Excluded(x) = self {
+			x.walk(v)
+		}
+	}
+	fn walk_mut<V: VisitorMut + ?Sized>(&mut self, v: &mut V) {
+		if let Bound::Included(x) | Bound::Excluded(x) = self {
+			x.walk_mut(v)
+		}
+	}
+}
+
+impl<A: Walk, B: Walk> Walk for (A, B) {
+	fn walk<V: Visitor + ?Sized>(&self, v: &mut V) {
+		self.0.walk(v);
+		self.1.walk(v);
+	}
+	fn walk_mut<V: VisitorMut + ?Sized>(&mut self, v: &mut V) {
+		self.0.walk_mut(v);
+		self.1.walk_mut(v);
+	}
+}
+
+/// Implements [`Walk`] for a struct by walking the listed fields in order.
+///
+/// The trait methods are called explicitly, as [`Value::walk`] is also an
+/// inherent method, which would otherwise take precedence.
+macro_rules! walk_fields {
+	($($ty:ty { $($field:tt),* });* $(;)?) => {
+		$(
+			impl Walk for $ty {
+				fn walk<V: Visitor + ?Sized>(&self, v: &mut V) {
+					$(Walk::walk(&self.$field, v);)*
+				}
+				fn walk_mut<V: VisitorMut + ?Sized>(&mut self, v: &mut V) {
+					$(Walk::walk_mut(&mut self.$field, v);)*
+				}
+			}
+		)*
+	};
+}
+
+/// Implements [`Walk`] for an enum by walking the listed single field variants.
+macro_rules! walk_variants {
+	($($ty:ident { $($var:ident),* });* $(;)?) => {
+		$(
+			impl Walk for $ty {
+				#[allow(unreachable_patterns)]
+				fn walk<V: Visitor + ?Sized>(&self, v: &mut V) {
+					match self {
+						$($ty::$var(x) => Walk::walk(x, v),)*
+						_ => (),
+					}
+				}
+				#[allow(unreachable_patterns)]
+				fn walk_mut<V: VisitorMut + ?Sized>(&mut self, v: &mut V) {
+					match self {
+						$($ty::$var(x) => Walk::walk_mut(x, v),)*
+						_ => (),
+					}
+				}
+			}
+		)*
+	};
+}
+
+walk_fields! {
+	Query { 0 };
+	Statements { 0 };
+	Values { 0 };
+	Fields { 0 };
+	Cond { 0 };
+	Block { 0 };
+	Idiom { 0 };
+	Idioms { 0 };
+	Limit { 0 };
+	Start { 0 };
+	Splits { 0 };
+	Split { 0 };
+	Groups { 0 };
+	Group { 0 };
+	Orders { 0 };
+	Order { order };
+	Fetchs { 0 };
+	Fetch { 0 };
+	Graph { expr, cond, split, group, order, limit, start };
+	SelectStatement { expr, omit, what, cond, split, group, order, limit, start, fetch };
+	Thing { id };
+	Range { beg, end };
+	Edges { from };
+	CreateStatement { what, data, output };
+	UpdateStatement { what, data, cond, output };
+	UpsertStatement { what, data, cond, output };
+	DeleteStatement { what, cond, output };
+	RelateStatement { from, kind, with, data, output };
+	InsertStatement { into, data, update, output };
+	SetStatement { what };
+	OutputStatement { what };
+	IfelseStatement { exprs, close };
+	ForeachStatement { range, block };
+	ThrowStatement { error };
+}
+
+walk_variants! {
+	Statement {
+		Value, Create, Delete, Foreach, Ifelse, Insert, Output, Relate, Select, Set, Throw,
+		Update, Upsert
+	};
+	Subquery { Value, Ifelse, Output, Select, Create, Update, Delete, Relate, Insert, Upsert };
+	Entry {
+		Value, Set, Ifelse, Select, Create, Update, Delete, Relate, Insert, Output, Throw,
+		Foreach, Upsert
+	};
+	Output { Fields };
+}
+
+impl Walk for Part {
+	fn walk<V: Visitor + ?Sized>(&self, v: &mut V) {
+		match self {
+			Part::Where(x) | Part::Value(x) | Part::Start(x) => Walk::walk(x, v),
+			Part::Method(_, x) => x.walk(v),
+			Part::Graph(x) => x.walk(v),
+			_ => (),
+		}
+	}
+	fn walk_mut<V: VisitorMut + ?Sized>(&mut self, v: &mut V) {
+		match self {
+			Part::Where(x) | Part::Value(x) | Part::Start(x) => x.walk_mut(v),
+			Part::Method(_, x) => x.walk_mut(v),
+			Part::Graph(x) => x.walk_mut(v),
+			_ => (),
+		}
+	}
+}
+
+impl Walk for Id {
+	fn walk<V: Visitor + ?Sized>(&self, v: &mut V) {
+		match self {
+			Id::Array(x) => x.0.walk(v),
+			Id::Object(x) => x.0.values().for_each(|x| Walk::walk(x, v)),
+			_ => (),
+		}
+	}
+	fn walk_mut<V: VisitorMut + ?Sized>(&mut self, v: &mut V) {
+		match self {
+			Id::Array(x) => x.0.walk_mut(v),
+			Id::Object(x) => x.0.values_mut().for_each(|x| x.walk_mut(v)),
+			_ => (),
+		}
+	}
+}
+
+impl Walk for Field {
+	fn walk<V: Visitor + ?Sized>(&self, v: &mut V) {
+		if let Field::Single {
+			expr,
+			..
+		} = self
+		{
+			Walk::walk(expr, v)
+		}
+	}
+	fn walk_mut<V: VisitorMut + ?Sized>(&mut self, v: &mut V) {
+		if let Field::Single {
+			expr,
+			..
+		} = self
+		{
+			expr.walk_mut(v)
+		}
+	}
+}
+
+impl Walk for Data {
+	fn walk<V: Visitor + ?Sized>(&self, v: &mut V) {
+		match self {
+			Data::SetExpression(x) | Data::UpdateExpression(x) => x.iter().for_each(|(i, _, x)| {
+				i.walk(v);
+				Walk::walk(x, v);
+			}),
+			Data::ValuesExpression(x) => x.walk(v),
+			Data::UnsetExpression(x) => x.walk(v),
+			Data::PatchExpression(x)
+			| Data::MergeExpression(x)
+			| Data::ReplaceExpression(x)
+			| Data::ContentExpression(x)
+			| Data::SingleExpression(x) => Walk::walk(x, v),
+			Data::EmptyExpression => (),
+		}
+	}
+	fn walk_mut<V: VisitorMut + ?Sized>(&mut self, v: &mut V) {
+		match self {
+			Data::SetExpression(x) | Data::UpdateExpression(x) => {
+				x.iter_mut().for_each(|(i, _, x)| {
+					i.walk_mut(v);
+					x.walk_mut(v);
+				})
+			}
+			Data::ValuesExpression(x) => x.walk_mut(v),
+			Data::UnsetExpression(x) => x.walk_mut(v),
+			Data::PatchExpression(x)
+			| Data::MergeExpression(x)
+			| Data::ReplaceExpression(x)
+			| Data::ContentExpression(x)
+			| Data::SingleExpression(x) => x.walk_mut(v),
+			Data::EmptyExpression => (),
+		}
+	}
+}
+
+impl Walk for Expression {
+	fn walk<V: Visitor + ?Sized>(&self, v: &mut V) {
+		match self {
+			Expression::Unary {
+				v: x,
+				..
+			} => Walk::walk(x, v),
+			Expression::Binary {
+				l,
+				r,
+				..
+			} => {
+				Walk::walk(l, v);
+				Walk::walk(r, v);
+			}
+		}
+	}
+	fn walk_mut<V: VisitorMut + ?Sized>(&mut self, v: &mut V) {
+		match self {
+			Expression::Unary {
+				v: x,
+				..
+			} => x.walk_mut(v),
+			Expression::Binary {
+				l,
+				r,
+				..
+			} => {
+				l.walk_mut(v);
+				r.walk_mut(v);
+			}
+		}
+	}
+}
+
+impl Walk for Function {
+	fn walk<V: Visitor + ?Sized>(&self, v: &mut V) {
+		match self {
+			Function::Normal(_, x) | Function::Custom(_, x) | Function::Script(_, x) => x.walk(v),
+		}
+	}
+	fn walk_mut<V: VisitorMut + ?Sized>(&mut self, v: &mut V) {
+		match self {
+			Function::Normal(_, x) | Function::Custom(_, x) | Function::Script(_, x) => {
+				x.walk_mut(v)
+			}
+		}
+	}
+}
+
+impl Walk for Value {
+	fn walk<V: Visitor + ?Sized>(&self, v: &mut V) {
+		if !v.visit_value(self) {
+			return;
+		}
+		match self {
+			Value::Array(x) => x.0.walk(v),
+			Value::Object(x) => x.0.values().for_each(|x| Walk::walk(x, v)),
+			Value::Thing(x) => x.walk(v),
+			Value::Range(x) => x.walk(v),
+			Value::Edges(x) => x.walk(v),
+			Value::Idiom(x) => x.walk(v),
+			Value::Cast(x) => Walk::walk(&x.1, v),
+			Value::Block(x) => x.walk(v),
+			Value::Future(x) => x.0.walk(v),
+			Value::Function(x) => x.walk(v),
+			Value::Subquery(x) => x.walk(v),
+			Value::Expression(x) => x.walk(v),
+			Value::Query(x) => x.walk(v),
+			Value::Model(x) => x.args.walk(v),
+			_ => (),
+		}
+	}
+	fn walk_mut<V: VisitorMut + ?Sized>(&mut self, v: &mut V) {
+		if !v.visit_value_mut(self) {
+			return;
+		}
+		match self {
+			Value::Array(x) => x.0.walk_mut(v),
+			Value::Object(x) => x.0.values_mut().for_each(|x| x.walk_mut(v)),
+			Value::Thing(x) => x.walk_mut(v),
+			Value::Range(x) => x.walk_mut(v),
+			Value::Edges(x) => x.walk_mut(v),
+			Value::Idiom(x) => x.walk_mut(v),
+			Value::Cast(x) => x.1.walk_mut(v),
+			Value::Block(x) => x.walk_mut(v),
+			Value::Future(x) => x.0.walk_mut(v),
+			Value::Function(x) => x.walk_mut(v),
+			Value::Subquery(x) => x.walk_mut(v),
+			Value::Expression(x) => x.walk_mut(v),
+			Value::Query(x) => x.walk_mut(v),
+			Value::Model(x) => x.args.walk_mut(v),
+			_ => (),
+		}
+	}
+}
+
+#[cfg(test)]
+mod tests {
+
+	use super::*;
+	use crate::sql::{Param, Strand};
+	use crate::syn::parse;
+
+	fn params(sql: &str) -> Vec<String> {
+		let sql = parse(sql).unwrap();
+		let mut params = Vec::new();
+		sql.walk(&mut |v: &Value| {
+			if let Value::Param(p) = v {
+				params.push(p.0.to_raw());
+			}
+			true
+		});
+		params
+	}
+
+	#[test]
+	fn walk_collects_params() {
+		let res = params(
+			"LET $a = 1; SELECT * FROM person WHERE age > $min AND name = $name; IF $a { RETURN [$b, { c: $c }] };",
+		);
+		assert_eq!(res, vec!["min", "name", "a", "b", "c"]);
+	}
+
+	#[test]
+	fn walk_select_clauses() {
+		let res = params(
+			"SELECT $a, ->(edge WHERE x = $b) AS e OMIT c[$c] FROM person WHERE d = $d LIMIT $e START $f FETCH g[$g]",
+		);
+		assert_eq!(res, vec!["a", "b", "c", "d", "e", "f", "g"]);
+	}
+
+	#[test]
+	fn walk_output_clauses() {
+		for sql in [
+			"CREATE person SET a = $a RETURN $b",
+			"UPDATE person SET a = $a RETURN $b",
+			"UPSERT person SET a = $a RETURN $b",
+			"DELETE person WHERE a = $a RETURN $b",
+			"RELATE person:one->likes->person:two SET a = $a RETURN $b",
+			"INSERT INTO person { a: $a } RETURN $b",
+		] {
+			assert_eq!(params(sql), vec!["a", "b"], "{sql}");
+		}
+	}
+
+	#[test]
+	fn walk_record_ids() {
+		let res = params("SELECT * FROM person:[$a, { b: $b }], person:[$c]..=[$d]");
+		assert_eq!(res, vec!["a", "b", "c", "d"]);
+	}
+
+	#[test]
+	fn walk_method_arguments() {
+		let val = Value::Idiom(Idiom(vec![
+			Part::Start(Value::Param(Param::from("a"))),
+			Part::Method("slice".to_owned(), vec![Value::Param(Param::from("b"))]),
+		]));
+		let mut params = Vec::new();
+		Walk::walk(&val, &mut |v: &Value| {
+			if let Value::Param(p) = v {
+				params.push(p.0.to_raw());
+			}
+			true
+		});
+		assert_eq!(params, vec!["a", "b"]);
+	}
+
+	#[test]
+	fn walk_skips_children() {
+		let sql = parse("RETURN [$a, [$b]]").unwrap();
+		let mut params = Vec::new();
+		sql.walk(&mut |v: &Value| match v {
+			Value::Param(p) => {
+				params.push(p.0.to_raw());
+				true
+			}
+			Value::Array(a) => !a.iter().any(|v| v.is_array()),
+			_ => true,
+		});
+		assert!(params.is_empty());
+	}
+
+	#[test]
+	fn walk_mut_rewrites_params() {
+		let mut sql = parse("SELECT * FROM person WHERE name = $name").unwrap();
+		sql.walk_mut(&mut |v: &mut Value| {
+			if matches!(v, Value::Param(Param(p)) if p.0 == "name") {
+				*v = Value::Strand(Strand::from("Tobie"));
+			}
+			true
+		});
+		assert_eq!(sql.to_string(), "SELECT * FROM person WHERE name = 'Tobie';");
+	}
+
+	#[test]
+	fn walk_mut_rewrites_record_ids() {
+		let mut sql = parse("SELECT * FROM person:[$a, { b: $b }]").unwrap();
+		sql.walk_mut(&mut |v: &mut Value| {
+			if v.is_param() {
+				*v = Value::from(1);
+			}
+			true
+		});
+		assert_eq!(sql.to_string(), "SELECT * FROM person:[1, { b: 1 }];");
+	}
+}
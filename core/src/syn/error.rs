@@ -7,6 +7,36 @@ use super::common::Location;
 pub struct RenderedError {
 	pub text: String,
 	pub snippets: Vec<Snippet>,
+	/// The token found where the error occurred, if the error was caused by an unexpected token.
+	pub(crate) found: Option<String>,
+	/// The tokens, or kinds of tokens, which the parser expected instead.
+	pub(crate) expected: Vec<String>,
+}
+
+impl RenderedError {
+	pub fn new(text: String, snippets: Vec<Snippet>) -> Self {
+		RenderedError {
+			text,
+			snippets,
+			found: None,
+			expected: Vec::new(),
+		}
+	}
+
+	/// The token found where the error occurred, if the error was caused by an unexpected token.
+	pub fn found(&self) -> Option<&str> {
+		self.found.as_deref()
+	}
+
+	/// The tokens, or kinds of tokens, which the parser expected instead.
+	pub fn expected(&self) -> &[String] {
+		&self.expected
+	}
+
+	/// The location in the source where the error occurred.
+	pub fn location(&self) -> Option<Location> {
+		self.snippets.first().map(|s| s.location)
+	}
 }
 
 impl fmt::Display for RenderedError {
@@ -57,6 +87,21 @@ impl Snippet {
 	/// How far the will have to be in the source line before everything before it gets truncated.
 	const MAX_ERROR_LINE_OFFSET: usize = 50;
 
+	/// The location of the snippet in the original source code.
+	pub fn location(&self) -> Location {
+		self.location
+	}
+
+	/// The amount of characters, starting at the location, which the snippet points to.
+	pub fn length(&self) -> usize {
+		self.length
+	}
+
+	/// A possible explanation for this snippet.
+	pub fn explain(&self) -> Option<&str> {
+		self.explain.as_deref()
+	}
+
 	pub fn from_source_location(
 		source: &str,
 		location: Location,
@@ -278,6 +323,8 @@ mod test {
 				length: 5,
 				explain: Some("this is wrong".to_owned()),
 			}],
+			found: None,
+			expected: Vec::new(),
 		};
 
 		let error_string = format!("{}", error);
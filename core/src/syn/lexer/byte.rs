@@ -9,6 +9,7 @@ use crate::syn::{
 impl<'a> Lexer<'a> {
 	/// Eats a single line comment.
 	pub fn eat_single_line_comment(&mut self) {
+		self.comments = true;
 		loop {
 			let Some(byte) = self.reader.next() else {
 				break;
@@ -45,6 +46,7 @@ impl<'a> Lexer<'a> {
 
 	/// Eats a multi line comment and returns an error if `*/` would be missing.
 	pub fn eat_multi_line_comment(&mut self) -> Result<(), Error> {
+		self.comments = true;
 		loop {
 			let Some(byte) = self.reader.next() else {
 				return Err(Error::UnexpectedEof);
@@ -24,8 +24,10 @@ pub static RESERVED_KEYWORD: phf::Set<UniCase<&'static str>> = phf_set! {
 	UniCase::ascii("OPTION"),
 	UniCase::ascii("REBUILD"),
 	UniCase::ascii("RETURN"),
+	UniCase::ascii("REVOKE"),
 	UniCase::ascii("RELATE"),
 	UniCase::ascii("REMOVE"),
+	UniCase::ascii("RENAME"),
 	UniCase::ascii("SELECT"),
 	UniCase::ascii("LET"),
 	UniCase::ascii("SHOW"),
@@ -59,6 +61,8 @@ pub(crate) static KEYWORDS: phf::Map<UniCase<&'static str>, TokenKind> = phf_map
 	UniCase::ascii("AFTER") => TokenKind::Keyword(Keyword::After),
 	UniCase::ascii("ALGORITHM") => TokenKind::Keyword(Keyword::Algorithm),
 	UniCase::ascii("ALL") => TokenKind::Keyword(Keyword::All),
+	UniCase::ascii("ALTER") => TokenKind::Keyword(Keyword::Alter),
+	UniCase::ascii("ALLOW") => TokenKind::Keyword(Keyword::Allow),
 	UniCase::ascii("ANALYZE") => TokenKind::Keyword(Keyword::Analyze),
 	UniCase::ascii("ANALYZER") => TokenKind::Keyword(Keyword::Analyzer),
 	UniCase::ascii("AS") => TokenKind::Keyword(Keyword::As),
@@ -67,6 +71,7 @@ pub(crate) static KEYWORDS: phf::Map<UniCase<&'static str>, TokenKind> = phf_map
 	UniCase::ascii("ASCII") => TokenKind::Keyword(Keyword::Ascii),
 	UniCase::ascii("ASSERT") => TokenKind::Keyword(Keyword::Assert),
 	UniCase::ascii("AT") => TokenKind::Keyword(Keyword::At),
+	UniCase::ascii("AUTHENTICATE") => TokenKind::Keyword(Keyword::Authenticate),
 	UniCase::ascii("BEFORE") => TokenKind::Keyword(Keyword::Before),
 	UniCase::ascii("BEGIN") => TokenKind::Keyword(Keyword::Begin),
 	UniCase::ascii("BLANK") => TokenKind::Keyword(Keyword::Blank),
@@ -75,6 +80,7 @@ pub(crate) static KEYWORDS: phf::Map<UniCase<&'static str>, TokenKind> = phf_map
 	UniCase::ascii("BY") => TokenKind::Keyword(Keyword::By),
 	UniCase::ascii("CAMEL") => TokenKind::Keyword(Keyword::Camel),
 	UniCase::ascii("CANCEL") => TokenKind::Keyword(Keyword::Cancel),
+	UniCase::ascii("CASE") => TokenKind::Keyword(Keyword::Case),
 	UniCase::ascii("CHANGEFEED") => TokenKind::Keyword(Keyword::ChangeFeed),
 	UniCase::ascii("CHANGES") => TokenKind::Keyword(Keyword::Changes),
 	UniCase::ascii("CAPACITY") => TokenKind::Keyword(Keyword::Capacity),
@@ -126,12 +132,14 @@ pub(crate) static KEYWORDS: phf::Map<UniCase<&'static str>, TokenKind> = phf_map
 	UniCase::ascii("GROUP") => TokenKind::Keyword(Keyword::Group),
 	UniCase::ascii("HIGHLIGHTS") => TokenKind::Keyword(Keyword::Highlights),
 	UniCase::ascii("HNSW") => TokenKind::Keyword(Keyword::Hnsw),
+	UniCase::ascii("ID") => TokenKind::Keyword(Keyword::Id),
 	UniCase::ascii("IGNORE") => TokenKind::Keyword(Keyword::Ignore),
 	UniCase::ascii("INCLUDE") => TokenKind::Keyword(Keyword::Include),
 	UniCase::ascii("INDEX") => TokenKind::Keyword(Keyword::Index),
 	UniCase::ascii("INFO") => TokenKind::Keyword(Keyword::Info),
 	UniCase::ascii("INSERT") => TokenKind::Keyword(Keyword::Insert),
 	UniCase::ascii("INTO") => TokenKind::Keyword(Keyword::Into),
+	UniCase::ascii("IP") => TokenKind::Keyword(Keyword::Ip),
 	UniCase::ascii("IF") => TokenKind::Keyword(Keyword::If),
 	UniCase::ascii("IS") => TokenKind::Keyword(Keyword::Is),
 	UniCase::ascii("ISSUER") => TokenKind::Keyword(Keyword::Issuer),
@@ -180,8 +188,10 @@ pub(crate) static KEYWORDS: phf::Map<UniCase<&'static str>, TokenKind> = phf_map
 	UniCase::ascii("RELATION") => TokenKind::Keyword(Keyword::Relation),
 	UniCase::ascii("REBUILD") => TokenKind::Keyword(Keyword::Rebuild),
 	UniCase::ascii("REMOVE") => TokenKind::Keyword(Keyword::Remove),
+	UniCase::ascii("RENAME") => TokenKind::Keyword(Keyword::Rename),
 	UniCase::ascii("REPLACE") => TokenKind::Keyword(Keyword::Replace),
 	UniCase::ascii("RETURN") => TokenKind::Keyword(Keyword::Return),
+	UniCase::ascii("REVOKE") => TokenKind::Keyword(Keyword::Revoke),
 	UniCase::ascii("ROLES") => TokenKind::Keyword(Keyword::Roles),
 	UniCase::ascii("ROOT") => TokenKind::Keyword(Keyword::Root),
 	UniCase::ascii("KV") => TokenKind::Keyword(Keyword::Root),
@@ -193,6 +203,7 @@ pub(crate) static KEYWORDS: phf::Map<UniCase<&'static str>, TokenKind> = phf_map
 	UniCase::ascii("SEARCH") => TokenKind::Keyword(Keyword::Search),
 	UniCase::ascii("SELECT") => TokenKind::Keyword(Keyword::Select),
 	UniCase::ascii("SESSION") => TokenKind::Keyword(Keyword::Session),
+	UniCase::ascii("SESSIONS") => TokenKind::Keyword(Keyword::Session),
 	UniCase::ascii("SET") => TokenKind::Keyword(Keyword::Set),
 	UniCase::ascii("SHOW") => TokenKind::Keyword(Keyword::Show),
 	UniCase::ascii("SIGNIN") => TokenKind::Keyword(Keyword::Signin),
@@ -70,6 +70,8 @@ pub struct Lexer<'a> {
 	/// A buffer used to build the value of tokens which can't be read straight from the source.
 	/// like for example strings with escape characters.
 	scratch: String,
+	/// Whether any comments have been skipped in the source.
+	comments: bool,
 
 	// below are a collection of storage for values produced by tokens.
 	// For performance reasons we wan't to keep the tokens as small as possible.
@@ -102,6 +104,7 @@ impl<'a> Lexer<'a> {
 			reader,
 			last_offset: 0,
 			scratch: String::new(),
+			comments: false,
 			string: None,
 			error: None,
 			duration: None,
@@ -133,6 +136,7 @@ impl<'a> Lexer<'a> {
 			reader,
 			last_offset: 0,
 			scratch: self.scratch,
+			comments: false,
 			string: self.string,
 			error: self.error,
 			duration: self.duration,
@@ -141,6 +145,11 @@ impl<'a> Lexer<'a> {
 		}
 	}
 
+	/// Returns whether any comments have been skipped in the source so far.
+	pub fn has_comments(&self) -> bool {
+		self.comments
+	}
+
 	/// Returns the next token, driving the lexer forward.
 	///
 	/// If the lexer is at the end the source it will always return the Eof token.
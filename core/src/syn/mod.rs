@@ -2,7 +2,7 @@
 
 use crate::{
 	err::Error,
-	sql::{Datetime, Duration, Idiom, Query, Range, Subquery, Thing, Value},
+	sql::{Datetime, Duration, Idiom, Query, Range, Statement, Subquery, Thing, Value},
 };
 
 pub mod common;
@@ -19,7 +19,8 @@ pub trait Parse<T> {
 #[cfg(test)]
 mod test;
 
-use parser::Parser;
+use bytes::{Buf, BytesMut};
+use parser::{Parser, PartialResult};
 use reblessive::Stack;
 
 /// Takes a string and returns if it could be a reserved keyword in certain contexts.
@@ -49,6 +50,67 @@ pub fn parse(input: &str) -> Result<Query, Error> {
 		.map_err(Error::InvalidQuery)
 }
 
+/// Parses a SurrealQL [`Query`] and renders it back as canonical, indented SurrealQL.
+///
+/// Comments are not kept in the parsed query, so this returns [`Error::FormatComments`]
+/// if the input contains any comments, rather than silently removing them.
+pub fn format(input: &str) -> Result<String, Error> {
+	let mut parser = Parser::new(input.as_bytes());
+	let mut stack = Stack::new();
+	let query = stack
+		.enter(|stk| parser.parse_query(stk))
+		.finish()
+		.map_err(|e| e.render_on(input))
+		.map_err(Error::InvalidQuery)?;
+	if parser.has_comments() {
+		return Err(Error::FormatComments);
+	}
+	Ok(format!("{query:#}"))
+}
+
+/// Parses a single SurrealQL [`Statement`]
+///
+/// The input must contain exactly one statement, optionally followed by semicolons.
+#[instrument(level = "debug", name = "parser", skip_all, fields(length = input.len()))]
+pub fn statement(input: &str) -> Result<Statement, Error> {
+	debug!("parsing statement, input = {input}");
+	let mut parser = Parser::new(input.as_bytes());
+	let mut stack = Stack::new();
+	stack
+		.enter(|stk| parser.parse_full_statement(stk))
+		.finish()
+		.map_err(|e| e.render_on(input))
+		.map_err(Error::InvalidQuery)
+}
+
+/// Parses the first complete SurrealQL [`Statement`] from a buffer holding the start of a query.
+///
+/// The bytes used by the statement are removed from the buffer. Returns `None` if the buffer does
+/// not yet hold a complete statement, in which case more input should be appended to the buffer.
+#[instrument(level = "debug", name = "parser", skip_all, fields(length = buffer.len()))]
+pub fn parse_buffered_statement(buffer: &mut BytesMut) -> Result<Option<Statement>, Error> {
+	let res = {
+		let mut parser = Parser::new(&buffer[..]);
+		let mut stack = Stack::new();
+		stack.enter(|stk| parser.parse_partial_statement(stk)).finish()
+	};
+	match res {
+		PartialResult::Pending {
+			..
+		} => Ok(None),
+		PartialResult::Ready {
+			value,
+			used,
+		} => {
+			let value = value
+				.map_err(|e| e.render_on(&String::from_utf8_lossy(buffer)))
+				.map_err(Error::InvalidQuery)?;
+			buffer.advance(used);
+			Ok(Some(value))
+		}
+	}
+}
+
 /// Parses a SurrealQL [`Value`].
 #[instrument(level = "debug", name = "parser", skip_all, fields(length = input.len()))]
 pub fn value(input: &str) -> Result<Value, Error> {
@@ -122,7 +122,7 @@ impl Parser<'_> {
 				self.pop_peek();
 				Utc.fix()
 			}
-			x => unexpected!(self, x, "`Z` or a timezone"),
+			x => unexpected!(self, x, &["Z", "a timezone"]),
 		};
 
 		let date_time = NaiveDateTime::new(date, time);
@@ -116,7 +116,7 @@ impl Parser<'_> {
 			return Err(ParseError::new(
 				ParseErrorKind::Unexpected {
 					found: TokenKind::Strand,
-					expected: "UUID hex digits",
+					expected: "UUID hex digits".into(),
 				},
 				digits_span,
 			));
@@ -97,6 +97,7 @@ pub(crate) static PATHS: phf::Map<UniCase<&'static str>, PathKind> = phf_map! {
 		UniCase::ascii("array::concat") => PathKind::Function,
 		UniCase::ascii("array::difference") => PathKind::Function,
 		UniCase::ascii("array::distinct") => PathKind::Function,
+		UniCase::ascii("array::filter") => PathKind::Function,
 		UniCase::ascii("array::filter_index") => PathKind::Function,
 		UniCase::ascii("array::find_index") => PathKind::Function,
 		UniCase::ascii("array::first") => PathKind::Function,
@@ -110,12 +111,14 @@ pub(crate) static PATHS: phf::Map<UniCase<&'static str>, PathKind> = phf_map! {
 		UniCase::ascii("array::logical_and") => PathKind::Function,
 		UniCase::ascii("array::logical_or") => PathKind::Function,
 		UniCase::ascii("array::logical_xor") => PathKind::Function,
+		UniCase::ascii("array::map") => PathKind::Function,
 		UniCase::ascii("array::matches") => PathKind::Function,
 		UniCase::ascii("array::max") => PathKind::Function,
 		UniCase::ascii("array::min") => PathKind::Function,
 		UniCase::ascii("array::pop") => PathKind::Function,
 		UniCase::ascii("array::prepend") => PathKind::Function,
 		UniCase::ascii("array::push") => PathKind::Function,
+		UniCase::ascii("array::range") => PathKind::Function,
 		UniCase::ascii("array::remove") => PathKind::Function,
 		UniCase::ascii("array::reverse") => PathKind::Function,
 		UniCase::ascii("array::shuffle") => PathKind::Function,
@@ -130,6 +133,7 @@ pub(crate) static PATHS: phf::Map<UniCase<&'static str>, PathKind> = phf_map! {
 		UniCase::ascii("object::from_entries") => PathKind::Function,
 		UniCase::ascii("object::keys") => PathKind::Function,
 		UniCase::ascii("object::len") => PathKind::Function,
+		UniCase::ascii("object::merge") => PathKind::Function,
 		UniCase::ascii("object::values") => PathKind::Function,
 		UniCase::ascii("object::matches") => PathKind::Function,
 		//
@@ -141,6 +145,8 @@ pub(crate) static PATHS: phf::Map<UniCase<&'static str>, PathKind> = phf_map! {
 		UniCase::ascii("crypto::sha1") => PathKind::Function,
 		UniCase::ascii("crypto::sha256") => PathKind::Function,
 		UniCase::ascii("crypto::sha512") => PathKind::Function,
+		UniCase::ascii("crypto::hmac::sha256") => PathKind::Function,
+		UniCase::ascii("crypto::hmac::sha512") => PathKind::Function,
 		//
 		UniCase::ascii("duration::days") => PathKind::Function,
 		UniCase::ascii("duration::hours") => PathKind::Function,
@@ -219,6 +225,7 @@ pub(crate) static PATHS: phf::Map<UniCase<&'static str>, PathKind> = phf_map! {
 		//
 		UniCase::ascii("not") => PathKind::Function,
 		//
+		UniCase::ascii("parse::email::domain") => PathKind::Function,
 		UniCase::ascii("parse::email::host") => PathKind::Function,
 		UniCase::ascii("parse::email::user") => PathKind::Function,
 		UniCase::ascii("parse::url::domain") => PathKind::Function,
@@ -271,6 +278,8 @@ pub(crate) static PATHS: phf::Map<UniCase<&'static str>, PathKind> = phf_map! {
 		UniCase::ascii("string::distance::levenshtein") => PathKind::Function,
 		UniCase::ascii("string::html::encode") => PathKind::Function,
 		UniCase::ascii("string::html::sanitize") => PathKind::Function,
+		UniCase::ascii("string::pad::end") => PathKind::Function,
+		UniCase::ascii("string::pad::start") => PathKind::Function,
 		UniCase::ascii("string::is::alphanum") => PathKind::Function,
 		UniCase::ascii("string::is::alpha") => PathKind::Function,
 		UniCase::ascii("string::is::ascii") => PathKind::Function,
@@ -5,7 +5,7 @@ use crate::syn::{
 	token::{Span, TokenKind},
 };
 use std::{
-	fmt::Write,
+	fmt::{self, Display, Formatter, Write},
 	num::{ParseFloatError, ParseIntError},
 	ops::RangeInclusive,
 };
@@ -26,22 +26,64 @@ pub enum MissingKind {
 	Order,
 }
 
+/// What the parser expected to find in place of an unexpected token.
+#[derive(Clone, Copy, Debug)]
+#[non_exhaustive]
+pub enum Expected {
+	/// A single token, or a description of a kind of token, like "a value".
+	One(&'static str),
+	/// Any one of several tokens, or kinds of tokens.
+	OneOf(&'static [&'static str]),
+}
+
+impl Expected {
+	/// The individual tokens, or kinds of tokens, which were expected.
+	pub fn tokens(&self) -> &'static [&'static str] {
+		match self {
+			Self::One(x) => std::slice::from_ref(x),
+			Self::OneOf(x) => x,
+		}
+	}
+}
+
+impl From<&'static str> for Expected {
+	fn from(v: &'static str) -> Self {
+		Self::One(v)
+	}
+}
+
+impl<const N: usize> From<&'static [&'static str; N]> for Expected {
+	fn from(v: &'static [&'static str; N]) -> Self {
+		Self::OneOf(v)
+	}
+}
+
+impl Display for Expected {
+	fn fmt(&self, f: &mut Formatter) -> fmt::Result {
+		match self.tokens() {
+			[] => Ok(()),
+			[x] => f.write_str(x),
+			[rest @ .., last] => write!(f, "{} or {last}", rest.join(", ")),
+		}
+	}
+}
+
 #[derive(Debug)]
 #[non_exhaustive]
 pub enum ParseErrorKind {
 	/// The parser encountered an unexpected token.
 	Unexpected {
 		found: TokenKind,
-		expected: &'static str,
+		expected: Expected,
 	},
 	UnexpectedExplain {
 		found: TokenKind,
-		expected: &'static str,
+		expected: Expected,
 		explain: &'static str,
 	},
 	/// The parser encountered an unexpected token.
 	UnexpectedEof {
-		expected: &'static str,
+		expected: Expected,
 	},
 	/// An error for an unclosed delimiter with a span of the token which should be closed.
 	UnclosedDelimiter {
@@ -67,6 +109,8 @@ pub enum ParseErrorKind {
 		possibly: Option<&'static str>,
 	},
 	InvalidRegex(regex::Error),
+	/// An error for parsing an IP address or CIDR range
+	InvalidNetwork,
 	MissingField {
 		field: Span,
 		idiom: String,
@@ -107,7 +151,42 @@ impl ParseError {
 		}
 	}
 	pub fn render_on(&self, source: &str) -> RenderedError {
-		Self::render_on_inner(source, &self.kind, self.at)
+		let mut error = Self::render_on_inner(source, &self.kind, self.at);
+		match self.kind {
+			ParseErrorKind::Unexpected {
+				found,
+				expected,
+			}
+			| ParseErrorKind::UnexpectedExplain {
+				found,
+				expected,
+				..
+			} => {
+				error.found = Some(found.as_str().to_owned());
+				error.expected = expected.tokens().iter().map(|x| x.to_string()).collect();
+			}
+			ParseErrorKind::DisallowedStatement {
+				found,
+				expected,
+				..
+			} => {
+				error.found = Some(found.as_str().to_owned());
+				error.expected = vec![expected.as_str().to_owned()];
+			}
+			ParseErrorKind::UnexpectedEof {
+				expected,
+			} => {
+				error.expected = expected.tokens().iter().map(|x| x.to_string()).collect();
+			}
+			ParseErrorKind::UnclosedDelimiter {
+				expected,
+				..
+			} => {
+				error.expected = vec![expected.as_str().to_owned()];
+			}
+			_ => (),
+		}
+		error
 	}
 
 	/// Create a rendered error from the string this error was generated from.
@@ -120,10 +199,7 @@ impl ParseError {
 				let text = format!("Unexpected token '{}' expected {}", found.as_str(), expected);
 				let locations = Location::range_of_span(source, at);
 				let snippet = Snippet::from_source_location_range(source, locations, None);
-				RenderedError {
-					text,
-					snippets: vec![snippet],
-				}
+				RenderedError::new(text, vec![snippet])
 			}
 			ParseErrorKind::UnexpectedExplain {
 				found,
@@ -133,10 +209,7 @@ impl ParseError {
 				let text = format!("Unexpected token '{}' expected {}", found.as_str(), expected);
 				let locations = Location::range_of_span(source, at);
 				let snippet = Snippet::from_source_location_range(source, locations, Some(explain));
-				RenderedError {
-					text,
-					snippets: vec![snippet],
-				}
+				RenderedError::new(text, vec![snippet])
 			}
 			ParseErrorKind::UnexpectedEof {
 				expected,
@@ -144,10 +217,7 @@ impl ParseError {
 				let text = format!("Query ended early, expected {}", expected);
 				let locations = Location::range_of_span(source, at);
 				let snippet = Snippet::from_source_location_range(source, locations, None);
-				RenderedError {
-					text,
-					snippets: vec![snippet],
-				}
+				RenderedError::new(text, vec![snippet])
 			}
 			ParseErrorKind::UnclosedDelimiter {
 				expected,
@@ -162,10 +232,7 @@ impl ParseError {
 					locations,
 					Some("Expected this delimiter to close"),
 				);
-				RenderedError {
-					text,
-					snippets: vec![snippet, close_snippet],
-				}
+				RenderedError::new(text, vec![snippet, close_snippet])
 			}
 			ParseErrorKind::DisallowedStatement {
 				found,
@@ -185,19 +252,13 @@ impl ParseError {
 					locations,
 					Some("this keyword is not allowed to start a statement in this position"),
 				);
-				RenderedError {
-					text,
-					snippets: vec![snippet, dissallowed_snippet],
-				}
+				RenderedError::new(text, vec![snippet, dissallowed_snippet])
 			}
 			ParseErrorKind::InvalidToken(e) => {
 				let text = e.to_string();
 				let locations = Location::range_of_span(source, at);
 				let snippet = Snippet::from_source_location_range(source, locations, None);
-				RenderedError {
-					text,
-					snippets: vec![snippet],
-				}
+				RenderedError::new(text, vec![snippet])
 			}
 			ParseErrorKind::InvalidPath {
 				possibly,
@@ -213,73 +274,55 @@ impl ParseError {
 					locations,
 					Some("This path does not exist."),
 				);
-				RenderedError {
-					text,
-					snippets: vec![snippet],
-				}
+				RenderedError::new(text, vec![snippet])
 			}
 			ParseErrorKind::InvalidInteger(ref error) => {
 				let text = format!("failed to parse integer, {error}");
 				let locations = Location::range_of_span(source, at);
 				let snippet = Snippet::from_source_location_range(source, locations, None);
-				RenderedError {
-					text: text.to_string(),
-					snippets: vec![snippet],
-				}
+				RenderedError::new(text.to_string(), vec![snippet])
 			}
 			ParseErrorKind::InvalidFloat(ref error) => {
 				let text = format!("failed to parse floating point, {error}");
 				let locations = Location::range_of_span(source, at);
 				let snippet = Snippet::from_source_location_range(source, locations, None);
-				RenderedError {
-					text: text.to_string(),
-					snippets: vec![snippet],
-				}
+				RenderedError::new(text.to_string(), vec![snippet])
 			}
 			ParseErrorKind::InvalidDecimal(ref error) => {
 				let text = format!("failed to parse decimal number, {error}");
 				let locations = Location::range_of_span(source, at);
 				let snippet = Snippet::from_source_location_range(source, locations, None);
-				RenderedError {
-					text: text.to_string(),
-					snippets: vec![snippet],
-				}
+				RenderedError::new(text.to_string(), vec![snippet])
 			}
 			ParseErrorKind::InvalidRegex(ref error) => {
 				let text = format!("failed to parse regex, {error}");
 				let locations = Location::range_of_span(source, at);
 				let snippet = Snippet::from_source_location_range(source, locations, None);
-				RenderedError {
-					text: text.to_string(),
-					snippets: vec![snippet],
-				}
+				RenderedError::new(text.to_string(), vec![snippet])
+			}
+			ParseErrorKind::InvalidNetwork => {
+				let text = "failed to parse IP address or CIDR range";
+				let locations = Location::range_of_span(source, at);
+				let snippet = Snippet::from_source_location_range(source, locations, None);
+				RenderedError::new(text.to_string(), vec![snippet])
 			}
 			ParseErrorKind::NoWhitespace => {
 				let text = "Whitespace is dissallowed in this position";
 				let locations = Location::range_of_span(source, at);
 				let snippet = Snippet::from_source_location_range(source, locations, None);
-				RenderedError {
-					text: text.to_string(),
-					snippets: vec![snippet],
-				}
+				RenderedError::new(text.to_string(), vec![snippet])
 			}
 			ParseErrorKind::ExceededObjectDepthLimit => {
 				let text = "Parsing exceeded the depth limit for objects";
 				let locations = Location::range_of_span(source, at);
 				let snippet = Snippet::from_source_location_range(source, locations, None);
-				RenderedError {
-					text: text.to_string(),
-					snippets: vec![snippet],
-				}
+				RenderedError::new(text.to_string(), vec![snippet])
 			}
 			ParseErrorKind::ExceededQueryDepthLimit => {
 				let text = "Parsing exceeded the depth limit for queries";
 				let locations = Location::range_of_span(source, at);
 				let snippet = Snippet::from_source_location_range(source, locations, None);
-				RenderedError {
-					text: text.to_string(),
-					snippets: vec![snippet],
-				}
+				RenderedError::new(text.to_string(), vec![snippet])
 			}
 			ParseErrorKind::MissingField {
 				field,
@@ -305,28 +348,19 @@ impl ParseError {
 					locations,
 					Some("Idiom missing here"),
 				);
-				RenderedError {
-					text: text.to_string(),
-					snippets: vec![snippet_error, snippet_hint],
-				}
+				RenderedError::new(text.to_string(), vec![snippet_error, snippet_hint])
 			}
 			ParseErrorKind::DurationOverflow => {
 				let text = "Duration specified exceeds maximum allowed value";
 				let locations = Location::range_of_span(source, at);
 				let snippet = Snippet::from_source_location_range(source, locations, None);
-				RenderedError {
-					text: text.to_string(),
-					snippets: vec![snippet],
-				}
+				RenderedError::new(text.to_string(), vec![snippet])
 			}
 			ParseErrorKind::InvalidIdent => {
 				let text = "Duration specified exceeds maximum allowed value";
 				let locations = Location::range_of_span(source, at);
 				let snippet = Snippet::from_source_location_range(source, locations, None);
-				RenderedError {
-					text: text.to_string(),
-					snippets: vec![snippet],
-				}
+				RenderedError::new(text.to_string(), vec![snippet])
 			}
 			ParseErrorKind::InvalidUuidPart {
 				len,
@@ -336,10 +370,7 @@ impl ParseError {
 				);
 				let locations = Location::range_of_span(source, at);
 				let snippet = Snippet::from_source_location_range(source, locations, None);
-				RenderedError {
-					text,
-					snippets: vec![snippet],
-				}
+				RenderedError::new(text, vec![snippet])
 			}
 			ParseErrorKind::InvalidDatetimePart {
 				len,
@@ -349,10 +380,7 @@ impl ParseError {
 				);
 				let locations = Location::range_of_span(source, at);
 				let snippet = Snippet::from_source_location_range(source, locations, None);
-				RenderedError {
-					text,
-					snippets: vec![snippet],
-				}
+				RenderedError::new(text, vec![snippet])
 			}
 			ParseErrorKind::OutrangeDatetimePart {
 				range,
@@ -364,10 +392,7 @@ impl ParseError {
 				);
 				let locations = Location::range_of_span(source, at);
 				let snippet = Snippet::from_source_location_range(source, locations, None);
-				RenderedError {
-					text,
-					snippets: vec![snippet],
-				}
+				RenderedError::new(text, vec![snippet])
 			}
 			ParseErrorKind::TooManyNanosecondsDatetime => {
 				let text = "Too many digits in Datetime nanoseconds".to_owned();
@@ -377,28 +402,19 @@ impl ParseError {
 					locations,
 					Some("Nanoseconds can at most be 9 characters"),
 				);
-				RenderedError {
-					text,
-					snippets: vec![snippet],
-				}
+				RenderedError::new(text, vec![snippet])
 			}
 			ParseErrorKind::InvalidDatetimeDate => {
 				let text = "Invalid Datetime date".to_owned();
 				let locations = Location::range_of_span(source, at);
 				let snippet = Snippet::from_source_location_range(source, locations, None);
-				RenderedError {
-					text,
-					snippets: vec![snippet],
-				}
+				RenderedError::new(text, vec![snippet])
 			}
 			ParseErrorKind::InvalidDatetimeTime => {
 				let text = "Datetime time outside of valid time range".to_owned();
 				let locations = Location::range_of_span(source, at);
 				let snippet = Snippet::from_source_location_range(source, locations, None);
-				RenderedError {
-					text,
-					snippets: vec![snippet],
-				}
+				RenderedError::new(text, vec![snippet])
 			}
 		}
 	}
@@ -3,7 +3,7 @@
 use reblessive::Stk;
 
 use super::mac::unexpected;
-use super::ParseError;
+use super::{Expected, ParseError};
 use crate::sql::{value::TryNeg, Cast, Expression, Number, Operator, Value};
 use crate::syn::token::Token;
 use crate::syn::{
@@ -216,7 +216,7 @@ impl Parser<'_> {
 					return Err(ParseError::new(
 						ParseErrorKind::UnexpectedExplain {
 							found: token.kind,
-							expected: "a distance or an integer",
+							expected: Expected::OneOf(&["a distance", "an integer"]),
 							explain: "The NN operator accepts either a distance for brute force operation, or an EF value for approximate operations",
 						},
 						token.span,
@@ -327,7 +327,7 @@ impl Parser<'_> {
 					return Err(ParseError::new(
 							    ParseErrorKind::UnexpectedExplain {
 								    found: token.kind,
-								    expected: "an operator",
+								    expected: "an operator".into(),
 								    explain: "assignement operator are only allowed in SET and DUPLICATE KEY UPDATE statements",
 							    },
 							    token.span,
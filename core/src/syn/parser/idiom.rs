@@ -106,7 +106,7 @@ impl Parser<'_> {
 					return Err(ParseError::new(
 						ParseErrorKind::UnexpectedExplain {
 							found: t!(".."),
-							expected: "an idiom",
+							expected: "an idiom".into(),
 							explain: "Did you maybe mean the flatten operator `...`",
 						},
 						self.last_span(),
@@ -167,7 +167,7 @@ impl Parser<'_> {
 					return Err(ParseError::new(
 						ParseErrorKind::UnexpectedExplain {
 							found: t!(".."),
-							expected: "an idiom",
+							expected: "an idiom".into(),
 							explain: "Did you maybe mean the flatten operator `...`",
 						},
 						self.last_span(),
@@ -272,9 +272,9 @@ impl Parser<'_> {
 			}
 			t!("-") => {
 				if let TokenKind::Digits = self.peek_whitespace_token_at(1).kind {
-					unexpected!(self, t!("-"),"$, * or a number" => "an index can't be negative");
+					unexpected!(self, t!("-"),&["$", "*", "a number"] => "an index can't be negative");
 				}
-				unexpected!(self, t!("-"), "$, * or a number");
+				unexpected!(self, t!("-"), &["$", "*", "a number"]);
 			}
 			t!("?") | t!("WHERE") => {
 				self.pop_peek();
@@ -334,11 +334,11 @@ impl Parser<'_> {
 							let peek_digit = self.peek_whitespace_token_at(1);
 							if let TokenKind::Digits = peek_digit.kind {
 								let span = self.recent_span().covers(peek_digit.span);
-								unexpected!(@ span, self, t!("-"),"$, * or a number" => "an index can't be negative");
+								unexpected!(@ span, self, t!("-"),&["$", "*", "a number"] => "an index can't be negative");
 							}
-							unexpected!(self, t!("-"), "$, * or a number");
+							unexpected!(self, t!("-"), &["$", "*", "a number"]);
 						}
-						x => unexpected!(self, x, "$, * or a number"),
+						x => unexpected!(self, x, &["$", "*", "a number"]),
 					};
 					self.expect_closing_delimiter(t!("]"), token.span)?;
 					res
@@ -380,11 +380,11 @@ impl Parser<'_> {
 							let peek_digit = self.peek_whitespace_token_at(1);
 							if let TokenKind::Digits = peek_digit.kind {
 								let span = self.recent_span().covers(peek_digit.span);
-								unexpected!(@ span, self, t!("-"),"$, * or a number" => "an index can't be negative");
+								unexpected!(@ span, self, t!("-"),&["$", "*", "a number"] => "an index can't be negative");
 							}
-							unexpected!(self, t!("-"), "$, * or a number");
+							unexpected!(self, t!("-"), &["$", "*", "a number"]);
 						}
-						x => unexpected!(self, x, "$, * or a number"),
+						x => unexpected!(self, x, &["$", "*", "a number"]),
 					};
 					self.expect_closing_delimiter(t!("]"), token.span)?;
 					res
@@ -402,7 +402,7 @@ impl Parser<'_> {
 				return Err(ParseError::new(
 					ParseErrorKind::UnexpectedExplain {
 						found: t!("..."),
-						expected: "local idiom to end.",
+						expected: "local idiom to end.".into(),
 						explain: "Flattening can only be done at the end of a local idiom.",
 					},
 					span,
@@ -476,7 +476,7 @@ impl Parser<'_> {
 						}
 						tables
 					}
-					x => unexpected!(self, x, "`?` or an identifier"),
+					x => unexpected!(self, x, &["?", "an identifier"]),
 				};
 
 				let cond = self.try_parse_condition(ctx).await?;
@@ -508,7 +508,7 @@ impl Parser<'_> {
 					..Default::default()
 				})
 			}
-			x => unexpected!(self, x, "`?`, `(` or an identifier"),
+			x => unexpected!(self, x, &["?", "(", "an identifier"]),
 		}
 	}
 }
@@ -19,7 +19,7 @@ macro_rules! unexpected {
 				));
 			}
 			$crate::syn::token::TokenKind::Eof => {
-				let expected = $expected;
+				let expected = $crate::syn::parser::Expected::from($expected);
 				return Err($crate::syn::parser::ParseError::new(
 					$crate::syn::parser::ParseErrorKind::UnexpectedEof {
 						expected,
@@ -28,7 +28,7 @@ macro_rules! unexpected {
 				));
 			}
 			x => {
-				let expected = $expected;
+				let expected = $crate::syn::parser::Expected::from($expected);
 				return Err($crate::syn::parser::ParseError::new(
 					$crate::syn::parser::ParseErrorKind::Unexpected {
 						found: x,
@@ -50,7 +50,7 @@ macro_rules! unexpected {
 				));
 			}
 			$crate::syn::token::TokenKind::Eof => {
-				let expected = $expected;
+				let expected = $crate::syn::parser::Expected::from($expected);
 				return Err($crate::syn::parser::ParseError::new(
 					$crate::syn::parser::ParseErrorKind::UnexpectedEof {
 						expected,
@@ -59,7 +59,7 @@ macro_rules! unexpected {
 				));
 			}
 			x => {
-				let expected = $expected;
+				let expected = $crate::syn::parser::Expected::from($expected);
 				return Err($crate::syn::parser::ParseError::new(
 					$crate::syn::parser::ParseErrorKind::UnexpectedExplain {
 						found: x,
@@ -87,7 +87,7 @@ macro_rules! expected {
 				));
 			}
 			x => {
-				let expected = $($kind)*.as_str();
+				let expected = $crate::syn::parser::Expected::from($($kind)*.as_str());
 				let kind = if let $crate::syn::token::TokenKind::Eof = x {
 					$crate::syn::parser::ParseErrorKind::UnexpectedEof {
 						expected,
@@ -119,7 +119,7 @@ macro_rules! expected_whitespace {
 				));
 			}
 			x => {
-				let expected = $($kind)*.as_str();
+				let expected = $crate::syn::parser::Expected::from($($kind)*.as_str());
 				let kind = if let $crate::syn::token::TokenKind::Eof = x {
 					$crate::syn::parser::ParseErrorKind::UnexpectedEof {
 						expected,
@@ -80,7 +80,7 @@ mod token_buffer;
 #[cfg(test)]
 pub mod test;
 
-pub use error::{IntErrorKind, ParseError, ParseErrorKind};
+pub use error::{Expected, IntErrorKind, ParseError, ParseErrorKind};
 
 /// The result returned by most parser function.
 pub type ParseResult<T> = Result<T, ParseError>;
@@ -191,6 +191,11 @@ impl<'a> Parser<'a> {
 		}
 	}
 
+	/// Returns whether any comments have been skipped in the source so far.
+	pub fn has_comments(&self) -> bool {
+		self.lexer.has_comments()
+	}
+
 	/// Returns the next token and advance the parser one token forward.
 	#[allow(clippy::should_implement_trait)]
 	pub fn next(&mut self) -> Token {
@@ -383,6 +388,18 @@ impl<'a> Parser<'a> {
 		self.parse_stmt(ctx).await
 	}
 
+	/// Parse a single statement which makes up the full input.
+	///
+	/// Unlike [`Parser::parse_statement`] this errors if anything other than semicolons follows
+	/// the statement.
+	pub async fn parse_full_statement(&mut self, ctx: &mut Stk) -> ParseResult<sql::Statement> {
+		while self.eat(t!(";")) {}
+		let stmt = ctx.run(|ctx| self.parse_stmt(ctx)).await?;
+		while self.eat(t!(";")) {}
+		expected!(self, t!("eof"));
+		Ok(stmt)
+	}
+
 	/// Parse a possibly partial statement.
 	///
 	/// This will try to parse a statement if a full statement can be parsed from the buffer parser
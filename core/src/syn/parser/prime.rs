@@ -232,6 +232,13 @@ impl Parser<'_> {
 					Value::Subquery(Box::new(Subquery::Ifelse(stmt)))
 				})
 			}
+			t!("CASE") if self.peek_token_at(1).kind == t!("WHEN") => {
+				enter_query_recursion!(this = self => {
+					this.pop_peek();
+					let stmt = ctx.run(|ctx| this.parse_case_expr(ctx)).await?;
+					Value::Subquery(Box::new(Subquery::Ifelse(stmt)))
+				})
+			}
 			t!("(") => {
 				self.pop_peek();
 				self.parse_inner_subquery_or_coordinate(ctx, token.span).await?
@@ -442,7 +449,7 @@ impl Parser<'_> {
 							return Err(ParseError::new(
 								ParseErrorKind::UnexpectedExplain {
 									found: TokenKind::Digits,
-									expected: "a non-decimal, non-nan number",
+									expected: "a non-decimal, non-nan number".into(),
 									explain: "coordinate numbers can't be NaN or a decimal",
 								},
 								number_token.span,
@@ -452,7 +459,7 @@ impl Parser<'_> {
 							return Err(ParseError::new(
 								ParseErrorKind::UnexpectedExplain {
 									found: TokenKind::Digits,
-									expected: "a non-decimal, non-nan number",
+									expected: "a non-decimal, non-nan number".into(),
 									explain: "coordinate numbers can't be NaN or a decimal",
 								},
 								number_token.span,
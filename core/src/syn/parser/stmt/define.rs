@@ -7,6 +7,7 @@ use crate::{
 		access_type,
 		base::Base,
 		filter::Filter,
+		id::Gen,
 		index::{Distance, VectorType},
 		statements::{
 			DefineAccessStatement, DefineAnalyzerStatement, DefineDatabaseStatement,
@@ -16,13 +17,13 @@ use crate::{
 		},
 		table_type,
 		tokenizer::Tokenizer,
-		user, AccessType, Ident, Idioms, Index, Kind, Param, Permissions, Scoring, Strand,
+		user, AccessType, AllowIp, Ident, Idioms, Index, Kind, Param, Permissions, Scoring, Strand,
 		TableType, Values,
 	},
 	syn::{
 		parser::{
 			mac::{expected, unexpected},
-			ParseResult, Parser,
+			ParseError, ParseErrorKind, ParseResult, Parser,
 		},
 		token::{t, Keyword, TokenKind},
 	},
@@ -68,9 +69,18 @@ impl Parser<'_> {
 			..Default::default()
 		};
 
-		while let t!("COMMENT") = self.peek_kind() {
-			self.pop_peek();
-			res.comment = Some(self.next_token_value()?);
+		loop {
+			match self.peek_kind() {
+				t!("COMMENT") => {
+					self.pop_peek();
+					res.comment = Some(self.next_token_value()?);
+				}
+				t!("ALLOW") => {
+					self.pop_peek();
+					res.allow = self.parse_allow_ip()?;
+				}
+				_ => break,
+			}
 		}
 
 		Ok(res)
@@ -203,6 +213,10 @@ impl Parser<'_> {
 					self.pop_peek();
 					res.set_passhash(self.next_token_value::<Strand>()?.0);
 				}
+				t!("ALLOW") => {
+					self.pop_peek();
+					res.allow = self.parse_allow_ip()?;
+				}
 				t!("ROLES") => {
 					self.pop_peek();
 					res.roles = vec![self.next_token_value()?];
@@ -312,6 +326,14 @@ impl Parser<'_> {
 						_ => break,
 					}
 				}
+				t!("AUTHENTICATE") => {
+					self.pop_peek();
+					res.authenticate = Some(stk.run(|stk| self.parse_value(stk)).await?);
+				}
+				t!("ALLOW") => {
+					self.pop_peek();
+					res.allow = self.parse_allow_ip()?;
+				}
 				t!("DURATION") => {
 					self.pop_peek();
 					while self.eat(t!("FOR")) {
@@ -417,7 +439,7 @@ impl Parser<'_> {
 										},
 									);
 								}
-								x => unexpected!(self, x, "a token algorithm or 'JWKS'"),
+								x => unexpected!(self, x, &["a token algorithm", "JWKS"]),
 							}
 						}
 						_ => break,
@@ -459,7 +481,7 @@ impl Parser<'_> {
 										},
 									);
 								}
-								x => unexpected!(self, x, "a token algorithm or 'JWKS'"),
+								x => unexpected!(self, x, &["a token algorithm", "JWKS"]),
 							}
 						}
 						_ => break,
@@ -584,6 +606,15 @@ impl Parser<'_> {
 					self.pop_peek();
 					res.drop = true;
 				}
+				t!("ID") => {
+					self.pop_peek();
+					res.generate = Some(match self.next().kind {
+						t!("RAND") => Gen::Rand,
+						t!("ULID") => Gen::Ulid,
+						t!("UUID") => Gen::Uuid,
+						x => unexpected!(self, x, &["RAND", "ULID", "UUID"]),
+					});
+				}
 				t!("TYPE") => {
 					self.pop_peek();
 					match self.peek_kind() {
@@ -599,7 +630,7 @@ impl Parser<'_> {
 							self.pop_peek();
 							res.kind = TableType::Any;
 						}
-						x => unexpected!(self, x, "`NORMAL`, `RELATION`, or `ANY`"),
+						x => unexpected!(self, x, &["NORMAL", "RELATION", "ANY"]),
 					}
 				}
 				t!("SCHEMALESS") => {
@@ -629,7 +660,7 @@ impl Parser<'_> {
 						t!("SELECT") => {
 							res.view = Some(self.parse_view(ctx).await?);
 						}
-						x => unexpected!(self, x, "`SELECT`"),
+						x => unexpected!(self, x, "SELECT"),
 					}
 				}
 				_ => break,
@@ -1187,7 +1218,7 @@ impl Parser<'_> {
 					url,
 				});
 			}
-			x => unexpected!(self, x, "`ALGORITHM`, or `URL`"),
+			x => unexpected!(self, x, &["ALGORITHM", "URL"]),
 		}
 
 		if self.eat(t!("WITH")) {
@@ -1204,7 +1235,7 @@ impl Parser<'_> {
 										unexpected!(
 											self,
 											t!("ALGORITHM"),
-											"a compatible algorithm or no algorithm"
+											&["a compatible algorithm", "no algorithm"]
 										);
 									}
 								}
@@ -1219,7 +1250,7 @@ impl Parser<'_> {
 						// If the algorithm is symmetric and a key is already defined, a different key is not expected.
 						if let JwtAccessVerify::Key(ref ver) = res.verify {
 							if ver.alg.is_symmetric() && key != ver.key {
-								unexpected!(self, t!("KEY"), "a symmetric key or no key");
+								unexpected!(self, t!("KEY"), &["a symmetric key", "no key"]);
 							}
 						}
 						iss.key = key;
@@ -1232,4 +1263,21 @@ impl Parser<'_> {
 
 		Ok(res)
 	}
+
+	pub fn parse_allow_ip(&mut self) -> ParseResult<AllowIp> {
+		expected!(self, t!("IP"));
+		let mut res = AllowIp::default();
+		loop {
+			let v = self.next_token_value::<Strand>()?;
+			// Check that the address or range is valid
+			if AllowIp::parse(&v.0).is_none() {
+				return Err(ParseError::new(ParseErrorKind::InvalidNetwork, self.last_span()));
+			}
+			res.0.push(v);
+			if !self.eat(t!(",")) {
+				break;
+			}
+		}
+		Ok(res)
+	}
 }
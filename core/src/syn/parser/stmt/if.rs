@@ -33,12 +33,42 @@ impl Parser<'_> {
 				res.exprs.push((condition, body.into()));
 				self.parse_bracketed_tail(ctx, &mut res).await?;
 			}
-			x => unexpected!(self, x, "THEN or '{'"),
+			x => unexpected!(self, x, &["THEN", "{"]),
 		}
 
 		Ok(res)
 	}
 
+	/// Parses a CASE expression into the equivalent IF statement.
+	///
+	/// # Parser State
+	/// Expects `CASE` to already be consumed.
+	pub async fn parse_case_expr(&mut self, ctx: &mut Stk) -> ParseResult<IfelseStatement> {
+		let mut res = IfelseStatement {
+			exprs: Vec::new(),
+			close: None,
+		};
+
+		expected!(self, t!("WHEN"));
+		loop {
+			let condition = ctx.run(|ctx| self.parse_value_field(ctx)).await?;
+			expected!(self, t!("THEN"));
+			let body = ctx.run(|ctx| self.parse_value_field(ctx)).await?;
+			res.exprs.push((condition, body));
+			match self.next().kind {
+				t!("WHEN") => {}
+				t!("ELSE") => {
+					let value = ctx.run(|ctx| self.parse_value_field(ctx)).await?;
+					expected!(self, t!("END"));
+					res.close = Some(value);
+					return Ok(res);
+				}
+				t!("END") => return Ok(res),
+				x => unexpected!(self, x, &["WHEN", "ELSE", "END"]),
+			}
+		}
+	}
+
 	async fn parse_worded_tail(
 		&mut self,
 		ctx: &mut Stk,
@@ -6,7 +6,8 @@ use crate::sql::statements::rebuild::{RebuildIndexStatement, RebuildStatement};
 use crate::sql::statements::show::{ShowSince, ShowStatement};
 use crate::sql::statements::sleep::SleepStatement;
 use crate::sql::statements::{
-	KillStatement, LiveStatement, OptionStatement, SetStatement, ThrowStatement,
+	KillStatement, LiveStatement, OptionStatement, RenameFieldStatement, RenameStatement,
+	RenameTableStatement, RevokeStatement, SetStatement, ThrowStatement,
 };
 use crate::sql::{Fields, Ident, Param};
 use crate::syn::parser::{ParseError, ParseErrorKind};
@@ -61,7 +62,7 @@ impl Parser<'_> {
 							return Err(ParseError::new(
 								ParseErrorKind::UnexpectedExplain {
 									found: self.peek_kind(),
-									expected: "the query to end",
+									expected: "the query to end".into(),
 									explain:
 										"maybe forgot a semicolon after the previous statement?",
 								},
@@ -94,7 +95,8 @@ impl Parser<'_> {
 				| t!("LET") | t!("SHOW")
 				| t!("SLEEP") | t!("THROW")
 				| t!("UPDATE") | t!("UPSERT")
-				| t!("USE")
+				| t!("USE") | t!("REVOKE")
+				| t!("RENAME") | t!("ALTER")
 		)
 	}
 
@@ -187,6 +189,18 @@ impl Parser<'_> {
 				self.pop_peek();
 				self.parse_remove_stmt().map(Statement::Remove)
 			}
+			t!("RENAME") => {
+				self.pop_peek();
+				self.parse_rename_stmt().map(Statement::Rename)
+			}
+			t!("ALTER") => {
+				self.pop_peek();
+				self.parse_alter_stmt().map(Statement::Rename)
+			}
+			t!("REVOKE") => {
+				self.pop_peek();
+				ctx.run(|ctx| self.parse_revoke_stmt(ctx)).await.map(Statement::Revoke)
+			}
 			t!("SELECT") => {
 				self.pop_peek();
 				ctx.run(|ctx| self.parse_select_stmt(ctx)).await.map(Statement::Select)
@@ -460,6 +474,7 @@ impl Parser<'_> {
 				let base = self.eat(t!("ON")).then(|| self.parse_base(false)).transpose()?;
 				InfoStatement::User(ident, base, false)
 			}
+			t!("SESSION") => InfoStatement::Sessions,
 			x => unexpected!(self, x, "an info target"),
 		};
 
@@ -478,7 +493,7 @@ impl Parser<'_> {
 		let id = match self.peek_kind() {
 			t!("u\"") | t!("u'") => self.next_token_value().map(Value::Uuid)?,
 			t!("$param") => self.next_token_value().map(Value::Param)?,
-			x => unexpected!(self, x, "a UUID or a parameter"),
+			x => unexpected!(self, x, &["a UUID", "a parameter"]),
 		};
 		Ok(KillStatement {
 			id,
@@ -520,7 +535,7 @@ impl Parser<'_> {
 			match self.next().kind {
 				t!("true") => true,
 				t!("false") => false,
-				x => unexpected!(self, x, "either 'true' or 'false'"),
+				x => unexpected!(self, x, &["true", "false"]),
 			}
 		} else {
 			true
@@ -556,6 +571,129 @@ impl Parser<'_> {
 		Ok(res)
 	}
 
+	/// Parsers a RENAME statement.
+	///
+	/// # Parser State
+	/// Expects `RENAME` to already be consumed.
+	pub fn parse_rename_stmt(&mut self) -> ParseResult<RenameStatement> {
+		let res = match self.next().kind {
+			t!("TABLE") => {
+				let if_exists = if self.eat(t!("IF")) {
+					expected!(self, t!("EXISTS"));
+					true
+				} else {
+					false
+				};
+				let name = self.next_token_value()?;
+				expected!(self, t!("TO"));
+				let into = self.next_token_value()?;
+
+				RenameStatement::Table(RenameTableStatement {
+					name,
+					into,
+					if_exists,
+				})
+			}
+			t!("FIELD") => {
+				let if_exists = if self.eat(t!("IF")) {
+					expected!(self, t!("EXISTS"));
+					true
+				} else {
+					false
+				};
+				let name = self.parse_local_idiom()?;
+				expected!(self, t!("ON"));
+				self.eat(t!("TABLE"));
+				let what = self.next_token_value()?;
+				expected!(self, t!("TO"));
+				let into = self.parse_local_idiom()?;
+
+				RenameStatement::Field(RenameFieldStatement {
+					name,
+					what,
+					into,
+					if_exists,
+				})
+			}
+			x => unexpected!(self, x, "a rename statement keyword"),
+		};
+		Ok(res)
+	}
+
+	/// Parsers an ALTER statement, which renames a table or field.
+	///
+	/// # Parser State
+	/// Expects `ALTER` to already be consumed.
+	pub fn parse_alter_stmt(&mut self) -> ParseResult<RenameStatement> {
+		let res = match self.next().kind {
+			t!("TABLE") => {
+				let if_exists = if self.eat(t!("IF")) {
+					expected!(self, t!("EXISTS"));
+					true
+				} else {
+					false
+				};
+				let name = self.next_token_value()?;
+				expected!(self, t!("RENAME"));
+				expected!(self, t!("TO"));
+				let into = self.next_token_value()?;
+
+				RenameStatement::Table(RenameTableStatement {
+					name,
+					into,
+					if_exists,
+				})
+			}
+			t!("FIELD") => {
+				let if_exists = if self.eat(t!("IF")) {
+					expected!(self, t!("EXISTS"));
+					true
+				} else {
+					false
+				};
+				let name = self.parse_local_idiom()?;
+				expected!(self, t!("ON"));
+				self.eat(t!("TABLE"));
+				let what = self.next_token_value()?;
+				expected!(self, t!("RENAME"));
+				expected!(self, t!("TO"));
+				let into = self.parse_local_idiom()?;
+
+				RenameStatement::Field(RenameFieldStatement {
+					name,
+					what,
+					into,
+					if_exists,
+				})
+			}
+			x => unexpected!(self, x, "an alter statement keyword"),
+		};
+		Ok(res)
+	}
+
+	/// Parsers a REVOKE statement.
+	///
+	/// # Parser State
+	/// Expects `REVOKE` to already be consumed.
+	pub(crate) async fn parse_revoke_stmt(
+		&mut self,
+		ctx: &mut Stk,
+	) -> ParseResult<RevokeStatement> {
+		let res = match self.next().kind {
+			t!("TOKEN") => RevokeStatement::Token(ctx.run(|ctx| self.parse_value(ctx)).await?),
+			t!("SESSION") => RevokeStatement::Session(ctx.run(|ctx| self.parse_value(ctx)).await?),
+			t!("USER") => {
+				let name = self.next_token_value()?;
+				expected!(self, t!("ON"));
+				let base = self.parse_base(false)?;
+				RevokeStatement::User(name, base)
+			}
+			t!("RECORD") => RevokeStatement::Record(ctx.run(|ctx| self.parse_value(ctx)).await?),
+			x => unexpected!(self, x, &["TOKEN", "SESSION", "USER", "RECORD"]),
+		};
+		Ok(res)
+	}
+
 	/// Parsers a RETURN statement.
 	///
 	/// # Parser State
@@ -605,7 +743,7 @@ impl Parser<'_> {
 				Some(table)
 			}
 			t!("DATABASE") => None,
-			x => unexpected!(self, x, "`TABLE` or `DATABASE`"),
+			x => unexpected!(self, x, &["TABLE", "DATABASE"]),
 		};
 
 		expected!(self, t!("SINCE"));
@@ -616,7 +754,7 @@ impl Parser<'_> {
 				ShowSince::Versionstamp(self.next_token_value()?)
 			}
 			t!("d\"") | t!("d'") => ShowSince::Timestamp(self.next_token_value()?),
-			x => unexpected!(self, x, "a version stamp or a date-time"),
+			x => unexpected!(self, x, &["a version stamp", "a date-time"]),
 		};
 
 		let limit = self.eat(t!("LIMIT")).then(|| self.next_token_value()).transpose()?;
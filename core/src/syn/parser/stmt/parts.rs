@@ -235,7 +235,7 @@ impl Parser<'_> {
 				}
 				Ok(permission)
 			}
-			x => unexpected!(self, x, "'NONE', 'FULL' or 'FOR'"),
+			x => unexpected!(self, x, &["NONE", "FULL", "FOR"]),
 		}
 	}
 
@@ -269,7 +269,7 @@ impl Parser<'_> {
 				t!("DELETE") => {
 					delete = true;
 				}
-				x => unexpected!(self, x, "'SELECT', 'CREATE', 'UPDATE' or 'DELETE'"),
+				x => unexpected!(self, x, &["SELECT", "CREATE", "UPDATE", "DELETE"]),
 			}
 			if !self.eat(t!(",")) {
 				break;
@@ -303,7 +303,7 @@ impl Parser<'_> {
 			t!("NONE") => Ok(Permission::None),
 			t!("FULL") => Ok(Permission::Full),
 			t!("WHERE") => Ok(Permission::Specific(self.parse_value_field(stk).await?)),
-			x => unexpected!(self, x, "'NONE', 'FULL', or 'WHERE'"),
+			x => unexpected!(self, x, &["NONE", "FULL", "WHERE"]),
 		}
 	}
 
@@ -328,9 +328,9 @@ impl Parser<'_> {
 			}
 			x => {
 				if scope_allowed {
-					unexpected!(self, x, "'NAMEPSPACE', 'DATABASE', 'ROOT' or 'SCOPE'")
+					unexpected!(self, x, &["NAMESPACE", "DATABASE", "ROOT", "SCOPE"])
 				} else {
-					unexpected!(self, x, "'NAMEPSPACE', 'DATABASE' or 'ROOT'")
+					unexpected!(self, x, &["NAMESPACE", "DATABASE", "ROOT"])
 				}
 			}
 		}
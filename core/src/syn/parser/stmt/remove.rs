@@ -6,7 +6,7 @@ use crate::{
 			RemoveIndexStatement, RemoveNamespaceStatement, RemoveParamStatement, RemoveStatement,
 			RemoveUserStatement,
 		},
-		Param,
+		Base, Param,
 	},
 	syn::{
 		parser::{
@@ -83,6 +83,44 @@ impl Parser<'_> {
 					if_exists,
 				})
 			}
+			// REMOVE TOKEN is now REMOVE ACCESS
+			t!("TOKEN") => {
+				let if_exists = if self.eat(t!("IF")) {
+					expected!(self, t!("EXISTS"));
+					true
+				} else {
+					false
+				};
+				let name = self.next_token_value()?;
+				expected!(self, t!("ON"));
+				// Tokens defined on a scope are now part of a database access method
+				let base = match self.parse_base(true)? {
+					Base::Sc(_) => Base::Db,
+					base => base,
+				};
+
+				RemoveStatement::Access(RemoveAccessStatement {
+					name,
+					base,
+					if_exists,
+				})
+			}
+			// REMOVE SCOPE is now REMOVE ACCESS ON DATABASE
+			t!("SCOPE") => {
+				let if_exists = if self.eat(t!("IF")) {
+					expected!(self, t!("EXISTS"));
+					true
+				} else {
+					false
+				};
+				let name = self.next_token_value()?;
+
+				RemoveStatement::Access(RemoveAccessStatement {
+					name,
+					base: Base::Db,
+					if_exists,
+				})
+			}
 			t!("PARAM") => {
 				let if_exists = if self.eat(t!("IF")) {
 					expected!(self, t!("EXISTS"));
@@ -99,7 +99,7 @@ impl Parser<'_> {
 				}
 				With::Index(index)
 			}
-			x => unexpected!(self, x, "`NO`, `NOINDEX` or `INDEX`"),
+			x => unexpected!(self, x, &["NO", "NOINDEX", "INDEX"]),
 		};
 		Ok(Some(with))
 	}
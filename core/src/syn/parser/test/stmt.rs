@@ -8,6 +8,7 @@ use crate::{
 		block::Entry,
 		changefeed::ChangeFeed,
 		filter::Filter,
+		id::Gen,
 		index::{Distance, HnswParams, MTreeParams, SearchParams, VectorType},
 		language::Language,
 		statements::{
@@ -24,16 +25,17 @@ use crate::{
 			RemoveAnalyzerStatement, RemoveDatabaseStatement, RemoveEventStatement,
 			RemoveFieldStatement, RemoveFunctionStatement, RemoveIndexStatement,
 			RemoveNamespaceStatement, RemoveParamStatement, RemoveStatement, RemoveTableStatement,
-			RemoveUserStatement, SelectStatement, SetStatement, ThrowStatement, UpdateStatement,
+			RemoveUserStatement, RenameFieldStatement, RenameStatement, RenameTableStatement,
+			RevokeStatement, SelectStatement, SetStatement, ThrowStatement, UpdateStatement,
 			UpsertStatement, UseStatement,
 		},
 		tokenizer::Tokenizer,
 		user::UserDuration,
-		Algorithm, Array, Base, Block, Cond, Data, Datetime, Dir, Duration, Edges, Explain,
-		Expression, Fetch, Fetchs, Field, Fields, Future, Graph, Group, Groups, Id, Ident, Idiom,
-		Idioms, Index, Kind, Limit, Number, Object, Operator, Order, Orders, Output, Param, Part,
-		Permission, Permissions, Scoring, Split, Splits, Start, Statement, Strand, Subquery, Table,
-		TableType, Tables, Thing, Timeout, Uuid, Value, Values, Version, With,
+		Algorithm, AllowIp, Array, Base, Block, Cond, Data, Datetime, Dir, Duration, Edges,
+		Explain, Expression, Fetch, Fetchs, Field, Fields, Future, Graph, Group, Groups, Id, Ident,
+		Idiom, Idioms, Index, Kind, Limit, Number, Object, Operator, Order, Orders, Output, Param,
+		Part, Permission, Permissions, Scoring, Split, Splits, Start, Statement, Strand, Subquery,
+		Table, TableType, Tables, Thing, Timeout, Uuid, Value, Values, Version, With,
 	},
 	syn::parser::mac::test_parse,
 };
@@ -131,6 +133,7 @@ fn parse_define_namespace() {
 			name: Ident("a".to_string()),
 			comment: Some(Strand("test".to_string())),
 			if_not_exists: false,
+			allow: AllowIp::default(),
 		}))
 	);
 
@@ -142,10 +145,28 @@ fn parse_define_namespace() {
 			name: Ident("a".to_string()),
 			comment: None,
 			if_not_exists: false,
+			allow: AllowIp::default(),
 		}))
 	)
 }
 
+#[test]
+fn parse_define_namespace_allow_ip() {
+	let res = test_parse!(parse_stmt, "DEFINE NS a ALLOW IP '10.0.0.0/8', '::1'").unwrap();
+	assert_eq!(
+		res,
+		Statement::Define(DefineStatement::Namespace(DefineNamespaceStatement {
+			id: None,
+			name: Ident("a".to_string()),
+			comment: None,
+			if_not_exists: false,
+			allow: AllowIp(vec![Strand("10.0.0.0/8".to_string()), Strand("::1".to_string())]),
+		}))
+	);
+
+	test_parse!(parse_stmt, "DEFINE NS a ALLOW IP '10.0.0.0/33'").unwrap_err();
+}
+
 #[test]
 fn parse_define_database() {
 	let res =
@@ -374,6 +395,8 @@ fn parse_define_token() {
 			},
 			comment: Some(Strand("bar".to_string())),
 			if_not_exists: false,
+			allow: AllowIp::default(),
+			authenticate: None,
 		})),
 	)
 }
@@ -449,6 +472,8 @@ fn parse_define_token_jwks() {
 			},
 			comment: Some(Strand("bar".to_string())),
 			if_not_exists: false,
+			allow: AllowIp::default(),
+			authenticate: None,
 		})),
 	)
 }
@@ -574,6 +599,8 @@ fn parse_define_access_jwt_key() {
 				},
 				comment: Some(Strand("bar".to_string())),
 				if_not_exists: false,
+				allow: AllowIp::default(),
+				authenticate: None,
 			})),
 		)
 	}
@@ -607,6 +634,8 @@ fn parse_define_access_jwt_key() {
 				},
 				comment: None,
 				if_not_exists: false,
+				allow: AllowIp::default(),
+				authenticate: None,
 			})),
 		)
 	}
@@ -640,6 +669,8 @@ fn parse_define_access_jwt_key() {
 				},
 				comment: None,
 				if_not_exists: false,
+				allow: AllowIp::default(),
+				authenticate: None,
 			})),
 		)
 	}
@@ -672,6 +703,8 @@ fn parse_define_access_jwt_key() {
 				},
 				comment: None,
 				if_not_exists: false,
+				allow: AllowIp::default(),
+				authenticate: None,
 			})),
 		)
 	}
@@ -705,6 +738,8 @@ fn parse_define_access_jwt_key() {
 				},
 				comment: None,
 				if_not_exists: false,
+				allow: AllowIp::default(),
+				authenticate: None,
 			})),
 		)
 	}
@@ -786,6 +821,8 @@ fn parse_define_access_jwt_jwks() {
 				},
 				comment: Some(Strand("bar".to_string())),
 				if_not_exists: false,
+				allow: AllowIp::default(),
+				authenticate: None,
 			})),
 		)
 	}
@@ -818,6 +855,8 @@ fn parse_define_access_jwt_jwks() {
 				},
 				comment: None,
 				if_not_exists: false,
+				allow: AllowIp::default(),
+				authenticate: None,
 			})),
 		)
 	}
@@ -849,6 +888,8 @@ fn parse_define_access_jwt_jwks() {
 				},
 				comment: None,
 				if_not_exists: false,
+				allow: AllowIp::default(),
+				authenticate: None,
 			})),
 		)
 	}
@@ -881,6 +922,8 @@ fn parse_define_access_jwt_jwks() {
 				},
 				comment: None,
 				if_not_exists: false,
+				allow: AllowIp::default(),
+				authenticate: None,
 			})),
 		)
 	}
@@ -912,6 +955,8 @@ fn parse_define_access_jwt_jwks() {
 				},
 				comment: None,
 				if_not_exists: false,
+				allow: AllowIp::default(),
+				authenticate: None,
 			})),
 		)
 	}
@@ -1043,6 +1088,8 @@ fn parse_define_access_record() {
 				},
 				comment: None,
 				if_not_exists: false,
+				allow: AllowIp::default(),
+				authenticate: None,
 			})),
 		);
 	}
@@ -1079,6 +1126,8 @@ fn parse_define_access_record() {
 				},
 				comment: None,
 				if_not_exists: false,
+				allow: AllowIp::default(),
+				authenticate: None,
 			})),
 		);
 	}
@@ -1115,6 +1164,8 @@ fn parse_define_access_record() {
 				},
 				comment: None,
 				if_not_exists: false,
+				allow: AllowIp::default(),
+				authenticate: None,
 			})),
 		);
 	}
@@ -1161,10 +1212,26 @@ fn parse_define_access_record_with_jwt() {
 			},
 			comment: Some(Strand("bar".to_string())),
 			if_not_exists: false,
+			allow: AllowIp::default(),
+			authenticate: None,
 		})),
 	)
 }
 
+#[test]
+fn parse_define_access_record_with_authenticate() {
+	let res = test_parse!(
+		parse_stmt,
+		r#"DEFINE ACCESS a ON DATABASE TYPE RECORD AUTHENTICATE $auth DURATION FOR SESSION 1h"#
+	)
+	.unwrap();
+	let Statement::Define(DefineStatement::Access(stmt)) = res else {
+		panic!("expected a DEFINE ACCESS statement");
+	};
+	assert_eq!(stmt.authenticate, Some(Value::Param(Param::from("auth"))));
+	assert!(stmt.to_string().contains(" AUTHENTICATE $auth DURATION FOR TOKEN 1h, FOR SESSION 1h"));
+}
+
 #[test]
 fn parse_define_param() {
 	let res =
@@ -1193,7 +1260,7 @@ fn parse_define_param() {
 #[test]
 fn parse_define_table() {
 	let res =
-		test_parse!(parse_stmt, r#"DEFINE TABLE name DROP SCHEMAFUL CHANGEFEED 1s INCLUDE ORIGINAL PERMISSIONS FOR SELECT WHERE a = 1 AS SELECT foo FROM bar GROUP BY foo"#)
+		test_parse!(parse_stmt, r#"DEFINE TABLE name DROP ID ULID SCHEMAFUL CHANGEFEED 1s INCLUDE ORIGINAL PERMISSIONS FOR SELECT WHERE a = 1 AS SELECT foo FROM bar GROUP BY foo"#)
 			.unwrap();
 
 	assert_eq!(
@@ -1234,6 +1301,7 @@ fn parse_define_table() {
 			comment: None,
 			if_not_exists: false,
 			kind: TableType::Any,
+			generate: Some(Gen::Ulid),
 		}))
 	);
 }
@@ -1600,6 +1668,9 @@ fn parse_info() {
 		res,
 		Statement::Info(InfoStatement::User(Ident("user".to_owned()), Some(Base::Ns), false))
 	);
+
+	let res = test_parse!(parse_stmt, "INFO FOR SESSIONS").unwrap();
+	assert_eq!(res, Statement::Info(InfoStatement::Sessions));
 }
 
 #[test]
@@ -1892,6 +1963,93 @@ fn parse_kill() {
 	);
 }
 
+#[test]
+fn parse_rename() {
+	let res = test_parse!(parse_stmt, r#"RENAME TABLE person TO user"#).unwrap();
+	assert_eq!(
+		res,
+		Statement::Rename(RenameStatement::Table(RenameTableStatement {
+			name: Ident("person".to_owned()),
+			into: Ident("user".to_owned()),
+			if_exists: false,
+		}))
+	);
+
+	let res = test_parse!(parse_stmt, r#"RENAME TABLE IF EXISTS person TO user"#).unwrap();
+	assert_eq!(
+		res,
+		Statement::Rename(RenameStatement::Table(RenameTableStatement {
+			name: Ident("person".to_owned()),
+			into: Ident("user".to_owned()),
+			if_exists: true,
+		}))
+	);
+
+	let res = test_parse!(parse_stmt, r#"ALTER TABLE IF EXISTS person RENAME TO user"#).unwrap();
+	assert_eq!(
+		res,
+		Statement::Rename(RenameStatement::Table(RenameTableStatement {
+			name: Ident("person".to_owned()),
+			into: Ident("user".to_owned()),
+			if_exists: true,
+		}))
+	);
+
+	let field = Statement::Rename(RenameStatement::Field(RenameFieldStatement {
+		name: Idiom(vec![Part::Field(Ident("name".to_owned()))]),
+		what: Ident("person".to_owned()),
+		into: Idiom(vec![Part::Field(Ident("title".to_owned()))]),
+		if_exists: false,
+	}));
+	let res = test_parse!(parse_stmt, r#"RENAME FIELD name ON person TO title"#).unwrap();
+	assert_eq!(res, field);
+	let res = test_parse!(parse_stmt, r#"RENAME FIELD name ON TABLE person TO title"#).unwrap();
+	assert_eq!(res, field);
+	let res = test_parse!(parse_stmt, r#"ALTER FIELD name ON person RENAME TO title"#).unwrap();
+	assert_eq!(res, field);
+
+	let res = test_parse!(parse_stmt, r#"RENAME FIELD IF EXISTS name ON person TO title"#).unwrap();
+	assert_eq!(
+		res,
+		Statement::Rename(RenameStatement::Field(RenameFieldStatement {
+			name: Idiom(vec![Part::Field(Ident("name".to_owned()))]),
+			what: Ident("person".to_owned()),
+			into: Idiom(vec![Part::Field(Ident("title".to_owned()))]),
+			if_exists: true,
+		}))
+	);
+}
+
+#[test]
+fn parse_revoke() {
+	let res = test_parse!(parse_stmt, r#"REVOKE TOKEN $jti"#).unwrap();
+	assert_eq!(
+		res,
+		Statement::Revoke(RevokeStatement::Token(Value::Param(Param(Ident("jti".to_owned())))))
+	);
+
+	let res = test_parse!(parse_stmt, r#"REVOKE SESSION u"e72bee20-f49b-11ec-b939-0242ac120002""#)
+		.unwrap();
+	assert_eq!(
+		res,
+		Statement::Revoke(RevokeStatement::Session(Value::Uuid(Uuid(uuid::uuid!(
+			"e72bee20-f49b-11ec-b939-0242ac120002"
+		)))))
+	);
+
+	let res = test_parse!(parse_stmt, r#"REVOKE USER tobie ON NAMESPACE"#).unwrap();
+	assert_eq!(res, Statement::Revoke(RevokeStatement::User(Ident("tobie".to_owned()), Base::Ns)));
+
+	let res = test_parse!(parse_stmt, r#"REVOKE RECORD user:tobie"#).unwrap();
+	assert_eq!(
+		res,
+		Statement::Revoke(RevokeStatement::Record(Value::Thing(Thing {
+			tb: "user".to_owned(),
+			id: Id::String("tobie".to_owned()),
+		})))
+	);
+}
+
 #[test]
 fn parse_live() {
 	let res = test_parse!(parse_stmt, r#"LIVE SELECT DIFF FROM $foo"#).unwrap();
@@ -2044,6 +2202,36 @@ fn parse_remove() {
 		}))
 	);
 
+	let res = test_parse!(parse_stmt, r#"REMOVE TOKEN IF EXISTS foo ON NAMESPACE"#).unwrap();
+	assert_eq!(
+		res,
+		Statement::Remove(RemoveStatement::Access(RemoveAccessStatement {
+			name: Ident("foo".to_owned()),
+			base: Base::Ns,
+			if_exists: true,
+		}))
+	);
+
+	let res = test_parse!(parse_stmt, r#"REMOVE TOKEN foo ON SCOPE bar"#).unwrap();
+	assert_eq!(
+		res,
+		Statement::Remove(RemoveStatement::Access(RemoveAccessStatement {
+			name: Ident("foo".to_owned()),
+			base: Base::Db,
+			if_exists: false,
+		}))
+	);
+
+	let res = test_parse!(parse_stmt, r#"REMOVE SCOPE foo"#).unwrap();
+	assert_eq!(
+		res,
+		Statement::Remove(RemoveStatement::Access(RemoveAccessStatement {
+			name: Ident("foo".to_owned()),
+			base: Base::Db,
+			if_exists: false,
+		}))
+	);
+
 	let res = test_parse!(parse_stmt, r#"REMOVE PARAM $foo"#).unwrap();
 	assert_eq!(
 		res,
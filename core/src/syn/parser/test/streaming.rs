@@ -20,11 +20,11 @@ use crate::{
 			UpsertStatement,
 		},
 		tokenizer::Tokenizer,
-		Algorithm, Array, Base, Block, Cond, Data, Datetime, Dir, Duration, Edges, Explain,
-		Expression, Fetch, Fetchs, Field, Fields, Future, Graph, Group, Groups, Id, Ident, Idiom,
-		Idioms, Index, Kind, Limit, Number, Object, Operator, Order, Orders, Output, Param, Part,
-		Permission, Permissions, Scoring, Split, Splits, Start, Statement, Strand, Subquery, Table,
-		TableType, Tables, Thing, Timeout, Uuid, Value, Values, Version, With,
+		Algorithm, AllowIp, Array, Base, Block, Cond, Data, Datetime, Dir, Duration, Edges,
+		Explain, Expression, Fetch, Fetchs, Field, Fields, Future, Graph, Group, Groups, Id, Ident,
+		Idiom, Idioms, Index, Kind, Limit, Number, Object, Operator, Order, Orders, Output, Param,
+		Part, Permission, Permissions, Scoring, Split, Splits, Start, Statement, Strand, Subquery,
+		Table, TableType, Tables, Thing, Timeout, Uuid, Value, Values, Version, With,
 	},
 	syn::parser::{Parser, PartialResult},
 };
@@ -156,12 +156,14 @@ fn statements() -> Vec<Statement> {
 			name: Ident("a".to_string()),
 			comment: Some(Strand("test".to_string())),
 			if_not_exists: false,
+			allow: AllowIp::default(),
 		})),
 		Statement::Define(DefineStatement::Namespace(DefineNamespaceStatement {
 			id: None,
 			name: Ident("a".to_string()),
 			comment: None,
 			if_not_exists: false,
+			allow: AllowIp::default(),
 		})),
 		Statement::Define(DefineStatement::Database(DefineDatabaseStatement {
 			id: None,
@@ -216,6 +218,8 @@ fn statements() -> Vec<Statement> {
 			},
 			comment: Some(Strand("bar".to_string())),
 			if_not_exists: false,
+			allow: AllowIp::default(),
+			authenticate: None,
 		})),
 		Statement::Define(DefineStatement::Param(DefineParamStatement {
 			name: Ident("a".to_string()),
@@ -267,6 +271,7 @@ fn statements() -> Vec<Statement> {
 			comment: None,
 			if_not_exists: false,
 			kind: TableType::Any,
+			generate: None,
 		})),
 		Statement::Define(DefineStatement::Event(DefineEventStatement {
 			name: Ident("event".to_owned()),
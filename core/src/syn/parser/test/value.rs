@@ -154,6 +154,27 @@ fn constant_mixedcase() {
 	assert_eq!(out, Value::Constant(Constant::MathNegInf));
 }
 
+#[test]
+fn case_expression() {
+	let res = test_parse!(
+		parse_value,
+		r#" CASE WHEN age < 18 THEN 'child' WHEN age < 65 THEN 'adult' ELSE 'senior' END "#
+	)
+	.unwrap();
+	let out = test_parse!(
+		parse_value,
+		r#" IF age < 18 THEN 'child' ELSE IF age < 65 THEN 'adult' ELSE 'senior' END "#
+	)
+	.unwrap();
+	assert_eq!(res, out);
+
+	let res = test_parse!(parse_value, r#" CASE WHEN active THEN 1 END "#).unwrap();
+	assert_eq!(res.to_string(), "IF active THEN 1 END");
+
+	let res = test_parse!(parse_value, r#" case "#).unwrap();
+	assert!(matches!(res, Value::Idiom(_)));
+}
+
 #[test]
 fn scientific_decimal() {
 	let res = test_parse!(parse_value, r#" 9.7e-7dec "#).unwrap();
@@ -96,7 +96,7 @@ impl Parser<'_> {
 						return Err(ParseError::new(
 									ParseErrorKind::UnexpectedExplain {
 										found: t!("$param"),
-										expected: "a record-id id",
+										expected: "a record-id id".into(),
 										explain: "you can create a record-id from a param with the function 'type::thing'",
 									},
 									self.recent_span(),
@@ -1,8 +1,10 @@
 use reblessive::Stack;
 
+use super::format;
 use super::lexer::Lexer;
 use super::parse;
 use super::parser::Parser;
+use super::statement;
 use super::Parse;
 use crate::sql::{Array, Expression, Ident, Idiom, Param, Script, Thing, Value};
 use crate::syn::token::{t, TokenKind};
@@ -70,3 +72,61 @@ CREATE person CONTENT { foo:'bar'};
 "#;
 	parse(q).unwrap_err();
 }
+
+#[test]
+fn test_parse_single_statement() {
+	let stmt = statement("SELECT * FROM person;").unwrap();
+	assert_eq!(stmt.to_string(), "SELECT * FROM person");
+	statement("SELECT * FROM person; SELECT * FROM user").unwrap_err();
+	statement("").unwrap_err();
+}
+
+#[test]
+fn test_format_query() {
+	let out = format("select * from person where age > 18 and name = 'Tobie'; return {a: [1, 2]}")
+		.unwrap();
+	assert_eq!(
+		out,
+		"SELECT * FROM person WHERE age > 18 AND name = 'Tobie';\nRETURN {\n\ta: [\n\t\t1,\n\t\t2\n\t]\n};"
+	);
+	// Formatting is idempotent
+	assert_eq!(format(&out).unwrap(), out);
+}
+
+#[test]
+fn test_format_query_with_comments() {
+	for sql in [
+		"-- Select the people\nSELECT * FROM person;",
+		"SELECT * FROM person; # Select the people",
+		"SELECT * FROM person // Select the people\n;",
+		"SELECT /* all fields */ * FROM person;",
+	] {
+		assert!(matches!(format(sql), Err(crate::err::Error::FormatComments)), "{sql}");
+	}
+	// Comment markers within strings and scripts are not comments
+	format("SELECT * FROM person WHERE name = '-- Tobie';").unwrap();
+	format("RETURN function() { // inline script comment\n return 1; };").unwrap();
+}
+
+#[test]
+fn test_parse_error_details() {
+	let Err(crate::err::Error::InvalidQuery(error)) = parse("SELECT * FROM person\nWHERE age >;")
+	else {
+		panic!("expected a parse error");
+	};
+	let location = error.location().unwrap();
+	assert_eq!((location.line, location.column), (2, 12));
+	assert_eq!(error.found(), Some(";"));
+	assert!(!error.expected().is_empty());
+	// Lists of expected tokens are reported as individual tokens
+	let Err(crate::err::Error::InvalidQuery(error)) = parse("SELECT * FROM a WITH foo;") else {
+		panic!("expected a parse error");
+	};
+	assert_eq!(error.expected(), ["NO", "NOINDEX", "INDEX"]);
+	assert!(error.to_string().contains("expected NO, NOINDEX or INDEX"));
+	// Kinds of tokens are listed along with individual tokens
+	let Err(crate::err::Error::InvalidQuery(error)) = parse("RETURN $a[-1];") else {
+		panic!("expected a parse error");
+	};
+	assert_eq!(error.expected(), ["$", "*", "a number"]);
+}
@@ -28,6 +28,8 @@ keyword! {
 	After => "AFTER",
 	Algorithm => "ALGORITHM",
 	All => "ALL",
+	Alter => "ALTER",
+	Allow => "ALLOW",
 	Analyze => "ANALYZE",
 	Analyzer => "ANALYZER",
 	As => "AS",
@@ -35,6 +37,7 @@ keyword! {
 	Ascii => "ASCII",
 	Assert => "ASSERT",
 	At => "AT",
+	Authenticate => "AUTHENTICATE",
 	Before => "BEFORE",
 	Begin => "BEGIN",
 	Blank => "BLANK",
@@ -43,6 +46,7 @@ keyword! {
 	By => "BY",
 	Camel => "CAMEL",
 	Cancel => "CANCEL",
+	Case => "CASE",
 	ChangeFeed => "CHANGEFEED",
 	Changes => "CHANGES",
 	Capacity => "CAPACITY",
@@ -88,12 +92,14 @@ keyword! {
 	Group => "GROUP",
 	Highlights => "HIGHLIGHTS",
 	Hnsw => "HNSW",
+	Id => "ID",
 	Ignore => "IGNORE",
 	Include => "INCLUDE",
 	Index => "INDEX",
 	Info => "INFO",
 	Insert => "INSERT",
 	Into => "INTO",
+	Ip => "IP",
 	If => "IF",
 	Is => "IS",
 	Issuer => "ISSUER",
@@ -140,8 +146,10 @@ keyword! {
 	Relate => "RELATE",
 	Relation => "RELATION",
 	Remove => "REMOVE",
+	Rename => "RENAME",
 	Replace => "REPLACE",
 	Return => "RETURN",
+	Revoke => "REVOKE",
 	Roles => "ROLES",
 	Root => "ROOT",
 	Schemafull => "SCHEMAFULL",
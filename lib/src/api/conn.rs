@@ -129,6 +129,7 @@ pub struct Param {
 	pub(crate) bytes_sender: Option<channel::Sender<Result<Vec<u8>>>>,
 	pub(crate) notification_sender: Option<channel::Sender<Notification>>,
 	pub(crate) ml_config: Option<MlConfig>,
+	pub(crate) tables: Vec<String>,
 }
 
 impl Param {
@@ -436,6 +436,7 @@ async fn export(
 	sess: &Session,
 	chn: channel::Sender<Vec<u8>>,
 	ml_config: Option<MlConfig>,
+	tables: Vec<String>,
 ) -> Result<()> {
 	match ml_config {
 		#[cfg(feature = "ml")]
@@ -461,7 +462,7 @@ async fn export(
 			}
 		}
 		_ => {
-			if let Err(error) = kvs.export(sess, chn).await?.await {
+			if let Err(error) = kvs.export_tables(sess, tables, chn).await?.await {
 				if let crate::error::Db::Channel(message) = error {
 					// This is not really an error. Just logging it for improved visibility.
 					trace!("{message}");
@@ -647,7 +648,7 @@ async fn router(
 					let (mut writer, mut reader) = io::duplex(10_240);
 
 					// Write to channel.
-					let export = export(kvs, session, tx, param.ml_config);
+					let export = export(kvs, session, tx, param.ml_config, param.tables);
 
 					// Read from channel and write to pipe.
 					let bridge = async move {
@@ -688,7 +689,9 @@ async fn router(
 					let session = session.clone();
 					tokio::spawn(async move {
 						let export = async {
-							if let Err(error) = export(&kvs, &session, tx, param.ml_config).await {
+							if let Err(error) =
+								export(&kvs, &session, tx, param.ml_config, param.tables).await
+							{
 								let _ = backup.send(Err(error)).await;
 							}
 						};
@@ -769,15 +772,15 @@ async fn router(
 					kvs.process(query, session, Some(vars.clone())).await?
 				}
 				_ => {
-					let mut statements = String::new();
-					if let Err(error) = file.read_to_string(&mut statements).await {
-						return Err(Error::FileRead {
-							path,
-							error,
-						}
-						.into());
-					}
-					kvs.execute(&statements, &*session, Some(vars.clone())).await?
+					// Stream the file in chunks, so large imports are not read into memory
+					let stream = futures::stream::try_unfold(file, |mut file| async move {
+						let mut chunk = vec![0; 64 * 1024];
+						let len = file.read(&mut chunk).await?;
+						chunk.truncate(len);
+						Ok::<_, crate::error::Db>((len > 0).then_some((chunk, file)))
+					});
+					kvs.import_stream(&*session, Some(vars.clone()), stream).await?;
+					Vec::new()
 				}
 			};
 			for response in responses {
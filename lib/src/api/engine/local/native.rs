@@ -110,8 +110,16 @@ pub(crate) fn router(
 			_ => &address.path,
 		};
 
-		let kvs = match Datastore::new(endpoint).await {
+		let (kvs, version) = match Datastore::new(endpoint).await {
 			Ok(kvs) => {
+				// Check that this release can read the storage format
+				let version = match kvs.check_version().await {
+					Ok(version) => version,
+					Err(error) => {
+						let _ = conn_tx.into_send_async(Err(error.into())).await;
+						return;
+					}
+				};
 				if let Err(error) = kvs.bootstrap().await {
 					let _ = conn_tx.into_send_async(Err(error.into())).await;
 					return;
@@ -125,7 +133,7 @@ pub(crate) fn router(
 					}
 				}
 				let _ = conn_tx.into_send_async(Ok(())).await;
-				kvs.with_auth_enabled(configured_root.is_some())
+				(kvs.with_auth_enabled(configured_root.is_some()), version)
 			}
 			Err(error) => {
 				let _ = conn_tx.into_send_async(Err(error.into())).await;
@@ -157,6 +165,19 @@ pub(crate) fn router(
 		};
 
 		let kvs = Arc::new(kvs);
+
+		// Upgrade an older storage format in the background, while the datastore is in use
+		if !version.is_latest() {
+			let kvs = kvs.clone();
+			tokio::spawn(async move {
+				if let Err(error) = kvs.migrate().await {
+					warn!(
+						"The datastore could not be upgraded to the latest storage format: {error}"
+					);
+				}
+			});
+		}
+
 		let mut vars = BTreeMap::new();
 		let mut live_queries = HashMap::new();
 		let mut session = Session::default().with_rt(true);
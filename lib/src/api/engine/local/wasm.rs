@@ -107,8 +107,16 @@ pub(crate) fn router(
 			_ => None,
 		};
 
-		let kvs = match Datastore::new(&address.path).await {
+		let (kvs, version) = match Datastore::new(&address.path).await {
 			Ok(kvs) => {
+				// Check that this release can read the storage format
+				let version = match kvs.check_version().await {
+					Ok(version) => version,
+					Err(error) => {
+						let _ = conn_tx.into_send_async(Err(error.into())).await;
+						return;
+					}
+				};
 				if let Err(error) = kvs.bootstrap().await {
 					let _ = conn_tx.into_send_async(Err(error.into())).await;
 					return;
@@ -122,7 +130,7 @@ pub(crate) fn router(
 					}
 				}
 				let _ = conn_tx.into_send_async(Ok(())).await;
-				kvs.with_auth_enabled(configured_root.is_some())
+				(kvs.with_auth_enabled(configured_root.is_some()), version)
 			}
 			Err(error) => {
 				let _ = conn_tx.into_send_async(Err(error.into())).await;
@@ -142,6 +150,19 @@ pub(crate) fn router(
 			.with_capabilities(address.config.capabilities);
 
 		let kvs = Arc::new(kvs);
+
+		// Upgrade an older storage format in the background, while the datastore is in use
+		if !version.is_latest() {
+			let kvs = kvs.clone();
+			spawn_local(async move {
+				if let Err(error) = kvs.migrate().await {
+					warn!(
+						"The datastore could not be upgraded to the latest storage format: {error}"
+					);
+				}
+			});
+		}
+
 		let mut vars = BTreeMap::new();
 		let mut live_queries = HashMap::new();
 		let mut session = Session::default().with_rt(true);
@@ -548,10 +548,12 @@ async fn router(
 				}) => base_url.join(&format!("ml/export/{name}/{version}"))?,
 				_ => base_url.join(Method::Export.as_str())?,
 			};
+			let tables: Vec<_> = param.tables.iter().map(|tb| ("table", tb.as_str())).collect();
 			let request = client
 				.get(path)
 				.headers(headers.clone())
 				.auth(auth)
+				.query(&tables)
 				.header(ACCEPT, "application/octet-stream");
 			let value = export(request, (param.file, param.bytes_sender)).await?;
 			Ok(DbResponse::Other(value))
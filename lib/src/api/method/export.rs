@@ -29,6 +29,7 @@ pub struct Export<'r, C: Connection, R, T = ()> {
 	pub(super) client: Cow<'r, Surreal<C>>,
 	pub(super) target: ExportDestination,
 	pub(super) ml_config: Option<MlConfig>,
+	pub(super) tables: Vec<String>,
 	pub(super) response: PhantomData<R>,
 	pub(super) export_type: PhantomData<T>,
 }
@@ -46,10 +47,21 @@ where
 				name: name.to_owned(),
 				version: version.to_string(),
 			}),
+			tables: Vec::new(),
 			response: self.response,
 			export_type: PhantomData,
 		}
 	}
+
+	/// Only export the specified tables and their records
+	pub fn tables<I, S>(mut self, tables: I) -> Self
+	where
+		I: IntoIterator<Item = S>,
+		S: Into<String>,
+	{
+		self.tables = tables.into_iter().map(Into::into).collect();
+		self
+	}
 }
 
 impl<C, R, T> Export<'_, C, R, T>
@@ -84,6 +96,7 @@ where
 				ExportDestination::Memory => unreachable!(),
 			};
 			param.ml_config = self.ml_config;
+			param.tables = self.tables;
 			conn.execute_unit(router, param).await
 		})
 	}
@@ -109,6 +122,7 @@ where
 			};
 			let mut param = Param::bytes_sender(tx);
 			param.ml_config = self.ml_config;
+			param.tables = self.tables;
 			conn.execute_unit(router, param).await?;
 			Ok(Backup {
 				rx,
@@ -1323,6 +1323,7 @@ where
 			client: Cow::Borrowed(self),
 			target: target.into_export_destination(),
 			ml_config: None,
+			tables: Vec::new(),
 			response: PhantomData,
 			export_type: PhantomData,
 		}
@@ -98,8 +98,31 @@ impl<T, P> Credentials<T, Jwt> for Record<'_, P> where P: Serialize {}
 /// * stored in a database with restricted access,
 /// * or encrypted in conjunction with other encryption mechanisms.
 #[derive(Clone, Serialize, Deserialize)]
+#[serde(from = "Token")]
 pub struct Jwt(pub(crate) String);
 
+/// The token returned by the server when signing in or signing up, which also
+/// contains a refresh grant when the record access method issues one
+#[derive(Deserialize)]
+#[serde(untagged)]
+enum Token {
+	Jwt(String),
+	Grant {
+		token: String,
+	},
+}
+
+impl From<Token> for Jwt {
+	fn from(token: Token) -> Self {
+		match token {
+			Token::Jwt(token) => Jwt(token),
+			Token::Grant {
+				token,
+			} => Jwt(token),
+		}
+	}
+}
+
 impl Jwt {
 	/// Returns the underlying token string.
 	///
@@ -155,4 +178,14 @@ mod tests {
 		let jwt = Jwt("super-long-jwt".to_owned());
 		assert_eq!(jwt.into_insecure_token(), "super-long-jwt");
 	}
+
+	#[test]
+	fn from_token_with_refresh_grant() {
+		let value = crate::sql::Value::from("super-long-jwt");
+		let jwt: Jwt = crate::sql::from_value(value).unwrap();
+		assert_eq!(jwt.as_insecure_token(), "super-long-jwt");
+		let value = crate::sql::json(r#"{ "token": "super-long-jwt", "refresh": "grant" }"#);
+		let jwt: Jwt = crate::sql::from_value(value.unwrap()).unwrap();
+		assert_eq!(jwt.as_insecure_token(), "super-long-jwt");
+	}
 }
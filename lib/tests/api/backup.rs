@@ -32,6 +32,25 @@ async fn export_import() {
 	res.unwrap();
 }
 
+#[test_log::test(tokio::test)]
+async fn export_tables() {
+	use futures::StreamExt;
+	let (permit, db) = new_db().await;
+	let db_name = Ulid::new().to_string();
+	db.use_ns(NS).use_db(&db_name).await.unwrap();
+	db.query("CREATE user:one; CREATE post:one").await.unwrap().check().unwrap();
+	drop(permit);
+	let mut backup = db.export(()).tables(["user"]).await.unwrap();
+	let mut bytes = Vec::new();
+	while let Some(chunk) = backup.next().await {
+		bytes.extend(chunk.unwrap());
+	}
+	let output = String::from_utf8(bytes).unwrap();
+	assert!(output.contains("OPTION IMPORT;"));
+	assert!(output.contains("INSERT [ { id: user:one } ];"));
+	assert!(!output.contains("post"));
+}
+
 #[test_log::test(tokio::test)]
 #[cfg(feature = "ml")]
 async fn ml_export_import() {
@@ -0,0 +1,82 @@
+mod helpers;
+use helpers::new_ds;
+use std::collections::BTreeMap;
+use std::fmt::Debug;
+use std::sync::{Arc, Mutex};
+use surrealdb::dbs::Session;
+use surrealdb::err::Error;
+use tracing::field::{Field, Visit};
+use tracing::{Event, Subscriber};
+use tracing_subscriber::layer::{Context, Layer, SubscriberExt};
+
+/// The fields of the audit events which were recorded
+type Events = Arc<Mutex<Vec<BTreeMap<String, String>>>>;
+
+/// Captures the audit events which are emitted
+struct Capture(Events);
+
+impl<S: Subscriber> Layer<S> for Capture {
+	fn on_event(&self, event: &Event<'_>, _: Context<'_, S>) {
+		if event.metadata().target() == "surrealdb::audit" {
+			let mut fields = Fields::default();
+			event.record(&mut fields);
+			self.0.lock().unwrap().push(fields.0);
+		}
+	}
+}
+
+#[derive(Default)]
+struct Fields(BTreeMap<String, String>);
+
+impl Visit for Fields {
+	fn record_str(&mut self, field: &Field, value: &str) {
+		self.0.insert(field.name().to_owned(), value.to_owned());
+	}
+	fn record_debug(&mut self, field: &Field, value: &dyn Debug) {
+		self.0.insert(field.name().to_owned(), format!("{value:?}"));
+	}
+}
+
+#[tokio::test]
+async fn audit_record_changes() -> Result<(), Error> {
+	// This is the only test in this file, so the setting is read after it is set
+	std::env::set_var("SURREAL_AUDIT_LOG", "true");
+	let events = Events::default();
+	let subscriber = tracing_subscriber::registry().with(Capture(events.clone()));
+	let _guard = tracing::subscriber::set_default(subscriber);
+	//
+	let sql = "
+		DEFINE EVENT log ON person WHEN $event = 'CREATE' THEN (CREATE log:1 SET of = $after.id);
+		CREATE person:tobie;
+		UPDATE person:tobie SET name = 'Tobie';
+		DELETE person:tobie;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	for res in dbs.execute(sql, &ses, None).await? {
+		res.result?;
+	}
+	// Each record change is recorded with its table and record id
+	let records: Vec<_> = events
+		.lock()
+		.unwrap()
+		.iter()
+		.filter(|e| e.get("message").map(String::as_str) == Some("Record"))
+		.map(|e| (e["action"].clone(), e["table"].clone(), e["record"].clone(), e["ns"].clone()))
+		.collect();
+	let expected = |action: &str, table: &str, record: &str| {
+		(action.to_owned(), table.to_owned(), record.to_owned(), "test".to_owned())
+	};
+	assert_eq!(
+		records,
+		vec![
+			expected("create", "person", "person:tobie"),
+			// The record which is created by the event is also recorded
+			expected("create", "log", "log:1"),
+			expected("update", "person", "person:tobie"),
+			expected("delete", "person", "person:tobie"),
+		]
+	);
+	//
+	Ok(())
+}
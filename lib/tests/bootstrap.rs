@@ -8,13 +8,97 @@ mod helpers;
 mod parse;
 
 use helpers::new_ds;
+use parse::Parse;
 use serial_test::serial;
+use surrealdb::dbs::Session;
 use surrealdb::err::Error;
+use surrealdb::key::root::sv::Sv;
 use surrealdb::kvs::LockType::Optimistic;
-use surrealdb::kvs::Transaction;
 use surrealdb::kvs::TransactionType::Write;
+use surrealdb::kvs::{Transaction, Version};
 use surrealdb::sql::statements::LiveStatement;
 use surrealdb::sql::Uuid;
+use surrealdb::sql::Value;
+
+#[tokio::test]
+#[serial]
+async fn migrate_outdated_storage_version() -> Result<(), Error> {
+	// Create the datastore
+	let dbs = new_ds().await?;
+	// An empty datastore uses the latest storage format
+	assert_eq!(dbs.check_version().await?, Version::LATEST);
+	// Create some data
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let sql = "
+		DEFINE TABLE person;
+		DEFINE FIELD name ON person;
+		DEFINE PARAM $greeting VALUE 'Hello';
+		DEFINE FUNCTION fn::greet($name: string) { RETURN $greeting + ' ' + $name; };
+		CREATE person:tobie SET name = 'Tobie';
+	";
+	for res in dbs.execute(sql, &ses, None).await? {
+		res.result?;
+	}
+	// Older releases never recorded the storage format
+	let mut tx = dbs.transaction(Write, Optimistic).await?;
+	tx.del(Sv::new()).await?;
+	tx.commit().await?;
+	assert_eq!(dbs.check_version().await?, Version(0));
+	// Migrate the datastore to the latest storage format
+	assert_eq!(dbs.migrate().await?, Version(0));
+	assert_eq!(dbs.check_version().await?, Version::LATEST);
+	assert_eq!(dbs.migrate().await?, Version::LATEST);
+	// Rewrite the data in the latest storage format
+	dbs.rewrite().await?;
+	// The data is unchanged
+	let res = &mut dbs.execute("SELECT * FROM person", &ses, None).await?;
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:tobie, name: 'Tobie' }]");
+	assert_eq!(tmp, val);
+	// The database definitions are unchanged
+	let res = &mut dbs.execute("RETURN fn::greet('Tobie')", &ses, None).await?;
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("'Hello Tobie'");
+	assert_eq!(tmp, val);
+	Ok(())
+}
+
+#[tokio::test]
+#[serial]
+async fn rewrite_with_concurrent_writes() -> Result<(), Error> {
+	// Create the datastore
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let sql = "
+		DEFINE TABLE person;
+		FOR $i IN 0..500 { CREATE type::thing('person', $i) SET count = 0; };
+	";
+	for res in dbs.execute(sql, &ses, None).await? {
+		res.result?;
+	}
+	// Rewrite the data while the records are being updated
+	let write = async {
+		for i in 1..=20 {
+			let sql = format!("UPDATE person SET count = {i}");
+			for res in dbs.execute(&sql, &ses, None).await? {
+				res.result?;
+			}
+		}
+		Ok::<_, Error>(())
+	};
+	let (rewrite, write) = tokio::join!(dbs.rewrite(), write);
+	rewrite?;
+	write?;
+	// No update was lost while the data was being rewritten
+	let res =
+		&mut dbs.execute("SELECT VALUE count FROM person WHERE count != 20", &ses, None).await?;
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::parse("[]"));
+	let res = &mut dbs.execute("RETURN count(SELECT * FROM person)", &ses, None).await?;
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::parse("500"));
+	Ok(())
+}
 
 #[tokio::test]
 #[serial]
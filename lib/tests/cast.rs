@@ -22,3 +22,49 @@ async fn cast_string_to_record() -> Result<(), Error> {
 	//
 	Ok(())
 }
+
+#[tokio::test]
+async fn cast_imported_strings() -> Result<(), Error> {
+	let sql = r#"
+		<int> '123';
+		<float> '12.5';
+		<decimal> '12.50';
+		<datetime> '2022-01-01T00:00:00Z';
+		<bool> 'true';
+		<int> 'abc';
+		<datetime> 'yesterday';
+	"#;
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 7);
+	//
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::parse("123"));
+	//
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::parse("12.5f"));
+	//
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::parse("12.50dec"));
+	//
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::parse("d'2022-01-01T00:00:00Z'"));
+	//
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::parse("true"));
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(
+		tmp.err(),
+		Some(e) if e.to_string() == "Expected a int but cannot convert 'abc' into a int"
+	));
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(
+		tmp.err(),
+		Some(e) if e.to_string() == "Expected a datetime but cannot convert 'yesterday' into a datetime"
+	));
+	//
+	Ok(())
+}
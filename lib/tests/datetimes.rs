@@ -44,3 +44,48 @@ async fn datetimes_conversion() -> Result<(), Error> {
 	//
 	Ok(())
 }
+
+#[tokio::test]
+async fn datetimes_arithmetic() -> Result<(), Error> {
+	let sql = r#"
+		d"2024-01-15T00:00:00Z" - 2w;
+		d"2024-01-15T00:00:00Z" + 1h30m;
+		d"2024-01-15T12:00:00Z" - d"2024-01-14T00:00:00Z";
+		CREATE event:1 SET created = d"2024-01-01T00:00:00Z";
+		CREATE event:2 SET created = d"2024-01-10T00:00:00Z";
+		CREATE event:3 SET created = d"2024-01-20T00:00:00Z";
+		SELECT VALUE id FROM event WHERE created > d"2024-01-21T00:00:00Z" - 2w ORDER BY created DESC;
+		SELECT VALUE created + 1d FROM event WHERE created < d"2024-01-05T00:00:00Z";
+	"#;
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 8);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("d'2024-01-01T00:00:00Z'");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("d'2024-01-15T01:30:00Z'");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("1d12h");
+	assert_eq!(tmp, val);
+	//
+	for _ in 0..3 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[event:3, event:2]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[d'2024-01-02T00:00:00Z']");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
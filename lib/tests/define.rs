@@ -129,6 +129,95 @@ async fn define_statement_table_drop() -> Result<(), Error> {
 	Ok(())
 }
 
+#[tokio::test]
+async fn define_statement_table_id_generation() -> Result<(), Error> {
+	let sql = "
+		DEFINE TABLE ulids ID ULID;
+		DEFINE TABLE uuids ID UUID;
+		CREATE ulids;
+		CREATE ulids SET name = 'test';
+		INSERT INTO ulids { name: 'test' };
+		CREATE uuids;
+		CREATE uuids:custom;
+		SELECT VALUE string::len(meta::id(id)) FROM ulids;
+		SELECT VALUE string::is::uuid(meta::id(id)) FROM uuids WHERE id != uuids:custom;
+		INFO FOR DB;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 10);
+	//
+	for _ in 0..7 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[26, 26, 26]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[true]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"{
+			accesses: {},
+			analyzers: {},
+			functions: {},
+			models: {},
+			params: {},
+			tables: {
+				ulids: 'DEFINE TABLE ulids TYPE ANY ID ULID SCHEMALESS PERMISSIONS NONE',
+				uuids: 'DEFINE TABLE uuids TYPE ANY ID UUID SCHEMALESS PERMISSIONS NONE',
+			},
+			users: {},
+		}",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn define_statement_table_id_generation_for_mocks() -> Result<(), Error> {
+	let sql = "
+		DEFINE TABLE ulids ID ULID;
+		DEFINE TABLE uuids ID UUID;
+		CREATE |ulids:100| RETURN VALUE meta::id(id);
+		CREATE |uuids:100| RETURN VALUE meta::id(id);
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 4);
+	//
+	for _ in 0..2 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	// Each generated id is greater than the one generated before it
+	let ids = |v: Value| -> Vec<String> {
+		let Value::Array(v) = v else {
+			panic!("Expected an array of ids, found {v}");
+		};
+		v.into_iter().map(|v| v.as_raw_string()).collect()
+	};
+	let tmp = ids(res.remove(0).result?);
+	assert_eq!(tmp.len(), 100);
+	assert!(tmp.iter().all(|v| v.len() == 26 && v.chars().all(|c| c.is_ascii_alphanumeric())));
+	assert!(tmp.windows(2).all(|w| w[0] < w[1]), "ULIDs are not increasing: {tmp:?}");
+	//
+	let tmp = ids(res.remove(0).result?);
+	assert_eq!(tmp.len(), 100);
+	assert!(tmp.iter().all(|v| v.len() == 36 && v.chars().nth(14) == Some('7')));
+	assert!(tmp.windows(2).all(|w| w[0] < w[1]), "UUIDs are not increasing: {tmp:?}");
+	//
+	Ok(())
+}
+
 #[tokio::test]
 async fn define_statement_table_schemaless() -> Result<(), Error> {
 	let sql = "
@@ -7,3 +7,67 @@ async fn modulo() -> Result<(), Error> {
 	Test::new("8 % 3").await?.expect_val("2")?;
 	Ok(())
 }
+
+#[tokio::test]
+async fn null_coalescing_operators() -> Result<(), Error> {
+	let sql = "
+		NONE ?? 'default';
+		NULL ?? 'default';
+		0 ?? 'default';
+		0 ?: 'default';
+		'' ?: 'default';
+		'value' ?: 'default';
+	";
+	Test::new(sql).await?.expect_vals(&[
+		"'default'",
+		"'default'",
+		"0",
+		"'default'",
+		"'default'",
+		"'value'",
+	])?;
+	Ok(())
+}
+
+#[tokio::test]
+async fn null_predicates() -> Result<(), Error> {
+	let sql = "
+		CREATE person:one SET name = NULL;
+		CREATE person:two SET name = 'Tobie';
+		CREATE person:three;
+		SELECT VALUE id FROM person WHERE name IS NULL;
+		SELECT VALUE id FROM person WHERE name IS NONE;
+		SELECT VALUE id FROM person WHERE name IS NOT NULL AND name IS NOT NONE;
+		SELECT VALUE id FROM person WHERE (name ?? NONE) IS NONE ORDER BY id;
+	";
+	Test::new(sql).await?.skip_ok(3)?.expect_vals(&[
+		"[person:one]",
+		"[person:three]",
+		"[person:two]",
+		"[person:one, person:three]",
+	])?;
+	Ok(())
+}
+
+#[tokio::test]
+async fn set_membership_operators() -> Result<(), Error> {
+	let sql = "
+		2 INSIDE [1, 2, 3];
+		4 NOT IN [1, 2, 3];
+		[1, 2, 3] CONTAINS 2;
+		[1, 2, 3] CONTAINSNOT 4;
+		[1, 2, 3] CONTAINSALL [1, 2];
+		[1, 2, 3] CONTAINSANY [3, 4];
+		[1, 2, 3] CONTAINSNONE [4, 5];
+		[1, 2] ALLINSIDE [1, 2, 3];
+		[3, 4] ANYINSIDE [1, 2, 3];
+		[4, 5] NONEINSIDE [1, 2, 3];
+		[1, 4] ALLINSIDE [1, 2, 3];
+		'surreal' CONTAINS 'real';
+	";
+	Test::new(sql).await?.expect_vals(&[
+		"true", "true", "true", "true", "true", "true", "true", "true", "true", "true", "false",
+		"true",
+	])?;
+	Ok(())
+}
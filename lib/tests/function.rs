@@ -279,6 +279,37 @@ async fn function_array_distinct() -> Result<(), Error> {
 	Ok(())
 }
 
+#[tokio::test]
+async fn function_array_filter() -> Result<(), Error> {
+	let sql = r#"RETURN array::filter([1, 2, 3, 4], $value > 2);
+RETURN array::filter([{ age: 17 }, { age: 21 }, { age: 30 }], $value.age >= 18);
+RETURN array::filter([], true);
+LET $min = 3;
+RETURN array::filter([1, 2, 3, 4], $value >= $min);
+RETURN array::filter(['a', 'b', 'c'], $index > 0);
+CREATE person:test SET min = 2, scores = [1, 2, 3];
+SELECT VALUE array::filter(scores, $value > min) FROM person;"#;
+	let desired_responses = [
+		"[3, 4]",
+		"[{ age: 21 }, { age: 30 }]",
+		"[]",
+		"NONE",
+		"[3, 4]",
+		"['b', 'c']",
+		"[{ id: person:test, min: 2, scores: [1, 2, 3] }]",
+		"[[3]]",
+	];
+	test_queries(sql, &desired_responses).await?;
+	//
+	let sql = r#"RETURN array::filter("test", true);
+RETURN array::filter([1, 2, 3]);"#;
+	Test::new(sql)
+		.await?
+		.expect_error("Incorrect arguments for function array::filter(). Argument 1 was the wrong type. Expected a array but found 'test'")?
+		.expect_error("Incorrect arguments for function array::filter(). Expected 2 arguments.")?;
+	Ok(())
+}
+
 #[tokio::test]
 async fn function_array_filter_index() -> Result<(), Error> {
 	let sql = r#"RETURN array::filter_index([0, 1, 2], 1);
@@ -567,6 +598,26 @@ RETURN array::logical_xor([0, 1], []);"#,
 	Ok(())
 }
 
+#[tokio::test]
+async fn function_array_map() -> Result<(), Error> {
+	let sql = r#"RETURN array::map([1, 2, 3], $value * 2);
+RETURN array::map([{ name: 'a' }, { name: 'b' }], string::uppercase($value.name));
+RETURN array::map([[1, 2], [3]], array::len($value));
+RETURN array::map(['a', 'b'], $index);
+CREATE person:test SET factor = 10, scores = [1, 2];
+SELECT VALUE array::map(scores, $value * factor) FROM person;"#;
+	let desired_responses = [
+		"[2, 4, 6]",
+		"['A', 'B']",
+		"[2, 1]",
+		"[0, 1]",
+		"[{ id: person:test, factor: 10, scores: [1, 2] }]",
+		"[[10, 20]]",
+	];
+	test_queries(sql, &desired_responses).await?;
+	Ok(())
+}
+
 #[tokio::test]
 async fn function_array_matches() -> Result<(), Error> {
 	test_queries(
@@ -758,6 +809,43 @@ async fn function_array_remove() -> Result<(), Error> {
 	Ok(())
 }
 
+#[tokio::test]
+async fn function_array_range() -> Result<(), Error> {
+	let sql = r#"
+		RETURN array::range(1, 3);
+		RETURN array::range(-2, 0);
+		RETURN array::range(0, -1);
+		FOR $i IN array::range(0, 4) { CREATE item SET n = $i * 2; };
+		RETURN math::sum((SELECT VALUE n FROM item));
+	"#;
+	let mut test = Test::new(sql).await?;
+	//
+	let tmp = test.next()?.result?;
+	let val = Value::parse("[1, 2, 3]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = test.next()?.result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = test.next()?.result;
+	assert!(
+		matches!(
+			&tmp,
+			Err(e) if e.to_string() ==
+				"Incorrect arguments for function array::range(). The count must be between 0 and 1048576."
+		),
+		"{tmp:?}"
+	);
+	//
+	test.next()?.result?;
+	let tmp = test.next()?.result?;
+	let val = Value::parse("12");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
 #[tokio::test]
 async fn function_array_reverse() -> Result<(), Error> {
 	let sql = r#"
@@ -1175,6 +1263,36 @@ async fn function_crypto_sha512() -> Result<(), Error> {
 	Ok(())
 }
 
+#[tokio::test]
+async fn function_crypto_hmac_sha256() -> Result<(), Error> {
+	let sql = r#"
+		RETURN crypto::hmac::sha256('Jefe', 'what do ya want for nothing?');
+	"#;
+	let mut test = Test::new(sql).await?;
+	//
+	let tmp = test.next()?.result?;
+	let val = Value::from("5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn function_crypto_hmac_sha512() -> Result<(), Error> {
+	let sql = r#"
+		RETURN crypto::hmac::sha512('Jefe', 'what do ya want for nothing?');
+	"#;
+	let mut test = Test::new(sql).await?;
+	//
+	let tmp = test.next()?.result?;
+	let val = Value::from(
+		"164b7a7bfcf819e2e395fbe73b56e0a387bd64222e831fd610270cd7ea2505549758bf75c05a994a6d034f65f8f0e6fdcaeab1a34d4a6b4b636e070a38bce737"
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
 // --------------------------------------------------
 // duration
 // --------------------------------------------------
@@ -2607,6 +2725,21 @@ async fn function_object_len() -> Result<(), Error> {
 	Ok(())
 }
 
+#[tokio::test]
+async fn function_object_merge() -> Result<(), Error> {
+	let sql = r#"
+		RETURN object::merge({ a: 1, b: 2 }, { b: 3, c: 4 });
+		RETURN object::merge({ a: 1, b: { c: 2, d: 3 } }, { b: { d: 4 } });
+		RETURN object::merge({ a: 1, b: 2 }, { b: NONE });
+	"#;
+	Test::new(sql)
+		.await?
+		.expect_val("{ a: 1, b: 3, c: 4 }")?
+		.expect_val("{ a: 1, b: { c: 2, d: 4 } }")?
+		.expect_val("{ a: 1 }")?;
+	Ok(())
+}
+
 #[tokio::test]
 async fn function_object_values() -> Result<(), Error> {
 	let sql = r#"
@@ -2673,6 +2806,17 @@ async fn function_not() -> Result<(), Error> {
 // parse
 // --------------------------------------------------
 
+#[tokio::test]
+async fn function_parse_email_domain() -> Result<(), Error> {
+	let sql = r#"
+		RETURN parse::email::domain("john.doe@example.com");
+		RETURN parse::email::domain("john.doe@[127.0.0.1]");
+		RETURN parse::email::domain("not an email");
+	"#;
+	Test::new(sql).await?.expect_val("'example.com'")?.expect_val("NONE")?.expect_val("NONE")?;
+	Ok(())
+}
+
 #[tokio::test]
 async fn function_parse_email_host() -> Result<(), Error> {
 	let sql = r#"
@@ -2987,6 +3131,94 @@ async fn function_rand_uuid_v7() -> Result<(), Error> {
 	Ok(())
 }
 
+#[tokio::test]
+async fn function_rand_seeded() -> Result<(), Error> {
+	let sql = r#"
+		RETURN [
+			rand(),
+			rand::bool(),
+			rand::enum('a', 'b', 'c', 'd'),
+			rand::float(1, 100),
+			rand::guid(),
+			rand::int(1, 100),
+			rand::string(16),
+			rand::time(),
+			rand::ulid(),
+			rand::uuid(),
+			rand::uuid::v4(),
+			rand::uuid::v7(),
+		];
+		CREATE person;
+		CREATE |person:5|;
+		SELECT VALUE id FROM person;
+	"#;
+	let run = |seed: Option<u64>| async move {
+		let dbs = new_ds().await?.with_random_seed(seed);
+		let ses = Session::owner().with_ns("test").with_db("test");
+		let res = &mut dbs.execute(sql, &ses, None).await?;
+		assert_eq!(res.len(), 4);
+		let values = res.remove(0).result?;
+		let ids = res.remove(2).result?;
+		Ok::<_, Error>((values, ids))
+	};
+	// The same seed produces the same values and record ids
+	let first = run(Some(42)).await?;
+	let second = run(Some(42)).await?;
+	assert_eq!(first, second);
+	// A different seed produces different values and record ids
+	let other = run(Some(7)).await?;
+	assert_ne!(first.0, other.0);
+	assert_ne!(first.1, other.1);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn function_rand_seeded_datastore_continues() -> Result<(), Error> {
+	let run = || async {
+		let dbs = new_ds().await?.with_random_seed(Some(42));
+		// Each query continues the sequence of the datastore
+		let mut ids = Vec::new();
+		for _ in 0..2 {
+			let ses = Session::owner().with_ns("test").with_db("test");
+			let res = &mut dbs.execute("CREATE person:ulid() RETURN VALUE id", &ses, None).await?;
+			ids.push(res.remove(0).result?);
+		}
+		Ok::<_, Error>(ids)
+	};
+	let first = run().await?;
+	assert_ne!(first[0], first[1]);
+	assert_eq!(first, run().await?);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn function_rand_seeded_session() -> Result<(), Error> {
+	let run = || async {
+		let dbs = new_ds().await?;
+		let ses = Session::owner().with_ns("test").with_db("test").with_random_seed(Some(42));
+		// Each query continues the sequence of the session
+		let mut ids = Vec::new();
+		for _ in 0..3 {
+			let res = &mut dbs.execute("CREATE person:ulid() RETURN VALUE id", &ses, None).await?;
+			ids.push(res.remove(0).result?);
+		}
+		Ok::<_, Error>(ids)
+	};
+	let first = run().await?;
+	assert_ne!(first[0], first[1]);
+	assert_ne!(first[1], first[2]);
+	assert_eq!(first, run().await?);
+	// An unseeded session is not affected by the seeded session
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute("CREATE person:ulid() RETURN VALUE id", &ses, None).await?;
+	assert_ne!(res.remove(0).result?, first[0]);
+	//
+	Ok(())
+}
+
 // --------------------------------------------------
 // string
 // --------------------------------------------------
@@ -3892,6 +4124,96 @@ async fn function_string_split() -> Result<(), Error> {
 	Ok(())
 }
 
+#[tokio::test]
+async fn function_string_split_limit() -> Result<(), Error> {
+	let sql = r#"
+		RETURN string::split("this, is, a, list", ", ", 2);
+		RETURN string::split("this, is, a, list", ", ", 10);
+		RETURN string::split("this, is, a, list", ", ", 1);
+	"#;
+	Test::new(sql)
+		.await?
+		.expect_val("['this', 'is, a, list']")?
+		.expect_val("['this', 'is', 'a', 'list']")?
+		.expect_val("['this, is, a, list']")?;
+	//
+	let sql = r#"
+		RETURN string::split("this, is, a, list", ", ", 0);
+	"#;
+	let error =
+		"Incorrect arguments for function string::split(). The limit must be greater than 0.";
+	Test::new(sql).await?.expect_error(error)?;
+	Ok(())
+}
+
+#[tokio::test]
+async fn function_string_pad() -> Result<(), Error> {
+	let sql = r#"
+		RETURN string::pad::start("5", 3, "0");
+		RETURN string::pad::start("abc", 2, "0");
+		RETURN string::pad::start("abc", 8, "xy");
+		RETURN string::pad::end("abc", 5);
+		RETURN string::pad::end("héllo", 7, "!");
+	"#;
+	Test::new(sql)
+		.await?
+		.expect_val("'005'")?
+		.expect_val("'abc'")?
+		.expect_val("'xyxyxabc'")?
+		.expect_val("'abc  '")?
+		.expect_val("'héllo!!'")?;
+	//
+	let sql = r#"
+		RETURN string::pad::start("abc", 5, "");
+	"#;
+	let error = "Incorrect arguments for function string::pad::start(). The padding string must not be empty.";
+	Test::new(sql).await?.expect_error(error)?;
+	Ok(())
+}
+
+#[tokio::test]
+async fn function_string_distance() -> Result<(), Error> {
+	let sql = r#"
+		RETURN string::distance::levenshtein("", "");
+		RETURN string::distance::levenshtein("kitten", "sitting");
+		RETURN string::distance::hamming("karolin", "kathrin");
+	"#;
+	Test::new(sql).await?.expect_val("0")?.expect_val("3")?.expect_val("3")?;
+	//
+	let sql = r#"
+		RETURN string::distance::hamming("abc", "ab");
+	"#;
+	let error = "Incorrect arguments for function string::distance::hamming(). The two strings must be of equal length.";
+	Test::new(sql).await?.expect_error(error)?;
+	//
+	let sql = r#"
+		RETURN string::distance::levenshtein(string::repeat("a", 8193), "a");
+		RETURN string::distance::hamming("a", string::repeat("a", 8193));
+	"#;
+	Test::new(sql).await?.expect_errors(&[
+		"Incorrect arguments for function string::distance::levenshtein(). The strings must not exceed 8192 bytes.",
+		"Incorrect arguments for function string::distance::hamming(). The strings must not exceed 8192 bytes.",
+	])?;
+	Ok(())
+}
+
+#[tokio::test]
+async fn function_string_similarity_jaro() -> Result<(), Error> {
+	let sql = r#"
+		RETURN string::similarity::jaro("", "");
+		RETURN string::similarity::jaro("abc", "abc");
+		RETURN string::similarity::jaro("abc", "xyz");
+	"#;
+	Test::new(sql).await?.expect_val("1f")?.expect_val("1f")?.expect_val("0f")?;
+	//
+	let sql = r#"
+		RETURN string::similarity::jaro(string::repeat("a", 8193), "a");
+	"#;
+	let error = "Incorrect arguments for function string::similarity::jaro(). The strings must not exceed 8192 bytes.";
+	Test::new(sql).await?.expect_error(error)?;
+	Ok(())
+}
+
 #[tokio::test]
 async fn function_string_starts_with() -> Result<(), Error> {
 	let sql = r#"
@@ -4063,6 +4385,10 @@ async fn function_time_format() -> Result<(), Error> {
 	let sql = r#"
 		RETURN time::format(d"1987-06-22T08:30:45Z", "%Y-%m-%d");
 		RETURN time::format(d"1987-06-22T08:30:45Z", "%T");
+		RETURN time::format(d"1987-06-22T20:30:45Z", "%Y-%m-%d %T %:z", "+05:30");
+		RETURN time::format(d"1987-06-22T08:30:45Z", "%T", "UTC");
+		RETURN time::format(d"1987-06-22T08:30:45Z", "%T", "Europe/London");
+		RETURN time::format(d"1987-06-22T08:30:45Z", "%T", "+25:00");
 	"#;
 	let mut test = Test::new(sql).await?;
 	//
@@ -4074,6 +4400,18 @@ async fn function_time_format() -> Result<(), Error> {
 	let val = Value::parse("'08:30:45'");
 	assert_eq!(tmp, val);
 	//
+	let tmp = test.next()?.result?;
+	let val = Value::parse("'1987-06-23 02:00:45 +05:30'");
+	assert_eq!(tmp, val);
+	//
+	let tmp = test.next()?.result?;
+	let val = Value::parse("'08:30:45'");
+	assert_eq!(tmp, val);
+	//
+	test.expect_error("Incorrect arguments for function time::format(). Timezone names such as 'Europe/London' are not supported. The third argument must be a timezone offset, such as '+05:30', '-08:00', or 'UTC'.")?;
+	//
+	test.expect_error("Incorrect arguments for function time::format(). The third argument must be a timezone offset, such as '+05:30', '-08:00', or 'UTC'.")?;
+	//
 	Ok(())
 }
 
@@ -4082,6 +4420,8 @@ async fn function_time_group() -> Result<(), Error> {
 	let sql = r#"
 		RETURN time::group(d"1987-06-22T08:30:45Z", 'hour');
 		RETURN time::group(d"1987-06-22T08:30:45Z", 'month');
+		RETURN time::group(d"1987-06-25T08:30:45Z", 'week');
+		RETURN time::group(d"1987-06-22T08:30:45Z", 'week');
 	"#;
 	let mut test = Test::new(sql).await?;
 	//
@@ -4093,6 +4433,14 @@ async fn function_time_group() -> Result<(), Error> {
 	let val = Value::parse("d'1987-06-01T00:00:00Z'");
 	assert_eq!(tmp, val);
 	//
+	let tmp = test.next()?.result?;
+	let val = Value::parse("d'1987-06-22T00:00:00Z'");
+	assert_eq!(tmp, val);
+	//
+	let tmp = test.next()?.result?;
+	let val = Value::parse("d'1987-06-22T00:00:00Z'");
+	assert_eq!(tmp, val);
+	//
 	Ok(())
 }
 
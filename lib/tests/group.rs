@@ -674,3 +674,77 @@ async fn select_array_count_subquery_group_by() -> Result<(), Error> {
 	//
 	Ok(())
 }
+
+#[tokio::test]
+async fn select_case_expression_group_by() -> Result<(), Error> {
+	let sql = "
+		CREATE person:1 SET age = 12;
+		CREATE person:2 SET age = 34;
+		CREATE person:3 SET age = 56;
+		CREATE person:4 SET age = 78;
+		SELECT
+			CASE WHEN age < 18 THEN 'child' WHEN age < 65 THEN 'adult' ELSE 'senior' END AS category,
+			count() AS total
+		FROM person GROUP BY category;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let mut res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 5);
+	//
+	skip_ok(&mut res, 4)?;
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{ category: 'adult', total: 2 },
+			{ category: 'child', total: 1 },
+			{ category: 'senior', total: 1 }
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn select_decimal_sum_group_by() -> Result<(), Error> {
+	let sql = "
+		CREATE item:1 SET kind = 'a', price = 0.1dec;
+		CREATE item:2 SET kind = 'a', price = 0.2dec;
+		CREATE item:3 SET kind = 'b', price = 1.005dec;
+		SELECT kind, math::sum(price) AS total FROM item GROUP BY kind;
+		SELECT VALUE price * 3 FROM item:1;
+		RETURN math::sum((SELECT VALUE price FROM item WHERE kind = 'a')) = 0.3dec;
+		RETURN 0.1f + 0.2f = 0.3f;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let mut res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 7);
+	//
+	skip_ok(&mut res, 3)?;
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{ kind: 'a', total: 0.3dec },
+			{ kind: 'b', total: 1.005dec }
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[0.3dec]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::Bool(true);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::Bool(false);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
@@ -360,6 +360,63 @@ async fn permissions_checks_info_table() {
 	assert!(res.is_ok(), "{}", res.unwrap_err());
 }
 
+#[tokio::test]
+async fn info_for_sessions() {
+	let dbs = new_ds().await.unwrap();
+	let ses = Session::owner().with_ns("test").with_db("test");
+	// Register a connected client session
+	let id = uuid::Uuid::new_v4();
+	dbs.register_session(id, &ses, || {});
+	let mut res = dbs.execute("INFO FOR SESSIONS", &ses, None).await.unwrap();
+	assert_eq!(res.len(), 1);
+	let out_str = res.remove(0).output().unwrap().to_string();
+	let output_regex = Regex::new(&format!(
+		r"^\[\{{ ac: NONE, actor: .*, db: 'test', id: u'{id}', ip: NONE, level: .*, ns: 'test', started: d'.*', token: NONE \}}\]$"
+	))
+	.unwrap();
+	assert!(
+		output_regex.is_match(&out_str),
+		"Output '{}' doesn't match regex '{}'",
+		out_str,
+		output_regex
+	);
+	// Disconnected sessions are no longer listed
+	dbs.unregister_session(&id);
+	let mut res = dbs.execute("INFO FOR SESSIONS", &ses, None).await.unwrap();
+	let out_str = res.remove(0).output().unwrap().to_string();
+	assert_eq!(out_str, "[]");
+}
+
+#[tokio::test]
+async fn permissions_checks_info_sessions() {
+	let scenario = HashMap::from([
+		("prepare", ""),
+		("test", "INFO FOR SESSIONS"),
+		("check", "INFO FOR SESSIONS"),
+	]);
+
+	// Define the expected results for the check statement when the test statement succeeded and when it failed
+	let check_results = [vec!["[]"], vec!["[]"]];
+
+	let test_cases = [
+		// Root level
+		((().into(), Role::Owner), ("NS", "DB"), true),
+		((().into(), Role::Editor), ("NS", "DB"), true),
+		((().into(), Role::Viewer), ("NS", "DB"), true),
+		// Namespace level
+		((("NS",).into(), Role::Owner), ("NS", "DB"), false),
+		((("NS",).into(), Role::Editor), ("NS", "DB"), false),
+		((("NS",).into(), Role::Viewer), ("NS", "DB"), false),
+		// Database level
+		((("NS", "DB").into(), Role::Owner), ("NS", "DB"), false),
+		((("NS", "DB").into(), Role::Editor), ("NS", "DB"), false),
+		((("NS", "DB").into(), Role::Viewer), ("NS", "DB"), false),
+	];
+
+	let res = iam_check_cases(test_cases.iter(), &scenario, check_results).await;
+	assert!(res.is_ok(), "{}", res.unwrap_err());
+}
+
 #[tokio::test]
 async fn permissions_checks_info_user_root() {
 	let scenario = HashMap::from([
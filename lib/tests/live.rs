@@ -2,9 +2,11 @@ mod helpers;
 mod parse;
 
 use helpers::new_ds;
+use std::time::Duration;
 use surrealdb::dbs::Session;
 use surrealdb::err::Error;
 use surrealdb::fflags::FFLAGS;
+use surrealdb::kvs::{LockType::*, TransactionType::*};
 use surrealdb::sql::Value;
 
 #[tokio::test]
@@ -97,3 +99,33 @@ async fn live_query_sends_registered_lq_details() -> Result<(), Error> {
 
 	Ok(())
 }
+
+#[tokio::test]
+async fn shutdown_closes_live_queries() -> Result<(), Error> {
+	if FFLAGS.change_feed_live_queries.enabled() {
+		return Ok(());
+	}
+	let sql = "
+		LIVE SELECT * FROM person;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test").with_rt(true);
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 1);
+	res.remove(0).result?;
+	// Shut down the datastore
+	dbs.drain(Duration::from_millis(100)).await;
+	dbs.shutdown().await?;
+	// New queries are rejected
+	let res = dbs.execute("CREATE person", &ses, None).await;
+	assert!(matches!(res, Err(Error::ShuttingDown)), "{:?}", res);
+	let res = dbs.compute(Value::from(1), &ses, None).await;
+	assert!(matches!(res, Err(Error::ShuttingDown)), "{:?}", res);
+	// The live query has been removed
+	let mut tx = dbs.transaction(Read, Optimistic).await?;
+	assert!(tx.all_tb_lives("test", "test", "person").await?.is_empty());
+	tx.cancel().await?;
+	// The notification channel has been closed
+	assert!(dbs.notifications().unwrap().recv().await.is_err());
+	Ok(())
+}
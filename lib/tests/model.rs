@@ -55,3 +55,46 @@ async fn model_range() -> Result<(), Error> {
 	//
 	Ok(())
 }
+
+#[tokio::test]
+async fn model_random_fixtures() -> Result<(), Error> {
+	let sql = "
+		CREATE |person:100| SET
+			role = rand::enum('admin', 'editor', 'viewer'),
+			age = rand::int(18, 65),
+			score = rand::float(0, 1),
+			joined = rand::time(1577836800, 1609459200),
+			code = rand::string(8),
+			token = rand::uuid::v7();
+		SELECT count() FROM person WHERE
+			role INSIDE ['admin', 'editor', 'viewer']
+			AND age >= 18 AND age <= 65
+			AND score >= 0 AND score <= 1
+			AND joined >= d'2020-01-01T00:00:00Z' AND joined <= d'2021-01-01T00:00:00Z'
+			AND string::len(code) = 8
+			AND type::is::uuid(token)
+		GROUP ALL;
+		RETURN array::len(array::distinct((SELECT VALUE token FROM person)));
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 3);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[{
+			count: 100
+		}]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::from(100);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
@@ -0,0 +1,120 @@
+mod helpers;
+use helpers::new_ds;
+use serial_test::serial;
+use surrealdb::cnf::{set_quotas, Quota};
+use surrealdb::dbs::Session;
+use surrealdb::err::Error;
+
+#[tokio::test]
+#[serial]
+async fn quota_table_records_and_storage() -> Result<(), Error> {
+	let mut quota = Quota::new("quota", Some("test".to_owned()));
+	quota.table_max_records = Some(2);
+	quota.storage_max_mb = Some(1);
+	set_quotas(vec![quota]);
+	let sql = "
+		CREATE person:one;
+		CREATE person:two;
+		CREATE person:three;
+		UPDATE person:two SET name = 'Two';
+		DELETE person:one;
+		CREATE person:three;
+		CREATE animal:one;
+		CREATE animal:two SET data = string::repeat('a', 1048576);
+		DELETE person;
+		CREATE animal:two SET data = string::repeat('a', 1000);
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("quota").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 10);
+	//
+	for _ in 0..2 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	// The table has reached its record quota
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(Error::TbRecordQuotaExceeded { .. })));
+	// Updating a record does not count against the record quota
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	// Deleting a record releases it from the record quota
+	for _ in 0..3 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	// The database has reached its storage quota
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(Error::DbStorageQuotaExceeded { .. })));
+	//
+	for _ in 0..2 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	// Other databases are not affected by the quota
+	let sql = "
+		CREATE person:one;
+		CREATE person:two;
+		CREATE person:three;
+	";
+	let ses = Session::owner().with_ns("quota").with_db("other");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	for _ in 0..3 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	//
+	set_quotas(Vec::new());
+	Ok(())
+}
+
+#[tokio::test]
+#[serial]
+async fn quota_counts_records_written_without_a_quota() -> Result<(), Error> {
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("quota").with_db("usage");
+	// Records are stored before any quota applies
+	let sql = "
+		CREATE person:one SET data = string::repeat('a', 600000);
+		CREATE person:two;
+		CREATE animal:one;
+		DELETE animal:one;
+		RENAME TABLE animal TO other;
+		RENAME TABLE person TO user;
+	";
+	for res in dbs.execute(sql, &ses, None).await? {
+		res.result?;
+	}
+	// The usage was tracked while no quota applied
+	let mut quota = Quota::new("quota", Some("usage".to_owned()));
+	quota.table_max_records = Some(2);
+	quota.storage_max_mb = Some(1);
+	set_quotas(vec![quota]);
+	let sql = "
+		CREATE user:three;
+		CREATE other:one;
+		CREATE other:two SET data = string::repeat('a', 600000);
+		REMOVE TABLE user;
+		CREATE other:two SET data = string::repeat('a', 600000);
+	";
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 5);
+	// The renamed table has reached its record quota
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(Error::TbRecordQuotaExceeded { .. })));
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	// The database has reached its storage quota
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(Error::DbStorageQuotaExceeded { .. })));
+	// Removing a table releases its records from the storage quota
+	for _ in 0..2 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	//
+	set_quotas(Vec::new());
+	Ok(())
+}
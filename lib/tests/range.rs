@@ -119,3 +119,56 @@ async fn select_start_limit_fetch() -> Result<(), Error> {
 	//
 	Ok(())
 }
+
+#[tokio::test]
+async fn record_id_range_statements() -> Result<(), Error> {
+	let sql = "
+		FOR $i IN array::range(1, 5) { CREATE type::thing('person', $i) SET num = $i };
+		CREATE event:['a', 1], event:['b', 1], event:['c', 1];
+		SELECT VALUE num FROM person:2..4;
+		SELECT VALUE num FROM person:2..=4;
+		SELECT VALUE num FROM person:..2;
+		UPDATE person:4.. SET old = true RETURN VALUE id;
+		DELETE person:1..3 RETURN BEFORE;
+		SELECT VALUE id FROM person;
+		SELECT VALUE id FROM event:['a', 1]..['c', 1];
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 9);
+	//
+	for _ in 0..2 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	//
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::parse("[2, 3]"));
+	//
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::parse("[2, 3, 4]"));
+	//
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::parse("[1]"));
+	//
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::parse("[person:4, person:5]"));
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{ id: person:1, num: 1 },
+			{ id: person:2, num: 2 }
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::parse("[person:3, person:4, person:5]"));
+	//
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::parse("[event:['a', 1], event:['b', 1]]"));
+	//
+	Ok(())
+}
@@ -0,0 +1,386 @@
+mod parse;
+use parse::Parse;
+
+mod helpers;
+use helpers::*;
+
+use surrealdb::dbs::Session;
+use surrealdb::err::Error;
+use surrealdb::sql::Value;
+
+#[tokio::test]
+async fn rename_table_statement() -> Result<(), Error> {
+	let sql = "
+		DEFINE TABLE person SCHEMAFULL;
+		DEFINE FIELD name ON person TYPE string;
+		DEFINE INDEX uniq_name ON person FIELDS name UNIQUE;
+		CREATE person:tobie SET name = 'Tobie';
+		CREATE person:jaime SET name = 'Jaime';
+		RENAME TABLE person TO user;
+		SELECT * FROM user ORDER BY id;
+		SELECT * FROM user WHERE name = 'Tobie';
+		INFO FOR TABLE user;
+		SELECT * FROM person;
+		CREATE user:other SET name = 'Tobie';
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 11);
+	for _ in 0..6 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{ id: user:jaime, name: 'Jaime' },
+			{ id: user:tobie, name: 'Tobie' }
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: user:tobie, name: 'Tobie' }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"{
+			events: {},
+			fields: { name: 'DEFINE FIELD name ON user TYPE string PERMISSIONS FULL' },
+			indexes: { uniq_name: 'DEFINE INDEX uniq_name ON user FIELDS name UNIQUE' },
+			lives: {},
+			tables: {}
+		}",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_err());
+	Ok(())
+}
+
+#[tokio::test]
+async fn rename_table_statement_errors() -> Result<(), Error> {
+	let sql = "
+		RENAME TABLE IF EXISTS person TO user;
+		RENAME TABLE person TO user;
+		CREATE person:tobie;
+		CREATE user:jaime;
+		RENAME TABLE person TO user;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 5);
+	//
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::None);
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(
+		tmp.err(),
+		Some(e) if e.to_string() == "The table 'person' does not exist"
+	));
+	//
+	for _ in 0..2 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(
+		tmp.err(),
+		Some(e) if e.to_string() == "The table 'user' already exists"
+	));
+	Ok(())
+}
+
+#[tokio::test]
+async fn rename_table_statement_references() -> Result<(), Error> {
+	let sql = "
+		CREATE person:tobie;
+		CREATE person:jaime SET friend = person:tobie;
+		RENAME TABLE person TO user;
+		UPDATE person:jaime UNSET friend;
+		CREATE company:surreal SET staff = [{ person: person:tobie }];
+		RENAME TABLE person TO user;
+		DELETE company;
+		DEFINE FIELD owner ON company TYPE option<record<person>>;
+		RENAME TABLE person TO user;
+		REMOVE FIELD owner ON company;
+		DEFINE TABLE animal CHANGEFEED 1h;
+		RENAME TABLE animal TO pet;
+		RENAME TABLE person TO user;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 13);
+	//
+	for _ in 0..2 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	// Records in the table link to other records in the table
+	let tmp = res.remove(0).result;
+	assert!(matches!(
+		tmp.err(),
+		Some(e) if e.to_string() == "The table 'person' can not be renamed: Records in table 'person' link to the table"
+	));
+	//
+	for _ in 0..2 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	// Records in other tables link to the table
+	let tmp = res.remove(0).result;
+	assert!(matches!(
+		tmp.err(),
+		Some(e) if e.to_string() == "The table 'person' can not be renamed: Records in table 'company' link to the table"
+	));
+	//
+	for _ in 0..2 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	// Field types refer to the table
+	let tmp = res.remove(0).result;
+	assert!(matches!(
+		tmp.err(),
+		Some(e) if e.to_string() == "The table 'person' can not be renamed: The field 'owner' on table 'company' refers to the table"
+	));
+	//
+	for _ in 0..2 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	// The table has a change feed
+	let tmp = res.remove(0).result;
+	assert!(matches!(
+		tmp.err(),
+		Some(e) if e.to_string() == "The table 'animal' can not be renamed: The table or database has a change feed"
+	));
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	Ok(())
+}
+
+#[tokio::test]
+async fn rename_table_statement_live_query() -> Result<(), Error> {
+	let sql = "
+		CREATE person:tobie;
+		LIVE SELECT * FROM person;
+		RENAME TABLE person TO user;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test").with_rt(true);
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 3);
+	//
+	for _ in 0..2 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(
+		tmp.err(),
+		Some(e) if e.to_string() == "The table 'person' can not be renamed: The table has running live queries"
+	));
+	Ok(())
+}
+
+#[tokio::test]
+async fn rename_table_statement_definitions() -> Result<(), Error> {
+	let sql = "
+		CREATE person:tobie;
+		DEFINE EVENT audit ON company WHEN $event = 'CREATE' THEN (CREATE person);
+		RENAME TABLE person TO user;
+		REMOVE EVENT audit ON company;
+		DEFINE FUNCTION fn::people() { RETURN SELECT * FROM person; };
+		RENAME TABLE person TO user;
+		REMOVE FUNCTION fn::people;
+		DEFINE FIELD owner ON company VALUE (SELECT VALUE id FROM persons);
+		ALTER TABLE person RENAME TO user;
+		SELECT * FROM user;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 10);
+	//
+	for _ in 0..2 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	// Events refer to the table
+	let tmp = res.remove(0).result;
+	assert!(matches!(
+		tmp.err(),
+		Some(e) if e.to_string() == "The table 'person' can not be renamed: The event 'audit' on table 'company' refers to the table"
+	));
+	//
+	for _ in 0..2 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	// Functions refer to the table
+	let tmp = res.remove(0).result;
+	assert!(matches!(
+		tmp.err(),
+		Some(e) if e.to_string() == "The table 'person' can not be renamed: The function 'fn::people' refers to the table"
+	));
+	// Other names which contain the table name do not refer to it
+	for _ in 0..3 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: user:tobie }]");
+	assert_eq!(tmp, val);
+	Ok(())
+}
+
+#[tokio::test]
+async fn rename_table_statement_in_transaction() -> Result<(), Error> {
+	let sql = "
+		CREATE person:tobie;
+		BEGIN;
+		RENAME TABLE person TO user;
+		COMMIT;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 2);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(
+		tmp.err(),
+		Some(e) if e.to_string() == "RENAME statements can not be run within a transaction"
+	));
+	Ok(())
+}
+
+#[tokio::test]
+async fn rename_field_statement() -> Result<(), Error> {
+	let sql = "
+		DEFINE TABLE person SCHEMAFULL;
+		DEFINE FIELD name ON person TYPE object;
+		DEFINE FIELD name.first ON person TYPE string;
+		DEFINE FIELD age ON person TYPE option<number>;
+		CREATE person:tobie SET name = { first: 'Tobie' }, age = 30;
+		CREATE person:jaime SET name = { first: 'Jaime' };
+		RENAME FIELD name ON person TO title;
+		ALTER FIELD age ON TABLE person RENAME TO years;
+		SELECT * FROM person ORDER BY id;
+		INFO FOR TABLE person;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 10);
+	for _ in 0..8 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{ id: person:jaime, title: { first: 'Jaime' } },
+			{ id: person:tobie, title: { first: 'Tobie' }, years: 30 }
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"{
+			events: {},
+			fields: {
+				title: 'DEFINE FIELD title ON person TYPE object PERMISSIONS FULL',
+				'title.first': 'DEFINE FIELD title.first ON person TYPE string PERMISSIONS FULL',
+				years: 'DEFINE FIELD years ON person TYPE option<number> PERMISSIONS FULL'
+			},
+			indexes: {},
+			lives: {},
+			tables: {}
+		}",
+	);
+	assert_eq!(tmp, val);
+	Ok(())
+}
+
+#[tokio::test]
+async fn rename_field_statement_errors() -> Result<(), Error> {
+	let sql = "
+		RENAME FIELD IF EXISTS name ON person TO title;
+		DEFINE FIELD name ON person TYPE string;
+		DEFINE FIELD title ON person TYPE string;
+		RENAME FIELD name ON person TO title;
+		RENAME FIELD id ON person TO key;
+		DEFINE INDEX uniq_name ON person FIELDS name UNIQUE;
+		RENAME FIELD name ON person TO alias;
+		REMOVE INDEX uniq_name ON person;
+		DEFINE EVENT audit ON person WHEN $before.name != $after.name THEN (CREATE log);
+		RENAME FIELD name ON person TO alias;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 10);
+	//
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::None);
+	//
+	for _ in 0..2 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(
+		tmp.err(),
+		Some(e) if e.to_string() == "The field 'title' already exists"
+	));
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(
+		tmp.err(),
+		Some(e) if e.to_string() == "The field 'id' does not exist"
+	));
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(
+		tmp.err(),
+		Some(e) if e.to_string() == "The field 'name' can not be renamed: The index 'uniq_name' refers to the field"
+	));
+	//
+	for _ in 0..2 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(
+		tmp.err(),
+		Some(e) if e.to_string() == "The field 'name' can not be renamed: The event 'audit' refers to the field"
+	));
+	Ok(())
+}
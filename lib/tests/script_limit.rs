@@ -0,0 +1,48 @@
+#![cfg(feature = "scripting")]
+
+mod parse;
+use parse::Parse;
+mod helpers;
+use helpers::new_ds;
+use surrealdb::dbs::Session;
+use surrealdb::err::Error;
+use surrealdb::sql::Value;
+
+#[tokio::test]
+async fn script_function_time_limit() -> Result<(), Error> {
+	// This is the only test in this file, so the setting is read after it is set
+	std::env::set_var("SURREAL_SCRIPTING_MAX_TIME_LIMIT", "1000");
+	let sql = "
+		RETURN function() {
+			while (true) {}
+		};
+		RETURN function() {
+			throw new Error('failed');
+		};
+		RETURN function() {
+			return 1;
+		};
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 3);
+	// The looping script is interrupted
+	let tmp = res.remove(0).result;
+	assert!(matches!(
+		tmp.err(),
+		Some(e) if e.to_string() == "Problem with embedded script function. The script exceeded the time limit of 1000ms"
+	));
+	// Other script errors are not reported as exceeding the time limit
+	let tmp = res.remove(0).result;
+	assert!(matches!(
+		tmp.err(),
+		Some(e) if e.to_string() == "Problem with embedded script function. An exception occurred: failed"
+	));
+	// Later scripts still run
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("1");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
@@ -5,7 +5,7 @@ use helpers::new_ds;
 use surrealdb::dbs::Session;
 use surrealdb::err::Error;
 use surrealdb::iam::Role;
-use surrealdb::sql::Value;
+use surrealdb::sql::{Thing, Value};
 
 #[tokio::test]
 async fn select_field_value() -> Result<(), Error> {
@@ -1155,3 +1155,171 @@ async fn select_issue_3510() -> Result<(), Error> {
 	assert_eq!(format!("{:#}", tmp), format!("{:#}", val));
 	Ok(())
 }
+
+#[tokio::test]
+async fn select_split_on_array_and_scalar() -> Result<(), Error> {
+	let sql = "
+		CREATE person:tobie SET tags = ['a', 'b', 'c'], name = 'Tobie';
+		CREATE person:jaime SET tags = 'd', name = 'Jaime';
+		SELECT name, tags FROM person SPLIT tags;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 3);
+	//
+	res.remove(0).result?;
+	res.remove(0).result?;
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{ name: 'Jaime', tags: 'd' },
+			{ name: 'Tobie', tags: 'a' },
+			{ name: 'Tobie', tags: 'b' },
+			{ name: 'Tobie', tags: 'c' }
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn select_memory_budget_exceeded() -> Result<(), Error> {
+	let sql = "
+		CREATE |person:100| SET data = string::repeat('x', 1000) RETURN NONE;
+		SELECT * FROM person;
+		SELECT count() FROM person GROUP ALL;
+		SELECT * FROM person LIMIT 2;
+	";
+	let dbs = new_ds().await?.with_memory_budget(Some(10_000));
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 4);
+	//
+	res.remove(0).result?;
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(Error::MemoryBudgetExceeded { .. })));
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ count: 100 }]");
+	assert_eq!(tmp, val);
+	// The budget is released once a query completes
+	let Value::Array(tmp) = res.remove(0).result? else {
+		unreachable!()
+	};
+	assert_eq!(tmp.len(), 2);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn select_memory_budget_held_by_responses() -> Result<(), Error> {
+	let dbs = new_ds().await?.with_memory_budget(Some(10_000));
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let sql = "CREATE |person:100| SET data = string::repeat('x', 1000) RETURN NONE";
+	dbs.execute(sql, &ses, None).await?.remove(0).result?;
+	// Grouped results are counted once they are output
+	let sql = "SELECT id, data FROM person GROUP BY id";
+	let tmp = dbs.execute(sql, &ses, None).await?.remove(0).result;
+	assert!(matches!(tmp, Err(Error::MemoryBudgetExceeded { .. })));
+	// Results are counted until their responses are dropped
+	let sql = "SELECT * FROM person LIMIT 5";
+	let one = dbs.execute(sql, &ses, None).await?;
+	assert!(one[0].result.is_ok());
+	let tmp = dbs.execute(sql, &ses, None).await?.remove(0).result;
+	assert!(matches!(tmp, Err(Error::MemoryBudgetExceeded { .. })));
+	drop(one);
+	let tmp = dbs.execute(sql, &ses, None).await?.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn select_memory_budget_within_transaction() -> Result<(), Error> {
+	let dbs = new_ds().await?.with_memory_budget(Some(10_000));
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let sql = "CREATE |person:100| SET data = string::repeat('x', 1000) RETURN NONE";
+	dbs.execute(sql, &ses, None).await?.remove(0).result?;
+	// Buffered results are counted until the transaction completes
+	let sql = "
+		BEGIN;
+		SELECT * FROM person LIMIT 5;
+		SELECT * FROM person LIMIT 5;
+		COMMIT;
+	";
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 2);
+	assert!(matches!(res.remove(0).result, Err(Error::QueryNotExecuted)));
+	assert!(matches!(res.remove(0).result, Err(Error::MemoryBudgetExceeded { .. })));
+	// Committed results are counted until their responses are dropped
+	let sql = "
+		BEGIN;
+		SELECT * FROM person LIMIT 5;
+		COMMIT;
+	";
+	let one = dbs.execute(sql, &ses, None).await?;
+	assert!(one[0].result.is_ok());
+	let sql = "SELECT * FROM person LIMIT 5";
+	let tmp = dbs.execute(sql, &ses, None).await?.remove(0).result;
+	assert!(matches!(tmp, Err(Error::MemoryBudgetExceeded { .. })));
+	drop(one);
+	let tmp = dbs.execute(sql, &ses, None).await?.remove(0).result;
+	assert!(tmp.is_ok());
+	// Parameters are counted until the query completes
+	let sql = "
+		BEGIN;
+		LET $people = (SELECT * FROM person LIMIT 5);
+		SELECT * FROM person LIMIT 5;
+		COMMIT;
+	";
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert!(matches!(res.remove(0).result, Err(Error::QueryNotExecuted)));
+	assert!(matches!(res.remove(0).result, Err(Error::MemoryBudgetExceeded { .. })));
+	let tmp = dbs.execute("SELECT * FROM person LIMIT 5", &ses, None).await?.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn select_with_changed_table_permissions() -> Result<(), Error> {
+	let dbs = new_ds().await?.with_auth_enabled(true);
+	let owner = Session::owner().with_ns("test").with_db("test");
+	let ses = Session::for_record("test", "test", "test", Thing::from(("user", "john")).into());
+	let sql = "
+		DEFINE TABLE person PERMISSIONS FOR select WHERE false;
+		CREATE person:tobie;
+	";
+	for res in dbs.execute(sql, &owner, None).await? {
+		res.result?;
+	}
+	let res = &mut dbs.execute("SELECT * FROM person", &ses, None).await?;
+	assert_eq!(res.remove(0).result?, Value::parse("[]"));
+	// A cancelled change to the table permissions is not used
+	let sql = "
+		BEGIN;
+		REMOVE TABLE person;
+		DEFINE TABLE person PERMISSIONS FULL;
+		CREATE person:jaime;
+		CANCEL;
+	";
+	dbs.execute(sql, &owner, None).await?;
+	let res = &mut dbs.execute("SELECT * FROM person", &ses, None).await?;
+	assert_eq!(res.remove(0).result?, Value::parse("[]"));
+	// A committed change to the table permissions is used straight away
+	let sql = "
+		REMOVE TABLE person;
+		DEFINE TABLE person PERMISSIONS FULL;
+		CREATE person:jaime;
+	";
+	for res in dbs.execute(sql, &owner, None).await? {
+		res.result?;
+	}
+	let res = &mut dbs.execute("SELECT * FROM person", &ses, None).await?;
+	assert_eq!(res.remove(0).result?, Value::parse("[{ id: person:jaime }]"));
+	Ok(())
+}
@@ -354,3 +354,63 @@ async fn subquery_ifelse_array() -> Result<(), Error> {
 	//
 	Ok(())
 }
+
+#[tokio::test]
+async fn subquery_uncorrelated_and_correlated() -> Result<(), Error> {
+	let sql = "
+		CREATE person:1 SET age = 20;
+		CREATE person:2 SET age = 30;
+		CREATE person:3 SET age = 40;
+		-- The same uncorrelated subquery is used in the projection and the condition
+		SELECT
+			id,
+			age > (SELECT VALUE math::mean(age) FROM person GROUP ALL)[0] AS older,
+			(SELECT VALUE id FROM person WHERE age < $parent.age) AS younger
+		FROM person
+		WHERE age >= (SELECT VALUE math::mean(age) FROM person GROUP ALL)[0];
+		-- Subqueries in write statements see the previous writes
+		UPDATE person SET ranked = (SELECT VALUE id FROM person WHERE ranked != NONE) RETURN NONE;
+		SELECT VALUE ranked FROM person;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 6);
+	//
+	for _ in 0..3 {
+		res.remove(0).result?;
+	}
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				id: person:2,
+				older: false,
+				younger: [person:1]
+			},
+			{
+				id: person:3,
+				older: true,
+				younger: [person:1, person:2]
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			[],
+			[person:1],
+			[person:1, person:2]
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
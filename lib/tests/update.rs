@@ -623,3 +623,21 @@ async fn check_permissions_auth_disabled() {
 		);
 	}
 }
+
+#[tokio::test]
+async fn update_with_mutation_operators() -> Result<(), Error> {
+	let sql = "
+		CREATE person:test SET tags = ['rust', 'old'], count = 1;
+		UPDATE person:test SET tags += 'go', tags -= 'old', count += 1;
+		UPDATE person:test SET tags +?= 'go', tags +?= 'js', count -= 5;
+		UPDATE person:test SET others += 'new', total += 2;
+	";
+	let mut t = Test::new(sql).await?;
+	t.skip_ok(1)?;
+	t.expect_val("[{ id: person:test, tags: ['rust', 'go'], count: 2 }]")?;
+	t.expect_val("[{ id: person:test, tags: ['rust', 'go', 'js'], count: -3 }]")?;
+	t.expect_val(
+		"[{ id: person:test, tags: ['rust', 'go', 'js'], count: -3, others: ['new'], total: 2 }]",
+	)?;
+	Ok(())
+}
@@ -0,0 +1,267 @@
+use crate::cli::abstraction::auth::{CredentialsBuilder, CredentialsLevel};
+use crate::cli::abstraction::{
+	AuthArguments, DatabaseConnectionArguments, DatabaseSelectionArguments,
+};
+use crate::err::Error;
+use clap::{Args, ValueEnum};
+use rand::distributions::{Alphanumeric, DistString};
+use rand::rngs::StdRng;
+use rand::{Rng, SeedableRng};
+use std::time::{Duration, Instant};
+use surrealdb::engine::any::{connect, Any, IntoEndpoint};
+use surrealdb::opt::{capabilities::Capabilities, Config};
+use surrealdb::sql::{self, Table, Value};
+use surrealdb::Surreal;
+
+/// The number of records returned by each range scan
+const SCAN_SIZE: u64 = 100;
+
+#[derive(ValueEnum, Clone, Copy, Debug, Eq, PartialEq)]
+enum Workload {
+	/// Create new records
+	Insert,
+	/// Select single records by id
+	Read,
+	/// Select ranges of records by id
+	Scan,
+	/// A mix of reads, updates, and inserts
+	Mixed,
+}
+
+#[derive(Args, Debug)]
+pub struct BenchCommandArguments {
+	#[arg(help = "The workload to run")]
+	#[arg(long, default_value = "mixed", value_enum)]
+	workload: Workload,
+	#[arg(help = "The number of operations to run")]
+	#[arg(long, default_value_t = 10_000)]
+	operations: u64,
+	#[arg(help = "The number of records to create before running the workload")]
+	#[arg(long, default_value_t = 10_000, value_parser = clap::value_parser!(u64).range(1..))]
+	records: u64,
+	#[arg(help = "The number of clients running operations concurrently")]
+	#[arg(long, default_value_t = 1)]
+	clients: u64,
+	#[arg(help = "The table to run the workload against, which must be empty")]
+	#[arg(long, default_value = "bench")]
+	table: String,
+	#[arg(help = "Delete any existing records in the table before running the workload")]
+	#[arg(long)]
+	clean: bool,
+	#[arg(help = "The seed used to generate the workload, so that runs are reproducible")]
+	#[arg(long, default_value_t = 1)]
+	seed: u64,
+	#[command(flatten)]
+	conn: DatabaseConnectionArguments,
+	#[command(flatten)]
+	auth: AuthArguments,
+	#[command(flatten)]
+	sel: DatabaseSelectionArguments,
+}
+
+pub async fn init(
+	BenchCommandArguments {
+		workload,
+		operations,
+		records,
+		clients,
+		table,
+		clean,
+		seed,
+		conn: DatabaseConnectionArguments {
+			endpoint,
+		},
+		auth: AuthArguments {
+			username,
+			password,
+			auth_level,
+		},
+		sel: DatabaseSelectionArguments {
+			namespace,
+			database,
+		},
+	}: BenchCommandArguments,
+) -> Result<(), Error> {
+	// Initialize opentelemetry and logging
+	crate::telemetry::builder().with_log_level("error").init();
+	// Default datastore configuration for local engines
+	let config = Config::new().capabilities(Capabilities::all());
+
+	// If username and password are specified, and we are connecting to a remote SurrealDB server, then we need to authenticate.
+	// If we are connecting directly to a datastore (i.e. file://local.db or tikv://...), then we don't need to authenticate because we use an embedded (local) SurrealDB instance with auth disabled.
+	let client = if username.is_some()
+		&& password.is_some()
+		&& !endpoint.clone().into_endpoint()?.parse_kind()?.is_local()
+	{
+		debug!("Connecting to the database engine with authentication");
+		let creds = CredentialsBuilder::default()
+			.with_username(username.as_deref())
+			.with_password(password.as_deref())
+			.with_namespace(namespace.as_str())
+			.with_database(database.as_str());
+
+		let client = connect(endpoint).await?;
+
+		debug!("Signing in to the database engine at '{:?}' level", auth_level);
+		match auth_level {
+			CredentialsLevel::Root => client.signin(creds.root()?).await?,
+			CredentialsLevel::Namespace => client.signin(creds.namespace()?).await?,
+			CredentialsLevel::Database => client.signin(creds.database()?).await?,
+		};
+
+		client
+	} else {
+		debug!("Connecting to the database engine without authentication");
+		connect((endpoint, config)).await?
+	};
+
+	// Use the specified namespace / database
+	client.use_ns(namespace).use_db(database).await?;
+	// Never modify a table which already contains data, unless requested
+	let table = Table::from(table);
+	if clean {
+		client.query(format!("DELETE {table}")).await?.check()?;
+	} else {
+		let mut res = client.query(format!("SELECT VALUE id FROM {table} LIMIT 1")).await?;
+		let existing: Value = res.take(0)?;
+		if matches!(existing, Value::Array(ref v) if !v.is_empty()) {
+			return Err(Error::Other(format!(
+				"The table {table} already contains records. Use a different table with --table, or delete the records with --clean"
+			)));
+		}
+	}
+	// Create the records which the workload reads and updates
+	if workload != Workload::Insert {
+		eprintln!("Creating {records} records in {table}");
+		let mut rng = StdRng::seed_from_u64(seed);
+		for id in 0..records {
+			create(&client, &table, id, &mut rng).await?;
+		}
+	}
+	// Run the workload on each client concurrently
+	let clients = clients.max(1);
+	eprintln!("Running {operations} {workload:?} operations with {clients} clients");
+	let start = Instant::now();
+	let mut tasks = Vec::with_capacity(clients as usize);
+	for c in 0..clients {
+		let client = client.clone();
+		let table = table.clone();
+		tasks.push(tokio::spawn(async move {
+			let mut rng = StdRng::seed_from_u64(seed.wrapping_add(c + 1));
+			let mut latencies = Vec::new();
+			// Each client runs every nth operation
+			for op in (c..operations).step_by(clients as usize) {
+				let now = Instant::now();
+				match workload {
+					Workload::Insert => create(&client, &table, op, &mut rng).await?,
+					Workload::Read => read(&client, &table, rng.gen_range(0..records)).await?,
+					Workload::Scan => scan(&client, &table, rng.gen_range(0..records)).await?,
+					Workload::Mixed => match rng.gen_range(0..10) {
+						// Half of the operations are reads
+						0..=4 => read(&client, &table, rng.gen_range(0..records)).await?,
+						// Three in ten operations are updates
+						5..=7 => {
+							let id = rng.gen_range(0..records);
+							create(&client, &table, id, &mut rng).await?
+						}
+						// The remaining operations insert new records
+						_ => create(&client, &table, records + op, &mut rng).await?,
+					},
+				}
+				latencies.push(now.elapsed());
+			}
+			Ok::<_, Error>(latencies)
+		}));
+	}
+	// Collect the latencies from all of the clients
+	let mut latencies = Vec::with_capacity(operations as usize);
+	for task in tasks {
+		latencies.extend(task.await.map_err(|e| Error::Other(e.to_string()))??);
+	}
+	let elapsed = start.elapsed();
+	// Remove the records created by the workload
+	client.query(format!("DELETE {table}")).await?.check()?;
+	// Output the throughput and latency percentiles
+	println!("{}", report(latencies, elapsed));
+	// Everything OK
+	Ok(())
+}
+
+/// Create or replace a record with random content
+async fn create(
+	client: &Surreal<Any>,
+	table: &Table,
+	id: u64,
+	rng: &mut StdRng,
+) -> Result<(), Error> {
+	let value = rng.gen::<i64>();
+	let text = Alphanumeric.sample_string(rng, 32);
+	client
+		.query(format!("UPSERT {table}:{id} CONTENT {{ value: $value, text: $text }} RETURN NONE"))
+		.bind(("value", value))
+		.bind(("text", text))
+		.await?
+		.check()?;
+	Ok(())
+}
+
+/// Select a single record by id
+async fn read(client: &Surreal<Any>, table: &Table, id: u64) -> Result<(), Error> {
+	client.query(format!("SELECT * FROM {table}:{id}")).await?.check()?;
+	Ok(())
+}
+
+/// Select a range of records by id
+async fn scan(client: &Surreal<Any>, table: &Table, id: u64) -> Result<(), Error> {
+	let end = id + SCAN_SIZE;
+	client.query(format!("SELECT * FROM {table}:{id}..{end}")).await?.check()?;
+	Ok(())
+}
+
+/// Summarise the throughput and latency percentiles of a workload
+fn report(mut latencies: Vec<Duration>, elapsed: Duration) -> Value {
+	latencies.sort_unstable();
+	let percentile = |p: f64| {
+		let latency = match latencies.is_empty() {
+			true => Duration::ZERO,
+			false => latencies[((latencies.len() - 1) as f64 * p).round() as usize],
+		};
+		Value::from(sql::Duration::from(latency))
+	};
+	Value::from(map! {
+		String::from("operations") => Value::from(latencies.len()),
+		String::from("elapsed") => Value::from(sql::Duration::from(elapsed)),
+		String::from("throughput") => Value::from(latencies.len() as f64 / elapsed.as_secs_f64()),
+		String::from("p50") => percentile(0.50),
+		String::from("p90") => percentile(0.90),
+		String::from("p99") => percentile(0.99),
+		String::from("max") => percentile(1.0),
+	})
+}
+
+#[cfg(test)]
+mod tests {
+	use super::report;
+	use std::time::Duration;
+	use surrealdb::sql::Value;
+
+	fn millis(ms: u64) -> Value {
+		Value::from(surrealdb::sql::Duration::from(Duration::from_millis(ms)))
+	}
+
+	#[test]
+	fn latency_percentiles() {
+		let latencies = (1..=100).rev().map(Duration::from_millis).collect();
+		let report = report(latencies, Duration::from_secs(2));
+		let expected = Value::from(map! {
+			String::from("operations") => Value::from(100),
+			String::from("elapsed") => millis(2000),
+			String::from("throughput") => Value::from(50.0),
+			String::from("p50") => millis(51),
+			String::from("p90") => millis(90),
+			String::from("p99") => millis(99),
+			String::from("max") => millis(100),
+		});
+		assert_eq!(report, expected);
+	}
+}
@@ -15,6 +15,7 @@ pub struct Config {
 	pub pass: Option<String>,
 	pub crt: Option<PathBuf>,
 	pub key: Option<PathBuf>,
+	pub client_ca: Option<PathBuf>,
 	pub engine: Option<EngineOptions>,
 	pub no_identification_headers: bool,
 }
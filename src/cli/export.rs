@@ -2,18 +2,31 @@ use crate::cli::abstraction::auth::{CredentialsBuilder, CredentialsLevel};
 use crate::cli::abstraction::{
 	AuthArguments, DatabaseConnectionArguments, DatabaseSelectionArguments,
 };
+use crate::cli::records::{self, Format};
 use crate::err::Error;
+use bytes::BytesMut;
 use clap::Args;
 use futures_util::StreamExt;
-use surrealdb::engine::any::{connect, IntoEndpoint};
-use tokio::io::{self, AsyncWriteExt};
+use surrealdb::engine::any::{connect, Any, IntoEndpoint};
+use surrealdb::sql::statements::{DefineStatement, InsertStatement};
+use surrealdb::sql::{Data, Statement, Value};
+use surrealdb::syn;
+use surrealdb::Surreal;
+use tokio::fs::File;
+use tokio::io::{self, AsyncWrite, AsyncWriteExt};
 
 #[derive(Args, Debug)]
 pub struct ExportCommandArguments {
-	#[arg(help = "Path to the SurrealQL file to export. Use dash - to write into stdout.")]
+	#[arg(help = "Path to the file to export. Use dash - to write into stdout.")]
 	#[arg(default_value = "-")]
 	#[arg(index = 1)]
 	file: String,
+	#[arg(help = "The format of the exported file")]
+	#[arg(long, default_value = "sql", value_enum)]
+	format: Format,
+	#[arg(help = "Only export the specified tables")]
+	#[arg(long = "table")]
+	tables: Vec<String>,
 
 	#[command(flatten)]
 	conn: DatabaseConnectionArguments,
@@ -26,6 +39,8 @@ pub struct ExportCommandArguments {
 pub async fn init(
 	ExportCommandArguments {
 		file,
+		format,
+		tables,
 		conn: DatabaseConnectionArguments {
 			endpoint,
 		},
@@ -75,9 +90,18 @@ pub async fn init(
 	client.use_ns(namespace).use_db(database).await?;
 	// Export the data from the database
 	debug!("Exporting data from the database");
-	if file == "-" {
+	if format != Format::Sql {
+		// Get a handle to the output
+		let mut output: Box<dyn AsyncWrite + Unpin + Send> = match file.as_str() {
+			"-" => Box::new(io::stdout()),
+			file => Box::new(File::create(file).await?),
+		};
+		// Export the records of the selected tables
+		export_records(&client, format, tables, &mut output).await?;
+		output.flush().await?;
+	} else if file == "-" {
 		// Prepare the backup
-		let mut backup = client.export(()).await?;
+		let mut backup = client.export(()).tables(tables).await?;
 		// Get a handle to standard output
 		let mut stdout = io::stdout();
 		// Write the backup to standard output
@@ -85,9 +109,81 @@ pub async fn init(
 			stdout.write_all(&bytes?).await?;
 		}
 	} else {
-		client.export(file).await?;
+		client.export(file).tables(tables).await?;
 	}
-	info!("The SurrealQL file was exported successfully");
+	info!("The file was exported successfully");
 	// Everything OK
 	Ok(())
 }
+
+/// Export the records in the specified tables, or all tables if none are
+/// specified. The records are read from a SQL export of the tables, so that
+/// they are paged by record id within a single read transaction.
+async fn export_records(
+	client: &Surreal<Any>,
+	format: Format,
+	tables: Vec<String>,
+	output: &mut (dyn AsyncWrite + Unpin + Send),
+) -> Result<(), Error> {
+	// The records are exported without their table name, so
+	// a file can only contain the records of a single table
+	if tables.len() != 1 {
+		return Err(Error::Other("Exporting to JSONL or CSV requires a single --table".to_owned()));
+	}
+	let mut backup = client.export(()).tables(tables).await?;
+	let mut buffer = BytesMut::new();
+	let mut columns: Vec<String> = Vec::new();
+	let mut header = false;
+	// The length of the incomplete statement at the end of the buffer
+	let mut pending = 0;
+	while let Some(bytes) = backup.next().await {
+		buffer.extend_from_slice(&bytes?);
+		// Only parse an incomplete statement again once the buffer has doubled
+		if buffer.len() < pending * 2 {
+			continue;
+		}
+		while let Some(stmt) = syn::parse_buffered_statement(&mut buffer)? {
+			let batch = match stmt {
+				// Output the defined fields as CSV columns
+				Statement::Define(DefineStatement::Field(fd)) => {
+					let name = fd.name.to_string();
+					if format == Format::Csv && !name.contains(['.', '[']) {
+						columns.push(name);
+					}
+					continue;
+				}
+				Statement::Insert(InsertStatement {
+					data: Data::SingleExpression(Value::Array(batch)),
+					..
+				}) => batch,
+				_ => continue,
+			};
+			let lines: Vec<String> = match format {
+				Format::Csv => {
+					let batch: Vec<_> = batch.into_iter().map(records::strip_id).collect();
+					let mut lines = Vec::with_capacity(batch.len() + 1);
+					// Output the header, with the defined fields and the fields of the first batch
+					if !header {
+						for record in batch.iter() {
+							for key in record.keys() {
+								if !columns.contains(key) {
+									columns.push(key.clone());
+								}
+							}
+						}
+						lines.push(records::to_csv(columns.iter().map(String::as_str)));
+						header = true;
+					}
+					for record in batch.iter() {
+						lines.push(records::to_row(&columns, record)?);
+					}
+					lines
+				}
+				_ => batch.into_iter().map(|v| records::to_jsonl(records::strip_id(v))).collect(),
+			};
+			output.write_all(format!("{}\n", lines.join("\n")).as_bytes()).await?;
+		}
+		pending = buffer.len();
+	}
+	Ok(())
+}
@@ -0,0 +1,98 @@
+use crate::err::Error;
+use clap::Args;
+use glob::glob;
+use std::io::{Error as IoError, ErrorKind};
+use surrealdb::error::Db as DbError;
+use surrealdb::sql::format;
+
+#[derive(Args, Debug)]
+pub struct FmtCommandArguments {
+	#[arg(help = "Glob pattern for the files to format")]
+	#[arg(default_value = "**/*.surql")]
+	patterns: Vec<String>,
+	#[arg(help = "Only check if the files are formatted, without writing any changes")]
+	#[arg(long)]
+	check: bool,
+}
+
+pub async fn init(args: FmtCommandArguments) -> Result<(), Error> {
+	let FmtCommandArguments {
+		patterns,
+		check,
+	} = args;
+
+	let mut entries = vec![];
+
+	for pattern in patterns {
+		let pattern_entries = match glob(&pattern) {
+			Ok(entries) => entries,
+			Err(error) => {
+				eprintln!("Error parsing glob pattern {pattern}: {error}");
+
+				return Err(Error::Io(IoError::new(
+					ErrorKind::Other,
+					format!("Error parsing glob pattern {pattern}: {error}"),
+				)));
+			}
+		};
+
+		entries.extend(pattern_entries.flatten());
+	}
+
+	let mut has_entries = false;
+	let mut unformatted = 0;
+	let mut commented = 0;
+
+	for entry in entries {
+		let file_content = tokio::fs::read_to_string(entry.clone()).await?;
+		let formatted = match format(&file_content) {
+			Ok(v) => format!("{v}\n"),
+			// Comments are not kept when formatting, so these files are never rewritten
+			Err(DbError::FormatComments) => {
+				println!("{}: SKIPPED (contains comments)", entry.display());
+				commented += 1;
+				has_entries = true;
+				continue;
+			}
+			Err(error) => {
+				println!("{}: KO", entry.display());
+				eprintln!("{error}");
+
+				return Err(crate::err::Error::from(error));
+			}
+		};
+
+		if formatted == file_content {
+			println!("{}: OK", entry.display());
+		} else if check {
+			println!("{}: UNFORMATTED", entry.display());
+			unformatted += 1;
+		} else {
+			tokio::fs::write(entry.clone(), formatted).await?;
+			println!("{}: FORMATTED", entry.display());
+		}
+
+		has_entries = true;
+	}
+
+	if !has_entries {
+		eprintln!("No files found");
+		return Err(Error::Io(IoError::new(ErrorKind::NotFound, "No files found".to_string())));
+	}
+
+	if commented > 0 {
+		return Err(Error::Io(IoError::new(
+			ErrorKind::Other,
+			format!("{commented} file(s) contain comments, which can not be formatted"),
+		)));
+	}
+
+	if unformatted > 0 {
+		return Err(Error::Io(IoError::new(
+			ErrorKind::Other,
+			format!("{unformatted} file(s) are not formatted"),
+		)));
+	}
+
+	Ok(())
+}
@@ -2,16 +2,31 @@ use crate::cli::abstraction::auth::{CredentialsBuilder, CredentialsLevel};
 use crate::cli::abstraction::{
 	AuthArguments, DatabaseConnectionArguments, DatabaseSelectionArguments,
 };
+use crate::cli::records::{self, Format, BATCH_SIZE};
 use crate::err::Error;
+use bytes::BytesMut;
 use clap::Args;
-use surrealdb::engine::any::{connect, IntoEndpoint};
+use std::mem;
+use surrealdb::engine::any::{connect, Any, IntoEndpoint};
 use surrealdb::opt::{capabilities::Capabilities, Config};
+use surrealdb::sql::statements::OutputStatement;
+use surrealdb::sql::{self, Object, Statement, Value};
+use surrealdb::syn;
+use surrealdb::Surreal;
+use tokio::fs::File;
+use tokio::io::{self, AsyncBufRead, AsyncBufReadExt, AsyncRead, AsyncReadExt, BufReader};
 
 #[derive(Args, Debug)]
 pub struct ImportCommandArguments {
-	#[arg(help = "Path to the SurrealQL file to import")]
+	#[arg(help = "Path to the file to import. Use dash - to read from stdin.")]
 	#[arg(index = 1)]
 	file: String,
+	#[arg(help = "The format of the imported file")]
+	#[arg(long, default_value = "sql", value_enum)]
+	format: Format,
+	#[arg(help = "The table to import JSONL or CSV records into")]
+	#[arg(long)]
+	table: Option<String>,
 	#[command(flatten)]
 	conn: DatabaseConnectionArguments,
 	#[command(flatten)]
@@ -23,6 +38,8 @@ pub struct ImportCommandArguments {
 pub async fn init(
 	ImportCommandArguments {
 		file,
+		format,
+		table,
 		conn: DatabaseConnectionArguments {
 			endpoint,
 		},
@@ -73,8 +90,192 @@ pub async fn init(
 	// Use the specified namespace / database
 	client.use_ns(namespace).use_db(database).await?;
 	// Import the data into the database
-	client.import(file).await?;
-	info!("The SurrealQL file was imported successfully");
+	match (format, file.as_str()) {
+		(Format::Sql, "-") => {
+			import_statements(&client, io::stdin()).await?;
+		}
+		(Format::Sql, _) => {
+			client.import(file).await?;
+		}
+		(format, file) => {
+			let Some(table) = table else {
+				return Err(Error::Other("Importing JSONL or CSV requires a --table".to_owned()));
+			};
+			// Get a handle to the input
+			let input: Box<dyn AsyncBufRead + Unpin + Send> = match file {
+				"-" => Box::new(BufReader::new(io::stdin())),
+				file => Box::new(BufReader::new(File::open(file).await?)),
+			};
+			import_records(&client, format, &table, input).await?;
+		}
+	}
+	info!("The file was imported successfully");
 	// Everything OK
 	Ok(())
 }
+
+/// Import the SurrealQL statements from the input, executing them in
+/// batches as they are read. Transactions are executed as a whole, and
+/// the parameters which are set with LET are kept between batches.
+async fn import_statements(
+	client: &Surreal<Any>,
+	mut input: impl AsyncRead + Unpin,
+) -> Result<(), Error> {
+	let mut buffer = BytesMut::new();
+	let mut batch = StatementBatch::default();
+	let mut chunk = vec![0; 64 * 1024];
+	// The length of the incomplete statement at the end of the buffer
+	let mut pending = 0;
+	loop {
+		let len = input.read(&mut chunk).await?;
+		if len == 0 {
+			break;
+		}
+		buffer.extend_from_slice(&chunk[..len]);
+		// Only parse an incomplete statement again once the buffer has doubled
+		if buffer.len() < pending * 2 {
+			continue;
+		}
+		while let Some(stmt) = syn::parse_buffered_statement(&mut buffer)? {
+			if let Some(stmts) = batch.push(stmt) {
+				execute_statements(client, stmts).await?;
+			}
+		}
+		pending = buffer.len();
+	}
+	// Parse any statements remaining at the end of the input
+	for stmt in syn::parse(&String::from_utf8_lossy(&buffer))? {
+		if let Some(stmts) = batch.push(stmt) {
+			execute_statements(client, stmts).await?;
+		}
+	}
+	if let Some(stmts) = batch.finish() {
+		execute_statements(client, stmts).await?;
+	}
+	Ok(())
+}
+
+/// Executes a batch of imported statements, and keeps the value
+/// of any parameters which are set, for the following batches
+async fn execute_statements(client: &Surreal<Any>, mut stmts: Vec<Statement>) -> Result<(), Error> {
+	// Return the value of each parameter at the end of the batch
+	let names: Vec<String> = stmts
+		.iter()
+		.filter_map(|stmt| match stmt {
+			Statement::Set(v) => Some(v.name.clone()),
+			_ => None,
+		})
+		.collect();
+	for name in names.iter() {
+		let mut stmt = OutputStatement::default();
+		stmt.what = Value::Param(name.as_str().into());
+		stmts.push(Statement::Output(stmt));
+	}
+	let offset = stmts.len() - names.len();
+	let mut res = client.query(stmts).await?.check()?;
+	for (i, name) in names.into_iter().enumerate() {
+		let value: Value = res.take(offset + i)?;
+		client.set(name, value).await?;
+	}
+	Ok(())
+}
+
+/// Groups imported statements into batches which can be executed on their own
+#[derive(Default)]
+struct StatementBatch {
+	/// The OPTION and USE statements which apply to every following batch
+	prelude: Vec<Statement>,
+	/// The statements which have not been executed yet
+	batch: Vec<Statement>,
+	/// Whether the batch contains an unfinished transaction
+	transaction: bool,
+}
+
+impl StatementBatch {
+	/// Adds a statement, returning a batch once enough statements have been read
+	fn push(&mut self, stmt: Statement) -> Option<Vec<Statement>> {
+		match stmt {
+			Statement::Begin(_) => self.transaction = true,
+			Statement::Commit(_) | Statement::Cancel(_) => self.transaction = false,
+			Statement::Option(_) | Statement::Use(_) if !self.transaction => {
+				self.prelude.push(stmt);
+				return None;
+			}
+			_ => (),
+		}
+		self.batch.push(stmt);
+		match !self.transaction && self.batch.len() >= BATCH_SIZE {
+			true => self.finish(),
+			false => None,
+		}
+	}
+
+	/// Returns a batch of any remaining statements
+	fn finish(&mut self) -> Option<Vec<Statement>> {
+		if self.batch.is_empty() {
+			return None;
+		}
+		let mut stmts = self.prelude.clone();
+		stmts.append(&mut self.batch);
+		Some(stmts)
+	}
+}
+
+/// Import the JSONL or CSV records from the input into the specified table
+async fn import_records(
+	client: &Surreal<Any>,
+	format: Format,
+	table: &str,
+	input: Box<dyn AsyncBufRead + Unpin + Send>,
+) -> Result<(), Error> {
+	let query = format!("INSERT INTO {} $data", sql::Table::from(table));
+	let mut batch = Vec::with_capacity(BATCH_SIZE);
+	let mut header: Option<Vec<String>> = None;
+	let mut pending = String::new();
+	let mut lines = input.lines();
+	while let Some(line) = lines.next_line().await? {
+		match format {
+			Format::Jsonl => {
+				if line.trim().is_empty() {
+					continue;
+				}
+				let record = sql::json(&line)
+					.map_err(|e| Error::Other(format!("Invalid JSON record: {e}")))?;
+				batch.push(record);
+			}
+			_ => {
+				// A quoted cell may continue on the next line
+				if !pending.is_empty() {
+					pending.push('\n');
+				}
+				pending.push_str(&line);
+				let Some(cells) = records::from_csv(&pending) else {
+					continue;
+				};
+				pending.clear();
+				// The first record contains the column names
+				let Some(columns) = &header else {
+					header = Some(cells);
+					continue;
+				};
+				let mut record = Object::default();
+				for (column, cell) in columns.iter().zip(cells) {
+					match records::from_cell(cell) {
+						Value::None => continue,
+						value => record.insert(column.clone(), value),
+					};
+				}
+				batch.push(Value::from(record));
+			}
+		}
+		// Insert the records in batches
+		if batch.len() == BATCH_SIZE {
+			let data = Value::from(mem::take(&mut batch));
+			client.query(&query).bind(("data", data)).await?.check()?;
+		}
+	}
+	if !batch.is_empty() {
+		client.query(&query).bind(("data", Value::from(batch))).await?.check()?;
+	}
+	Ok(())
+}
@@ -0,0 +1,47 @@
+use crate::err::Error;
+use clap::Args;
+use surrealdb::engine::any::IntoEndpoint;
+use surrealdb::kvs::{Datastore, Version};
+
+#[derive(Args, Debug)]
+pub struct MigrateCommandArguments {
+	#[arg(help = "Database path of the datastore to upgrade")]
+	#[arg(env = "SURREAL_PATH", index = 1)]
+	#[arg(value_parser = super::validator::path_valid)]
+	path: String,
+	#[arg(help = "Also rewrite all definitions and records in the latest storage format")]
+	#[arg(long)]
+	rewrite: bool,
+}
+
+pub async fn init(
+	MigrateCommandArguments {
+		path,
+		rewrite,
+	}: MigrateCommandArguments,
+) -> Result<(), Error> {
+	// Initialize opentelemetry and logging
+	crate::telemetry::builder().with_log_level("info").init();
+	// Clean the path
+	let endpoint = path.into_endpoint()?;
+	let path = if endpoint.path.is_empty() {
+		endpoint.url.to_string()
+	} else {
+		endpoint.path
+	};
+	// Upgrade the datastore to the latest storage format
+	let dbs = Datastore::new(&path).await?;
+	let version = dbs.migrate().await?;
+	if version.is_latest() {
+		info!("The datastore already uses the latest storage format");
+	} else {
+		info!("Upgraded the datastore from storage version {} to {}", version.0, Version::LATEST.0);
+	}
+	// Rewrite the stored data, if requested
+	if rewrite {
+		dbs.rewrite().await?;
+		info!("Rewrote all definitions and records in the latest storage format");
+	}
+	// All ok
+	Ok(())
+}
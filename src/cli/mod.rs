@@ -1,9 +1,13 @@
 pub(crate) mod abstraction;
+mod bench;
 mod config;
 mod export;
+mod fmt;
 mod import;
 mod isready;
+mod migrate;
 mod ml;
+mod records;
 mod sql;
 mod start;
 #[cfg(test)]
@@ -17,16 +21,20 @@ mod version_client;
 use crate::cli::version_client::VersionClient;
 use crate::cnf::{DEBUG_BUILD_WARNING, LOGO, PKG_VERSION};
 use crate::env::RELEASE;
+use bench::BenchCommandArguments;
 use clap::{Parser, Subcommand};
 pub use config::CF;
 use export::ExportCommandArguments;
+use fmt::FmtCommandArguments;
 use import::ImportCommandArguments;
 use isready::IsReadyCommandArguments;
+use migrate::MigrateCommandArguments;
 use ml::MlCommand;
 use semver::Version;
 use sql::SqlCommandArguments;
 use start::StartCommandArguments;
 use std::ops::Deref;
+use std::path::PathBuf;
 use std::process::ExitCode;
 use std::time::Duration;
 use upgrade::UpgradeCommandArguments;
@@ -57,6 +65,10 @@ struct Cli {
 	#[arg(env = "SURREAL_ONLINE_VERSION_CHECK", long)]
 	#[arg(default_value_t = true)]
 	online_version_check: bool,
+	#[arg(help = "A TOML or YAML settings file, which flags and environment variables override")]
+	#[arg(env = "SURREAL_CONFIG", long, global = true)]
+	#[allow(dead_code)] // The file is loaded before the arguments are parsed
+	config: Option<PathBuf>,
 }
 
 #[allow(clippy::large_enum_variant)]
@@ -87,6 +99,12 @@ enum Commands {
 	IsReady(IsReadyCommandArguments),
 	#[command(about = "Validate SurrealQL query files")]
 	Validate(ValidateCommandArguments),
+	#[command(about = "Format SurrealQL query files")]
+	Fmt(FmtCommandArguments),
+	#[command(about = "Benchmark a workload against an existing database")]
+	Bench(BenchCommandArguments),
+	#[command(about = "Upgrade the storage format of an existing datastore")]
+	Migrate(MigrateCommandArguments),
 }
 
 pub async fn init() -> ExitCode {
@@ -130,6 +148,9 @@ pub async fn init() -> ExitCode {
 		Commands::Ml(args) => ml::init(args).await,
 		Commands::IsReady(args) => isready::init(args).await,
 		Commands::Validate(args) => validate::init(args).await,
+		Commands::Fmt(args) => fmt::init(args).await,
+		Commands::Bench(args) => bench::init(args).await,
+		Commands::Migrate(args) => migrate::init(args).await,
 	};
 	// Save the flamegraph and profile
 	#[cfg(feature = "performance-profiler")]
@@ -0,0 +1,179 @@
+use crate::err::Error;
+use clap::ValueEnum;
+use std::mem;
+use surrealdb::sql::{self, Object, Value};
+
+/// The number of records or statements which are imported at once
+pub(crate) const BATCH_SIZE: usize = 1000;
+
+/// The format of an exported or imported file
+#[derive(ValueEnum, Clone, Copy, Debug, Eq, PartialEq)]
+pub(crate) enum Format {
+	/// SurrealQL statements
+	Sql,
+	/// One JSON object per line
+	Jsonl,
+	/// Comma-separated values, with a header row
+	Csv,
+}
+
+/// Replace the record id of a record with its key, so that
+/// it can be imported into a table with a different name
+pub(crate) fn strip_id(record: Value) -> Object {
+	match record {
+		Value::Object(mut v) => {
+			if let Some(Value::Thing(id)) = v.get("id") {
+				let key = Value::from(id.id.clone());
+				v.insert("id".to_owned(), key);
+			}
+			v
+		}
+		_ => Object::default(),
+	}
+}
+
+/// Output a record as a single line of JSON
+pub(crate) fn to_jsonl(record: Object) -> String {
+	Value::from(record).into_json().to_string()
+}
+
+/// Output a row of cells as a single CSV record
+pub(crate) fn to_csv<'a>(cells: impl Iterator<Item = &'a str>) -> String {
+	cells
+		.map(|v| match v.contains([',', '"', '\n', '\r']) {
+			true => format!("\"{}\"", v.replace('"', "\"\"")),
+			false => v.to_owned(),
+		})
+		.collect::<Vec<_>>()
+		.join(",")
+}
+
+/// Output a record as a CSV row with the specified columns. The columns
+/// of a CSV file are written in its header, so a record with a field
+/// which is not one of these columns can not be exported.
+pub(crate) fn to_row(columns: &[String], record: &Object) -> Result<String, Error> {
+	if let Some(key) = record.keys().find(|k| !columns.contains(k)) {
+		return Err(Error::Other(format!(
+			"The field '{key}' is not a column of the CSV header, define it on the table to export it"
+		)));
+	}
+	let cells: Vec<String> = columns.iter().map(|c| to_cell(record.get(c))).collect();
+	Ok(to_csv(cells.iter().map(String::as_str)))
+}
+
+/// Output a value as a CSV cell, writing strings as they are,
+/// and any other values as JSON. Strings which would be read
+/// back as a different value, such as "10", are written as JSON.
+pub(crate) fn to_cell(value: Option<&Value>) -> String {
+	match value {
+		None | Some(Value::None) => String::new(),
+		Some(Value::Strand(v)) if !v.is_empty() && !is_json(v) => v.as_str().to_owned(),
+		Some(v) => v.clone().into_json().to_string(),
+	}
+}
+
+/// Parse a CSV cell into a value, treating anything
+/// which is not valid JSON as a string
+pub(crate) fn from_cell(cell: String) -> Value {
+	if cell.is_empty() {
+		return Value::None;
+	}
+	match is_json(&cell) {
+		true => sql::json(&cell).unwrap_or_else(|_| Value::from(cell)),
+		false => Value::from(cell),
+	}
+}
+
+/// Check if a CSV cell holds a complete and valid JSON value
+fn is_json(cell: &str) -> bool {
+	serde_json::from_str::<serde_json::Value>(cell).is_ok()
+}
+
+/// Parse a CSV record into cells, returning None if a quoted
+/// cell has not been closed, and the record continues on
+/// the next line
+pub(crate) fn from_csv(input: &str) -> Option<Vec<String>> {
+	let mut cells = Vec::new();
+	let mut cell = String::new();
+	let mut quoted = false;
+	let mut chars = input.chars().peekable();
+	while let Some(c) = chars.next() {
+		match (quoted, c) {
+			(true, '"') if chars.peek() == Some(&'"') => {
+				chars.next();
+				cell.push('"');
+			}
+			(true, '"') => quoted = false,
+			(false, '"') => quoted = true,
+			(false, ',') => cells.push(mem::take(&mut cell)),
+			(_, c) => cell.push(c),
+		}
+	}
+	if quoted {
+		return None;
+	}
+	cells.push(cell);
+	Some(cells)
+}
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+
+	#[test]
+	fn csv_round_trip() {
+		let cells = ["one", "with, comma", "with \"quotes\"", "multi\nline"];
+		let line = to_csv(cells.iter().copied());
+		assert_eq!(line, "one,\"with, comma\",\"with \"\"quotes\"\"\",\"multi\nline\"");
+		let (first, rest) = line.split_once('\n').unwrap();
+		assert_eq!(from_csv(first), None);
+		assert_eq!(from_csv(&format!("{first}\n{rest}")).unwrap(), cells);
+	}
+
+	#[test]
+	fn row_with_unknown_column() {
+		let columns = vec!["id".to_owned(), "name".to_owned()];
+		let record = strip_id(Value::parse("{ id: person:one, name: 'Tobie' }"));
+		assert_eq!(to_row(&columns, &record).unwrap(), "one,Tobie");
+		let record = strip_id(Value::parse("{ id: person:two }"));
+		assert_eq!(to_row(&columns, &record).unwrap(), "two,");
+		let record = strip_id(Value::parse("{ id: person:three, age: 21 }"));
+		assert!(to_row(&columns, &record).is_err());
+	}
+
+	#[test]
+	fn cell_values() {
+		assert_eq!(to_cell(Some(&Value::from("text"))), "text");
+		assert_eq!(to_cell(Some(&Value::from(10))), "10");
+		assert_eq!(to_cell(None), "");
+		assert_eq!(from_cell("text".to_owned()), Value::from("text"));
+		assert_eq!(from_cell("10".to_owned()), Value::from(10));
+		assert_eq!(from_cell("\"10\"".to_owned()), Value::from("10"));
+		assert_eq!(from_cell(String::new()), Value::None);
+	}
+
+	#[test]
+	fn cell_round_trip() {
+		let values = [
+			Value::from("text"),
+			Value::from("00123"),
+			Value::from("10"),
+			Value::from("true"),
+			Value::from("\"quoted\""),
+			Value::from("10 apples"),
+			Value::from("person:tobie"),
+			Value::from(""),
+			Value::from(10),
+			Value::from(true),
+		];
+		for value in values {
+			assert_eq!(from_cell(to_cell(Some(&value))), value, "{value:?}");
+		}
+	}
+
+	#[test]
+	fn record_ids_are_stripped() {
+		let record = sql::value("{ id: person:tobie, name: 'Tobie' }").unwrap();
+		assert_eq!(to_jsonl(strip_id(record)), r#"{"id":"tobie","name":"Tobie"}"#);
+	}
+}
@@ -6,16 +6,19 @@ use crate::cnf::PKG_VERSION;
 use crate::err::Error;
 use clap::Args;
 use futures::StreamExt;
+use rustyline::completion::Completer;
 use rustyline::error::ReadlineError;
 use rustyline::validate::{ValidationContext, ValidationResult, Validator};
-use rustyline::{Completer, Editor, Helper, Highlighter, Hinter};
+use rustyline::{Context, Editor, Helper, Highlighter, Hinter};
 use serde::Serialize;
 use serde_json::ser::PrettyFormatter;
-use surrealdb::engine::any::{connect, IntoEndpoint};
+use std::collections::BTreeSet;
+use std::time::Instant;
+use surrealdb::engine::any::{connect, Any, IntoEndpoint};
 use surrealdb::method::{Stats, WithStats};
 use surrealdb::opt::{capabilities::Capabilities, Config};
 use surrealdb::sql::{self, Statement, Value};
-use surrealdb::{Notification, Response};
+use surrealdb::{Notification, Response, Surreal};
 
 #[derive(Args, Debug)]
 pub struct SqlCommandArguments {
@@ -31,6 +34,9 @@ pub struct SqlCommandArguments {
 	/// Whether to emit results in JSON
 	#[arg(long)]
 	json: bool,
+	/// Whether to emit results as a table
+	#[arg(long, conflicts_with = "json")]
+	table: bool,
 	/// Whether omitting semicolon causes a newline
 	#[arg(long)]
 	multi: bool,
@@ -55,6 +61,7 @@ pub async fn init(
 		},
 		pretty,
 		json,
+		table,
 		multi,
 		hide_welcome,
 		..
@@ -96,8 +103,9 @@ pub async fn init(
 	// Create a new terminal REPL
 	let mut rl = Editor::new().unwrap();
 	// Set custom input validation
-	rl.set_helper(Some(InputValidator {
+	rl.set_helper(Some(InputHelper {
 		multi,
+		completions: BTreeSet::new(),
 	}));
 	// Load the command-line history
 	let _ = rl.load_history("history.txt");
@@ -119,11 +127,18 @@ pub async fn init(
 		}
 		_ => {}
 	}
+	// Load the table and field names for completion
+	if let Some(helper) = rl.helper_mut() {
+		helper.completions = completions(&client).await;
+	}
+	// Whether to show the time taken by each query
+	let mut timing = false;
 
 	if !hide_welcome {
 		let hints = [
 			(true, "Different statements within a query should be separated by a (;) semicolon."),
 			(!multi, "To create a multi-line query, end your lines with a (\\) backslash, and press enter."),
+			(true, "To show the time taken by each query, toggle timing with \\timing."),
 			(true, "To exit, send a SIGTERM or press CTRL+C")
 		]
 		.iter()
@@ -177,12 +192,28 @@ pub async fn init(
 		if line.trim().is_empty() {
 			continue;
 		}
+		// Process any shell commands
+		if let Some(command) = line.trim().strip_prefix('\\') {
+			match command.trim() {
+				"timing" => {
+					timing = !timing;
+					let state = match timing {
+						true => "on",
+						false => "off",
+					};
+					eprintln!("Timing is {state}.\n");
+				}
+				command => eprintln!("Unknown command: \\{command}\n"),
+			}
+			continue;
+		}
 		// Complete the request
 		match sql::parse(&line) {
 			Ok(query) => {
 				let mut namespace = None;
 				let mut database = None;
 				let mut vars = Vec::new();
+				let mut refresh = false;
 				// Capture `use` and `set/let` statements from the query
 				for statement in query.iter() {
 					match statement {
@@ -193,10 +224,15 @@ pub async fn init(
 							if let Some(db) = &stmt.db {
 								database = Some(db.clone());
 							}
+							refresh = true;
 						}
 						Statement::Set(stmt) => {
 							vars.push((stmt.name.clone(), stmt.what.clone()));
 						}
+						// These statements change the table and field names
+						Statement::Define(_) | Statement::Remove(_) | Statement::Rename(_) => {
+							refresh = true;
+						}
 						_ => {}
 					}
 				}
@@ -210,10 +246,14 @@ pub async fn init(
 					continue;
 				}
 				// Run the query provided
+				let start = Instant::now();
 				let result = client.query(query).with_stats().await;
-				let result = process(pretty, json, result);
+				let result = process(pretty, json, table, result);
 				let result_is_error = result.is_err();
 				print(result);
+				if timing {
+					println!("-- Time: {:?}\n", start.elapsed());
+				}
 				if result_is_error {
 					continue;
 				}
@@ -238,6 +278,12 @@ pub async fn init(
 						prompt = format!("{namespace}/{database}> ");
 					}
 				}
+				// Refresh the table and field names for completion
+				if refresh {
+					if let Some(helper) = rl.helper_mut() {
+						helper.completions = completions(&client).await;
+					}
+				}
 			}
 			Err(e) => {
 				eprintln!("{e}\n");
@@ -253,6 +299,7 @@ pub async fn init(
 fn process(
 	pretty: bool,
 	json: bool,
+	table: bool,
 	res: surrealdb::Result<WithStats<Response>>,
 ) -> Result<String, Error> {
 	// Check query response for an error
@@ -326,6 +373,21 @@ fn process(
 		}
 	});
 
+	// Check if we should emit a table
+	if table {
+		return Ok(vec
+			.into_iter()
+			.enumerate()
+			.map(|(index, (stats, value))| {
+				let query_num = index + 1;
+				let execution_time = stats.execution_time.unwrap_or_default();
+				let output = render_table(&value);
+				format!("-- Query {query_num} (execution time: {execution_time:?})\n{output}")
+			})
+			.collect::<Vec<String>>()
+			.join("\n"));
+	}
+
 	// Check if we should emit JSON and/or prettify
 	Ok(match (json, pretty) {
 		// Don't prettify the SurrealQL response
@@ -380,14 +442,139 @@ fn print(result: Result<String, Error>) {
 	}
 }
 
-#[derive(Completer, Helper, Highlighter, Hinter)]
-struct InputValidator {
+/// Render an array of objects as a table, with a column for each field
+fn render_table(value: &Value) -> String {
+	let Value::Array(rows) = value else {
+		return value.to_string();
+	};
+	// Only arrays of objects can be shown as a table
+	let mut objects = Vec::with_capacity(rows.len());
+	for row in rows.iter() {
+		match row {
+			Value::Object(v) => objects.push(v),
+			_ => return value.to_string(),
+		}
+	}
+	// Collect the columns from all of the rows
+	let mut columns: Vec<&String> = Vec::new();
+	for object in objects.iter() {
+		for key in object.keys() {
+			if !columns.contains(&key) {
+				columns.push(key);
+			}
+		}
+	}
+	// Format each of the cells
+	let cells: Vec<Vec<String>> = objects
+		.iter()
+		.map(|object| {
+			columns
+				.iter()
+				.map(|column| match object.get(column.as_str()) {
+					Some(Value::Strand(v)) => v.as_str().to_owned(),
+					Some(v) => v.to_string(),
+					None => String::new(),
+				})
+				.collect()
+		})
+		.collect();
+	// Calculate the width of each column
+	let widths: Vec<usize> = columns
+		.iter()
+		.enumerate()
+		.map(|(i, column)| {
+			cells.iter().map(|row| row[i].chars().count()).fold(column.chars().count(), usize::max)
+		})
+		.collect();
+	// Output the header, divider, and rows
+	let line = |row: Vec<&str>| {
+		let row: Vec<String> =
+			row.iter().zip(&widths).map(|(cell, width)| format!(" {cell:width$} ")).collect();
+		format!("|{}|", row.join("|"))
+	};
+	let mut output = vec![
+		line(columns.iter().map(|c| c.as_str()).collect()),
+		format!("|{}|", widths.iter().map(|w| "-".repeat(w + 2)).collect::<Vec<_>>().join("|")),
+	];
+	for row in cells.iter() {
+		output.push(line(row.iter().map(String::as_str).collect()));
+	}
+	output.join("\n")
+}
+
+/// Fetch the table and field names in the selected database
+async fn completions(client: &Surreal<Any>) -> BTreeSet<String> {
+	let mut words = BTreeSet::new();
+	// Fetch the tables in the database
+	let Ok(mut response) = client.query("INFO FOR DB").await else {
+		return words;
+	};
+	let Ok(Value::Object(info)) = response.take::<Value>(0) else {
+		return words;
+	};
+	let Some(Value::Object(tables)) = info.get("tables") else {
+		return words;
+	};
+	words.extend(tables.keys().cloned());
+	// Fetch the fields on all of the tables in one request
+	let query: String = tables
+		.keys()
+		.map(|table| format!("INFO FOR TABLE {};", sql::Table::from(table.as_str())))
+		.collect();
+	if query.is_empty() {
+		return words;
+	}
+	let Ok(mut response) = client.query(query).await else {
+		return words;
+	};
+	for index in 0..tables.len() {
+		let Ok(Value::Object(info)) = response.take::<Value>(index) else {
+			continue;
+		};
+		if let Some(Value::Object(fields)) = info.get("fields") {
+			words.extend(fields.keys().cloned());
+		}
+	}
+	words
+}
+
+#[derive(Helper, Highlighter, Hinter)]
+struct InputHelper {
 	/// If omitting semicolon causes newline.
 	multi: bool,
+	/// The table and field names to complete.
+	completions: BTreeSet<String>,
+}
+
+impl Completer for InputHelper {
+	type Candidate = String;
+
+	fn complete(
+		&self,
+		line: &str,
+		pos: usize,
+		_: &Context<'_>,
+	) -> rustyline::Result<(usize, Vec<String>)> {
+		// Find the start of the word before the cursor
+		let start = line[..pos]
+			.char_indices()
+			.rev()
+			.find(|(_, c)| !(c.is_alphanumeric() || *c == '_' || *c == '.'))
+			.map(|(i, c)| i + c.len_utf8())
+			.unwrap_or(0);
+		let word = &line[start..pos];
+		// Don't complete an empty word
+		if word.is_empty() {
+			return Ok((pos, vec![]));
+		}
+		// Find the names which start with the word
+		let candidates = self.completions.iter().filter(|v| v.starts_with(word)).cloned().collect();
+		Ok((start, candidates))
+	}
 }
 
 #[allow(clippy::if_same_then_else)]
-impl Validator for InputValidator {
+impl Validator for InputHelper {
 	fn validate(&self, ctx: &mut ValidationContext) -> rustyline::Result<ValidationResult> {
 		use ValidationResult::{Incomplete, Invalid, Valid};
 		// Filter out all new line characters
@@ -395,7 +582,9 @@ impl Validator for InputValidator {
 		// Trim all whitespace from the user input
 		let input = input.trim();
 		// Process the input to check if we can send the query
-		let result = if self.multi && !input.ends_with(';') {
+		let result = if input.starts_with('\\') {
+			Valid(None) // The line is a shell command
+		} else if self.multi && !input.ends_with(';') {
 			Incomplete // The line doesn't end with a ; and we are in multi mode
 		} else if self.multi && input.is_empty() {
 			Incomplete // The line was empty and we are in multi mode
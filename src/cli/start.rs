@@ -8,6 +8,7 @@ use crate::dbs::{StartCommandDbsOptions, DB};
 use crate::env;
 use crate::err::Error;
 use crate::net::{self, client_ip::ClientIp};
+use crate::telemetry::LogFormat;
 use clap::Args;
 use opentelemetry::Context as TelemetryContext;
 use std::net::SocketAddr;
@@ -30,6 +31,10 @@ pub struct StartCommandArguments {
 	#[arg(default_value = "info")]
 	#[arg(value_parser = CustomEnvFilterParser::new())]
 	log: CustomEnvFilter,
+	#[arg(help = "The format of the logs written by the database server")]
+	#[arg(env = "SURREAL_LOG_FORMAT", long = "log-format")]
+	#[arg(default_value = "text", value_enum, ignore_case = true)]
+	log_format: LogFormat,
 	#[arg(help = "Whether to hide the startup banner")]
 	#[arg(env = "SURREAL_NO_BANNER", long)]
 	#[arg(default_value_t = false)]
@@ -132,6 +137,9 @@ struct StartCommandWebTlsOptions {
 	#[arg(help = "Path to the private key file for encrypted client connections")]
 	#[arg(env = "SURREAL_WEB_KEY", long = "web-key", value_parser = super::validator::file_exists)]
 	web_key: Option<PathBuf>,
+	#[arg(help = "Path to the CA file used to require and verify client certificates")]
+	#[arg(env = "SURREAL_WEB_CLIENT_CA", long = "web-client-ca", value_parser = super::validator::file_exists)]
+	web_client_ca: Option<PathBuf>,
 }
 
 pub async fn init(
@@ -144,6 +152,7 @@ pub async fn init(
 		dbs,
 		web,
 		log,
+		log_format,
 		tick_interval,
 		no_banner,
 		no_identification_headers,
@@ -151,7 +160,7 @@ pub async fn init(
 	}: StartCommandArguments,
 ) -> Result<(), Error> {
 	// Initialize opentelemetry and logging
-	crate::telemetry::builder().with_filter(log).init();
+	crate::telemetry::builder().with_filter(log).with_log_format(log_format).init();
 	// Start metrics subsystem
 	crate::telemetry::metrics::init(&TelemetryContext::current())
 		.expect("failed to initialize metrics");
@@ -178,6 +187,7 @@ pub async fn init(
 		no_identification_headers,
 		crt: web.as_ref().and_then(|x| x.web_crt.clone()),
 		key: web.as_ref().and_then(|x| x.web_key.clone()),
+		client_ca: web.as_ref().and_then(|x| x.web_client_ca.clone()),
 		engine: Some(EngineOptions::default().with_tick_interval(tick_interval)),
 	});
 	// This is the cancellation token propagated down to
@@ -192,6 +202,8 @@ pub async fn init(
 		&config::CF.get().unwrap().engine.unwrap_or_default(),
 		DB.get().unwrap().clone(),
 	);
+	// Start exporting the namespace usage metrics
+	tokio::spawn(crate::telemetry::metrics::usage::export(ct.clone()));
 	// Start the web server
 	net::init(ct.clone()).await?;
 	// Shutdown and stop closed tasks
@@ -205,3 +217,27 @@ pub async fn init(
 	// All ok
 	Ok(())
 }
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+	use clap::Parser;
+
+	#[derive(Parser, Debug)]
+	struct Cli {
+		#[command(flatten)]
+		start: StartCommandArguments,
+	}
+
+	#[test]
+	fn log_format_is_validated() {
+		let cli = Cli::try_parse_from(["surreal"]).unwrap();
+		assert_eq!(cli.start.log_format, LogFormat::Text);
+		let cli = Cli::try_parse_from(["surreal", "--log-format", "json"]).unwrap();
+		assert_eq!(cli.start.log_format, LogFormat::Json);
+		let cli = Cli::try_parse_from(["surreal", "--log-format", "JSON"]).unwrap();
+		assert_eq!(cli.start.log_format, LogFormat::Json);
+		let err = Cli::try_parse_from(["surreal", "--log-format", "xml"]).unwrap_err();
+		assert_eq!(err.kind(), clap::error::ErrorKind::InvalidValue);
+	}
+}
@@ -0,0 +1,616 @@
+//! Loads server settings from a TOML or YAML configuration file. Files with
+//! a `.yaml` or `.yml` extension are read as YAML, and other files as TOML.
+//!
+//! Each setting in the file is exported as the equivalent `SURREAL_`
+//! environment variable, so any option which can be specified with an
+//! environment variable can also be specified in the configuration file.
+//! Nested tables are joined with underscores, so `query_per_second = 100`
+//! within a `[rate_limit]` table sets `SURREAL_RATE_LIMIT_QUERY_PER_SECOND`.
+//!
+//! Settings are applied with the following precedence, from highest to
+//! lowest: command-line flags, environment variables, the configuration
+//! file, and the default values.
+//!
+//! Quotas for specific namespaces and databases, which take precedence over
+//! the default quotas, can only be specified in the configuration file:
+//!
+//! ```toml
+//! [[quota]]
+//! ns = "tenant"
+//! queries-per-second = 100
+//!
+//! [[quota]]
+//! ns = "tenant"
+//! db = "app"
+//! table-max-records = 100000
+//! storage-max-mb = 1024
+//! ```
+//!
+//! Rules which map verified client certificates to a role, for servers
+//! which require client certificates with `--web-client-ca`, can also only
+//! be specified in the configuration file. The first matching rule is used,
+//! and every attribute (`cn`, `ou`, or `san`) which is specified must match.
+//! Each rule needs to specify the role which is granted, and the `level` it
+//! is granted at, which is `root`, `namespace` with an `ns`, or `database`
+//! with an `ns` and a `db`:
+//!
+//! ```toml
+//! [[certificate]]
+//! cn = "billing-service"
+//! role = "editor"
+//! level = "database"
+//! ns = "billing"
+//! db = "app"
+//! ```
+//!
+//! Rules which set the idle timeout of WebSocket connections, in seconds,
+//! for sessions at a specific auth `level` (`root`, `namespace`, `database`,
+//! or `record`), on a specific `ns` or `db`, or authenticated with a specific
+//! `access` method, can also only be specified in the configuration file.
+//! The first matching rule is used, and every attribute which is specified
+//! must match. Other connections use `websocket-idle-timeout`:
+//!
+//! ```toml
+//! [[session]]
+//! level = "record"
+//! ns = "tenant"
+//! db = "app"
+//! access = "users"
+//! idle-timeout = 300
+//! ```
+//!
+//! The settings are only exported to the environment at startup, before
+//! any other threads are running. When the file is reloaded, the settings
+//! which can be changed at runtime are instead passed to the datastore,
+//! as modifying the environment of a running process is not thread-safe.
+//! The whole file is checked before any of it is applied, so a reload which
+//! fails leaves the previous configuration in place.
+
+use crate::err::Error;
+use crate::net::tls::{self, CertificateRule};
+use crate::rpc::idle::{self, IdleRule};
+use std::collections::{BTreeMap, HashMap};
+use std::path::{Path, PathBuf};
+use std::sync::OnceLock;
+use surrealdb::cnf::Quota;
+use surrealdb::iam::Level;
+
+/// The environment variable which specifies the configuration file
+const CONFIG_VAR: &str = "SURREAL_CONFIG";
+
+/// The log level which is used when none is specified
+const DEFAULT_LOG: &str = "info";
+
+/// The list of tables in the file which specify quotas
+const QUOTA_KEY: &str = "quota";
+
+/// The list of tables in the file which map client certificates to a role
+const CERTIFICATE_KEY: &str = "certificate";
+
+/// The list of tables in the file which set the idle timeout of sessions
+const SESSION_KEY: &str = "session";
+
+/// The configuration file which was loaded at startup
+static PATH: OnceLock<PathBuf> = OnceLock::new();
+
+/// The environment variables which were set outside of the configuration file
+static ENVIRONMENT: OnceLock<HashMap<String, String>> = OnceLock::new();
+
+/// The contents of a configuration file, which have been checked but not yet applied
+struct Config {
+	settings: BTreeMap<String, String>,
+	quotas: Vec<Quota>,
+	certificates: Vec<CertificateRule>,
+	sessions: Vec<IdleRule>,
+}
+
+impl Config {
+	/// Read and check the configuration file at the specified path
+	fn load(path: &Path) -> Result<Self, Error> {
+		let input = std::fs::read_to_string(path)?;
+		let table = read(path, &input)?;
+		Ok(Self {
+			settings: parse(&table),
+			quotas: quotas(&table)?,
+			certificates: certificates(&table)?,
+			sessions: sessions(&table)?,
+		})
+	}
+
+	/// Apply the quotas and rules in the file
+	fn apply(self) {
+		surrealdb::cnf::set_quotas(self.quotas);
+		tls::set_rules(self.certificates);
+		idle::set_rules(self.sessions);
+	}
+}
+
+/// Load the configuration file specified with the `--config` flag or the
+/// `SURREAL_CONFIG` environment variable, if any. This needs to run before
+/// the runtime is started and the command-line arguments are parsed, so
+/// that the settings in the file are used in place of any missing flags.
+pub fn init() -> Result<(), Error> {
+	// Check if a configuration file was specified
+	let Some(path) = flag(&["--config"]).or_else(|| std::env::var(CONFIG_VAR).ok()) else {
+		return Ok(());
+	};
+	// Remember which settings were already set in the environment
+	let environment = ENVIRONMENT.get_or_init(|| std::env::vars().collect());
+	let path = PATH.get_or_init(|| PathBuf::from(path));
+	// Read and check the settings in the file
+	let mut config = Config::load(path)?;
+	let settings: HashMap<_, _> = std::mem::take(&mut config.settings).into_iter().collect();
+	super::check(&settings).map_err(Error::Config)?;
+	// Export the settings which are not set in the environment
+	for (key, val) in settings {
+		if !environment.contains_key(&key) {
+			std::env::set_var(key, val);
+		}
+	}
+	// Apply the quotas and rules in the file
+	config.apply();
+	// All ok
+	Ok(())
+}
+
+/// Load the configuration file again, and apply the settings which can
+/// be changed while the server is running. Settings which were specified
+/// with a flag or an environment variable are left unchanged. Nothing is
+/// applied unless every setting in the file is valid.
+pub fn reload() -> Result<(), Error> {
+	// Read and check the settings in the file, if one was loaded
+	let config = match PATH.get() {
+		Some(path) => {
+			let mut config = Config::load(path)?;
+			let mut settings: HashMap<_, _> =
+				std::mem::take(&mut config.settings).into_iter().collect();
+			// Settings in the environment take precedence over the file
+			if let Some(environment) = ENVIRONMENT.get() {
+				settings.extend(environment.clone());
+			}
+			super::check(&settings).map_err(Error::Config)?;
+			Some((config, settings))
+		}
+		None => None,
+	};
+	// Check the log level, unless it was specified with a flag
+	let log = match flag(&["--log", "-l"]) {
+		Some(_) => None,
+		None => {
+			let log = match &config {
+				Some((_, settings)) => settings.get("SURREAL_LOG").cloned(),
+				None => surrealdb::cnf::setting("SURREAL_LOG"),
+			};
+			let log = log.unwrap_or_else(|| DEFAULT_LOG.to_owned());
+			crate::telemetry::filter_from_value(&log).map_err(|e| Error::Config(e.to_string()))?;
+			Some(log)
+		}
+	};
+	// Apply the settings, quotas, and rules in the file
+	if let Some((config, settings)) = config {
+		surrealdb::cnf::set_settings(settings);
+		config.apply();
+	}
+	// Reload the rate limits, quotas, and slow query threshold
+	super::reload();
+	// Reload the log level, which has already been checked
+	if let Some(log) = log {
+		crate::telemetry::reload_log_level(&log).map_err(|e| Error::Config(e.to_string()))?;
+	}
+	// Log the successful reload
+	info!("Reloaded the server configuration");
+	// All ok
+	Ok(())
+}
+
+/// Parse the contents of a configuration file, as YAML if the file has
+/// a `.yaml` or `.yml` extension, or as TOML otherwise
+fn read(path: &Path, input: &str) -> Result<toml::Table, Error> {
+	match path.extension().and_then(|v| v.to_str()) {
+		Some("yaml" | "yml") => {
+			serde_yaml::from_str(input).map_err(|e| Error::Config(e.to_string()))
+		}
+		_ => input.parse().map_err(|e: toml::de::Error| Error::Config(e.to_string())),
+	}
+}
+
+/// Convert the contents of a configuration file into environment variables
+fn parse(table: &toml::Table) -> BTreeMap<String, String> {
+	let mut table = table.clone();
+	table.remove(QUOTA_KEY);
+	table.remove(CERTIFICATE_KEY);
+	table.remove(SESSION_KEY);
+	let mut out = BTreeMap::new();
+	flatten("SURREAL", table, &mut out);
+	out
+}
+
+/// Convert the quota tables in a configuration file into quotas
+fn quotas(table: &toml::Table) -> Result<Vec<Quota>, Error> {
+	let Some(list) = table.get(QUOTA_KEY).cloned() else {
+		return Ok(Vec::new());
+	};
+	let toml::Value::Array(list) = list else {
+		return Err(Error::Config("Quotas must be specified as a list of [[quota]] tables".into()));
+	};
+	let mut out = Vec::with_capacity(list.len());
+	for item in list {
+		let toml::Value::Table(mut item) = item else {
+			return Err(Error::Config(
+				"Quotas must be specified as a list of [[quota]] tables".into(),
+			));
+		};
+		// Each quota must specify a namespace
+		let Some(toml::Value::String(ns)) = item.remove("ns") else {
+			return Err(Error::Config("Each quota must specify the namespace with `ns`".into()));
+		};
+		let db = match item.remove("db") {
+			Some(toml::Value::String(db)) => Some(db),
+			None => None,
+			Some(_) => {
+				return Err(Error::Config("The database of a quota must be a string".into()))
+			}
+		};
+		let mut quota = Quota::new(ns, db);
+		for (key, val) in item {
+			let val = val.as_integer().and_then(|v| u32::try_from(v).ok()).ok_or_else(|| {
+				Error::Config(format!("The quota `{key}` must be a positive integer"))
+			})?;
+			match key.replace('_', "-").as_str() {
+				"queries-per-second" if quota.db.is_some() => {
+					return Err(Error::Config(
+						"The `queries-per-second` quota can only be specified for a namespace"
+							.into(),
+					))
+				}
+				"queries-per-second" => quota.queries_per_second = Some(val),
+				"table-max-records" => quota.table_max_records = Some(val),
+				"storage-max-mb" => quota.storage_max_mb = Some(val),
+				_ => return Err(Error::Config(format!("Unknown quota `{key}`"))),
+			}
+		}
+		out.push(quota);
+	}
+	Ok(out)
+}
+
+/// Convert the certificate tables in a configuration file into certificate rules
+fn certificates(table: &toml::Table) -> Result<Vec<CertificateRule>, Error> {
+	let Some(list) = table.get(CERTIFICATE_KEY).cloned() else {
+		return Ok(Vec::new());
+	};
+	let invalid = || {
+		Error::Config(
+			"Certificate rules must be specified as a list of [[certificate]] tables".into(),
+		)
+	};
+	let toml::Value::Array(list) = list else {
+		return Err(invalid());
+	};
+	let mut out = Vec::with_capacity(list.len());
+	for item in list {
+		let toml::Value::Table(item) = item else {
+			return Err(invalid());
+		};
+		let mut rule = CertificateRule::default();
+		let (mut role, mut level, mut ns, mut db) = (None, None, None, None);
+		for (key, val) in item {
+			let toml::Value::String(val) = val else {
+				return Err(Error::Config(format!(
+					"The certificate rule `{key}` must be a string"
+				)));
+			};
+			match key.as_str() {
+				"cn" => rule.cn = Some(val),
+				"ou" => rule.ou = Some(val),
+				"san" => rule.san = Some(val),
+				"ns" => ns = Some(val),
+				"db" => db = Some(val),
+				"level" => level = Some(val),
+				"role" => {
+					role = Some(
+						val.parse()
+							.map_err(|e: surrealdb::iam::Error| Error::Config(e.to_string()))?,
+					)
+				}
+				_ => return Err(Error::Config(format!("Unknown certificate rule `{key}`"))),
+			}
+		}
+		// Each rule must match on at least one attribute
+		if rule.cn.is_none() && rule.ou.is_none() && rule.san.is_none() {
+			return Err(Error::Config(
+				"Each certificate rule must specify a `cn`, `ou`, or `san` to match".into(),
+			));
+		}
+		// Each rule must specify the role which is granted
+		let Some(role) = role else {
+			return Err(Error::Config("Each certificate rule must specify a `role`".into()));
+		};
+		rule.role = role;
+		// Each rule must specify the level the role is granted at
+		rule.level = match (level.as_deref(), ns, db) {
+			(Some("root"), None, None) => Level::Root,
+			(Some("namespace"), Some(ns), None) => Level::Namespace(ns),
+			(Some("database"), Some(ns), Some(db)) => Level::Database(ns, db),
+			(Some("root" | "namespace" | "database"), _, _) => {
+				return Err(Error::Config(
+					"The `ns` and `db` of a certificate rule must match its `level`".into(),
+				));
+			}
+			_ => {
+				return Err(Error::Config(
+					"Each certificate rule must specify a root, namespace, or database `level`"
+						.into(),
+				));
+			}
+		};
+		out.push(rule);
+	}
+	Ok(out)
+}
+
+/// Convert the session tables in a configuration file into idle timeout rules
+fn sessions(table: &toml::Table) -> Result<Vec<IdleRule>, Error> {
+	let Some(list) = table.get(SESSION_KEY).cloned() else {
+		return Ok(Vec::new());
+	};
+	let invalid =
+		|| Error::Config("Session rules must be specified as a list of [[session]] tables".into());
+	let toml::Value::Array(list) = list else {
+		return Err(invalid());
+	};
+	let mut out = Vec::with_capacity(list.len());
+	for item in list {
+		let toml::Value::Table(item) = item else {
+			return Err(invalid());
+		};
+		let mut rule = IdleRule::default();
+		let mut timeout = None;
+		for (key, val) in item {
+			match (key.replace('_', "-").as_str(), val) {
+				("idle-timeout", toml::Value::Integer(v)) => {
+					timeout = Some(u64::try_from(v).map_err(|_| {
+						Error::Config(
+							"The `idle-timeout` of a session rule must be positive".into(),
+						)
+					})?)
+				}
+				("level", toml::Value::String(v)) => match v.as_str() {
+					"root" | "namespace" | "database" | "record" => rule.level = Some(v),
+					_ => return Err(Error::Config(format!("Unknown session rule level `{v}`"))),
+				},
+				("ns", toml::Value::String(v)) => rule.ns = Some(v),
+				("db", toml::Value::String(v)) => rule.db = Some(v),
+				("access", toml::Value::String(v)) => rule.access = Some(v),
+				("idle-timeout" | "level" | "ns" | "db" | "access", _) => {
+					return Err(Error::Config(format!(
+						"The session rule `{key}` has an invalid type"
+					)))
+				}
+				_ => return Err(Error::Config(format!("Unknown session rule `{key}`"))),
+			}
+		}
+		// Each rule must specify the idle timeout which is applied
+		rule.timeout = timeout.ok_or_else(|| {
+			Error::Config("Each session rule must specify an `idle-timeout`".into())
+		})?;
+		out.push(rule);
+	}
+	Ok(out)
+}
+
+/// Convert a table of settings into environment variables, joining
+/// the keys of nested tables with underscores
+fn flatten(prefix: &str, table: toml::Table, out: &mut BTreeMap<String, String>) {
+	for (key, val) in table {
+		let key = format!("{prefix}_{}", key.to_ascii_uppercase().replace('-', "_"));
+		let val = match val {
+			toml::Value::Table(v) => {
+				flatten(&key, v, out);
+				continue;
+			}
+			toml::Value::String(v) => v,
+			// Lists are specified as comma-separated values
+			toml::Value::Array(v) => v
+				.into_iter()
+				.map(|v| match v {
+					toml::Value::String(v) => v,
+					v => v.to_string(),
+				})
+				.collect::<Vec<_>>()
+				.join(","),
+			v => v.to_string(),
+		};
+		out.insert(key, val);
+	}
+}
+
+/// Find the value of a command-line flag, before the arguments are parsed
+fn flag(names: &[&str]) -> Option<String> {
+	let mut args = std::env::args().skip(1);
+	while let Some(arg) = args.next() {
+		for name in names {
+			if arg == *name {
+				return args.next();
+			}
+			if let Some(v) = arg.strip_prefix(name).and_then(|v| v.strip_prefix('=')) {
+				return Some(v.to_owned());
+			}
+		}
+	}
+	None
+}
+
+#[cfg(test)]
+mod tests {
+	use super::{certificates, parse, quotas, read, sessions};
+	use std::path::Path;
+	use surrealdb::iam::{Level, Role};
+
+	/// Parse the contents of a TOML configuration file
+	fn toml(input: &str) -> toml::Table {
+		read(Path::new("surreal.toml"), input).unwrap()
+	}
+
+	#[test]
+	fn settings_are_converted_to_variables() {
+		let file = r#"
+			log = "debug"
+			strict = true
+			query-timeout = "5s"
+
+			[caps]
+			allow-func = ["http::get", "string::len"]
+
+			[rate_limit]
+			query_per_second = 100
+		"#;
+		let res = parse(&toml(file));
+		assert_eq!(res["SURREAL_LOG"], "debug");
+		assert_eq!(res["SURREAL_STRICT"], "true");
+		assert_eq!(res["SURREAL_QUERY_TIMEOUT"], "5s");
+		assert_eq!(res["SURREAL_CAPS_ALLOW_FUNC"], "http::get,string::len");
+		assert_eq!(res["SURREAL_RATE_LIMIT_QUERY_PER_SECOND"], "100");
+		assert_eq!(res.len(), 5);
+	}
+
+	#[test]
+	fn quotas_are_parsed() {
+		let file = r#"
+			log = "debug"
+
+			[[quota]]
+			ns = "tenant"
+			queries-per-second = 100
+
+			[[quota]]
+			ns = "tenant"
+			db = "app"
+			table-max-records = 1000
+			storage-max-mb = 64
+		"#;
+		let res = parse(&toml(file));
+		assert_eq!(res.len(), 1);
+		let res = quotas(&toml(file)).unwrap();
+		assert_eq!(res.len(), 2);
+		assert_eq!(res[0].ns, "tenant");
+		assert_eq!(res[0].db, None);
+		assert_eq!(res[0].queries_per_second, Some(100));
+		assert_eq!(res[1].db.as_deref(), Some("app"));
+		assert_eq!(res[1].table_max_records, Some(1000));
+		assert_eq!(res[1].storage_max_mb, Some(64));
+		// Query quotas only apply to namespaces
+		assert!(quotas(&toml("[[quota]]\nns = \"a\"\ndb = \"b\"\nqueries-per-second = 1")).is_err());
+		// Unknown quotas are rejected
+		assert!(quotas(&toml("[[quota]]\nns = \"a\"\nunknown = 1")).is_err());
+	}
+
+	#[test]
+	fn certificates_are_parsed() {
+		let file = r#"
+			[[certificate]]
+			cn = "billing-service"
+			role = "editor"
+			level = "database"
+			ns = "billing"
+			db = "app"
+
+			[[certificate]]
+			ou = "operations"
+			role = "viewer"
+			level = "root"
+		"#;
+		assert!(parse(&toml(file)).is_empty());
+		let res = certificates(&toml(file)).unwrap();
+		assert_eq!(res.len(), 2);
+		assert_eq!(res[0].cn.as_deref(), Some("billing-service"));
+		assert_eq!(res[0].role, Role::Editor);
+		assert_eq!(res[0].level, Level::Database("billing".to_owned(), "app".to_owned()));
+		assert_eq!(res[1].ou.as_deref(), Some("operations"));
+		assert_eq!(res[1].role, Role::Viewer);
+		assert_eq!(res[1].level, Level::Root);
+		// Rules need to match on a certificate attribute
+		assert!(certificates(&toml("[[certificate]]\nrole = \"owner\"\nlevel = \"root\"")).is_err());
+		// Rules need an explicit role and level
+		assert!(certificates(&toml("[[certificate]]\ncn = \"a\"\nlevel = \"root\"")).is_err());
+		assert!(certificates(&toml("[[certificate]]\ncn = \"a\"\nrole = \"owner\"")).is_err());
+		// Rules need the namespace and database of their level
+		assert!(certificates(&toml(
+			"[[certificate]]\ncn = \"a\"\nrole = \"owner\"\nlevel = \"namespace\""
+		))
+		.is_err());
+		assert!(certificates(&toml(
+			"[[certificate]]\ncn = \"a\"\nrole = \"owner\"\nlevel = \"root\"\nns = \"b\""
+		))
+		.is_err());
+		// Unknown roles are rejected
+		assert!(certificates(&toml(
+			"[[certificate]]\ncn = \"a\"\nrole = \"admin\"\nlevel = \"root\""
+		))
+		.is_err());
+	}
+
+	#[test]
+	fn sessions_are_parsed() {
+		let file = r#"
+			[[session]]
+			level = "record"
+			ns = "tenant"
+			db = "app"
+			access = "users"
+			idle-timeout = 300
+
+			[[session]]
+			level = "root"
+			idle_timeout = 0
+		"#;
+		assert!(parse(&toml(file)).is_empty());
+		let res = sessions(&toml(file)).unwrap();
+		assert_eq!(res.len(), 2);
+		assert_eq!(res[0].level.as_deref(), Some("record"));
+		assert_eq!(res[0].ns.as_deref(), Some("tenant"));
+		assert_eq!(res[0].db.as_deref(), Some("app"));
+		assert_eq!(res[0].access.as_deref(), Some("users"));
+		assert_eq!(res[0].timeout, 300);
+		assert_eq!(res[1].level.as_deref(), Some("root"));
+		assert_eq!(res[1].timeout, 0);
+		// Rules need an idle timeout
+		assert!(sessions(&toml("[[session]]\nlevel = \"root\"")).is_err());
+		assert!(sessions(&toml("[[session]]\nidle-timeout = -1")).is_err());
+		assert!(sessions(&toml("[[session]]\nidle-timeout = \"5m\"")).is_err());
+		// Unknown levels and attributes are rejected
+		assert!(sessions(&toml("[[session]]\nlevel = \"admin\"\nidle-timeout = 1")).is_err());
+		assert!(sessions(&toml("[[session]]\nrole = \"owner\"\nidle-timeout = 1")).is_err());
+	}
+
+	#[test]
+	fn yaml_files_are_parsed() {
+		let file = "
+log: debug
+query-timeout: 5s
+caps:
+  allow-func: ["http::get", "string::len"]
+quota:
+  - ns: tenant
+    queries-per-second: 100
+";
+		let table = read(Path::new("surreal.yaml"), file).unwrap();
+		let res = parse(&table);
+		assert_eq!(res["SURREAL_LOG"], "debug");
+		assert_eq!(res["SURREAL_QUERY_TIMEOUT"], "5s");
+		assert_eq!(res["SURREAL_CAPS_ALLOW_FUNC"], "http::get,string::len");
+		assert_eq!(res.len(), 3);
+		let res = quotas(&table).unwrap();
+		assert_eq!(res.len(), 1);
+		assert_eq!(res[0].queries_per_second, Some(100));
+		// Files with a `.yml` extension are also parsed as YAML
+		assert!(read(Path::new("surreal.yml"), file).is_ok());
+		assert!(read(Path::new("surreal.toml"), file).is_err());
+	}
+
+	#[test]
+	fn invalid_file() {
+		assert!(read(Path::new("surreal.toml"), "log = ").is_err());
+		assert!(read(Path::new("surreal.yaml"), "log: [").is_err());
+	}
+}
@@ -1,5 +1,9 @@
+pub mod file;
+
 use once_cell::sync::Lazy;
+use std::collections::HashMap;
 use std::time::Duration;
+use surrealdb::cnf::Dynamic;
 use surrealdb::{lazy_env_parse, lazy_env_parse_or_else};
 
 pub const LOGO: &str = "
@@ -31,6 +35,50 @@ pub const APP_ENDPOINT: &str = "https://surrealdb.com/app";
 /// Specifies the frequency with which ping messages should be sent to the client
 pub const WEBSOCKET_PING_FREQUENCY: Duration = Duration::from_secs(5);
 
+/// The minimum size of a HTTP response body before it is compressed (defaults to 512 bytes)
+#[cfg(feature = "http-compression")]
+pub static HTTP_COMPRESSION_MIN_SIZE: Lazy<u16> =
+	lazy_env_parse!("SURREAL_HTTP_COMPRESSION_MIN_SIZE", u16, 512);
+
+/// The comma-separated origins which are allowed to make cross-origin requests (defaults to any origin)
+pub static CORS_ALLOW_ORIGINS: Lazy<String> =
+	lazy_env_parse!("SURREAL_CORS_ALLOW_ORIGINS", String, String::from("*"));
+
+/// The comma-separated HTTP methods which are allowed in cross-origin requests
+pub static CORS_ALLOW_METHODS: Lazy<String> = lazy_env_parse!(
+	"SURREAL_CORS_ALLOW_METHODS",
+	String,
+	String::from("GET,PUT,POST,PATCH,DELETE,OPTIONS")
+);
+
+/// Any comma-separated headers which are allowed in cross-origin requests, in addition to those used by SurrealDB
+pub static CORS_ALLOW_HEADERS: Lazy<String> =
+	lazy_env_parse!("SURREAL_CORS_ALLOW_HEADERS", String, String::new());
+
+/// Whether cross-origin requests are allowed to include credentials, which requires explicit origins (defaults to false)
+pub static CORS_ALLOW_CREDENTIALS: Lazy<bool> =
+	lazy_env_parse!("SURREAL_CORS_ALLOW_CREDENTIALS", bool, false);
+
+/// How long the results of a cross-origin preflight request can be cached, in seconds (defaults to 1 day)
+pub static CORS_MAX_AGE: Lazy<u64> = lazy_env_parse!("SURREAL_CORS_MAX_AGE", u64, 86400);
+
+/// The number of authentication requests each client can make per second (0 disables the limit)
+pub static RATE_LIMIT_AUTH_PER_SECOND: Dynamic =
+	Dynamic::new("SURREAL_RATE_LIMIT_AUTH_PER_SECOND", 0);
+
+/// The number of authentication requests each client can make in a single burst (defaults to the per-second limit)
+pub static RATE_LIMIT_AUTH_BURST: Dynamic = Dynamic::new("SURREAL_RATE_LIMIT_AUTH_BURST", 0);
+
+/// The number of other requests each client can make per second (0 disables the limit)
+pub static RATE_LIMIT_QUERY_PER_SECOND: Dynamic =
+	Dynamic::new("SURREAL_RATE_LIMIT_QUERY_PER_SECOND", 0);
+
+/// The number of other requests each client can make in a single burst (defaults to the per-second limit)
+pub static RATE_LIMIT_QUERY_BURST: Dynamic = Dynamic::new("SURREAL_RATE_LIMIT_QUERY_BURST", 0);
+
+/// The maximum duration of the storage round trip performed by the readiness endpoint
+pub const READY_TIMEOUT: Duration = Duration::from_secs(5);
+
 /// What is the maximum WebSocket frame size (defaults to 16 MiB)
 pub static WEBSOCKET_MAX_FRAME_SIZE: Lazy<usize> =
 	lazy_env_parse!("SURREAL_WEBSOCKET_MAX_FRAME_SIZE", usize, 16 << 20);
@@ -43,6 +91,23 @@ pub static WEBSOCKET_MAX_MESSAGE_SIZE: Lazy<usize> =
 pub static WEBSOCKET_MAX_CONCURRENT_REQUESTS: Lazy<usize> =
 	lazy_env_parse!("SURREAL_WEBSOCKET_MAX_CONCURRENT_REQUESTS", usize, 24);
 
+/// How long running queries can take to finish when the server shuts down, in seconds, before they are cancelled
+pub static SHUTDOWN_GRACE_PERIOD: Lazy<u64> =
+	lazy_env_parse!("SURREAL_SHUTDOWN_GRACE_PERIOD", u64, 10);
+
+/// How long a WebSocket connection can be idle before it is closed, in seconds, unless a
+/// session rule in the configuration file matches its session (defaults to 0, disabled)
+pub static WEBSOCKET_IDLE_TIMEOUT: Lazy<u64> =
+	lazy_env_parse!("SURREAL_WEBSOCKET_IDLE_TIMEOUT", u64, 0);
+
+/// Whether session expiry is extended on activity over a WebSocket connection (defaults to false)
+pub static SESSION_SLIDING_EXPIRY: Lazy<bool> =
+	lazy_env_parse!("SURREAL_SESSION_SLIDING_EXPIRY", bool, false);
+
+/// Whether the authenticated runtime diagnostics endpoint is enabled (defaults to false)
+pub static DIAGNOSTICS_ENABLED: Lazy<bool> =
+	lazy_env_parse!("SURREAL_DIAGNOSTICS_ENABLED", bool, false);
+
 /// What is the runtime thread memory stack size (defaults to 10MiB)
 pub static RUNTIME_STACK_SIZE: Lazy<usize> =
 	lazy_env_parse_or_else!("SURREAL_RUNTIME_STACK_SIZE", usize, |_| {
@@ -66,3 +131,21 @@ pub static PKG_VERSION: Lazy<String> = Lazy::new(|| match option_env!("SURREAL_B
 	}
 	_ => env!("CARGO_PKG_VERSION").to_owned(),
 });
+
+/// Check the settings which can be changed while the server is running, before they are applied
+pub fn check(settings: &HashMap<String, String>) -> Result<(), String> {
+	surrealdb::cnf::check(settings)?;
+	RATE_LIMIT_AUTH_PER_SECOND.check(settings)?;
+	RATE_LIMIT_AUTH_BURST.check(settings)?;
+	RATE_LIMIT_QUERY_PER_SECOND.check(settings)?;
+	RATE_LIMIT_QUERY_BURST.check(settings)
+}
+
+/// Reload the settings which can be changed while the server is running
+pub fn reload() {
+	surrealdb::cnf::reload();
+	RATE_LIMIT_AUTH_PER_SECOND.reload();
+	RATE_LIMIT_AUTH_BURST.reload();
+	RATE_LIMIT_QUERY_PER_SECOND.reload();
+	RATE_LIMIT_QUERY_BURST.reload();
+}
@@ -254,6 +254,8 @@ pub async fn init(
 	// Make immutable
 	let dbs = dbs;
 
+	let version = dbs.check_version().await?;
+
 	dbs.bootstrap().await?;
 
 	if let Some(user) = opt.user.as_ref() {
@@ -261,7 +263,24 @@ pub async fn init(
 	}
 
 	// Store database instance
-	let _ = DB.set(Arc::new(dbs));
+	let dbs = Arc::new(dbs);
+	let _ = DB.set(dbs.clone());
+
+	// Upgrade an older storage format in the background, while the datastore is in use
+	if !version.is_latest() {
+		warn!(
+			"The datastore uses an older storage format (version {}), and is being upgraded in the background",
+			version.0
+		);
+		tokio::spawn(async move {
+			match dbs.migrate().await {
+				Ok(_) => info!("The datastore was upgraded to the latest storage format"),
+				Err(e) => error!(
+					"The datastore could not be upgraded, run `surreal migrate` to retry: {e}"
+				),
+			}
+		});
+	}
 
 	// All ok
 	Ok(())
@@ -11,6 +11,8 @@ use std::io::Error as IoError;
 use std::string::FromUtf8Error as Utf8Error;
 use surrealdb::error::Db as SurrealDbError;
 use surrealdb::iam::Error as SurrealIamError;
+use surrealdb::sql::Value;
+use surrealdb::syn::error::RenderedError as SurrealParserError;
 use surrealdb::Error as SurrealError;
 use thiserror::Error;
 
@@ -67,6 +69,9 @@ pub enum Error {
 	#[error("There was an error with the node agent")]
 	NodeAgent,
 
+	#[error("There was a problem with the configuration file: {0}")]
+	Config(String),
+
 	/// Statement has been deprecated
 	#[error("{0}")]
 	Other(String),
@@ -163,6 +168,45 @@ pub(super) struct Message {
 	description: Option<String>,
 	#[serde(skip_serializing_if = "Option::is_none")]
 	information: Option<String>,
+	#[serde(skip_serializing_if = "Option::is_none")]
+	query: Option<QueryError>,
+}
+
+/// The position of a query parse error, and the tokens which were
+/// expected there, so that clients can highlight the error in editors.
+#[derive(Clone, Debug, Serialize)]
+pub(crate) struct QueryError {
+	line: usize,
+	column: usize,
+	length: usize,
+	found: Option<String>,
+	expected: Vec<String>,
+}
+
+impl From<&SurrealParserError> for QueryError {
+	fn from(err: &SurrealParserError) -> Self {
+		let snippet = err.snippets.first();
+		QueryError {
+			line: snippet.map(|s| s.location().line).unwrap_or_default(),
+			column: snippet.map(|s| s.location().column).unwrap_or_default(),
+			length: snippet.map(|s| s.length()).unwrap_or_default(),
+			found: err.found().map(str::to_owned),
+			expected: err.expected().to_vec(),
+		}
+	}
+}
+
+impl From<QueryError> for Value {
+	fn from(err: QueryError) -> Self {
+		map! {
+			String::from("line") => Value::from(err.line),
+			String::from("column") => Value::from(err.column),
+			String::from("length") => Value::from(err.length),
+			String::from("found") => Value::from(err.found),
+			String::from("expected") => Value::from(err.expected),
+		}
+		.into()
+	}
 }
 
 impl IntoResponse for Error {
@@ -175,6 +219,7 @@ impl IntoResponse for Error {
 					details: Some("Authentication failed".to_string()),
 					description: Some("Your authentication details are invalid. Reauthenticate using valid authentication parameters.".to_string()),
 					information: Some(err.to_string()),
+					query: None,
 				})
 			),
 			err @ Error::Db(SurrealError::Db(SurrealDbError::IamError(SurrealIamError::NotAllowed { .. }))) => (
@@ -184,6 +229,7 @@ impl IntoResponse for Error {
 					details: Some("Forbidden".to_string()),
 					description: Some("Not allowed to do this.".to_string()),
 					information: Some(err.to_string()),
+					query: None,
 				})
 			),
 			Error::InvalidType => (
@@ -193,6 +239,7 @@ impl IntoResponse for Error {
 					details: Some("Unsupported media type".to_string()),
 					description: Some("The request needs to adhere to certain constraints. Refer to the documentation for supported content types.".to_string()),
 					information: None,
+					query: None,
 				}),
 			),
 			Error::InvalidStorage => (
@@ -202,6 +249,17 @@ impl IntoResponse for Error {
 					details: Some("Health check failed".to_string()),
 					description: Some("The database health check for this instance failed. There was an issue with the underlying storage engine.".to_string()),
 					information: Some(self.to_string()),
+					query: None,
+				}),
+			),
+			Error::Db(SurrealError::Db(SurrealDbError::InvalidQuery(ref err))) => (
+				StatusCode::BAD_REQUEST,
+				Json(Message {
+					code: StatusCode::BAD_REQUEST.as_u16(),
+					details: Some("Request problems detected".to_string()),
+					description: Some("There is a problem with your query. Refer to the query field for the location of the error.".to_string()),
+					information: Some(self.to_string()),
+					query: Some(QueryError::from(err)),
 				}),
 			),
 			_ => (
@@ -211,6 +269,7 @@ impl IntoResponse for Error {
 					details: Some("Request problems detected".to_string()),
 					description: Some("There is a problem with your request. Refer to the documentation for further information.".to_string()),
 					information: Some(self.to_string()),
+					query: None,
 				}),
 			),
 		}.into_response()
@@ -30,6 +30,11 @@ use std::future::Future;
 use std::process::ExitCode;
 
 fn main() -> ExitCode {
+	// Load the settings from the configuration file, before any are read
+	if let Err(e) = cnf::file::init() {
+		eprintln!("{e}");
+		return ExitCode::FAILURE;
+	}
 	// Initiate the command line
 	with_enough_stack(cli::init())
 }
@@ -7,11 +7,14 @@ use axum::{
 	Extension, RequestPartsExt, TypedHeader,
 };
 use futures_util::future::BoxFuture;
-use http::{request::Parts, StatusCode};
+use http::{header::AUTHORIZATION, request::Parts, StatusCode};
 use hyper::{Request, Response};
 use surrealdb::{
 	dbs::Session,
-	iam::verify::{basic, token},
+	iam::{
+		impersonate::impersonate,
+		verify::{basic, token},
+	},
 };
 use tower_http::auth::AsyncAuthorizeRequest;
 
@@ -21,8 +24,9 @@ use super::{
 	client_ip::ExtractClientIP,
 	headers::{
 		parse_typed_header, SurrealAuthDatabase, SurrealAuthNamespace, SurrealDatabase, SurrealId,
-		SurrealNamespace,
+		SurrealImpersonate, SurrealImpersonateAccess, SurrealNamespace,
 	},
+	tls::{self, ClientCert},
 	AppState,
 };
 
@@ -108,6 +112,14 @@ async fn check_auth(parts: &mut Parts) -> Result<Session, Error> {
 		parts.extract::<TypedHeader<SurrealAuthDatabase>>().await,
 	)?;
 
+	// Extract the record user to impersonate from the headers.
+	let imp_rid = parse_typed_header::<SurrealImpersonate>(
+		parts.extract::<TypedHeader<SurrealImpersonate>>().await,
+	)?;
+	let imp_ac = parse_typed_header::<SurrealImpersonateAccess>(
+		parts.extract::<TypedHeader<SurrealImpersonateAccess>>().await,
+	)?;
+
 	let Extension(state) = parts.extract::<Extension<AppState>>().await.map_err(|err| {
 		tracing::error!("Error extracting the app state: {:?}", err);
 		Error::InvalidAuth
@@ -124,6 +136,13 @@ async fn check_auth(parts: &mut Parts) -> Result<Session, Error> {
 	session.ns = ns;
 	session.db = db;
 
+	// If a verified client certificate was presented without any credentials
+	if !parts.headers.contains_key(AUTHORIZATION) {
+		if let Some(Some(cert)) = parts.extensions.get::<Option<ClientCert>>() {
+			tls::authenticate(kvs, &mut session, cert).await?;
+		}
+	}
+
 	// If Basic authentication data was supplied
 	if let Ok(au) = parts.extract::<TypedHeader<Authorization<Basic>>>().await {
 		basic(
@@ -142,5 +161,12 @@ async fn check_auth(parts: &mut Parts) -> Result<Session, Error> {
 		token(kvs, &mut session, au.token()).await?;
 	};
 
+	// If a record user to impersonate was specified
+	match (imp_ac, imp_rid) {
+		(Some(ac), Some(rid)) => impersonate(kvs, &mut session, &ac, &rid).await?,
+		(None, None) => (),
+		_ => return Err(Error::InvalidAuth),
+	}
+
 	Ok(session)
 }
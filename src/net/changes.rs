@@ -0,0 +1,186 @@
+use crate::dbs::DB;
+use crate::err::Error;
+use crate::net::output;
+use axum::extract::Path;
+use axum::response::sse::{Event, KeepAlive, Sse};
+use axum::routing::get;
+use axum::{Extension, Router};
+use axum_extra::extract::Query;
+use futures::{stream, Stream, StreamExt};
+use http::HeaderMap;
+use http_body::Body as HttpBody;
+use serde::Deserialize;
+use std::collections::BTreeMap;
+use std::convert::Infallible;
+use std::time::Duration;
+use surrealdb::dbs::Session;
+use surrealdb::iam::check::check_ns_db;
+use surrealdb::sql::{Table, Value};
+use surrealdb::syn;
+
+/// How often the change feed is checked for new changes
+const POLL_INTERVAL: Duration = Duration::from_secs(1);
+
+/// The maximum number of change sets which are fetched at once
+const BATCH_SIZE: u32 = 100;
+
+#[derive(Default, Deserialize, Debug, Clone)]
+struct ChangesOptions {
+	pub since: Option<u64>,
+	pub cond: Option<String>,
+}
+
+pub(super) fn router<S, B>() -> Router<S, B>
+where
+	B: HttpBody + Send + 'static,
+	S: Clone + Send + Sync + 'static,
+{
+	Router::new().route("/changes/:table", get(handler))
+}
+
+async fn handler(
+	Extension(session): Extension<Session>,
+	Path(table): Path<String>,
+	Query(query): Query<ChangesOptions>,
+	headers: HeaderMap,
+) -> Result<Sse<impl Stream<Item = Result<Event, Infallible>>>, Error> {
+	// Ensure a NS and DB are set
+	let _ = check_ns_db(&session)?;
+	// Resume after the last event received by the client, if any
+	let since = match headers.get("last-event-id").map(|v| v.to_str().map(str::parse::<u64>)) {
+		Some(Ok(Ok(id))) => id + 1,
+		Some(_) => return Err(Error::Request),
+		None => query.since.unwrap_or(0),
+	};
+	// Only output the record changes which match the condition, if any
+	let cond = match query.cond {
+		Some(cond) => Some(syn::value(&cond).map_err(|_| Error::Request)?),
+		None => None,
+	};
+	// Ensure the table name is correctly escaped
+	let table = Table::from(table).to_string();
+	// Poll the change feed for new change sets
+	let events = stream::unfold(Some(since), move |since| {
+		let session = session.clone();
+		let table = table.clone();
+		let cond = cond.clone();
+		async move {
+			// The stream ends once an error has been sent
+			let since = since?;
+			// Get the datastore reference
+			let db = DB.get().unwrap();
+			// Specify the request statement
+			let sql = format!("SHOW CHANGES FOR TABLE {table} SINCE {since} LIMIT {BATCH_SIZE}");
+			// Fetch the next change sets
+			let changes = match db.execute(&sql, &session, None).await {
+				Ok(mut res) => match res.pop().map(|r| r.result) {
+					Some(Ok(Value::Array(v))) => v.0,
+					Some(Ok(_)) => vec![],
+					Some(Err(err)) => {
+						let event = Event::default().event("error").data(err.to_string());
+						return Some((vec![event], None));
+					}
+					None => vec![],
+				},
+				Err(err) => {
+					let event = Event::default().event("error").data(err.to_string());
+					return Some((vec![event], None));
+				}
+			};
+			// Wait before polling again if there are no new changes
+			if changes.is_empty() {
+				tokio::time::sleep(POLL_INTERVAL).await;
+			}
+			// Remove the record changes which do not match the condition
+			let changes = match cond {
+				Some(cond) => match filter(&session, &cond, changes).await {
+					Ok(v) => v,
+					Err(err) => {
+						let event = Event::default().event("error").data(err.to_string());
+						return Some((vec![event], None));
+					}
+				},
+				None => changes,
+			};
+			// Output each change set, identified by its versionstamp
+			let mut next = since;
+			let mut events = Vec::with_capacity(changes.len());
+			for change in changes {
+				let change = output::simplify(change);
+				// Each change set must be identified, so that the stream can advance
+				let Some(vs) = change["versionstamp"].as_u64() else {
+					let data =
+						format!("Invalid change set versionstamp: {}", change["versionstamp"]);
+					events.push(Event::default().event("error").data(data));
+					return Some((events, None));
+				};
+				next = vs + 1;
+				// Skip change sets without any matching changes
+				if change["changes"].as_array().is_some_and(Vec::is_empty) {
+					continue;
+				}
+				events.push(Event::default().id(vs.to_string()).data(change.to_string()));
+			}
+			Some((events, Some(next)))
+		}
+	});
+	// Output each of the change set events
+	let events = events.flat_map(|events| stream::iter(events.into_iter().map(Ok)));
+	// Return the event stream
+	Ok(Sse::new(events).keep_alive(KeepAlive::default()))
+}
+
+/// Get the record which was updated or deleted by a change
+fn record(change: &Value) -> Option<&Value> {
+	match change {
+		Value::Object(v) => v.get("update").or_else(|| v.get("delete")),
+		_ => None,
+	}
+}
+
+/// Keep only the record changes in each change set which match the condition,
+/// removing any other changes, such as table definitions
+async fn filter(
+	session: &Session,
+	cond: &Value,
+	mut changes: Vec<Value>,
+) -> Result<Vec<Value>, surrealdb::err::Error> {
+	// Collect the records which were changed
+	let records: Vec<Value> = changes
+		.iter()
+		.filter_map(|v| match v {
+			Value::Object(v) => v.get("changes"),
+			_ => None,
+		})
+		.filter_map(|v| match v {
+			Value::Array(v) => Some(v.iter().filter_map(record).cloned()),
+			_ => None,
+		})
+		.flatten()
+		.collect();
+	// Check the condition against each changed record
+	let matched = match records.is_empty() {
+		true => vec![],
+		false => {
+			let sql = format!("SELECT VALUE {cond} FROM $records");
+			let vars = BTreeMap::from([("records".to_owned(), Value::from(records))]);
+			match DB.get().unwrap().execute(&sql, session, Some(vars)).await?.pop() {
+				Some(res) => match res.result? {
+					Value::Array(v) => v.0,
+					_ => vec![],
+				},
+				None => vec![],
+			}
+		}
+	};
+	// Remove the record changes which do not match
+	let mut matched = matched.into_iter();
+	for change in changes.iter_mut() {
+		if let Value::Object(v) = change {
+			if let Some(Value::Array(v)) = v.get_mut("changes") {
+				v.retain(|v| record(v).is_some() && matched.next().is_some_and(|v| v.is_truthy()));
+			}
+		}
+	}
+	Ok(changes)
+}
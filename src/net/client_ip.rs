@@ -67,6 +67,19 @@ impl ClientIp {
 			ClientIp::XForwardedFor => true,
 		}
 	}
+
+	/// Extract the client address from a header value. Any proxy appends the
+	/// address it received the request from to the X-Forwarded-For header, so
+	/// the entries to the left of the right-most one are set by the client,
+	/// and can not be trusted.
+	fn extract(&self, value: &str) -> String {
+		match self {
+			ClientIp::XForwardedFor => {
+				value.rsplit(',').next().unwrap_or_default().trim().to_owned()
+			}
+			_ => value.to_owned(),
+		}
+	}
 }
 
 pub(super) struct ExtractClientIP(pub Option<String>);
@@ -96,7 +109,7 @@ where
 			// Get the IP from the corresponding header
 			var if var.is_header() => {
 				if let Some(ip) = parts.headers.get(var.to_string()) {
-					ip.to_str().map(|s| ExtractClientIP(Some(s.to_string()))).unwrap_or_else(
+					ip.to_str().map(|s| ExtractClientIP(Some(var.extract(s)))).unwrap_or_else(
 						|err| {
 							debug!("Invalid header value for {}: {}", var, err);
 							ExtractClientIP(None)
@@ -0,0 +1,132 @@
+use crate::cnf;
+use crate::err::Error;
+use http::{header, HeaderName, HeaderValue, Method};
+use std::time::Duration;
+use surrealdb::headers::{AUTH_DB, AUTH_NS, DB, ID, IMPERSONATE, IMPERSONATE_AC, NS};
+use tower_http::cors::{AllowOrigin, CorsLayer};
+
+/// The W3C trace context headers, which clients send to join a server trace
+static TRACEPARENT: HeaderName = HeaderName::from_static("traceparent");
+static TRACESTATE: HeaderName = HeaderName::from_static("tracestate");
+
+/// Splits a comma-separated configuration value into its entries
+fn entries(v: &str) -> impl Iterator<Item = &str> {
+	v.split(',').map(str::trim).filter(|v| !v.is_empty())
+}
+
+/// The request headers which the HTTP server reads, and which are always allowed
+fn headers() -> Vec<HeaderName> {
+	let mut headers = vec![
+		header::ACCEPT,
+		header::AUTHORIZATION,
+		header::CONTENT_TYPE,
+		header::ORIGIN,
+		NS.clone(),
+		DB.clone(),
+		ID.clone(),
+		AUTH_NS.clone(),
+		AUTH_DB.clone(),
+		IMPERSONATE.clone(),
+		IMPERSONATE_AC.clone(),
+		TRACEPARENT.clone(),
+		TRACESTATE.clone(),
+	];
+	#[cfg(feature = "http-compression")]
+	headers.push(header::ACCEPT_ENCODING);
+	headers
+}
+
+/// Parses the allowed methods
+fn methods(v: &str) -> Result<Vec<Method>, Error> {
+	entries(v)
+		.map(|v| v.to_ascii_uppercase().parse::<Method>())
+		.collect::<Result<Vec<_>, _>>()
+		.map_err(|e| Error::Other(format!("Invalid CORS method: {e}")))
+}
+
+/// Parses any additional allowed headers, along with the headers which are always allowed
+fn allowed_headers(v: &str) -> Result<Vec<HeaderName>, Error> {
+	headers()
+		.into_iter()
+		.map(Ok)
+		.chain(entries(v).map(|v| v.parse::<HeaderName>()))
+		.collect::<Result<Vec<_>, _>>()
+		.map_err(|e| Error::Other(format!("Invalid CORS header: {e}")))
+}
+
+/// Parses the allowed origins
+fn origins(v: &str, credentials: bool) -> Result<AllowOrigin, Error> {
+	match entries(v).any(|v| v == "*") {
+		// Credentials would be sent to any website which makes a request
+		true if credentials => Err(Error::Other(
+			"CORS credentials can not be allowed from any origin. Specify the allowed origins explicitly".to_owned(),
+		)),
+		// Allow requests from any origin
+		true => Ok(AllowOrigin::any()),
+		// Allow requests from the specified origins
+		false => entries(v)
+			.map(HeaderValue::from_str)
+			.collect::<Result<Vec<_>, _>>()
+			.map(AllowOrigin::list)
+			.map_err(|e| Error::Other(format!("Invalid CORS origin: {e}"))),
+	}
+}
+
+/// Builds the CORS policy for the HTTP server from the configuration
+pub(super) fn layer() -> Result<CorsLayer, Error> {
+	Ok(CorsLayer::new()
+		.allow_methods(methods(&cnf::CORS_ALLOW_METHODS)?)
+		.allow_headers(allowed_headers(&cnf::CORS_ALLOW_HEADERS)?)
+		.allow_origin(origins(&cnf::CORS_ALLOW_ORIGINS, *cnf::CORS_ALLOW_CREDENTIALS)?)
+		.allow_credentials(*cnf::CORS_ALLOW_CREDENTIALS)
+		.max_age(Duration::from_secs(*cnf::CORS_MAX_AGE)))
+}
+
+#[cfg(test)]
+mod tests {
+	use super::{allowed_headers, entries, methods, origins};
+	use http::Method;
+
+	#[test]
+	fn entries_are_split() {
+		let res: Vec<_> = entries(" GET, POST,,PUT ,").collect();
+		assert_eq!(res, ["GET", "POST", "PUT"]);
+		assert_eq!(entries("").count(), 0);
+	}
+
+	#[test]
+	fn methods_are_parsed() {
+		let res = methods("get, POST").unwrap();
+		assert_eq!(res, [Method::GET, Method::POST]);
+		assert!(methods("GET, NOT A METHOD").is_err());
+	}
+
+	#[test]
+	fn headers_are_allowed() {
+		let res = allowed_headers("x-custom").unwrap();
+		for header in [
+			"authorization",
+			"surreal-ns",
+			"surreal-db",
+			"surreal-auth-ns",
+			"surreal-auth-db",
+			"surreal-impersonate",
+			"surreal-impersonate-ac",
+			"traceparent",
+			"tracestate",
+			"x-custom",
+		] {
+			assert!(res.iter().any(|h| h == header), "{header} is not allowed");
+		}
+		assert!(allowed_headers("invalid header").is_err());
+	}
+
+	#[test]
+	fn origins_are_parsed() {
+		assert!(origins("*", false).is_ok());
+		assert!(origins("https://a.example, https://b.example", true).is_ok());
+		// Credentials can not be allowed from any origin
+		assert!(origins("https://a.example, *", true).is_err());
+		assert!(origins("https://\u{1}.example", false).is_err());
+	}
+}
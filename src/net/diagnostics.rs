@@ -0,0 +1,68 @@
+use crate::cnf::PKG_VERSION;
+use crate::dbs::DB;
+use crate::err::Error;
+use crate::net::output;
+use crate::rpc::{LIVE_QUERIES, WEBSOCKETS};
+use axum::response::IntoResponse;
+use axum::routing::get;
+use axum::{Extension, Router};
+use http_body::Body as HttpBody;
+use serde_json::json;
+use surrealdb::dbs::Session;
+use surrealdb::iam::Action::View;
+use surrealdb::iam::ResourceKind::Any;
+
+pub(super) fn router<S, B>() -> Router<S, B>
+where
+	B: HttpBody + Send + 'static,
+	S: Clone + Send + Sync + 'static,
+{
+	Router::new().route("/diagnostics", get(handler))
+}
+
+async fn handler(Extension(session): Extension<Session>) -> Result<impl IntoResponse, Error> {
+	// Get the datastore reference
+	let db = DB.get().unwrap();
+	// Diagnostics are only available to root users
+	db.check(&session, View, Any.on_root())?;
+	// Collect the currently running queries
+	let queries = db
+		.active_queries()
+		.into_iter()
+		.map(|q| {
+			json!({
+				"id": q.id,
+				"ns": q.ns,
+				"db": q.db,
+				"started": q.started.to_raw(),
+			})
+		})
+		.collect::<Vec<_>>();
+	// Collect the currently connected sessions
+	let sessions = db
+		.active_sessions()
+		.into_iter()
+		.map(|s| {
+			json!({
+				"id": s.id,
+				"ns": s.ns,
+				"db": s.db,
+				"ac": s.ac,
+				"actor": s.actor,
+				"level": s.level,
+				"token": s.token,
+				"ip": s.ip,
+				"started": s.started.to_raw(),
+			})
+		})
+		.collect::<Vec<_>>();
+	// Output the runtime diagnostics
+	Ok(output::json(&json!({
+		"version": *PKG_VERSION,
+		"storage": db.to_string(),
+		"websockets": WEBSOCKETS.read().await.len(),
+		"live_queries": LIVE_QUERIES.read().await.len(),
+		"queries": queries,
+		"sessions": sessions,
+	})))
+}
@@ -4,15 +4,23 @@ use axum::response::IntoResponse;
 use axum::routing::get;
 use axum::Router;
 use axum::{response::Response, Extension};
+use axum_extra::extract::Query;
 use bytes::Bytes;
 use http::StatusCode;
 use http_body::Body as HttpBody;
 use hyper::body::Body;
+use serde::Deserialize;
 use surrealdb::dbs::Session;
 use surrealdb::iam::check::check_ns_db;
 use surrealdb::iam::Action::View;
 use surrealdb::iam::ResourceKind::Any;
 
+#[derive(Default, Deserialize, Debug, Clone)]
+struct ExportOptions {
+	#[serde(default, rename = "table")]
+	pub tables: Vec<String>,
+}
+
 pub(super) fn router<S, B>() -> Router<S, B>
 where
 	B: HttpBody + Send + 'static,
@@ -21,7 +29,10 @@ where
 	Router::new().route("/export", get(handler))
 }
 
-async fn handler(Extension(session): Extension<Session>) -> Result<impl IntoResponse, Error> {
+async fn handler(
+	Extension(session): Extension<Session>,
+	Query(params): Query<ExportOptions>,
+) -> Result<impl IntoResponse, Error> {
 	// Get the datastore reference
 	let db = DB.get().unwrap();
 	// Create a chunked response
@@ -33,7 +44,7 @@ async fn handler(Extension(session): Extension<Session>) -> Result<impl IntoResp
 	// Create a new bounded channel
 	let (snd, rcv) = surrealdb::channel::bounded(1);
 	// Start the export task
-	let task = db.export(&session, snd).await?;
+	let task = db.export_tables(&session, params.tables, snd).await?;
 	// Spawn a new database export job
 	tokio::spawn(task);
 	// Process all chunk values
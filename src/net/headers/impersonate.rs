@@ -0,0 +1,52 @@
+use axum::headers;
+use axum::headers::Header;
+use http::HeaderName;
+use http::HeaderValue;
+use surrealdb::headers::IMPERSONATE;
+
+/// Typed header implementation for the `surreal-impersonate` header.
+/// It's used to specify the record user to impersonate.
+pub struct SurrealImpersonate(String);
+
+impl Header for SurrealImpersonate {
+	fn name() -> &'static HeaderName {
+		&IMPERSONATE
+	}
+
+	fn decode<'i, I>(values: &mut I) -> Result<Self, headers::Error>
+	where
+		I: Iterator<Item = &'i HeaderValue>,
+	{
+		let value = values.next().ok_or_else(headers::Error::invalid)?;
+		let value = value.to_str().map_err(|_| headers::Error::invalid())?.to_string();
+
+		Ok(SurrealImpersonate(value))
+	}
+
+	fn encode<E>(&self, values: &mut E)
+	where
+		E: Extend<HeaderValue>,
+	{
+		values.extend(std::iter::once(self.into()));
+	}
+}
+
+impl std::ops::Deref for SurrealImpersonate {
+	type Target = String;
+
+	fn deref(&self) -> &Self::Target {
+		&self.0
+	}
+}
+
+impl From<SurrealImpersonate> for HeaderValue {
+	fn from(value: SurrealImpersonate) -> Self {
+		HeaderValue::from(&value)
+	}
+}
+
+impl From<&SurrealImpersonate> for HeaderValue {
+	fn from(value: &SurrealImpersonate) -> Self {
+		HeaderValue::from_str(value.0.as_str()).unwrap()
+	}
+}
@@ -0,0 +1,52 @@
+use axum::headers;
+use axum::headers::Header;
+use http::HeaderName;
+use http::HeaderValue;
+use surrealdb::headers::IMPERSONATE_AC;
+
+/// Typed header implementation for the `surreal-impersonate-ac` header.
+/// It's used to specify the access method of the record user to impersonate.
+pub struct SurrealImpersonateAccess(String);
+
+impl Header for SurrealImpersonateAccess {
+	fn name() -> &'static HeaderName {
+		&IMPERSONATE_AC
+	}
+
+	fn decode<'i, I>(values: &mut I) -> Result<Self, headers::Error>
+	where
+		I: Iterator<Item = &'i HeaderValue>,
+	{
+		let value = values.next().ok_or_else(headers::Error::invalid)?;
+		let value = value.to_str().map_err(|_| headers::Error::invalid())?.to_string();
+
+		Ok(SurrealImpersonateAccess(value))
+	}
+
+	fn encode<E>(&self, values: &mut E)
+	where
+		E: Extend<HeaderValue>,
+	{
+		values.extend(std::iter::once(self.into()));
+	}
+}
+
+impl std::ops::Deref for SurrealImpersonateAccess {
+	type Target = String;
+
+	fn deref(&self) -> &Self::Target {
+		&self.0
+	}
+}
+
+impl From<SurrealImpersonateAccess> for HeaderValue {
+	fn from(value: SurrealImpersonateAccess) -> Self {
+		HeaderValue::from(&value)
+	}
+}
+
+impl From<&SurrealImpersonateAccess> for HeaderValue {
+	fn from(value: &SurrealImpersonateAccess) -> Self {
+		HeaderValue::from_str(value.0.as_str()).unwrap()
+	}
+}
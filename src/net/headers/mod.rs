@@ -17,6 +17,8 @@ mod auth_ns;
 mod content_type;
 mod db;
 mod id;
+mod impersonate;
+mod impersonate_ac;
 mod ns;
 
 pub use accept::Accept;
@@ -25,6 +27,8 @@ pub use auth_ns::SurrealAuthNamespace;
 pub use content_type::ContentType;
 pub use db::SurrealDatabase;
 pub use id::SurrealId;
+pub use impersonate::SurrealImpersonate;
+pub use impersonate_ac::SurrealImpersonateAccess;
 pub use ns::SurrealNamespace;
 
 pub fn add_version_header(enabled: bool) -> SetResponseHeaderLayer<Option<HeaderValue>> {
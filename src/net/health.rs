@@ -1,17 +1,27 @@
+use crate::cnf::READY_TIMEOUT;
 use crate::dbs::DB;
 use crate::err::Error;
+use crate::net::output;
 use axum::response::IntoResponse;
 use axum::routing::get;
 use axum::Router;
 use http_body::Body as HttpBody;
+use serde_json::json;
 use surrealdb::kvs::{LockType::*, TransactionType::*};
 
+/// The key which is written and read back when checking readiness. The
+/// transaction is always cancelled, so this key is never persisted.
+const READY_KEY: &[u8] = b"/!ready";
+
+/// The number of cluster nodes which are fetched in each batch
+const NODE_BATCH_SIZE: u32 = 1000;
+
 pub(super) fn router<S, B>() -> Router<S, B>
 where
 	B: HttpBody + Send + 'static,
 	S: Clone + Send + Sync + 'static,
 {
-	Router::new().route("/health", get(handler))
+	Router::new().route("/health", get(handler)).route("/ready", get(ready))
 }
 
 async fn handler() -> impl IntoResponse {
@@ -31,3 +41,36 @@ async fn handler() -> impl IntoResponse {
 		}
 	}
 }
+
+async fn ready() -> Result<impl IntoResponse, Error> {
+	// Get the datastore reference
+	let db = DB.get().unwrap();
+	// Perform a write and read round trip against the storage engine
+	let check = async {
+		// Attempt to open a writeable transaction
+		let mut tx = db.transaction(Write, Optimistic).await?;
+		// Write a key and ensure it can be read back
+		let res = async {
+			tx.set(READY_KEY, READY_KEY).await?;
+			let val = tx.get(READY_KEY).await?;
+			let nodes = tx.scan_nd(NODE_BATCH_SIZE).await?;
+			Ok::<_, surrealdb::error::Db>((val, nodes))
+		}
+		.await;
+		// Never persist the readiness check
+		trace!("Ready endpoint cancelling transaction");
+		let _ = tx.cancel().await;
+		res
+	};
+	// Ensure the storage engine responds in time
+	match tokio::time::timeout(READY_TIMEOUT, check).await {
+		// The key was written and read back successfully
+		Ok(Ok((Some(val), nodes))) if val == READY_KEY => Ok(output::json(&json!({
+			"status": "ready",
+			"storage": db.to_string(),
+			"nodes": nodes.len(),
+		}))),
+		// The storage engine failed or did not respond in time
+		_ => Err(Error::InvalidStorage),
+	}
+}
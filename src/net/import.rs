@@ -1,17 +1,18 @@
 use super::headers::Accept;
 use crate::dbs::DB;
 use crate::err::Error;
-use crate::net::input::bytes_to_utf8;
 use crate::net::output;
-use axum::extract::DefaultBodyLimit;
+use axum::extract::{BodyStream, DefaultBodyLimit};
 use axum::response::IntoResponse;
 use axum::routing::post;
 use axum::Extension;
 use axum::Router;
 use axum::TypedHeader;
 use bytes::Bytes;
+use futures::StreamExt;
 use http_body::Body as HttpBody;
-use surrealdb::dbs::Session;
+use surrealdb::dbs::{Response, Session};
+use surrealdb::error::Db as DbError;
 use surrealdb::iam::Action::Edit;
 use surrealdb::iam::ResourceKind::Any;
 use tower_http::limit::RequestBodyLimitLayer;
@@ -21,7 +22,7 @@ const MAX: usize = 1024 * 1024 * 1024 * 4; // 4 GiB
 pub(super) fn router<S, B>() -> Router<S, B>
 where
 	B: HttpBody + Send + 'static,
-	B::Data: Send,
+	B::Data: Send + Into<Bytes>,
 	B::Error: std::error::Error + Send + Sync + 'static,
 	S: Clone + Send + Sync + 'static,
 {
@@ -34,28 +35,31 @@ where
 async fn handler(
 	Extension(session): Extension<Session>,
 	accept: Option<TypedHeader<Accept>>,
-	sql: Bytes,
+	stream: BodyStream,
 ) -> Result<impl IntoResponse, impl IntoResponse> {
 	// Get the datastore reference
 	let db = DB.get().unwrap();
-	// Convert the body to a byte slice
-	let sql = bytes_to_utf8(&sql)?;
 	// Check the permissions level
 	db.check(&session, Edit, Any.on_level(session.au.level().to_owned()))?;
-	// Execute the sql query in the database
-	match db.import(sql, &session).await {
-		Ok(res) => match accept.as_deref() {
-			// Simple serialization
-			Some(Accept::ApplicationJson) => Ok(output::json(&output::simplify(res))),
-			Some(Accept::ApplicationCbor) => Ok(output::cbor(&output::simplify(res))),
-			Some(Accept::ApplicationPack) => Ok(output::pack(&output::simplify(res))),
-			// Return nothing
-			Some(Accept::ApplicationOctetStream) => Ok(output::none()),
-			// Internal serialization
-			Some(Accept::Surrealdb) => Ok(output::full(&res)),
-			// An incorrect content-type was requested
-			_ => Err(Error::InvalidType),
-		},
+	// Execute the statements as the request body is received
+	let stream = stream.map(|chunk| chunk.map_err(|e| DbError::Http(e.to_string())));
+	match db.import_stream(&session, None, stream).await {
+		// The statement results are not kept, so the import is not held in memory
+		Ok(_) => {
+			let res: Vec<Response> = Vec::new();
+			match accept.as_deref() {
+				// Simple serialization
+				Some(Accept::ApplicationJson) => Ok(output::json(&output::simplify(&res))),
+				Some(Accept::ApplicationCbor) => Ok(output::cbor(&output::simplify(&res))),
+				Some(Accept::ApplicationPack) => Ok(output::pack(&output::simplify(&res))),
+				// Return nothing
+				Some(Accept::ApplicationOctetStream) => Ok(output::none()),
+				// Internal serialization
+				Some(Accept::Surrealdb) => Ok(output::full(&res)),
+				// An incorrect content-type was requested
+				_ => Err(Error::InvalidType),
+			}
+		}
 		// There was an error when executing the query
 		Err(err) => Err(Error::from(err)),
 	}
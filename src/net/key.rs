@@ -11,6 +11,7 @@ use axum_extra::extract::Query;
 use bytes::Bytes;
 use http_body::Body as HttpBody;
 use serde::Deserialize;
+use std::collections::BTreeMap;
 use std::str;
 use surrealdb::dbs::Session;
 use surrealdb::iam::check::check_ns_db;
@@ -63,6 +64,32 @@ where
 		)
 }
 
+/// Builds a WHERE clause from any query string parameters which are
+/// not query options, requiring each field to equal the given value
+fn filter(params: Vec<(String, String)>, vars: &mut BTreeMap<String, Value>) -> String {
+	let mut conds = Vec::new();
+	for (field, value) in params {
+		// Skip any recognised query options
+		if matches!(field.as_str(), "limit" | "start" | "fields") {
+			continue;
+		}
+		// Parse the value as a SurrealQL value
+		let value = match surrealdb::sql::json(&value) {
+			Ok(value) => value,
+			Err(_) => Value::from(value),
+		};
+		// Bind the field and value as variables
+		let i = conds.len();
+		vars.insert(format!("filter_field_{i}"), Value::from(field));
+		vars.insert(format!("filter_value_{i}"), value);
+		conds.push(format!("type::field($filter_field_{i}) = $filter_value_{i}"));
+	}
+	match conds.is_empty() {
+		true => String::new(),
+		false => format!("WHERE {}", conds.join(" AND ")),
+	}
+}
+
 // ------------------------------
 // Routes for a table
 // ------------------------------
@@ -72,30 +99,35 @@ async fn select_all(
 	accept: Option<TypedHeader<Accept>>,
 	Path(table): Path<String>,
 	Query(query): Query<QueryOptions>,
+	Query(filters): Query<Vec<(String, String)>>,
 ) -> Result<impl IntoResponse, impl IntoResponse> {
 	// Get the datastore reference
 	let db = DB.get().unwrap();
 	// Ensure a NS and DB are set
 	let _ = check_ns_db(&session)?;
-	// Specify the request statement
-	let sql = match query.fields {
-		None => "SELECT * FROM type::table($table) LIMIT $limit START $start",
-		_ => "SELECT type::fields($fields) FROM type::table($table) LIMIT $limit START $start",
-	};
 	// Specify the request variables
-	let vars = map! {
+	let mut vars = map! {
 		String::from("table") => Value::from(table),
 		String::from("start") => Value::from(query.start.unwrap_or(0)),
 		String::from("limit") => Value::from(query.limit.unwrap_or(100)),
 		String::from("fields") => Value::from(query.fields.unwrap_or_default()),
 	};
+	// Filter the records using the query string
+	let cond = filter(filters, &mut vars);
+	// Specify the request statement
+	let sql = match query.fields {
+		None => format!("SELECT * FROM type::table($table) {cond} LIMIT $limit START $start"),
+		_ => format!(
+			"SELECT type::fields($fields) FROM type::table($table) {cond} LIMIT $limit START $start"
+		),
+	};
 	// Execute the query and return the result
 	match db.execute(sql, &session, Some(vars)).await {
 		Ok(res) => match accept.as_deref() {
 			// Simple serialization
-			Some(Accept::ApplicationJson) => Ok(output::json(&output::simplify(res))),
-			Some(Accept::ApplicationCbor) => Ok(output::cbor(&output::simplify(res))),
-			Some(Accept::ApplicationPack) => Ok(output::pack(&output::simplify(res))),
+			Some(Accept::ApplicationJson) => Ok(output::json(&output::simplify(&res))),
+			Some(Accept::ApplicationCbor) => Ok(output::cbor(&output::simplify(&res))),
+			Some(Accept::ApplicationPack) => Ok(output::pack(&output::simplify(&res))),
 			// Internal serialization
 			// TODO: remove format in 2.0.0
 			Some(Accept::Surrealdb) => Ok(output::full(&res)),
@@ -135,9 +167,9 @@ async fn create_all(
 			match db.execute(sql, &session, Some(vars)).await {
 				Ok(res) => match accept.as_deref() {
 					// Simple serialization
-					Some(Accept::ApplicationJson) => Ok(output::json(&output::simplify(res))),
-					Some(Accept::ApplicationCbor) => Ok(output::cbor(&output::simplify(res))),
-					Some(Accept::ApplicationPack) => Ok(output::pack(&output::simplify(res))),
+					Some(Accept::ApplicationJson) => Ok(output::json(&output::simplify(&res))),
+					Some(Accept::ApplicationCbor) => Ok(output::cbor(&output::simplify(&res))),
+					Some(Accept::ApplicationPack) => Ok(output::pack(&output::simplify(&res))),
 					// Internal serialization
 					Some(Accept::Surrealdb) => Ok(output::full(&res)),
 					// An incorrect content-type was requested
@@ -179,9 +211,9 @@ async fn update_all(
 			match db.execute(sql, &session, Some(vars)).await {
 				Ok(res) => match accept.as_deref() {
 					// Simple serialization
-					Some(Accept::ApplicationJson) => Ok(output::json(&output::simplify(res))),
-					Some(Accept::ApplicationCbor) => Ok(output::cbor(&output::simplify(res))),
-					Some(Accept::ApplicationPack) => Ok(output::pack(&output::simplify(res))),
+					Some(Accept::ApplicationJson) => Ok(output::json(&output::simplify(&res))),
+					Some(Accept::ApplicationCbor) => Ok(output::cbor(&output::simplify(&res))),
+					Some(Accept::ApplicationPack) => Ok(output::pack(&output::simplify(&res))),
 					// Internal serialization
 					Some(Accept::Surrealdb) => Ok(output::full(&res)),
 					// An incorrect content-type was requested
@@ -223,9 +255,9 @@ async fn modify_all(
 			match db.execute(sql, &session, Some(vars)).await {
 				Ok(res) => match accept.as_deref() {
 					// Simple serialization
-					Some(Accept::ApplicationJson) => Ok(output::json(&output::simplify(res))),
-					Some(Accept::ApplicationCbor) => Ok(output::cbor(&output::simplify(res))),
-					Some(Accept::ApplicationPack) => Ok(output::pack(&output::simplify(res))),
+					Some(Accept::ApplicationJson) => Ok(output::json(&output::simplify(&res))),
+					Some(Accept::ApplicationCbor) => Ok(output::cbor(&output::simplify(&res))),
+					Some(Accept::ApplicationPack) => Ok(output::pack(&output::simplify(&res))),
 					// Internal serialization
 					Some(Accept::Surrealdb) => Ok(output::full(&res)),
 					// An incorrect content-type was requested
@@ -260,9 +292,9 @@ async fn delete_all(
 	match db.execute(sql, &session, Some(vars)).await {
 		Ok(res) => match accept.as_deref() {
 			// Simple serialization
-			Some(Accept::ApplicationJson) => Ok(output::json(&output::simplify(res))),
-			Some(Accept::ApplicationCbor) => Ok(output::cbor(&output::simplify(res))),
-			Some(Accept::ApplicationPack) => Ok(output::pack(&output::simplify(res))),
+			Some(Accept::ApplicationJson) => Ok(output::json(&output::simplify(&res))),
+			Some(Accept::ApplicationCbor) => Ok(output::cbor(&output::simplify(&res))),
+			Some(Accept::ApplicationPack) => Ok(output::pack(&output::simplify(&res))),
 			// Internal serialization
 			Some(Accept::Surrealdb) => Ok(output::full(&res)),
 			// An incorrect content-type was requested
@@ -307,9 +339,9 @@ async fn select_one(
 	match db.execute(sql, &session, Some(vars)).await {
 		Ok(res) => match accept.as_deref() {
 			// Simple serialization
-			Some(Accept::ApplicationJson) => Ok(output::json(&output::simplify(res))),
-			Some(Accept::ApplicationCbor) => Ok(output::cbor(&output::simplify(res))),
-			Some(Accept::ApplicationPack) => Ok(output::pack(&output::simplify(res))),
+			Some(Accept::ApplicationJson) => Ok(output::json(&output::simplify(&res))),
+			Some(Accept::ApplicationCbor) => Ok(output::cbor(&output::simplify(&res))),
+			Some(Accept::ApplicationPack) => Ok(output::pack(&output::simplify(&res))),
 			// Internal serialization
 			Some(Accept::Surrealdb) => Ok(output::full(&res)),
 			// An incorrect content-type was requested
@@ -354,9 +386,9 @@ async fn create_one(
 			match db.execute(sql, &session, Some(vars)).await {
 				Ok(res) => match accept.as_deref() {
 					// Simple serialization
-					Some(Accept::ApplicationJson) => Ok(output::json(&output::simplify(res))),
-					Some(Accept::ApplicationCbor) => Ok(output::cbor(&output::simplify(res))),
-					Some(Accept::ApplicationPack) => Ok(output::pack(&output::simplify(res))),
+					Some(Accept::ApplicationJson) => Ok(output::json(&output::simplify(&res))),
+					Some(Accept::ApplicationCbor) => Ok(output::cbor(&output::simplify(&res))),
+					Some(Accept::ApplicationPack) => Ok(output::pack(&output::simplify(&res))),
 					// Internal serialization
 					Some(Accept::Surrealdb) => Ok(output::full(&res)),
 					// An incorrect content-type was requested
@@ -404,9 +436,9 @@ async fn update_one(
 			match db.execute(sql, &session, Some(vars)).await {
 				Ok(res) => match accept.as_deref() {
 					// Simple serialization
-					Some(Accept::ApplicationJson) => Ok(output::json(&output::simplify(res))),
-					Some(Accept::ApplicationCbor) => Ok(output::cbor(&output::simplify(res))),
-					Some(Accept::ApplicationPack) => Ok(output::pack(&output::simplify(res))),
+					Some(Accept::ApplicationJson) => Ok(output::json(&output::simplify(&res))),
+					Some(Accept::ApplicationCbor) => Ok(output::cbor(&output::simplify(&res))),
+					Some(Accept::ApplicationPack) => Ok(output::pack(&output::simplify(&res))),
 					// Internal serialization
 					Some(Accept::Surrealdb) => Ok(output::full(&res)),
 					// An incorrect content-type was requested
@@ -454,9 +486,9 @@ async fn modify_one(
 			match db.execute(sql, &session, Some(vars)).await {
 				Ok(res) => match accept.as_deref() {
 					// Simple serialization
-					Some(Accept::ApplicationJson) => Ok(output::json(&output::simplify(res))),
-					Some(Accept::ApplicationCbor) => Ok(output::cbor(&output::simplify(res))),
-					Some(Accept::ApplicationPack) => Ok(output::pack(&output::simplify(res))),
+					Some(Accept::ApplicationJson) => Ok(output::json(&output::simplify(&res))),
+					Some(Accept::ApplicationCbor) => Ok(output::cbor(&output::simplify(&res))),
+					Some(Accept::ApplicationPack) => Ok(output::pack(&output::simplify(&res))),
 					// Internal serialization
 					Some(Accept::Surrealdb) => Ok(output::full(&res)),
 					// An incorrect content-type was requested
@@ -495,9 +527,9 @@ async fn delete_one(
 	match db.execute(sql, &session, Some(vars)).await {
 		Ok(res) => match accept.as_deref() {
 			// Simple serialization
-			Some(Accept::ApplicationJson) => Ok(output::json(&output::simplify(res))),
-			Some(Accept::ApplicationCbor) => Ok(output::cbor(&output::simplify(res))),
-			Some(Accept::ApplicationPack) => Ok(output::pack(&output::simplify(res))),
+			Some(Accept::ApplicationJson) => Ok(output::json(&output::simplify(&res))),
+			Some(Accept::ApplicationCbor) => Ok(output::cbor(&output::simplify(&res))),
+			Some(Accept::ApplicationPack) => Ok(output::pack(&output::simplify(&res))),
 			// Internal serialization
 			Some(Accept::Surrealdb) => Ok(output::full(&res)),
 			// An incorrect content-type was requested
@@ -1,5 +1,8 @@
 mod auth;
+mod changes;
 pub mod client_ip;
+mod cors;
+mod diagnostics;
 mod export;
 pub(crate) mod headers;
 mod health;
@@ -8,12 +11,16 @@ mod input;
 mod key;
 pub(crate) mod output;
 mod params;
+pub(crate) mod rate_limit;
+mod reload;
+mod renew;
 mod rpc;
 mod signals;
 mod signin;
 mod signup;
 mod sql;
 mod sync;
+pub(crate) mod tls;
 mod tracer;
 mod version;
 
@@ -34,13 +41,10 @@ use axum_server::Handle;
 use http::header;
 use std::net::SocketAddr;
 use std::sync::Arc;
-use std::time::Duration;
-use surrealdb::headers::{AUTH_DB, AUTH_NS, DB, ID, NS};
 use tokio_util::sync::CancellationToken;
 use tower::ServiceBuilder;
 use tower_http::add_extension::AddExtensionLayer;
 use tower_http::auth::AsyncRequireAuthorizationLayer;
-use tower_http::cors::{Any, CorsLayer};
 use tower_http::request_id::MakeRequestUuid;
 use tower_http::sensitive_headers::SetSensitiveRequestHeadersLayer;
 use tower_http::sensitive_headers::SetSensitiveResponseHeadersLayer;
@@ -87,8 +91,8 @@ pub async fn init(ct: CancellationToken) -> Result<(), Error> {
 	#[cfg(feature = "http-compression")]
 	let service = service.layer(
 		CompressionLayer::new().compress_when(
-			// Don't compress below 512 bytes
-			SizeAbove::new(512)
+			// Don't compress small responses
+			SizeAbove::new(*cnf::HTTP_COMPRESSION_MIN_SIZE)
 				// Don't compress gRPC
 				.and(NotForContentType::GRPC)
 				// Don't compress images
@@ -96,33 +100,6 @@ pub async fn init(ct: CancellationToken) -> Result<(), Error> {
 		),
 	);
 
-	#[cfg(feature = "http-compression")]
-	let allow_header = [
-		http::header::ACCEPT,
-		http::header::ACCEPT_ENCODING,
-		http::header::AUTHORIZATION,
-		http::header::CONTENT_TYPE,
-		http::header::ORIGIN,
-		NS.clone(),
-		DB.clone(),
-		ID.clone(),
-		AUTH_NS.clone(),
-		AUTH_DB.clone(),
-	];
-
-	#[cfg(not(feature = "http-compression"))]
-	let allow_header = [
-		http::header::ACCEPT,
-		http::header::AUTHORIZATION,
-		http::header::CONTENT_TYPE,
-		http::header::ORIGIN,
-		NS.clone(),
-		DB.clone(),
-		ID.clone(),
-		AUTH_NS.clone(),
-		AUTH_DB.clone(),
-	];
-
 	let service = service
 		.layer(AddExtensionLayer::new(app_state))
 		.layer(middleware::from_fn(client_ip::client_ip_middleware))
@@ -136,30 +113,19 @@ pub async fn init(ct: CancellationToken) -> Result<(), Error> {
 		)
 		.layer(HttpMetricsLayer)
 		.layer(SetSensitiveResponseHeadersLayer::from_shared(headers))
+		// The CORS headers are added to rate limited and unauthorized responses
+		.layer(cors::layer()?)
+		.layer(middleware::from_fn(rate_limit::rate_limit_middleware))
 		.layer(AsyncRequireAuthorizationLayer::new(auth::SurrealAuth))
 		.layer(headers::add_server_header(!opt.no_identification_headers))
-		.layer(headers::add_version_header(!opt.no_identification_headers))
-		.layer(
-			CorsLayer::new()
-				.allow_methods([
-					http::Method::GET,
-					http::Method::PUT,
-					http::Method::POST,
-					http::Method::PATCH,
-					http::Method::DELETE,
-					http::Method::OPTIONS,
-				])
-				.allow_headers(allow_header)
-				// allow requests from any origin
-				.allow_origin(Any)
-				.max_age(Duration::from_secs(86400)),
-		);
+		.layer(headers::add_version_header(!opt.no_identification_headers));
 
 	let axum_app = Router::new()
 		// Redirect until we provide a UI
 		.route("/", get(|| async { Redirect::temporary(cnf::APP_ENDPOINT) }))
 		.route("/status", get(|| async {}))
 		.merge(health::router())
+		.merge(changes::router())
 		.merge(export::router())
 		.merge(import::router())
 		.merge(rpc::router())
@@ -168,25 +134,40 @@ pub async fn init(ct: CancellationToken) -> Result<(), Error> {
 		.merge(sql::router())
 		.merge(signin::router())
 		.merge(signup::router())
+		.merge(renew::router())
+		.merge(reload::router())
 		.merge(key::router());
 
 	#[cfg(feature = "ml")]
 	let axum_app = axum_app.merge(ml::router());
 
+	let axum_app = match *cnf::DIAGNOSTICS_ENABLED {
+		true => axum_app.merge(diagnostics::router()),
+		false => axum_app,
+	};
+
 	let axum_app = axum_app.layer(service);
 
 	// Get a new server handler
 	let handle = Handle::new();
 	// Setup the graceful shutdown handler
 	let shutdown_handler = graceful_shutdown(ct.clone(), handle.clone());
+	// Reload the configuration when a SIGHUP signal is received
+	#[cfg(unix)]
+	signals::reload_on_hangup(ct.clone());
 	// Spawn a task to handle notifications
 	tokio::spawn(async move { notifications(ct.clone()).await });
 	// If a certificate and key are specified then setup TLS
 	if let (Some(cert), Some(key)) = (&opt.crt, &opt.key) {
 		// Configure certificate and private key used by https
-		let tls = RustlsConfig::from_pem_file(cert, key).await.unwrap();
-		// Setup the Axum server with TLS
-		let server = axum_server::bind_rustls(opt.bind, tls);
+		let tls = match &opt.client_ca {
+			// Require and verify client certificates
+			Some(ca) => tls::mutual(cert, key, ca)?,
+			// Allow clients without certificates
+			None => RustlsConfig::from_pem_file(cert, key).await.unwrap(),
+		};
+		// Setup the Axum server with TLS, passing client certificates to requests
+		let server = axum_server::bind(opt.bind).acceptor(tls::ClientCertAcceptor::new(tls));
 		// Log the server startup to the CLI
 		info!(target: LOG, "Started web server on {}", &opt.bind);
 		// Start the server and listen for connections
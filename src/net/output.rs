@@ -66,7 +66,9 @@ where
 	}
 }
 
-/// Convert and simplify the value into JSON
+/// Convert and simplify the value into JSON. Query responses should be
+/// passed by reference, so that the memory reserved for their results
+/// is held until the output has been serialized.
 pub fn simplify<T: Serialize>(v: T) -> Json {
 	sql::to_value(v).unwrap().into()
 }
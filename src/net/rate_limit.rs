@@ -0,0 +1,228 @@
+use crate::cnf;
+use crate::telemetry::metrics::http::record_rate_limited;
+use axum::extract::ConnectInfo;
+use axum::middleware::Next;
+use axum::response::{IntoResponse, Response};
+use http::header::RETRY_AFTER;
+use http::{HeaderMap, HeaderName, HeaderValue, Request, StatusCode};
+use once_cell::sync::Lazy;
+use std::collections::HashMap;
+use std::net::SocketAddr;
+use std::sync::Mutex;
+use std::time::Instant;
+use surrealdb::cnf::Dynamic;
+use surrealdb::rpc::RpcError;
+
+use super::client_ip::ExtractClientIP;
+
+/// The number of buckets which are kept before idle buckets are pruned
+const MAX_IDLE_BUCKETS: usize = 10_000;
+
+static RATELIMIT_LIMIT: HeaderName = HeaderName::from_static("ratelimit-limit");
+static RATELIMIT_REMAINING: HeaderName = HeaderName::from_static("ratelimit-remaining");
+static RATELIMIT_RESET: HeaderName = HeaderName::from_static("ratelimit-reset");
+
+static AUTH: Lazy<Limiter> =
+	Lazy::new(|| Limiter::new(&cnf::RATE_LIMIT_AUTH_PER_SECOND, &cnf::RATE_LIMIT_AUTH_BURST));
+
+static QUERY: Lazy<Limiter> =
+	Lazy::new(|| Limiter::new(&cnf::RATE_LIMIT_QUERY_PER_SECOND, &cnf::RATE_LIMIT_QUERY_BURST));
+
+/// The state of the rate limit for a single client
+struct Bucket {
+	tokens: f64,
+	updated: Instant,
+}
+
+/// The outcome of checking the rate limit for a request
+struct Check {
+	allowed: bool,
+	limit: u32,
+	remaining: u32,
+	reset: u64,
+}
+
+/// The address which a client is rate limited by. This is the client IP
+/// address, or the address of the connection if the client IP address is
+/// not known, so that unknown clients do not share a single limit.
+#[derive(Clone, Debug)]
+pub(crate) struct ClientKey(String);
+
+/// The buckets of all of the clients of a rate limiter
+struct Buckets {
+	clients: HashMap<String, Bucket>,
+	/// The number of buckets at which idle buckets are next pruned
+	prune_at: usize,
+}
+
+/// A token bucket rate limiter, keyed by client, whose
+/// limits can be changed while the server is running
+struct Limiter {
+	rate: &'static Dynamic,
+	burst: &'static Dynamic,
+	buckets: Mutex<Buckets>,
+}
+
+impl Limiter {
+	fn new(rate: &'static Dynamic, burst: &'static Dynamic) -> Self {
+		Self {
+			rate,
+			burst,
+			buckets: Mutex::new(Buckets {
+				clients: HashMap::new(),
+				prune_at: MAX_IDLE_BUCKETS,
+			}),
+		}
+	}
+
+	/// Take a token from the bucket for the specified client,
+	/// returning None if the rate limiter is disabled
+	fn check(&self, key: &str) -> Option<Check> {
+		// Fetch the current limits
+		let rate = match self.rate.get() {
+			0 => return None,
+			v => v as f64,
+		};
+		let burst = match self.burst.get() {
+			0 => rate,
+			v => v as f64,
+		};
+		let now = Instant::now();
+		let mut buckets = self.buckets.lock().unwrap_or_else(|e| e.into_inner());
+		// Prune any buckets which have refilled completely. The buckets are
+		// only pruned once their number has doubled since they were last
+		// pruned, so that the cost of pruning is spread across the requests
+		if buckets.clients.len() > buckets.prune_at {
+			buckets.clients.retain(|_, b| {
+				b.tokens + now.duration_since(b.updated).as_secs_f64() * rate < burst
+			});
+			buckets.prune_at = MAX_IDLE_BUCKETS.max(buckets.clients.len() * 2);
+		}
+		// Refill the bucket for the time which has passed
+		let bucket = buckets.clients.entry(key.to_owned()).or_insert(Bucket {
+			tokens: burst,
+			updated: now,
+		});
+		let elapsed = now.duration_since(bucket.updated).as_secs_f64();
+		bucket.tokens = (bucket.tokens + elapsed * rate).min(burst);
+		bucket.updated = now;
+		// Take a token if one is available
+		let allowed = bucket.tokens >= 1.0;
+		if allowed {
+			bucket.tokens -= 1.0;
+		}
+		Some(Check {
+			allowed,
+			limit: burst as u32,
+			remaining: bucket.tokens.floor() as u32,
+			reset: ((burst - bucket.tokens) / rate).ceil() as u64,
+		})
+	}
+}
+
+/// Add the standard rate limit headers to a response
+fn headers(headers: &mut HeaderMap, check: &Check) {
+	headers.insert(RATELIMIT_LIMIT.clone(), HeaderValue::from(check.limit));
+	headers.insert(RATELIMIT_REMAINING.clone(), HeaderValue::from(check.remaining));
+	headers.insert(RATELIMIT_RESET.clone(), HeaderValue::from(check.reset));
+}
+
+/// Take a token from the authentication rate limit of a client, for the
+/// signin and signup methods of the RPC endpoints, which are not limited
+/// along with the /signin and /signup endpoints.
+pub(crate) fn check_auth(client: Option<&ClientKey>) -> Result<(), RpcError> {
+	let Some(ClientKey(key)) = client else {
+		return Ok(());
+	};
+	match AUTH.check(key) {
+		Some(check) if !check.allowed => {
+			record_rate_limited("auth");
+			Err(RpcError::Thrown(format!(
+				"Too many authentication attempts, retry after {} seconds",
+				check.reset.max(1)
+			)))
+		}
+		_ => Ok(()),
+	}
+}
+
+pub(super) async fn rate_limit_middleware<B>(mut request: Request<B>, next: Next<B>) -> Response {
+	// Limit each client by IP address, before authentication takes place
+	let key =
+		request.extensions().get::<ExtractClientIP>().and_then(|v| v.0.clone()).or_else(|| {
+			request.extensions().get::<ConnectInfo<SocketAddr>>().map(|v| v.0.ip().to_string())
+		});
+	// Clients which can not be identified are not limited
+	let Some(key) = key else {
+		return next.run(request).await;
+	};
+	// Store the client for the rate limits of the RPC endpoints
+	request.extensions_mut().insert(ClientKey(key.clone()));
+	// Authentication endpoints are limited separately
+	let (class, limiter) = match request.uri().path() {
+		"/signin" | "/signup" | "/renew" => ("auth", &*AUTH),
+		_ => ("query", &*QUERY),
+	};
+	// Check the rate limit for this client, unless the rate limiter is disabled
+	let Some(check) = limiter.check(&key) else {
+		return next.run(request).await;
+	};
+	// Reject the request if the limit has been reached
+	if !check.allowed {
+		record_rate_limited(class);
+		let mut res = StatusCode::TOO_MANY_REQUESTS.into_response();
+		headers(res.headers_mut(), &check);
+		res.headers_mut().insert(RETRY_AFTER, HeaderValue::from(check.reset.max(1)));
+		return res;
+	}
+	// Process the request
+	let mut res = next.run(request).await;
+	headers(res.headers_mut(), &check);
+	res
+}
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+
+	static RATE: Dynamic = Dynamic::new("SURREAL_TEST_RATE_LIMIT_PER_SECOND", 1);
+	static BURST: Dynamic = Dynamic::new("SURREAL_TEST_RATE_LIMIT_BURST", 3);
+	static DISABLED: Dynamic = Dynamic::new("SURREAL_TEST_RATE_LIMIT_DISABLED", 0);
+
+	#[test]
+	fn limiter_allows_burst_then_rejects() {
+		let limiter = Limiter::new(&RATE, &BURST);
+		for remaining in (0..3).rev() {
+			let check = limiter.check("127.0.0.1").unwrap();
+			assert!(check.allowed);
+			assert_eq!(check.remaining, remaining);
+		}
+		assert!(!limiter.check("127.0.0.1").unwrap().allowed);
+		// Other clients are limited separately
+		assert!(limiter.check("127.0.0.2").unwrap().allowed);
+	}
+
+	#[test]
+	fn limiter_prunes_idle_buckets() {
+		let limiter = Limiter::new(&RATE, &BURST);
+		for i in 0..=MAX_IDLE_BUCKETS {
+			limiter.check(&i.to_string()).unwrap();
+		}
+		// The buckets are only pruned once there are too many
+		let buckets = limiter.buckets.lock().unwrap();
+		assert_eq!(buckets.clients.len(), MAX_IDLE_BUCKETS + 1);
+		drop(buckets);
+		// None of the buckets are idle, so they are not pruned again
+		// until their number has doubled
+		limiter.check("other").unwrap();
+		let buckets = limiter.buckets.lock().unwrap();
+		assert_eq!(buckets.clients.len(), MAX_IDLE_BUCKETS + 2);
+		assert_eq!(buckets.prune_at, (MAX_IDLE_BUCKETS + 1) * 2);
+	}
+
+	#[test]
+	fn limiter_disabled() {
+		let limiter = Limiter::new(&DISABLED, &BURST);
+		assert!(limiter.check("127.0.0.1").is_none());
+	}
+}
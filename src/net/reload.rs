@@ -0,0 +1,27 @@
+use crate::cnf;
+use crate::dbs::DB;
+use crate::err::Error;
+use axum::response::IntoResponse;
+use axum::routing::post;
+use axum::{Extension, Router};
+use http_body::Body as HttpBody;
+use surrealdb::dbs::Session;
+use surrealdb::iam::Action::Edit;
+use surrealdb::iam::ResourceKind::Any;
+
+pub(super) fn router<S, B>() -> Router<S, B>
+where
+	B: HttpBody + Send + 'static,
+	S: Clone + Send + Sync + 'static,
+{
+	Router::new().route("/reload", post(handler))
+}
+
+async fn handler(Extension(session): Extension<Session>) -> Result<impl IntoResponse, Error> {
+	// Get the datastore reference
+	let db = DB.get().unwrap();
+	// The configuration can only be reloaded by root users
+	db.check(&session, Edit, Any.on_root())?;
+	// Reload the server configuration
+	cnf::file::reload()
+}
@@ -0,0 +1,95 @@
+use crate::dbs::DB;
+use crate::err::Error;
+use crate::net::input::bytes_to_utf8;
+use crate::net::output;
+use axum::extract::DefaultBodyLimit;
+use axum::response::IntoResponse;
+use axum::routing::options;
+use axum::{Extension, Router, TypedHeader};
+use bytes::Bytes;
+use http_body::Body as HttpBody;
+use serde::Serialize;
+use surrealdb::dbs::Session;
+use surrealdb::sql::Value;
+use tower_http::limit::RequestBodyLimitLayer;
+
+use super::headers::Accept;
+
+const MAX: usize = 1024; // 1 KiB
+
+#[derive(Serialize)]
+struct Success {
+	code: u16,
+	details: String,
+	token: String,
+	refresh: String,
+}
+
+impl Success {
+	fn new(token: String, refresh: String) -> Success {
+		Success {
+			token,
+			refresh,
+			code: 200,
+			details: String::from("Authentication renewed"),
+		}
+	}
+}
+
+pub(super) fn router<S, B>() -> Router<S, B>
+where
+	B: HttpBody + Send + 'static,
+	B::Data: Send,
+	B::Error: std::error::Error + Send + Sync + 'static,
+	S: Clone + Send + Sync + 'static,
+{
+	Router::new()
+		.route("/renew", options(|| async {}).post(handler))
+		.route_layer(DefaultBodyLimit::disable())
+		.layer(RequestBodyLimitLayer::new(MAX))
+}
+
+async fn handler(
+	Extension(mut session): Extension<Session>,
+	accept: Option<TypedHeader<Accept>>,
+	body: Bytes,
+) -> Result<impl IntoResponse, impl IntoResponse> {
+	// Get a database reference
+	let kvs = DB.get().unwrap();
+	// Convert the HTTP body into text
+	let data = bytes_to_utf8(&body)?;
+	// Parse the provided data as JSON
+	let vars = match surrealdb::sql::json(data) {
+		Ok(Value::Object(vars)) => vars,
+		_ => return Err(Error::Request),
+	};
+	// Parse the specified variables
+	let ns = vars.get("NS").or_else(|| vars.get("ns"));
+	let db = vars.get("DB").or_else(|| vars.get("db"));
+	let ac = vars.get("AC").or_else(|| vars.get("ac"));
+	let rf = vars.get("refresh");
+	// Check that all of the parameters exist
+	let (ns, db, ac, rf) = match (ns, db, ac, rf) {
+		(Some(ns), Some(db), Some(ac), Some(rf)) => {
+			(ns.to_raw_string(), db.to_raw_string(), ac.to_raw_string(), rf.to_raw_string())
+		}
+		_ => return Err(Error::Request),
+	};
+	// Exchange the refresh grant for a new token
+	let (tk, rf) = surrealdb::iam::grant::renew(kvs, &mut session, ns, db, ac, rf).await?;
+	// Return the new token and refresh grant
+	match accept.as_deref() {
+		// Simple serialization
+		Some(Accept::ApplicationJson) => Ok(output::json(&Success::new(tk, rf))),
+		Some(Accept::ApplicationCbor) => Ok(output::cbor(&Success::new(tk, rf))),
+		Some(Accept::ApplicationPack) => Ok(output::pack(&Success::new(tk, rf))),
+		// Text serialization
+		Some(Accept::TextPlain) => Ok(output::text(tk)),
+		// Internal serialization
+		Some(Accept::Surrealdb) => Ok(output::full(&Success::new(tk, rf))),
+		// Return nothing
+		None => Ok(output::none()),
+		// An incorrect content-type was requested
+		_ => Err(Error::InvalidType),
+	}
+}
@@ -9,6 +9,7 @@ use crate::rpc::format::HttpFormat;
 use crate::rpc::post_context::PostRpcContext;
 use crate::rpc::response::IntoRpcResponse;
 use crate::rpc::WEBSOCKETS;
+use crate::telemetry::traces::extract_parent;
 use axum::routing::get;
 use axum::routing::post;
 use axum::TypedHeader;
@@ -18,8 +19,10 @@ use axum::{
 	Extension, Router,
 };
 use bytes::Bytes;
+use http::HeaderMap;
 use http::HeaderValue;
 use http_body::Body as HttpBody;
+use opentelemetry::Context as TelemetryContext;
 use surrealdb::dbs::Session;
 use surrealdb::rpc::format::Format;
 use surrealdb::rpc::format::PROTOCOLS;
@@ -29,6 +32,7 @@ use uuid::Uuid;
 
 use super::headers::Accept;
 use super::headers::ContentType;
+use super::rate_limit::ClientKey;
 
 use surrealdb::rpc::rpc_context::RpcContext;
 
@@ -46,6 +50,8 @@ async fn get_handler(
 	ws: WebSocketUpgrade,
 	Extension(id): Extension<RequestId>,
 	Extension(sess): Extension<Session>,
+	client: Option<Extension<ClientKey>>,
+	headers: HeaderMap,
 ) -> Result<impl IntoResponse, impl IntoResponse> {
 	// Check if there is a request id header specified
 	let id = match id.header_value().is_empty() {
@@ -68,6 +74,8 @@ async fn get_handler(
 	if WEBSOCKETS.read().await.contains_key(&id) {
 		return Err(Error::Request);
 	}
+	// Continue any trace propagated by the client
+	let parent = extract_parent(&headers);
 	// Now let's upgrade the WebSocket connection
 	Ok(ws
 		// Set the potential WebSocket protocols
@@ -77,10 +85,16 @@ async fn get_handler(
 		// Set the maximum WebSocket message size
 		.max_message_size(*cnf::WEBSOCKET_MAX_MESSAGE_SIZE)
 		// Handle the WebSocket upgrade and process messages
-		.on_upgrade(move |socket| handle_socket(socket, sess, id)))
+		.on_upgrade(move |socket| handle_socket(socket, sess, client, id, parent)))
 }
 
-async fn handle_socket(ws: WebSocket, sess: Session, id: Uuid) {
+async fn handle_socket(
+	ws: WebSocket,
+	sess: Session,
+	client: Option<Extension<ClientKey>>,
+	id: Uuid,
+	parent: TelemetryContext,
+) {
 	// Check if there is a WebSocket protocol specified
 	let format = match ws.protocol().map(HeaderValue::to_str) {
 		// Any selected protocol will always be a valie value
@@ -90,13 +104,14 @@ async fn handle_socket(ws: WebSocket, sess: Session, id: Uuid) {
 	};
 	// Format::Unsupported is not in the PROTOCOLS list so cannot be the value of format here
 	// Create a new connection instance
-	let rpc = Connection::new(id, sess, format);
+	let rpc = Connection::new(id, sess, client.map(|v| v.0), format, parent);
 	// Serve the socket connection requests
 	Connection::serve(rpc, ws).await;
 }
 
 async fn post_handler(
 	Extension(session): Extension<Session>,
+	client: Option<Extension<ClientKey>>,
 	output: Option<TypedHeader<Accept>>,
 	content_type: TypedHeader<ContentType>,
 	body: Bytes,
@@ -112,7 +127,8 @@ async fn post_handler(
 		return Err(Error::InvalidType);
 	}
 
-	let mut rpc_ctx = PostRpcContext::new(DB.get().unwrap(), session, BTreeMap::new());
+	let mut rpc_ctx = PostRpcContext::new(DB.get().unwrap(), session, BTreeMap::new())
+		.with_client(client.map(|v| v.0));
 
 	match fmt.req_http(body) {
 		Ok(req) => {
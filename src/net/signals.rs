@@ -1,12 +1,15 @@
 use axum_server::Handle;
+use std::time::Duration;
 use tokio::task::JoinHandle;
 use tokio_util::sync::CancellationToken;
 
-use crate::{err::Error, rpc, telemetry};
+use crate::{cnf, dbs::DB, err::Error, rpc, telemetry};
 
 /// Start a graceful shutdown:
 /// * Signal the Axum Handle when a shutdown signal is received.
+/// * Give running queries a grace period to finish, then cancel them.
 /// * Stop all WebSocket connections.
+/// * Close live queries and flush the datastore.
 /// * Flush all telemetry data.
 ///
 /// A second signal will force an immediate shutdown.
@@ -20,13 +23,23 @@ pub fn graceful_shutdown(ct: CancellationToken, http_handle: Handle) -> JoinHand
 			let ct = ct.clone();
 
 			tokio::spawn(async move {
-				// Stop accepting new HTTP requests and wait until all connections are closed
+				// Stop accepting new HTTP connections
 				http_handle.graceful_shutdown(None);
+
+				// Wait for running queries to finish, or cancel them
+				let db = DB.get().unwrap();
+				db.drain(Duration::from_secs(*cnf::SHUTDOWN_GRACE_PERIOD)).await;
+
+				// Close all WebSocket connections, and wait until all connections are closed
+				rpc::graceful_shutdown().await;
 				while http_handle.connection_count() > 0 {
-					tokio::time::sleep(tokio::time::Duration::from_millis(100)).await;
+					tokio::time::sleep(Duration::from_millis(100)).await;
 				}
 
-				rpc::graceful_shutdown().await;
+				// Close live queries and flush the datastore to storage
+				if let Err(err) = db.shutdown().await {
+					error!(target: super::LOG, "Failed to shut down the datastore: {}", err);
+				}
 
 				ct.cancel();
 
@@ -61,21 +74,47 @@ pub fn graceful_shutdown(ct: CancellationToken, http_handle: Handle) -> JoinHand
 	})
 }
 
+/// Reload the server configuration whenever a SIGHUP signal is received,
+/// until the server is shut down.
+#[cfg(unix)]
+pub fn reload_on_hangup(ct: CancellationToken) -> JoinHandle<()> {
+	tokio::spawn(async move {
+		// Import the OS signals
+		use tokio::signal::unix::{signal, SignalKind};
+		// Get the operating system signal type
+		let mut sighup = match signal(SignalKind::hangup()) {
+			Ok(v) => v,
+			Err(err) => {
+				error!(target: super::LOG, "Failed to listen to reload signal: {}", err);
+				return;
+			}
+		};
+		loop {
+			tokio::select! {
+				// Stop listening once the server is shut down
+				_ = ct.cancelled() => break,
+				// Wait for a SIGHUP signal
+				Some(_) = sighup.recv() => {
+					info!(target: super::LOG, "SIGHUP received. Reloading the server configuration...");
+					if let Err(err) = cnf::file::reload() {
+						error!(target: super::LOG, "Failed to reload the server configuration: {}", err);
+					}
+				}
+			}
+		}
+	})
+}
+
 #[cfg(unix)]
 pub async fn listen() -> Result<String, Error> {
 	// Import the OS signals
 	use tokio::signal::unix::{signal, SignalKind};
 	// Get the operating system signal types
-	let mut sighup = signal(SignalKind::hangup())?;
 	let mut sigint = signal(SignalKind::interrupt())?;
 	let mut sigquit = signal(SignalKind::quit())?;
 	let mut sigterm = signal(SignalKind::terminate())?;
 	// Listen and wait for the system signals
 	tokio::select! {
-		// Wait for a SIGHUP signal
-		_ = sighup.recv() => {
-			Ok(String::from("SIGHUP"))
-		}
 		// Wait for a SIGINT signal
 		_ = sigint.recv() => {
 			Ok(String::from("SIGINT"))
@@ -22,12 +22,15 @@ struct Success {
 	code: u16,
 	details: String,
 	token: Option<String>,
+	#[serde(skip_serializing_if = "Option::is_none")]
+	refresh: Option<String>,
 }
 
 impl Success {
-	fn new(token: Option<String>) -> Success {
+	fn new(token: Option<String>, refresh: Option<String>) -> Success {
 		Success {
 			token,
+			refresh,
 			code: 200,
 			details: String::from("Authentication succeeded"),
 		}
@@ -63,20 +66,24 @@ async fn handler(
 			match surrealdb::iam::signup::signup(kvs, &mut session, vars).await.map_err(Error::from)
 			{
 				// Authentication was successful
-				Ok(v) => match accept.as_deref() {
-					// Simple serialization
-					Some(Accept::ApplicationJson) => Ok(output::json(&Success::new(v))),
-					Some(Accept::ApplicationCbor) => Ok(output::cbor(&Success::new(v))),
-					Some(Accept::ApplicationPack) => Ok(output::pack(&Success::new(v))),
-					// Text serialization
-					Some(Accept::TextPlain) => Ok(output::text(v.unwrap_or_default())),
-					// Internal serialization
-					Some(Accept::Surrealdb) => Ok(output::full(&Success::new(v))),
-					// Return nothing
-					None => Ok(output::none()),
-					// An incorrect content-type was requested
-					_ => Err(Error::InvalidType),
-				},
+				Ok(v) => {
+					// Issue a refresh grant if the access method supports it
+					let r = surrealdb::iam::grant::issue(kvs, &session).await?;
+					match accept.as_deref() {
+						// Simple serialization
+						Some(Accept::ApplicationJson) => Ok(output::json(&Success::new(v, r))),
+						Some(Accept::ApplicationCbor) => Ok(output::cbor(&Success::new(v, r))),
+						Some(Accept::ApplicationPack) => Ok(output::pack(&Success::new(v, r))),
+						// Text serialization
+						Some(Accept::TextPlain) => Ok(output::text(v.unwrap_or_default())),
+						// Internal serialization
+						Some(Accept::Surrealdb) => Ok(output::full(&Success::new(v, r))),
+						// Return nothing
+						None => Ok(output::none()),
+						// An incorrect content-type was requested
+						_ => Err(Error::InvalidType),
+					}
+				}
 				// There was an error with authentication
 				Err(err) => Err(err),
 			}
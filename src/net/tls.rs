@@ -0,0 +1,279 @@
+use crate::err::Error;
+use axum_server::accept::Accept;
+use axum_server::tls_rustls::{RustlsAcceptor, RustlsConfig};
+use futures_util::future::BoxFuture;
+use rustls::server::AllowAnyAuthenticatedClient;
+use rustls::{Certificate, PrivateKey, RootCertStore, ServerConfig};
+use rustls_pemfile::Item;
+use std::fs::File;
+use std::io::{self, BufReader};
+use std::net::{Ipv4Addr, Ipv6Addr};
+use std::path::Path;
+use std::sync::{Arc, RwLock};
+use surrealdb::dbs::Session;
+use surrealdb::iam::{signin, Level, Role};
+use surrealdb::kvs::Datastore;
+use tokio::net::TcpStream;
+use tower_http::add_extension::AddExtension;
+use x509_parser::prelude::{FromDer, GeneralName, X509Certificate};
+
+/// Read all of the certificates from a PEM file
+fn certificates(path: &Path) -> Result<Vec<Certificate>, Error> {
+	let mut file = BufReader::new(File::open(path)?);
+	Ok(rustls_pemfile::certs(&mut file)?.into_iter().map(Certificate).collect())
+}
+
+/// Read the first private key from a PEM file
+fn private_key(path: &Path) -> Result<PrivateKey, Error> {
+	let mut file = BufReader::new(File::open(path)?);
+	while let Some(item) = rustls_pemfile::read_one(&mut file)? {
+		match item {
+			Item::PKCS8Key(key) | Item::RSAKey(key) | Item::ECKey(key) => {
+				return Ok(PrivateKey(key));
+			}
+			_ => continue,
+		}
+	}
+	Err(Error::Other(format!("No private key found in {}", path.display())))
+}
+
+/// Configure TLS for the web server, requiring clients to present
+/// a certificate signed by the specified certificate authority
+pub(super) fn mutual(crt: &Path, key: &Path, ca: &Path) -> Result<RustlsConfig, Error> {
+	// Load the trusted client certificate authorities
+	let mut roots = RootCertStore::empty();
+	for cert in certificates(ca)? {
+		roots.add(&cert).map_err(|e| Error::Other(format!("Invalid client CA: {e}")))?;
+	}
+	// Require and verify client certificates
+	let verifier = AllowAnyAuthenticatedClient::new(roots).boxed();
+	// Configure the server certificate and private key
+	let mut config = ServerConfig::builder()
+		.with_safe_defaults()
+		.with_client_cert_verifier(verifier)
+		.with_single_cert(certificates(crt)?, private_key(key)?)
+		.map_err(|e| Error::Other(format!("Invalid certificate: {e}")))?;
+	config.alpn_protocols = vec![b"h2".to_vec(), b"http/1.1".to_vec()];
+	// Return the server configuration
+	Ok(RustlsConfig::from_config(Arc::new(config)))
+}
+
+/// The rules which map client certificates to an auth level
+static RULES: RwLock<Vec<CertificateRule>> = RwLock::new(Vec::new());
+
+/// Replace the rules which map client certificates to an auth level
+pub(crate) fn set_rules(rules: Vec<CertificateRule>) {
+	*RULES.write().unwrap_or_else(|e| e.into_inner()) = rules;
+}
+
+/// Authenticate a session with a verified client certificate, using the
+/// first rule which matches the certificate. The certificate is signed in
+/// like any other credentials, so the client address is checked against
+/// the allowed addresses, and the attempt is audited. Returns whether a
+/// rule matched.
+pub(super) async fn authenticate(
+	kvs: &Datastore,
+	session: &mut Session,
+	cert: &ClientCert,
+) -> Result<bool, Error> {
+	// Find the first rule which matches the certificate
+	let rule = {
+		let rules = RULES.read().unwrap_or_else(|e| e.into_inner());
+		rules.iter().find(|r| r.matches(cert)).cloned()
+	};
+	let Some(rule) = rule else {
+		return Ok(false);
+	};
+	// Identify the actor by the certificate subject
+	let id = cert.cn.first().or(cert.san.first()).cloned().unwrap_or_default();
+	signin::certificate(kvs, session, id, rule.role, rule.level).await?;
+	Ok(true)
+}
+
+/// A rule which grants a role to client certificates with matching
+/// attributes. Every attribute which is specified needs to match.
+#[derive(Clone, Debug, Default)]
+pub(crate) struct CertificateRule {
+	/// The common name of the certificate subject
+	pub cn: Option<String>,
+	/// The organisational unit of the certificate subject
+	pub ou: Option<String>,
+	/// A subject alternative name of the certificate
+	pub san: Option<String>,
+	/// The role which is granted
+	pub role: Role,
+	/// The root, namespace, or database which the role is granted on
+	pub level: Level,
+}
+
+impl CertificateRule {
+	/// Check if a client certificate matches this rule
+	fn matches(&self, cert: &ClientCert) -> bool {
+		let attr = |rule: &Option<String>, vals: &[String]| match rule {
+			Some(rule) => vals.iter().any(|v| v == rule),
+			None => true,
+		};
+		attr(&self.cn, &cert.cn) && attr(&self.ou, &cert.ou) && attr(&self.san, &cert.san)
+	}
+}
+
+/// The subject attributes of a verified client certificate
+#[derive(Clone, Debug, Default, PartialEq)]
+pub(super) struct ClientCert {
+	/// The common names of the subject
+	pub cn: Vec<String>,
+	/// The organisational units of the subject
+	pub ou: Vec<String>,
+	/// The DNS names, email addresses, URIs, and IP addresses of the subject
+	pub san: Vec<String>,
+}
+
+impl ClientCert {
+	/// Read the subject attributes from a DER encoded X.509 certificate.
+	/// The certificate has already been verified by rustls, so this only
+	/// extracts the fields which can be used in certificate rules.
+	fn parse(der: &[u8]) -> Option<Self> {
+		let (_, cert) = X509Certificate::from_der(der).ok()?;
+		let mut out = ClientCert::default();
+		// Read the attributes of the subject
+		let subject = cert.subject();
+		out.cn =
+			subject.iter_common_name().filter_map(|v| v.as_str().ok()).map(Into::into).collect();
+		out.ou = subject
+			.iter_organizational_unit()
+			.filter_map(|v| v.as_str().ok())
+			.map(Into::into)
+			.collect();
+		// Read the subject alternative names
+		if let Some(ext) = cert.subject_alternative_name().ok()? {
+			for name in ext.value.general_names.iter() {
+				match name {
+					GeneralName::DNSName(v) | GeneralName::RFC822Name(v) | GeneralName::URI(v) => {
+						out.san.push(v.to_string())
+					}
+					GeneralName::IPAddress(v) => match v.len() {
+						4 => {
+							out.san.push(Ipv4Addr::from(<[u8; 4]>::try_from(*v).ok()?).to_string())
+						}
+						16 => {
+							out.san.push(Ipv6Addr::from(<[u8; 16]>::try_from(*v).ok()?).to_string())
+						}
+						_ => (),
+					},
+					_ => (),
+				}
+			}
+		}
+		Some(out)
+	}
+}
+
+/// Accepts TLS connections, and adds the verified client
+/// certificate, if any, to every request on the connection
+#[derive(Clone)]
+pub(super) struct ClientCertAcceptor {
+	inner: RustlsAcceptor,
+}
+
+impl ClientCertAcceptor {
+	pub(super) fn new(config: RustlsConfig) -> Self {
+		Self {
+			inner: RustlsAcceptor::new(config),
+		}
+	}
+}
+
+impl<S> Accept<TcpStream, S> for ClientCertAcceptor
+where
+	S: Send + 'static,
+{
+	type Stream = <RustlsAcceptor as Accept<TcpStream, S>>::Stream;
+	type Service = AddExtension<S, Option<ClientCert>>;
+	type Future = BoxFuture<'static, io::Result<(Self::Stream, Self::Service)>>;
+
+	fn accept(&self, stream: TcpStream, service: S) -> Self::Future {
+		let acceptor = self.inner.clone();
+		Box::pin(async move {
+			let (stream, service) = acceptor.accept(stream, service).await?;
+			// Read the attributes of the verified client certificate
+			let cert = stream
+				.get_ref()
+				.1
+				.peer_certificates()
+				.and_then(|certs| certs.first())
+				.and_then(|cert| ClientCert::parse(&cert.0));
+			Ok((stream, AddExtension::new(service, cert)))
+		})
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	use super::{authenticate, set_rules, CertificateRule, ClientCert};
+	use rcgen::{Certificate, CertificateParams, DnType, SanType};
+	use std::net::{IpAddr, Ipv4Addr};
+	use surrealdb::dbs::Session;
+	use surrealdb::iam::{Level, Role};
+	use surrealdb::kvs::Datastore;
+
+	fn certificate() -> Vec<u8> {
+		let mut params = CertificateParams::new(vec!["billing.internal".to_owned()]);
+		params.distinguished_name.push(DnType::CommonName, "billing-service");
+		params.distinguished_name.push(DnType::OrganizationalUnitName, "services");
+		params.subject_alt_names.push(SanType::Rfc822Name("ops@example.com".to_owned()));
+		params.subject_alt_names.push(SanType::IpAddress(IpAddr::V4(Ipv4Addr::LOCALHOST)));
+		Certificate::from_params(params).unwrap().serialize_der().unwrap()
+	}
+
+	#[test]
+	fn certificate_attributes_are_parsed() {
+		let cert = ClientCert::parse(&certificate()).unwrap();
+		assert_eq!(cert.cn, vec!["billing-service"]);
+		assert_eq!(cert.ou, vec!["services"]);
+		assert_eq!(cert.san, vec!["billing.internal", "ops@example.com", "127.0.0.1"]);
+		// Invalid certificates are not parsed
+		assert_eq!(ClientCert::parse(&[0x30, 0x05, 0x01]), None);
+	}
+
+	#[tokio::test]
+	async fn certificates_are_mapped_to_roles() {
+		let ds = Datastore::new("memory").await.unwrap();
+		let sql = "DEFINE NAMESPACE billing ALLOW IP '10.0.0.0/8'";
+		ds.execute(sql, &Session::owner(), None).await.unwrap();
+		let cert = ClientCert::parse(&certificate()).unwrap();
+		set_rules(vec![
+			CertificateRule {
+				cn: Some("billing-service".to_owned()),
+				ou: Some("operations".to_owned()),
+				role: Role::Owner,
+				level: Level::Root,
+				..Default::default()
+			},
+			CertificateRule {
+				san: Some("billing.internal".to_owned()),
+				role: Role::Editor,
+				level: Level::Namespace("billing".to_owned()),
+				..Default::default()
+			},
+		]);
+		let mut session = Session::default();
+		session.ip = Some("10.0.0.1".to_owned());
+		assert!(authenticate(&ds, &mut session, &cert).await.unwrap());
+		// The first rule doesn't match the organisational unit
+		assert!(session.au.has_role(&Role::Editor));
+		assert_eq!(session.au.id(), "billing-service");
+		assert_eq!(session.au.level(), &Level::Namespace("billing".to_owned()));
+		assert_eq!(session.ns.as_deref(), Some("billing"));
+		// The client address needs to be allowed by the namespace
+		let mut session = Session::default();
+		session.ip = Some("192.168.0.1".to_owned());
+		assert!(authenticate(&ds, &mut session, &cert).await.is_err());
+		assert!(session.au.is_anon());
+		// Certificates without a matching rule are not authenticated
+		let mut session = Session::default();
+		assert!(!authenticate(&ds, &mut session, &ClientCert::default()).await.unwrap());
+		assert!(session.au.is_anon());
+		//
+		set_rules(Vec::new());
+	}
+}
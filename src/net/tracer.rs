@@ -8,8 +8,10 @@ use tower_http::{
 	trace::{MakeSpan, OnFailure, OnRequest, OnResponse},
 };
 use tracing::{field, Level, Span};
+use tracing_opentelemetry::OpenTelemetrySpanExt;
 
 use super::client_ip::ExtractClientIP;
+use crate::telemetry::traces::extract_parent;
 
 ///
 /// HttpTraceLayerHooks implements custom hooks for the tower_http::trace::TraceLayer layer.
@@ -96,6 +98,9 @@ impl<B> MakeSpan<B> for HttpTraceLayerHooks {
 			}
 		}
 
+		// Continue any trace propagated by the client
+		span.set_parent(extract_parent(req.headers()));
+
 		span
 	}
 }
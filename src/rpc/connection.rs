@@ -1,9 +1,12 @@
 use crate::cnf::{
-	PKG_NAME, PKG_VERSION, WEBSOCKET_MAX_CONCURRENT_REQUESTS, WEBSOCKET_PING_FREQUENCY,
+	PKG_NAME, PKG_VERSION, SESSION_SLIDING_EXPIRY, WEBSOCKET_MAX_CONCURRENT_REQUESTS,
+	WEBSOCKET_PING_FREQUENCY,
 };
 use crate::dbs::DB;
+use crate::net::rate_limit::{self, ClientKey};
 use crate::rpc::failure::Failure;
 use crate::rpc::format::WsFormat;
+use crate::rpc::idle;
 use crate::rpc::response::{failure, IntoRpcResponse};
 use crate::rpc::{CONN_CLOSED_ERR, LIVE_QUERIES, WEBSOCKETS};
 use crate::telemetry;
@@ -15,7 +18,9 @@ use futures_util::{SinkExt, StreamExt};
 use opentelemetry::trace::FutureExt;
 use opentelemetry::Context as TelemetryContext;
 use std::collections::BTreeMap;
+use std::sync::atomic::{AtomicI64, Ordering};
 use std::sync::Arc;
+use std::time::{SystemTime, UNIX_EPOCH};
 use surrealdb::channel::{self, Receiver, Sender};
 use surrealdb::dbs::Session;
 use surrealdb::kvs::Datastore;
@@ -37,26 +42,49 @@ pub struct Connection {
 	pub(crate) id: Uuid,
 	pub(crate) format: Format,
 	pub(crate) session: Session,
+	/// The client which authentication attempts are rate limited by
+	pub(crate) client: Option<ClientKey>,
 	pub(crate) vars: BTreeMap<String, Value>,
 	pub(crate) limiter: Arc<Semaphore>,
 	pub(crate) canceller: CancellationToken,
 	pub(crate) channels: (Sender<Message>, Receiver<Message>),
+	pub(crate) parent: TelemetryContext,
+	/// The unix timestamp at which a message was last received
+	pub(crate) active: AtomicI64,
+	/// The unix timestamp at which the session expires, or 0 if it doesn't
+	pub(crate) expiry: AtomicI64,
+	/// The lifetime of the session in seconds, used to extend its expiry on activity
+	pub(crate) lifetime: AtomicI64,
 }
 
 impl Connection {
 	/// Instantiate a new RPC
-	pub fn new(id: Uuid, mut session: Session, format: Format) -> Arc<RwLock<Connection>> {
+	pub fn new(
+		id: Uuid,
+		mut session: Session,
+		client: Option<ClientKey>,
+		format: Format,
+		parent: TelemetryContext,
+	) -> Arc<RwLock<Connection>> {
 		// Enable real-time mode
 		session.rt = true;
+		// Get the expiry of the session, which was authenticated with the request
+		let expiry = session.exp.unwrap_or_default();
+		let lifetime = session.exp.map(|exp| exp - now()).unwrap_or_default().max(0);
 		// Create and store the RPC connection
 		Arc::new(RwLock::new(Connection {
 			id,
 			format,
 			session,
+			client,
 			vars: BTreeMap::new(),
 			limiter: Arc::new(Semaphore::new(*WEBSOCKET_MAX_CONCURRENT_REQUESTS)),
 			canceller: CancellationToken::new(),
 			channels: channel::bounded(*WEBSOCKET_MAX_CONCURRENT_REQUESTS),
+			parent,
+			active: AtomicI64::new(now()),
+			expiry: AtomicI64::new(expiry),
+			lifetime: AtomicI64::new(lifetime),
 		}))
 	}
 
@@ -79,6 +107,13 @@ impl Connection {
 		// Add this WebSocket to the list
 		WEBSOCKETS.write().await.insert(id, rpc.clone());
 
+		// Register the session so that it can be revoked
+		{
+			let canceller = rpc.read().await.canceller.clone();
+			let session = rpc.read().await.session.clone();
+			DB.get().unwrap().register_session(id, &session, move || canceller.cancel());
+		}
+
 		// Spawn async tasks for the WebSocket
 		let mut tasks = JoinSet::new();
 		tasks.spawn(Self::ping(rpc.clone(), internal_sender.clone()));
@@ -99,25 +134,36 @@ impl Connection {
 		// Remove this WebSocket from the list
 		WEBSOCKETS.write().await.remove(&id);
 
+		// Remove the registered session
+		DB.get().unwrap().unregister_session(&id);
+
 		// Remove all live queries
+		Self::remove_live_queries(&id).await;
+
+		if let Err(err) = telemetry::metrics::ws::on_disconnect() {
+			error!("Error running metrics::ws::on_disconnect hook: {}", err);
+		}
+	}
+
+	/// Remove and garbage collect all live queries for a WebSocket
+	async fn remove_live_queries(id: &Uuid) {
+		// Remove the live queries for this WebSocket
 		let mut gc = Vec::new();
 		LIVE_QUERIES.write().await.retain(|key, value| {
-			if value == &id {
+			if value == id {
 				trace!("Removing live query: {}", key);
 				gc.push(*key);
 				return false;
 			}
 			true
 		});
-
 		// Garbage collect queries
+		if gc.is_empty() {
+			return;
+		}
 		if let Err(e) = DB.get().unwrap().garbage_collect_dead_session(gc.as_slice()).await {
 			error!("Failed to garbage collect dead sessions: {:?}", e);
 		}
-
-		if let Err(err) = telemetry::metrics::ws::on_disconnect() {
-			error!("Error running metrics::ws::on_disconnect hook: {}", err);
-		}
 	}
 
 	/// Send Ping messages to the client
@@ -135,6 +181,23 @@ impl Connection {
 				_ = canceller.cancelled() => break,
 				// Send a regular ping message
 				_ = interval.tick() => {
+					// Check the state of the connection
+					let (id, idle, expired) = {
+						let rpc = rpc.read().await;
+						(rpc.id, rpc.idle(), rpc.expired())
+					};
+					// Close the connection if it has been idle for too long
+					if idle {
+						trace!("WebSocket {} has been idle for too long", id);
+						// Cancel the WebSocket tasks
+						rpc.read().await.canceller.cancel();
+						// Exit out of the loop
+						break;
+					}
+					// Clean up the live queries of an expired session
+					if expired {
+						Self::remove_live_queries(&id).await;
+					}
 					// Create a new ping message
 					let msg = Message::Ping(vec![]);
 					// Close the connection if the message fails
@@ -264,7 +327,12 @@ impl Connection {
 		// Get the current output format
 		let mut fmt = rpc.read().await.format;
 		// Prepare Span and Otel context
-		let span = span_for_request(&rpc.read().await.id);
+		let span = {
+			let rpc = rpc.read().await;
+			span_for_request(&rpc.id, &rpc.parent)
+		};
+		// Record activity on the connection
+		rpc.read().await.touch();
 		// Acquire concurrent request rate limiter
 		let permit = rpc.read().await.limiter.clone().acquire_owned().await.unwrap();
 		// Calculate the length of the message
@@ -344,11 +412,82 @@ impl Connection {
 
 		// if the write lock is a bottleneck then execute could be refactored into execute_mut and execute
 		// rpc.write().await.execute(method, params).await.map_err(Into::into)
+		// Extend the session expiry on activity
+		if *SESSION_SLIDING_EXPIRY {
+			let expiry = rpc.read().await.slide();
+			if let Some(expiry) = expiry {
+				let mut rpc = rpc.write().await;
+				// Ignore sessions which were changed in the meantime
+				if rpc.expiry.load(Ordering::Acquire) == expiry {
+					rpc.session.exp = Some(expiry);
+				}
+			}
+		}
+
 		match method.needs_mut() {
-			true => rpc.write().await.execute(method, params).await.map_err(Into::into),
+			true => {
+				let mut rpc = rpc.write().await;
+				let exp = rpc.session.exp;
+				let res = rpc.execute(method, params).await;
+				// Store the lifetime of a newly authenticated session
+				if rpc.session.exp != exp {
+					let expiry = rpc.session.exp.unwrap_or_default();
+					let lifetime = rpc.session.exp.map(|exp| exp - now()).unwrap_or_default();
+					rpc.expiry.store(expiry, Ordering::Release);
+					rpc.lifetime.store(lifetime, Ordering::Release);
+				}
+				// Keep the registered session up to date
+				DB.get().unwrap().update_session(&rpc.id, &rpc.session);
+				res.map_err(Into::into)
+			}
 			false => rpc.read().await.execute_immut(method, params).await.map_err(Into::into),
 		}
 	}
+
+	/// Record that a message has been received on the connection
+	fn touch(&self) {
+		self.active.store(now(), Ordering::Release);
+	}
+
+	/// Check whether the connection has exceeded the idle timeout of its session
+	fn idle(&self) -> bool {
+		match idle::timeout(&self.session) {
+			0 => false,
+			secs => now() - self.active.load(Ordering::Acquire) >= secs as i64,
+		}
+	}
+
+	/// Check whether the session on the connection has expired
+	fn expired(&self) -> bool {
+		matches!(self.session.exp, Some(exp) if now() > exp)
+	}
+
+	/// Extend the expiry of a session which has not yet expired, returning
+	/// the new expiry if the session needs to be updated. The expiry has a
+	/// resolution of seconds, so this changes at most once every second, and
+	/// only one concurrent request will see the change.
+	fn slide(&self) -> Option<i64> {
+		let lifetime = self.lifetime.load(Ordering::Acquire);
+		let expiry = self.expiry.load(Ordering::Acquire);
+		let time = now();
+		// Sessions without a lifetime, or which have expired, are not extended
+		if lifetime == 0 || expiry == 0 || time > expiry {
+			return None;
+		}
+		// Check if the expiry would change
+		let next = time + lifetime;
+		if next <= expiry {
+			return None;
+		}
+		// Update the expiry, unless another request already has
+		self.expiry.compare_exchange(expiry, next, Ordering::AcqRel, Ordering::Acquire).ok()?;
+		Some(next)
+	}
+}
+
+/// The current unix timestamp in seconds
+fn now() -> i64 {
+	SystemTime::now().duration_since(UNIX_EPOCH).map(|d| d.as_secs() as i64).unwrap_or_default()
 }
 
 impl RpcContext for Connection {
@@ -395,11 +534,14 @@ impl RpcContext for Connection {
 		let Ok(Value::Object(v)) = params.needs_one() else {
 			return Err(RpcError::InvalidParams);
 		};
+		rate_limit::check_auth(self.client.as_ref())?;
+		let kvs = DB.get().unwrap();
 		let out: Result<Value, RpcError> =
-			surrealdb::iam::signup::signup(DB.get().unwrap(), &mut self.session, v)
-				.await
-				.map(Into::into)
-				.map_err(Into::into);
+			match surrealdb::iam::signup::signup(kvs, &mut self.session, v).await {
+				// Issue a refresh grant if the access method supports it
+				Ok(token) => crate::rpc::grant(kvs, &self.session, token).await,
+				Err(e) => Err(e.into()),
+			};
 
 		out
 	}
@@ -408,11 +550,14 @@ impl RpcContext for Connection {
 		let Ok(Value::Object(v)) = params.needs_one() else {
 			return Err(RpcError::InvalidParams);
 		};
+		rate_limit::check_auth(self.client.as_ref())?;
+		let kvs = DB.get().unwrap();
 		let out: Result<Value, RpcError> =
-			surrealdb::iam::signin::signin(DB.get().unwrap(), &mut self.session, v)
-				.await
-				.map(Into::into)
-				.map_err(Into::into);
+			match surrealdb::iam::signin::signin(kvs, &mut self.session, v).await {
+				// Issue a refresh grant if the access method supports it
+				Ok(token) => crate::rpc::grant(kvs, &self.session, token).await,
+				Err(e) => Err(e.into()),
+			};
 		out
 	}
 
@@ -424,3 +569,46 @@ impl RpcContext for Connection {
 		Ok(Value::None)
 	}
 }
+
+#[cfg(test)]
+mod tests {
+	use super::{now, Connection};
+	use opentelemetry::Context as TelemetryContext;
+	use std::sync::atomic::Ordering;
+	use surrealdb::dbs::Session;
+	use surrealdb::rpc::format::Format;
+	use uuid::Uuid;
+
+	#[tokio::test]
+	async fn sessions_authenticated_with_the_request_slide() {
+		// The session was authenticated with the request headers
+		let mut session = Session::owner();
+		session.exp = Some(now() + 60);
+		let rpc =
+			Connection::new(Uuid::new_v4(), session, None, Format::Json, TelemetryContext::new());
+		let rpc = rpc.read().await;
+		assert!(rpc.lifetime.load(Ordering::Acquire) >= 59);
+		// The expiry is extended by the lifetime of the session
+		rpc.expiry.store(now() + 10, Ordering::Release);
+		let next = rpc.slide().unwrap();
+		assert!(next >= now() + 59);
+		assert_eq!(rpc.expiry.load(Ordering::Acquire), next);
+		// Expired sessions are not extended
+		rpc.expiry.store(now() - 1, Ordering::Release);
+		assert!(rpc.slide().is_none());
+	}
+
+	#[tokio::test]
+	async fn sessions_without_expiry_do_not_slide() {
+		let rpc = Connection::new(
+			Uuid::new_v4(),
+			Session::owner(),
+			None,
+			Format::Json,
+			TelemetryContext::new(),
+		);
+		let rpc = rpc.read().await;
+		assert_eq!(rpc.lifetime.load(Ordering::Acquire), 0);
+		assert!(rpc.slide().is_none());
+	}
+}
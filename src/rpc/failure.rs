@@ -1,8 +1,9 @@
-use crate::err::Error;
+use crate::err::{Error, QueryError};
 use revision::revisioned;
 use revision::Revisioned;
 use serde::Serialize;
 use std::borrow::Cow;
+use surrealdb::error::Db as DbError;
 use surrealdb::rpc::RpcError;
 use surrealdb::sql::Value;
 
@@ -10,6 +11,9 @@ use surrealdb::sql::Value;
 pub struct Failure {
 	pub(crate) code: i64,
 	pub(crate) message: Cow<'static, str>,
+	/// Structured details about the error, like the position of a query parse error
+	#[serde(skip_serializing_if = "Option::is_none")]
+	pub(crate) data: Option<Value>,
 }
 
 #[revisioned(revision = 1)]
@@ -48,7 +52,12 @@ impl From<&str> for Failure {
 
 impl From<Error> for Failure {
 	fn from(err: Error) -> Self {
-		Failure::custom(err.to_string())
+		match &err {
+			Error::Db(surrealdb::Error::Db(DbError::InvalidQuery(e))) => {
+				Failure::query(err.to_string(), e.into())
+			}
+			_ => Failure::custom(err.to_string()),
+		}
 	}
 }
 
@@ -59,6 +68,9 @@ impl From<RpcError> for Failure {
 			RpcError::InvalidRequest => Failure::INVALID_REQUEST,
 			RpcError::MethodNotFound => Failure::METHOD_NOT_FOUND,
 			RpcError::InvalidParams => Failure::INVALID_PARAMS,
+			RpcError::InternalError(DbError::InvalidQuery(ref e)) => {
+				Failure::query(err.to_string(), e.into())
+			}
 			RpcError::InternalError(_) => Failure::custom(err.to_string()),
 			RpcError::Thrown(_) => Failure::custom(err.to_string()),
 			_ => Failure::custom(err.to_string()),
@@ -68,11 +80,14 @@ impl From<RpcError> for Failure {
 
 impl From<Failure> for Value {
 	fn from(err: Failure) -> Self {
-		map! {
+		let mut value = map! {
 			String::from("code") => Value::from(err.code),
 			String::from("message") => Value::from(err.message.to_string()),
+		};
+		if let Some(data) = err.data {
+			value.insert(String::from("data"), data);
 		}
-		.into()
+		value.into()
 	}
 }
 
@@ -81,26 +96,31 @@ impl Failure {
 	pub const PARSE_ERROR: Failure = Failure {
 		code: -32700,
 		message: Cow::Borrowed("Parse error"),
+		data: None,
 	};
 
 	pub const INVALID_REQUEST: Failure = Failure {
 		code: -32600,
 		message: Cow::Borrowed("Invalid Request"),
+		data: None,
 	};
 
 	pub const METHOD_NOT_FOUND: Failure = Failure {
 		code: -32601,
 		message: Cow::Borrowed("Method not found"),
+		data: None,
 	};
 
 	pub const INVALID_PARAMS: Failure = Failure {
 		code: -32602,
 		message: Cow::Borrowed("Invalid params"),
+		data: None,
 	};
 
 	pub const INTERNAL_ERROR: Failure = Failure {
 		code: -32603,
 		message: Cow::Borrowed("Internal error"),
+		data: None,
 	};
 
 	pub fn custom<S>(message: S) -> Failure
@@ -110,6 +130,19 @@ impl Failure {
 		Failure {
 			code: -32000,
 			message: message.into(),
+			data: None,
+		}
+	}
+
+	/// A query parse error, with the location of the error and the expected tokens
+	pub(crate) fn query<S>(message: S, details: QueryError) -> Failure
+	where
+		Cow<'static, str>: From<S>,
+	{
+		Failure {
+			code: -32000,
+			message: message.into(),
+			data: Some(details.into()),
 		}
 	}
 }
@@ -0,0 +1,100 @@
+use crate::cnf::WEBSOCKET_IDLE_TIMEOUT;
+use std::sync::RwLock;
+use surrealdb::dbs::Session;
+
+/// The rules which set the idle timeout of WebSocket connections
+static RULES: RwLock<Vec<IdleRule>> = RwLock::new(Vec::new());
+
+/// Replace the rules which set the idle timeout of WebSocket connections
+pub(crate) fn set_rules(rules: Vec<IdleRule>) {
+	*RULES.write().unwrap_or_else(|e| e.into_inner()) = rules;
+}
+
+/// Get the idle timeout in seconds for a WebSocket connection with the
+/// specified session, using the first rule which matches the session,
+/// or the default idle timeout if no rule matches. Returns 0 if the
+/// connection never times out.
+pub(crate) fn timeout(session: &Session) -> u64 {
+	let rules = RULES.read().unwrap_or_else(|e| e.into_inner());
+	match rules.iter().find(|r| r.matches(session)) {
+		Some(rule) => rule.timeout,
+		None => *WEBSOCKET_IDLE_TIMEOUT,
+	}
+}
+
+/// A rule which sets the idle timeout of WebSocket connections with
+/// a matching session. Every attribute which is specified needs to match.
+#[derive(Clone, Debug, Default, PartialEq)]
+pub(crate) struct IdleRule {
+	/// The auth level of the session, which is `root`, `namespace`, `database`, or `record`
+	pub level: Option<String>,
+	/// The namespace the session is authenticated on
+	pub ns: Option<String>,
+	/// The database the session is authenticated on
+	pub db: Option<String>,
+	/// The access method the session was authenticated with
+	pub access: Option<String>,
+	/// The idle timeout in seconds, or 0 to never time out
+	pub timeout: u64,
+}
+
+impl IdleRule {
+	/// Check if a session matches this rule
+	fn matches(&self, session: &Session) -> bool {
+		let level = session.au.level();
+		let attr = |rule: &Option<String>, val: Option<&str>| match rule {
+			Some(rule) => val.is_some_and(|v| v.eq_ignore_ascii_case(rule)),
+			None => true,
+		};
+		!session.au.is_anon()
+			&& attr(&self.level, Some(level.level_name()))
+			&& attr(&self.ns, level.ns())
+			&& attr(&self.db, level.db())
+			&& attr(&self.access, session.ac.as_deref())
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	use super::IdleRule;
+	use surrealdb::dbs::Session;
+	use surrealdb::iam::Role;
+	use surrealdb::sql::Thing;
+
+	#[test]
+	fn rules_match_sessions() {
+		let root = Session::owner();
+		let ns = Session::for_level(("app",).into(), Role::Viewer);
+		let db = Session::for_level(("app", "main").into(), Role::Viewer);
+		let record =
+			Session::for_record("app", "main", "users", Thing::from(("user", "one")).into());
+		// Rules can match an auth level
+		let rule = IdleRule {
+			level: Some("record".to_owned()),
+			..Default::default()
+		};
+		assert!(!rule.matches(&root));
+		assert!(!rule.matches(&db));
+		assert!(rule.matches(&record));
+		// Rules can match a namespace and database
+		let rule = IdleRule {
+			ns: Some("app".to_owned()),
+			db: Some("main".to_owned()),
+			..Default::default()
+		};
+		assert!(!rule.matches(&root));
+		assert!(!rule.matches(&ns));
+		assert!(rule.matches(&db));
+		assert!(rule.matches(&record));
+		// Rules can match an access method
+		let rule = IdleRule {
+			access: Some("users".to_owned()),
+			..Default::default()
+		};
+		assert!(!rule.matches(&db));
+		assert!(rule.matches(&record));
+		// Anonymous sessions never match a rule
+		assert!(!IdleRule::default().matches(&Session::default()));
+		assert!(IdleRule::default().matches(&root));
+	}
+}
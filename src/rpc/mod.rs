@@ -1,6 +1,7 @@
 pub mod connection;
 pub mod failure;
 pub mod format;
+pub(crate) mod idle;
 pub mod post_context;
 pub mod response;
 
@@ -13,6 +14,10 @@ use opentelemetry::Context as TelemetryContext;
 use std::collections::HashMap;
 use std::sync::Arc;
 use std::time::Duration;
+use surrealdb::dbs::Session;
+use surrealdb::kvs::Datastore;
+use surrealdb::rpc::RpcError;
+use surrealdb::sql::{Object, Value};
 use tokio::sync::RwLock;
 use tokio_util::sync::CancellationToken;
 use uuid::Uuid;
@@ -30,6 +35,25 @@ pub(crate) static WEBSOCKETS: Lazy<WebSockets> = Lazy::new(WebSockets::default);
 /// Stores the currently initiated LIVE queries
 pub(crate) static LIVE_QUERIES: Lazy<LiveQueries> = Lazy::new(LiveQueries::default);
 
+/// Issues a refresh grant to a record user which has just signed in or signed
+/// up. The response is the authentication token, or an object containing the
+/// token and the refresh grant if the access method issues refresh grants.
+pub(crate) async fn grant(
+	kvs: &Datastore,
+	session: &Session,
+	token: Option<String>,
+) -> Result<Value, RpcError> {
+	match surrealdb::iam::grant::issue(kvs, session).await? {
+		Some(refresh) => {
+			let mut out = Object::default();
+			out.insert("token".to_owned(), token.into());
+			out.insert("refresh".to_owned(), refresh.into());
+			Ok(out.into())
+		}
+		None => Ok(token.into()),
+	}
+}
+
 /// Performs notification delivery to the WebSockets
 pub(crate) async fn notifications(canceller: CancellationToken) {
 	// Listen to the notifications channel
@@ -1,6 +1,7 @@
 use std::collections::BTreeMap;
 
 use crate::cnf::{PKG_NAME, PKG_VERSION};
+use crate::net::rate_limit::{self, ClientKey};
 use surrealdb::dbs::Session;
 use surrealdb::kvs::Datastore;
 use surrealdb::rpc::args::Take;
@@ -14,6 +15,8 @@ pub struct PostRpcContext<'a> {
 	pub kvs: &'a Datastore,
 	pub session: Session,
 	pub vars: BTreeMap<String, Value>,
+	/// The client which authentication attempts are rate limited by
+	pub(crate) client: Option<ClientKey>,
 }
 
 impl<'a> PostRpcContext<'a> {
@@ -22,8 +25,15 @@ impl<'a> PostRpcContext<'a> {
 			kvs,
 			session,
 			vars,
+			client: None,
 		}
 	}
+
+	/// Set the client which authentication attempts are rate limited by
+	pub(crate) fn with_client(mut self, client: Option<ClientKey>) -> Self {
+		self.client = client;
+		self
+	}
 }
 
 impl RpcContext for PostRpcContext<'_> {
@@ -72,11 +82,14 @@ impl RpcContext for PostRpcContext<'_> {
 		let Ok(Value::Object(v)) = params.needs_one() else {
 			return Err(RpcError::InvalidParams);
 		};
+		rate_limit::check_auth(self.client.as_ref())?;
+		let kvs = self.kvs;
 		let out: Result<Value, RpcError> =
-			surrealdb::iam::signup::signup(self.kvs, &mut self.session, v)
-				.await
-				.map(Into::into)
-				.map_err(Into::into);
+			match surrealdb::iam::signup::signup(kvs, &mut self.session, v).await {
+				// Issue a refresh grant if the access method supports it
+				Ok(token) => crate::rpc::grant(kvs, &self.session, token).await,
+				Err(e) => Err(e.into()),
+			};
 
 		out
 	}
@@ -85,11 +98,14 @@ impl RpcContext for PostRpcContext<'_> {
 		let Ok(Value::Object(v)) = params.needs_one() else {
 			return Err(RpcError::InvalidParams);
 		};
+		rate_limit::check_auth(self.client.as_ref())?;
+		let kvs = self.kvs;
 		let out: Result<Value, RpcError> =
-			surrealdb::iam::signin::signin(self.kvs, &mut self.session, v)
-				.await
-				.map(Into::into)
-				.map_err(Into::into);
+			match surrealdb::iam::signin::signin(kvs, &mut self.session, v).await {
+				// Issue a refresh grant if the access method supports it
+				Ok(token) => crate::rpc::grant(kvs, &self.session, token).await,
+				Err(e) => Err(e.into()),
+			};
 		out
 	}
 
@@ -7,6 +7,7 @@ use revision::revisioned;
 use serde::Serialize;
 use std::sync::Arc;
 use surrealdb::channel::Sender;
+use surrealdb::kvs::MemoryReservation;
 use surrealdb::rpc::format::Format;
 use surrealdb::rpc::Data;
 use surrealdb::sql::Value;
@@ -37,7 +38,7 @@ impl Response {
 	}
 
 	/// Send the response to the WebSocket channel
-	pub async fn send(self, cx: Arc<TelemetryContext>, fmt: Format, chn: &Sender<Message>) {
+	pub async fn send(mut self, cx: Arc<TelemetryContext>, fmt: Format, chn: &Sender<Message>) {
 		// Create a new tracing span
 		let span = Span::current();
 		// Log the rpc response call
@@ -53,12 +54,24 @@ impl Response {
 			span.record("rpc.error_code", err.code);
 			span.record("rpc.error_message", err.message.as_ref());
 		}
+		// Hold the memory reserved for query results until they have been sent
+		let memory = self.take_memory();
 		// Process the response for the format
 		let (len, msg) = fmt.res_ws(self).unwrap();
 		// Send the message to the write channel
 		if chn.send(msg).await.is_ok() {
 			record_rpc(cx.as_ref(), len, is_error);
 		};
+		// Release the memory reserved for query results
+		drop(memory);
+	}
+
+	/// Detach the memory reserved for any query results
+	fn take_memory(&mut self) -> Vec<MemoryReservation> {
+		match &mut self.result {
+			Ok(Data::Query(v)) => v.iter_mut().filter_map(|v| v.take_memory()).collect(),
+			_ => vec![],
+		}
 	}
 }
 
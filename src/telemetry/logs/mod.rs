@@ -1,18 +1,59 @@
+use clap::ValueEnum;
+use std::sync::OnceLock;
 use tracing::Subscriber;
 use tracing_subscriber::fmt::format::FmtSpan;
-use tracing_subscriber::Layer;
+use tracing_subscriber::{reload, EnvFilter, Layer};
 
 use crate::cli::validator::parser::env_filter::CustomEnvFilter;
 
-pub fn new<S>(filter: CustomEnvFilter) -> Box<dyn Layer<S> + Send + Sync>
+/// The format in which logs are written
+#[derive(ValueEnum, Clone, Copy, Debug, Default, Eq, PartialEq)]
+pub enum LogFormat {
+	/// Human readable logs
+	#[default]
+	Text,
+	/// One JSON object per line, including the fields of all parent spans
+	Json,
+}
+
+type Reloader = Box<dyn Fn(EnvFilter) -> Result<(), reload::Error> + Send + Sync>;
+
+/// Replaces the filter of the installed log layer
+static RELOADER: OnceLock<Reloader> = OnceLock::new();
+
+/// Change the filter of the installed log layer, while the server is running
+pub fn reload(filter: EnvFilter) -> Result<(), reload::Error> {
+	match RELOADER.get() {
+		Some(reload) => reload(filter),
+		None => Ok(()),
+	}
+}
+
+// Returns a log layer which writes logs in the given format
+pub fn new<S>(filter: CustomEnvFilter, format: LogFormat) -> Box<dyn Layer<S> + Send + Sync>
 where
-	S: Subscriber + for<'a> tracing_subscriber::registry::LookupSpan<'a> + Send + Sync,
+	S: Subscriber + for<'a> tracing_subscriber::registry::LookupSpan<'a> + Send + Sync + 'static,
 {
-	tracing_subscriber::fmt::layer()
-		.compact()
-		.with_ansi(true)
-		.with_span_events(FmtSpan::NONE)
-		.with_writer(std::io::stderr)
-		.with_filter(filter.0)
-		.boxed()
+	// Allow the filter to be changed after the layer is installed
+	let (filter, handle) = reload::Layer::new(filter.0);
+	let _ = RELOADER.set(Box::new(move |v| handle.reload(v)));
+	match format {
+		// Output structured logs, including the fields of all parent spans
+		LogFormat::Json => tracing_subscriber::fmt::layer()
+			.json()
+			.with_current_span(true)
+			.with_span_list(true)
+			.with_span_events(FmtSpan::NONE)
+			.with_writer(std::io::stderr)
+			.with_filter(filter)
+			.boxed(),
+		// Output human readable logs
+		LogFormat::Text => tracing_subscriber::fmt::layer()
+			.compact()
+			.with_ansi(true)
+			.with_span_events(FmtSpan::NONE)
+			.with_writer(std::io::stderr)
+			.with_filter(filter)
+			.boxed(),
+	}
 }
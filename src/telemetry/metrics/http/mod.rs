@@ -1,8 +1,8 @@
 pub(super) mod tower_layer;
 
 use once_cell::sync::Lazy;
-use opentelemetry::metrics::{Histogram, MetricsError, Unit, UpDownCounter};
-use opentelemetry::Context as TelemetryContext;
+use opentelemetry::metrics::{Counter, Histogram, MetricsError, Unit, UpDownCounter};
+use opentelemetry::{Context as TelemetryContext, KeyValue};
 
 use self::tower_layer::HttpCallMetricTracker;
 
@@ -39,6 +39,22 @@ pub static HTTP_SERVER_RESPONSE_SIZE: Lazy<Histogram<u64>> = Lazy::new(|| {
 		.init()
 });
 
+pub static HTTP_SERVER_RATE_LIMITED: Lazy<Counter<u64>> = Lazy::new(|| {
+	METER_DURATION
+		.u64_counter("http.server.rate_limited")
+		.with_description("The number of HTTP requests rejected by the rate limiter.")
+		.init()
+});
+
+/// Record a request which was rejected by the rate limiter
+pub fn record_rate_limited(class: &'static str) {
+	HTTP_SERVER_RATE_LIMITED.add(
+		&TelemetryContext::current(),
+		1,
+		&[KeyValue::new("rate_limit.class", class)],
+	);
+}
+
 fn observe_active_request(value: i64, tracker: &HttpCallMetricTracker) -> Result<(), MetricsError> {
 	let attrs = tracker.active_req_attrs();
 
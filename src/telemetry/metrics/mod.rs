@@ -1,4 +1,5 @@
 pub mod http;
+pub mod usage;
 pub mod ws;
 
 use std::time::Duration;
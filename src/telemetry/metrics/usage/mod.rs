@@ -0,0 +1,85 @@
+use std::collections::BTreeMap;
+use std::time::Duration;
+
+use once_cell::sync::Lazy;
+use opentelemetry::metrics::{Counter, Unit};
+use opentelemetry::{Context as TelemetryContext, KeyValue};
+use surrealdb::kvs::Usage;
+use tokio_util::sync::CancellationToken;
+
+use super::METER_DURATION;
+use crate::dbs::DB;
+
+/// How often the namespace usage is exported
+const USAGE_EXPORT_INTERVAL: Duration = Duration::from_secs(5);
+
+pub static NAMESPACE_ROWS_READ: Lazy<Counter<u64>> = Lazy::new(|| {
+	METER_DURATION
+		.u64_counter("surrealdb.namespace.rows_read")
+		.with_description("The number of records read from a namespace.")
+		.init()
+});
+
+pub static NAMESPACE_ROWS_WRITTEN: Lazy<Counter<u64>> = Lazy::new(|| {
+	METER_DURATION
+		.u64_counter("surrealdb.namespace.rows_written")
+		.with_description("The number of records written to a namespace.")
+		.init()
+});
+
+pub static NAMESPACE_BYTES_WRITTEN: Lazy<Counter<u64>> = Lazy::new(|| {
+	METER_DURATION
+		.u64_counter("surrealdb.namespace.bytes_written")
+		.with_description("The number of bytes written to a namespace.")
+		.with_unit(Unit::new("By"))
+		.init()
+});
+
+pub static NAMESPACE_QUERY_TIME: Lazy<Counter<u64>> = Lazy::new(|| {
+	METER_DURATION
+		.u64_counter("surrealdb.namespace.query_time")
+		.with_description("The time spent processing queries in a namespace in milliseconds.")
+		.with_unit(Unit::new("ms"))
+		.init()
+});
+
+/// Records the resources consumed by each namespace since the previous snapshot
+fn record_usage(prev: &BTreeMap<String, Usage>, next: &BTreeMap<String, Usage>) {
+	let cx = TelemetryContext::current();
+	for (ns, usage) in next.iter() {
+		let last = prev.get(ns).copied().unwrap_or_default();
+		let attrs = [KeyValue::new("ns", ns.clone())];
+		NAMESPACE_ROWS_READ.add(&cx, usage.rows_read - last.rows_read, &attrs);
+		NAMESPACE_ROWS_WRITTEN.add(&cx, usage.rows_written - last.rows_written, &attrs);
+		NAMESPACE_BYTES_WRITTEN.add(&cx, usage.bytes_written - last.bytes_written, &attrs);
+		NAMESPACE_QUERY_TIME.add(
+			&cx,
+			(usage.query_time - last.query_time).as_millis() as u64,
+			&attrs,
+		);
+	}
+}
+
+/// Periodically exports the resources consumed by each namespace
+pub async fn export(canceller: CancellationToken) {
+	// The previously exported usage
+	let mut prev = BTreeMap::new();
+	// Export the usage at a regular interval
+	let mut interval = tokio::time::interval(USAGE_EXPORT_INTERVAL);
+	loop {
+		tokio::select! {
+			//
+			biased;
+			// Check if this has shutdown
+			_ = canceller.cancelled() => break,
+			// Export the latest usage snapshot
+			_ = interval.tick() => {
+				if let Some(db) = DB.get() {
+					let next = db.usage();
+					record_usage(&prev, &next);
+					prev = next;
+				}
+			},
+		}
+	}
+}
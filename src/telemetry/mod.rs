@@ -2,6 +2,8 @@ mod logs;
 pub mod metrics;
 pub mod traces;
 
+pub use logs::LogFormat;
+
 use std::time::Duration;
 
 use crate::cli::validator::parser::env_filter::CustomEnvFilter;
@@ -41,6 +43,7 @@ pub static OTEL_DEFAULT_RESOURCE: Lazy<Resource> = Lazy::new(|| {
 #[derive(Debug, Clone)]
 pub struct Builder {
 	filter: CustomEnvFilter,
+	format: LogFormat,
 }
 
 pub fn builder() -> Builder {
@@ -51,6 +54,7 @@ impl Default for Builder {
 	fn default() -> Self {
 		Self {
 			filter: CustomEnvFilter(EnvFilter::default()),
+			format: LogFormat::default(),
 		}
 	}
 }
@@ -70,12 +74,18 @@ impl Builder {
 		self
 	}
 
+	/// Set the log format on the builder
+	pub fn with_log_format(mut self, format: LogFormat) -> Self {
+		self.format = format;
+		self
+	}
+
 	/// Build a tracing dispatcher with the fmt subscriber (logs) and the chosen tracer subscriber
 	pub fn build(self) -> Box<dyn Subscriber + Send + Sync + 'static> {
 		let registry = tracing_subscriber::registry();
 
 		// Setup logging layer
-		let registry = registry.with(logs::new(self.filter.clone()));
+		let registry = registry.with(logs::new(self.filter.clone(), self.format));
 
 		// Setup tracing layer
 		let registry = registry.with(traces::new(self.filter));
@@ -89,6 +99,15 @@ impl Builder {
 	}
 }
 
+/// Change the log level of the installed log layer
+pub fn reload_log_level(log_level: &str) -> Result<(), tracing_subscriber::filter::ParseError> {
+	let filter = filter_from_value(log_level)?;
+	if let Err(err) = logs::reload(filter) {
+		warn!("Failed to change the log level: {}", err);
+	}
+	Ok(())
+}
+
 pub fn shutdown() -> Result<(), MetricsError> {
 	// Flush all telemetry data
 	opentelemetry::global::shutdown_tracer_provider();
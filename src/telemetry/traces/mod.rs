@@ -1,3 +1,6 @@
+use http::HeaderMap;
+use opentelemetry::propagation::{Extractor, TextMapPropagator};
+use opentelemetry::sdk::propagation::TraceContextPropagator;
 use tracing::Subscriber;
 use tracing_subscriber::Layer;
 
@@ -31,9 +34,31 @@ where
 	}
 }
 
+/// Reads W3C trace context propagation headers from an HTTP header map
+struct HeaderExtractor<'a>(&'a HeaderMap);
+
+impl<'a> Extractor for HeaderExtractor<'a> {
+	fn get(&self, key: &str) -> Option<&str> {
+		self.0.get(key).and_then(|v| v.to_str().ok())
+	}
+
+	fn keys(&self) -> Vec<&str> {
+		self.0.keys().map(|k| k.as_str()).collect()
+	}
+}
+
+/// Extracts the remote parent context from any incoming `traceparent` and
+/// `tracestate` headers, so that server side spans join the caller's trace
+pub fn extract_parent(headers: &HeaderMap) -> opentelemetry::Context {
+	TraceContextPropagator::new().extract(&HeaderExtractor(headers))
+}
+
 #[cfg(test)]
 pub mod tests {
+	use super::extract_parent;
 	use futures::StreamExt;
+	use http::{HeaderMap, HeaderValue};
+	use opentelemetry::trace::{SpanId, TraceContextExt, TraceId};
 	use opentelemetry_proto::tonic::collector::trace::v1::{
 		trace_service_server::{TraceService, TraceServiceServer},
 		ExportTraceServiceRequest, ExportTraceServiceResponse,
@@ -90,4 +115,53 @@ pub mod tests {
 		});
 		(addr, req_rx)
 	}
+
+	fn headers(traceparent: Option<&'static str>) -> HeaderMap {
+		let mut headers = HeaderMap::new();
+		if let Some(v) = traceparent {
+			headers.insert("traceparent", HeaderValue::from_static(v));
+			headers.insert("tracestate", HeaderValue::from_static("vendor=value"));
+		}
+		headers
+	}
+
+	#[test]
+	fn extract_parent_from_valid_headers() {
+		let cx = extract_parent(&headers(Some(
+			"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		)));
+		let span = cx.span();
+		let sc = span.span_context();
+		assert!(sc.is_valid());
+		assert!(sc.is_remote());
+		assert!(sc.is_sampled());
+		assert_eq!(sc.trace_id(), TraceId::from_hex("4bf92f3577b34da6a3ce929d0e0e4736").unwrap());
+		assert_eq!(sc.span_id(), SpanId::from_hex("00f067aa0ba902b7").unwrap());
+		assert_eq!(sc.trace_state().get("vendor"), Some("value"));
+	}
+
+	#[test]
+	fn extract_parent_from_missing_headers() {
+		let cx = extract_parent(&headers(None));
+		assert!(!cx.span().span_context().is_valid());
+	}
+
+	#[test]
+	fn extract_parent_from_malformed_headers() {
+		for traceparent in [
+			"",
+			"not a traceparent",
+			// The trace id must not be all zeroes
+			"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+			// The span id must not be all zeroes
+			"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+			// The trace id must be 32 hexadecimal characters
+			"00-4bf92f3577b34da6a3ce929d0e0e47-00f067aa0ba902b7-01",
+			// The version must be hexadecimal
+			"zz-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		] {
+			let cx = extract_parent(&headers(Some(traceparent)));
+			assert!(!cx.span().span_context().is_valid(), "{traceparent} was accepted");
+		}
+	}
 }
@@ -1,7 +1,8 @@
 use tracing::{field, Span};
+use tracing_opentelemetry::OpenTelemetrySpanExt;
 use uuid::Uuid;
 
-pub fn span_for_request(ws_id: &Uuid) -> Span {
+pub fn span_for_request(ws_id: &Uuid, parent: &opentelemetry::Context) -> Span {
 	let span = tracing::debug_span!(
 		// Dynamic span names need to be 'recorded', can't be used on the macro. Use a static name here and overwrite later on
 		"rpc/call",
@@ -21,5 +22,7 @@ pub fn span_for_request(ws_id: &Uuid) -> Span {
 		rpc.error_message = field::Empty,
 	);
 
+	span.set_parent(parent.clone());
+
 	span
 }
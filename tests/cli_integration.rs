@@ -62,6 +62,15 @@ mod cli_integration {
 		assert!(common::run("version --turbo").output().is_err());
 	}
 
+	#[test]
+	fn bench_command() {
+		let args = "bench --conn memory --ns test --db test --workload mixed --operations 100 --records 100 --clients 2";
+		let output = common::run(args).output().expect("failed to run bench: {args}");
+		assert!(output.contains("operations: 100"), "missing operations in {output}");
+		assert!(output.contains("throughput"), "missing throughput in {output}");
+		assert!(output.contains("p99"), "missing latency percentiles in {output}");
+	}
+
 	fn debug_builds_contain_debug_message(addr: &str, creds: &str, ns: &Ulid, db: &Ulid) {
 		info!("* Debug builds contain debug message");
 		let args =
@@ -135,6 +144,64 @@ mod cli_integration {
 			assert_eq!(rest, "[\n\t{\n\t\tid: thing:one\n\t}\n]\n\n", "failed to send sql: {args}");
 		}
 
+		info!("* Export a table as JSONL and import it from stdin");
+		{
+			let args = format!(
+				"export --conn http://{addr} {creds} --ns {ns} --db {db} --format jsonl --table thing -"
+			);
+			let output = common::run(&args).output().expect("failed to run jsonl export: {args}");
+			assert_eq!(output, "{\"id\":\"one\"}\n", "unexpected jsonl export: {args}");
+			// The records of several tables can not be told apart once exported
+			let args =
+				format!("export --conn http://{addr} {creds} --ns {ns} --db {db} --format jsonl -");
+			assert!(common::run(&args).output().is_err(), "jsonl export without a table: {args}");
+			let db3 = Ulid::new();
+			let args = format!(
+				"import --conn http://{addr} {creds} --ns {ns} --db {db3} --format jsonl --table thing -"
+			);
+			common::run(&args).input(&output).output().expect("failed to run jsonl import: {args}");
+			let args =
+				format!("sql --conn http://{addr} {creds} --ns {ns} --db {db3} --hide-welcome");
+			let output = common::run(&args).input("SELECT * FROM thing;\n").output().unwrap();
+			assert!(
+				output.contains("[[{ id: thing:one }]]"),
+				"missing imported record in {output}"
+			);
+		}
+
+		info!("* Export a table as SQL and import it from stdin");
+		{
+			let args =
+				format!("export --conn http://{addr} {creds} --ns {ns} --db {db} --table thing -");
+			let output = common::run(&args).output().expect("failed to run table export: {args}");
+			assert!(output.starts_with("-- ------------------------------\n-- OPTION"));
+			assert!(output.contains("OPTION IMPORT;"), "missing import option in {output}");
+			assert!(output.contains("INSERT [ { id: thing:one } ];"));
+			let db4 = Ulid::new();
+			let args = format!("import --conn http://{addr} {creds} --ns {ns} --db {db4} -");
+			let input = format!("LET $name = 'two';\n{output}\nCREATE thing:two SET name = $name;");
+			common::run(&args).input(&input).output().expect("failed to run sql import: {args}");
+			let args =
+				format!("sql --conn http://{addr} {creds} --ns {ns} --db {db4} --hide-welcome");
+			let output = common::run(&args).input("SELECT * FROM thing;\n").output().unwrap();
+			assert!(
+				output.contains("[[{ id: thing:one }, { id: thing:two, name: 'two' }]]"),
+				"missing imported records in {output}"
+			);
+		}
+
+		info!("* Query from the import as a table, with timing");
+		{
+			let args = format!(
+				"sql --conn http://{addr} {creds} --ns {ns} --db {db2} --table --hide-welcome"
+			);
+			let output =
+				common::run(&args).input("\\timing\nSELECT * FROM thing;\n").output().unwrap();
+			assert!(output.contains("| id        |"), "missing table header in {output}");
+			assert!(output.contains("| thing:one |"), "missing table row in {output}");
+			assert!(output.contains("-- Time:"), "missing timing in {output}");
+		}
+
 		info!("* Advanced uncomputed variable to be computed before saving");
 		{
 			let args = format!(
@@ -713,6 +780,15 @@ mod cli_integration {
 		assert!(common::run_in_dir("validate", &temp_dir).output().is_err());
 	}
 
+	#[test]
+	fn start_fails_with_invalid_log_format() {
+		let vars = [("SURREAL_LOG_FORMAT".to_string(), "xml".to_string())].into();
+		let output = common::run_internal::<String>("start", None, Some(vars)).output();
+		assert!(output.unwrap_err().contains("invalid value 'xml'"));
+		let output = common::run("start --log-format xml").output();
+		assert!(output.unwrap_err().contains("invalid value 'xml'"));
+	}
+
 	#[test(tokio::test)]
 	async fn test_server_graceful_shutdown() {
 		let (_, mut server) = common::start_server_with_defaults().await.unwrap();
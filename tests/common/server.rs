@@ -1,4 +1,5 @@
 use rand::{thread_rng, Rng};
+use std::collections::HashMap;
 use std::error::Error;
 use std::fs::File;
 use std::path::{Path, PathBuf};
@@ -95,7 +96,11 @@ impl Drop for Child {
 	}
 }
 
-pub fn run_internal<P: AsRef<Path>>(args: &str, current_dir: Option<P>) -> Child {
+pub fn run_internal<P: AsRef<Path>>(
+	args: &str,
+	current_dir: Option<P>,
+	vars: Option<HashMap<String, String>>,
+) -> Child {
 	let mut path = std::env::current_exe().unwrap();
 	assert!(path.pop());
 	if path.ends_with("deps") {
@@ -118,6 +123,9 @@ pub fn run_internal<P: AsRef<Path>>(args: &str, current_dir: Option<P>) -> Child
 	let stderr = Stdio::from(File::create(&stderr_path).unwrap());
 
 	cmd.env_clear();
+	if let Some(vars) = vars {
+		cmd.envs(vars);
+	}
 	cmd.stdin(Stdio::piped());
 	cmd.stdout(stdout);
 	cmd.stderr(stderr);
@@ -132,12 +140,12 @@ pub fn run_internal<P: AsRef<Path>>(args: &str, current_dir: Option<P>) -> Child
 
 /// Run the CLI with the given args
 pub fn run(args: &str) -> Child {
-	run_internal::<String>(args, None)
+	run_internal::<String>(args, None, None)
 }
 
 /// Run the CLI with the given args inside a temporary directory
 pub fn run_in_dir<P: AsRef<Path>>(args: &str, current_dir: P) -> Child {
-	run_internal(args, Some(current_dir))
+	run_internal(args, Some(current_dir), None)
 }
 
 pub fn tmp_file(name: &str) -> String {
@@ -153,6 +161,7 @@ pub struct StartServerArguments {
 	pub tick_interval: time::Duration,
 	pub temporary_directory: Option<String>,
 	pub args: String,
+	pub vars: Option<HashMap<String, String>>,
 }
 
 impl Default for StartServerArguments {
@@ -165,6 +174,7 @@ impl Default for StartServerArguments {
 			tick_interval: time::Duration::new(1, 0),
 			temporary_directory: None,
 			args: "--allow-all".to_string(),
+			vars: None,
 		}
 	}
 }
@@ -200,6 +210,7 @@ pub async fn start_server(
 		tick_interval,
 		temporary_directory,
 		args,
+		vars,
 	}: StartServerArguments,
 ) -> Result<(String, Child), Box<dyn Error>> {
 	let mut rng = thread_rng();
@@ -241,7 +252,7 @@ pub async fn start_server(
 		info!("starting server with args: {start_args}");
 
 		// Configure where the logs go when running the test
-		let server = run_internal::<String>(&start_args, None);
+		let server = run_internal::<String>(&start_args, None, vars.clone());
 
 		if !wait_is_ready {
 			return Ok((addr, server));
@@ -589,6 +589,30 @@ async fn query() -> Result<(), Box<dyn std::error::Error>> {
 	Ok(())
 }
 
+#[test(tokio::test)]
+async fn query_parse_error() -> Result<(), Box<dyn std::error::Error>> {
+	// Setup database server
+	let (addr, mut server) = common::start_server_with_defaults().await.unwrap();
+	// Connect to WebSocket
+	let mut socket = Socket::connect(&addr, SERVER, FORMAT).await?;
+	// Authenticate the connection
+	socket.send_message_signin(USER, PASS, None, None, None).await?;
+	// Specify a namespace and database
+	socket.send_message_use(Some(NS), Some(DB)).await?;
+	// Send an invalid QUERY command
+	let res = socket.send_request("query", json!(["SELECT * FROM tester WITH foo;",])).await?;
+	assert!(res["error"].is_object(), "result: {:?}", res);
+	// Verify the error details are structured
+	let data = &res["error"]["data"];
+	assert_eq!(data["line"], json!(1), "result: {:?}", res);
+	assert_eq!(data["column"], json!(27), "result: {:?}", res);
+	assert_eq!(data["found"], json!("an identifier"), "result: {:?}", res);
+	assert_eq!(data["expected"], json!(["NO", "NOINDEX", "INDEX"]), "result: {:?}", res);
+	// Test passed
+	server.finish().unwrap();
+	Ok(())
+}
+
 #[test(tokio::test)]
 async fn version() -> Result<(), Box<dyn std::error::Error>> {
 	// Setup database server
@@ -2,6 +2,7 @@
 mod common;
 
 mod http_integration {
+	use std::collections::HashMap;
 	use std::time::Duration;
 
 	use http::{header, Method};
@@ -298,6 +299,205 @@ mod http_integration {
 		Ok(())
 	}
 
+	/// Read server-sent events until one contains the given text
+	async fn read_events(
+		res: &mut reqwest::Response,
+		until: &str,
+	) -> Result<Vec<(String, String)>, Box<dyn std::error::Error>> {
+		let mut buf = String::new();
+		let mut events = Vec::new();
+		loop {
+			let Some(chunk) = tokio::time::timeout(Duration::from_secs(10), res.chunk()).await??
+			else {
+				return Err("the event stream ended".into());
+			};
+			buf.push_str(std::str::from_utf8(&chunk)?);
+			while let Some(end) = buf.find("\n\n") {
+				let event: String = buf.drain(..end + 2).collect();
+				let mut id = String::new();
+				let mut data = String::new();
+				for line in event.lines() {
+					if let Some(v) = line.strip_prefix("id:") {
+						id = v.trim().to_owned();
+					}
+					if let Some(v) = line.strip_prefix("data:") {
+						data.push_str(v.trim());
+					}
+				}
+				// Skip keep-alive comments
+				if data.is_empty() {
+					continue;
+				}
+				let done = data.contains(until);
+				events.push((id, data));
+				if done {
+					return Ok(events);
+				}
+			}
+		}
+	}
+
+	#[test(tokio::test)]
+	async fn changes_endpoint() -> Result<(), Box<dyn std::error::Error>> {
+		let (addr, _server) = common::start_server_with_defaults().await.unwrap();
+		let url = &format!("http://{addr}/changes/foo");
+
+		// Prepare HTTP client
+		let mut headers = reqwest::header::HeaderMap::new();
+		headers.insert("surreal-ns", Ulid::new().to_string().parse()?);
+		headers.insert("surreal-db", Ulid::new().to_string().parse()?);
+		headers.insert(header::ACCEPT, "application/json".parse()?);
+		let client = reqwest::Client::builder()
+			.connect_timeout(Duration::from_millis(10))
+			.default_headers(headers)
+			.build()?;
+
+		// Create a table with a change feed
+		{
+			let res = client
+				.post(format!("http://{addr}/sql"))
+				.basic_auth(USER, Some(PASS))
+				.body("DEFINE TABLE foo CHANGEFEED 1h; CREATE foo:one;")
+				.send()
+				.await?;
+			assert_eq!(res.status(), 200, "body: {}", res.text().await?);
+		}
+
+		// Change sets are streamed as events, identified by their versionstamp
+		let last = {
+			let mut res = client.get(url).basic_auth(USER, Some(PASS)).send().await?;
+			assert_eq!(res.status(), 200);
+			assert_eq!(res.headers()[header::CONTENT_TYPE], "text/event-stream");
+			let events = read_events(&mut res, "foo:one").await?;
+			let (id, data) = events.last().unwrap();
+			let data: serde_json::Value = serde_json::from_str(data)?;
+			assert_eq!(data["versionstamp"].to_string(), *id, "data: {}", data);
+			id.parse::<u64>()?
+		};
+
+		// Clients resume after the last event they received
+		{
+			let res = client
+				.post(format!("http://{addr}/sql"))
+				.basic_auth(USER, Some(PASS))
+				.body("CREATE foo:two;")
+				.send()
+				.await?;
+			assert_eq!(res.status(), 200, "body: {}", res.text().await?);
+
+			let mut res = client
+				.get(url)
+				.basic_auth(USER, Some(PASS))
+				.header("last-event-id", last.to_string())
+				.send()
+				.await?;
+			assert_eq!(res.status(), 200);
+			let events = read_events(&mut res, "foo:two").await?;
+			for (id, data) in events {
+				assert!(id.parse::<u64>()? > last, "id: {id}");
+				assert!(!data.contains("foo:one"), "data: {data}");
+			}
+		}
+
+		// Only the record changes which match the condition are streamed
+		{
+			let res = client
+				.post(format!("http://{addr}/sql"))
+				.basic_auth(USER, Some(PASS))
+				.body("CREATE foo:three SET keep = false; CREATE foo:four SET keep = true;")
+				.send()
+				.await?;
+			assert_eq!(res.status(), 200, "body: {}", res.text().await?);
+
+			let mut res = client
+				.get(url)
+				.basic_auth(USER, Some(PASS))
+				.query(&[("cond", "keep = true")])
+				.send()
+				.await?;
+			assert_eq!(res.status(), 200);
+			let events = read_events(&mut res, "foo:four").await?;
+			for (_, data) in events {
+				assert!(!data.contains("foo:one"), "data: {data}");
+				assert!(!data.contains("foo:three"), "data: {data}");
+			}
+		}
+
+		// An invalid condition is rejected
+		{
+			let res = client
+				.get(url)
+				.basic_auth(USER, Some(PASS))
+				.query(&[("cond", "keep = ")])
+				.send()
+				.await?;
+			assert_eq!(res.status(), 400, "body: {}", res.text().await?);
+		}
+
+		// An invalid event id is rejected
+		{
+			let res = client
+				.get(url)
+				.basic_auth(USER, Some(PASS))
+				.header("last-event-id", "invalid")
+				.send()
+				.await?;
+			assert_eq!(res.status(), 400, "body: {}", res.text().await?);
+		}
+
+		Ok(())
+	}
+
+	#[test(tokio::test)]
+	async fn diagnostics_endpoint() -> Result<(), Box<dyn std::error::Error>> {
+		// The endpoint is disabled by default
+		{
+			let (addr, _server) = common::start_server_with_defaults().await.unwrap();
+			let url = &format!("http://{addr}/diagnostics");
+			let res = Client::default().get(url).basic_auth(USER, Some(PASS)).send().await?;
+			assert_eq!(res.status(), 404, "body: {}", res.text().await?);
+		}
+
+		let (addr, _server) = common::start_server(StartServerArguments {
+			vars: Some(HashMap::from([(
+				"SURREAL_DIAGNOSTICS_ENABLED".to_string(),
+				"true".to_string(),
+			)])),
+			..Default::default()
+		})
+		.await
+		.unwrap();
+		let url = &format!("http://{addr}/diagnostics");
+
+		// When no auth is provided, the endpoint returns a 403
+		{
+			let res = Client::default().get(url).send().await?;
+			assert_eq!(res.status(), 403, "body: {}", res.text().await?);
+		}
+
+		// Connect an authenticated WebSocket session
+		let mut socket = common::Socket::connect(&addr, None, common::Format::Json).await?;
+		socket.send_message_signin(USER, PASS, None, None, None).await?;
+
+		// When root auth is provided, it returns the runtime state
+		{
+			let res = Client::default().get(url).basic_auth(USER, Some(PASS)).send().await?;
+			assert_eq!(res.status(), 200, "body: {}", res.text().await?);
+			let body: serde_json::Value = serde_json::from_str(&res.text().await?)?;
+			assert!(body["version"].is_string(), "body: {}", body);
+			assert_eq!(body["storage"], "memory", "body: {}", body);
+			assert_eq!(body["websockets"], 1, "body: {}", body);
+			assert!(body["queries"].is_array(), "body: {}", body);
+			let sessions = body["sessions"].as_array().unwrap();
+			assert_eq!(sessions.len(), 1, "body: {}", body);
+			assert_eq!(sessions[0]["actor"], USER, "body: {}", body);
+		}
+
+		socket.close().await?;
+
+		Ok(())
+	}
+
 	#[test(tokio::test)]
 	async fn export_endpoint() -> Result<(), Box<dyn std::error::Error>> {
 		let (addr, _server) = common::start_server_with_defaults().await.unwrap();
@@ -352,6 +552,20 @@ mod http_integration {
 		Ok(())
 	}
 
+	#[test(tokio::test)]
+	async fn ready_endpoint() -> Result<(), Box<dyn std::error::Error>> {
+		let (addr, _server) = common::start_server_with_defaults().await.unwrap();
+		let url = &format!("http://{addr}/ready");
+
+		let res = Client::default().get(url).send().await?;
+		assert_eq!(res.status(), 200, "response: {:#?}", res);
+		let body = res.text().await?;
+		let body: serde_json::Value = serde_json::from_str(&body).unwrap();
+		assert_eq!(body["status"], "ready", "body: {}", body);
+
+		Ok(())
+	}
+
 	#[test(tokio::test)]
 	async fn no_server_id_headers() -> Result<(), Box<dyn std::error::Error>> {
 		// default server has the id headers
@@ -708,6 +922,100 @@ mod http_integration {
 		Ok(())
 	}
 
+	#[test(tokio::test)]
+	async fn renew_endpoint() -> Result<(), Box<dyn std::error::Error>> {
+		let (addr, _server) = common::start_server_with_defaults().await.unwrap();
+		let url = &format!("http://{addr}/renew");
+
+		let ns = Ulid::new().to_string();
+		let db = Ulid::new().to_string();
+
+		// Prepare HTTP client
+		let mut headers = reqwest::header::HeaderMap::new();
+		headers.insert("surreal-ns", ns.parse()?);
+		headers.insert("surreal-db", db.parse()?);
+		headers.insert(header::ACCEPT, "application/json".parse()?);
+		let client = reqwest::Client::builder()
+			.connect_timeout(Duration::from_millis(10))
+			.default_headers(headers)
+			.build()?;
+
+		// Define a record access method which issues refresh grants
+		{
+			let res = client
+				.post(format!("http://{addr}/sql"))
+				.basic_auth(USER, Some(PASS))
+				.body(
+					r#"
+					DEFINE ACCESS user ON DB TYPE RECORD
+					SIGNIN (
+						SELECT * FROM user WHERE name = $user AND crypto::argon2::compare(pass, $pass)
+					)
+					DURATION FOR GRANT 1d, FOR TOKEN 5m;
+					CREATE user:1 SET name = 'user', pass = crypto::argon2::generate('pass');
+					"#,
+				)
+				.send()
+				.await?;
+			assert!(res.status().is_success(), "body: {}", res.text().await?);
+		}
+
+		// Signin and get the token and refresh grant
+		let refresh = {
+			let req_body = serde_json::to_string(
+				json!({
+					"ns": ns,
+					"db": db,
+					"ac": "user",
+					"user": "user",
+					"pass": "pass",
+				})
+				.as_object()
+				.unwrap(),
+			)
+			.unwrap();
+
+			let res = client.post(format!("http://{addr}/signin")).body(req_body).send().await?;
+			assert_eq!(res.status(), 200, "body: {}", res.text().await?);
+
+			let body: serde_json::Value = serde_json::from_str(&res.text().await?).unwrap();
+			assert!(!body["token"].as_str().unwrap().is_empty(), "body: {}", body);
+			body["refresh"].as_str().unwrap().to_owned()
+		};
+
+		let renew = |refresh: &str| {
+			serde_json::to_string(
+				json!({
+					"ns": ns,
+					"db": db,
+					"ac": "user",
+					"refresh": refresh,
+				})
+				.as_object()
+				.unwrap(),
+			)
+			.unwrap()
+		};
+
+		// Exchange the refresh grant for a new token and refresh grant
+		{
+			let res = client.post(url).body(renew(&refresh)).send().await?;
+			assert_eq!(res.status(), 200, "body: {}", res.text().await?);
+
+			let body: serde_json::Value = serde_json::from_str(&res.text().await?).unwrap();
+			assert!(!body["token"].as_str().unwrap().is_empty(), "body: {}", body);
+			assert_ne!(body["refresh"].as_str().unwrap(), refresh, "body: {}", body);
+		}
+
+		// The refresh grant can not be used a second time
+		{
+			let res = client.post(url).body(renew(&refresh)).send().await?;
+			assert_eq!(res.status(), 401, "body: {}", res.text().await?);
+		}
+
+		Ok(())
+	}
+
 	#[test(tokio::test)]
 	async fn signup_endpoint() -> Result<(), Box<dyn std::error::Error>> {
 		let (addr, _server) = common::start_server_with_defaults().await.unwrap();
@@ -815,6 +1123,27 @@ mod http_integration {
 			assert_eq!(body[0]["status"], "OK", "body: {}", body);
 		}
 
+		// A query which can't be parsed returns the location of the error
+		{
+			let res = client
+				.post(url)
+				.basic_auth(USER, Some(PASS))
+				.body("SELECT * FROM foo WITH bar")
+				.send()
+				.await?;
+			assert_eq!(res.status(), 400);
+
+			let body: serde_json::Value = serde_json::from_str(&res.text().await?).unwrap();
+			assert_eq!(body["query"]["line"], 1, "body: {}", body);
+			assert_eq!(body["query"]["column"], 24, "body: {}", body);
+			assert_eq!(
+				body["query"]["expected"],
+				json!(["NO", "NOINDEX", "INDEX"]),
+				"body: {}",
+				body
+			);
+		}
+
 		// Creating a record with Accept CBOR encoding is allowed
 		{
 			let res = client
@@ -1074,6 +1403,32 @@ mod http_integration {
 			);
 		}
 
+		// GET records matching a filter
+		{
+			let res = client
+				.get(format!("{}?default=content&limit=10", url))
+				.basic_auth(USER, Some(PASS))
+				.send()
+				.await?;
+			assert_eq!(res.status(), 200, "body: {}", res.text().await?);
+
+			let body: serde_json::Value = serde_json::from_str(&res.text().await?).unwrap();
+			assert_eq!(body[0]["result"].as_array().unwrap().len(), 10, "body: {}", body);
+		}
+
+		// GET records not matching a filter
+		{
+			let res = client
+				.get(format!("{}?default=other", url))
+				.basic_auth(USER, Some(PASS))
+				.send()
+				.await?;
+			assert_eq!(res.status(), 200, "body: {}", res.text().await?);
+
+			let body: serde_json::Value = serde_json::from_str(&res.text().await?).unwrap();
+			assert_eq!(body[0]["result"].as_array().unwrap().len(), 0, "body: {}", body);
+		}
+
 		// GET without authentication returns no records
 		{
 			let res = client.get(url).send().await?;